@@ -10,6 +10,7 @@ import (
 
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/server"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/tracing"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +32,11 @@ var serverCmd = &cobra.Command{
 func runServe(cfg model.Config) error {
 	var servers []server.Server
 
+	shutdownTracing, err := tracing.Init(cfg.Tracing)
+	if err != nil {
+		return err
+	}
+
 	apiOpts := server.TxnAPIServerOpts{
 		ListenPort: cfg.APIServer.Port,
 		Config:     cfg,
@@ -73,6 +79,9 @@ func runServe(cfg model.Config) error {
 			return err
 		}
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error("failed to flush traces: ", err)
+	}
 	log.Info("server shutdown gracefully")
 	return nil
 }
@@ -4,19 +4,25 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/service"
 	"github.com/labstack/echo/v4"
 )
 
 // HealthHandler is the request handler for the health endpoint.
 type HealthHandler interface {
 	Health(c echo.Context) error
+	Live(c echo.Context) error
+	Ready(c echo.Context) error
 }
 
-type healthHandler struct{}
+type healthHandler struct {
+	service service.Health
+}
 
 // NewHealth returns a new instance of the health handler.
-func NewHealth() HealthHandler {
-	return &healthHandler{}
+func NewHealth(s service.Health) HealthHandler {
+	return &healthHandler{service: s}
 }
 
 // @Summary	Health check
@@ -32,3 +38,35 @@ func (t *healthHandler) Health(c echo.Context) error {
 		},
 	})
 }
+
+// @Summary	Liveness probe
+// @Description	Reports that the process is running. It never checks dependencies.
+// @Tags		health
+// @Produce	json
+// @Success	200	{object}	ResponseData{data=time.Time}
+// @Router		/health/live [get]
+func (t *healthHandler) Live(c echo.Context) error {
+	return c.JSON(http.StatusOK, ResponseData{
+		Data: map[string]interface{}{
+			"status":    model.HealthUp,
+			"timestamp": time.Now(),
+		},
+	})
+}
+
+// @Summary	Readiness probe
+// @Description	Checks Postgres and pending migrations.
+// @Description	Returns 200 when up or degraded and 503 otherwise.
+// @Tags		health
+// @Produce	json
+// @Success	200	{object}	ResponseData{data=model.Readiness}
+// @Failure	503	{object}	ResponseData{data=model.Readiness}
+// @Router		/health/ready [get]
+func (t *healthHandler) Ready(c echo.Context) error {
+	readiness := t.service.Readiness(c.Request().Context())
+	status := http.StatusOK
+	if readiness.Status == model.HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, ResponseData{Data: readiness})
+}
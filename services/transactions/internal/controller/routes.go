@@ -9,5 +9,22 @@ func InitRoutes(api *echo.Group, controller TransactionHandler) {
 	{
 		transactions.POST("", controller.CreateTransactionPair)
 		transactions.GET("/:subject_wallet_id", controller.GetTransactions)
+		transactions.GET("/:subject_wallet_id/entries", controller.GetTransactionPage)
+	}
+
+	settlements := api.Group("/settlements")
+	{
+		settlements.GET("/unsettled", controller.GetUnsettledTransactions)
+		settlements.PUT("/:settlement_id", controller.SettleTransactions)
+	}
+
+	activity := api.Group("/activity")
+	{
+		activity.GET("", controller.GetLastActivity)
+	}
+
+	balances := api.Group("/balances")
+	{
+		balances.GET("", controller.GetBalances)
 	}
 }
@@ -31,8 +31,15 @@ func TestRegister(t *testing.T) {
 		expectedCode int
 	}{
 		{"Health_Check", http.MethodGet, "/api/v1/health", http.StatusOK},
+		{"Liveness_Check", http.MethodGet, "/api/v1/health/live", http.StatusOK},
+		{"Readiness_Check", http.MethodGet, "/api/v1/health/ready", http.StatusOK},
 		{"Create_Transaction_without_body", http.MethodPost, "/api/v1/transactions", http.StatusBadRequest},          // Assuming no body is sent, should return BadRequest
 		{"Get_non-existent_Transactions", http.MethodGet, "/api/v1/transactions/non-existent-wallet", http.StatusOK}, // Should return empty array
+		{"Page_of_non-existent_Transactions", http.MethodGet, "/api/v1/transactions/non-existent-wallet/entries", http.StatusOK},
+		{"Unsettled_without_wallets", http.MethodGet, "/api/v1/settlements/unsettled", http.StatusBadRequest},
+		{"Settle_without_body", http.MethodPut, "/api/v1/settlements/stl-1", http.StatusBadRequest},
+		{"Activity_without_wallets", http.MethodGet, "/api/v1/activity", http.StatusBadRequest},
+		{"Balances_without_time", http.MethodGet, "/api/v1/balances", http.StatusBadRequest},
 	}
 
 	for _, tt := range tests {
@@ -54,8 +61,10 @@ func setupTestRoutes(e *echo.Echo, db *gorm.DB) {
 	api := e.Group("/api/v1")
 
 	// Register health check endpoint
-	healthHandler := NewHealth()
+	healthHandler := NewHealth(service.NewHealthService(0))
 	api.GET("/health", healthHandler.Health)
+	api.GET("/health/live", healthHandler.Live)
+	api.GET("/health/ready", healthHandler.Ready)
 
 	// Initialize transaction handler with dependencies
 	transactionRepo := repository.NewTransactionRepository(db)
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/errors"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
@@ -13,6 +14,11 @@ import (
 type TransactionHandler interface {
 	CreateTransactionPair(c echo.Context) error
 	GetTransactions(c echo.Context) error
+	GetTransactionPage(c echo.Context) error
+	GetUnsettledTransactions(c echo.Context) error
+	SettleTransactions(c echo.Context) error
+	GetLastActivity(c echo.Context) error
+	GetBalances(c echo.Context) error
 }
 
 type transactionHandler struct {
@@ -29,6 +35,8 @@ func NewTransactionHandler(s service.TransactionService) TransactionHandler {
 type TransactionPairRequest struct {
 	DebitTransaction  TransactionRequest `json:"debit_transaction" validate:"required"`
 	CreditTransaction TransactionRequest `json:"credit_transaction" validate:"required"`
+	// IdempotencyKey makes a retried request write the pair only once
+	IdempotencyKey string `json:"idempotency_key,omitempty" validate:"max=64"`
 }
 
 // TransactionRequest represents a single transaction in the request
@@ -39,6 +47,8 @@ type TransactionRequest struct {
 	OperationType   model.OperationType     `json:"operation_type" validate:"required"`
 	Amount          int64                   `json:"amount" validate:"required,gt=0"`
 	Status          model.TransactionStatus `json:"status" validate:"required"`
+	GroupID         string                  `json:"group_id,omitempty" validate:"max=64"`
+	ActorUserID     string                  `json:"actor_user_id,omitempty" validate:"max=255"`
 }
 
 // GetTransactionsRequest represents the request for getting transactions
@@ -46,6 +56,40 @@ type GetTransactionsRequest struct {
 	SubjectWalletID string `param:"subject_wallet_id" validate:"required"`
 }
 
+// TransactionPageRequest represents the request for a page of the transactions of a wallet
+type TransactionPageRequest struct {
+	SubjectWalletID string `param:"subject_wallet_id" validate:"required"`
+	After           string `query:"after"` // RFC 3339 time, exclusive; from the first entry if empty
+	AfterID         int    `query:"after_id" validate:"gte=0"`
+	Before          string `query:"before"` // RFC 3339 time, exclusive; up to the last entry if empty
+	Limit           int    `query:"limit" validate:"omitempty,gt=0,lte=1000"`
+}
+
+// defaultPageLimit is the number of entries in a page when no limit is given.
+const defaultPageLimit = 100
+
+// UnsettledTransactionsRequest represents the request for the provider entries still to be settled
+type UnsettledTransactionsRequest struct {
+	SubjectWalletIDs []string `query:"subject_wallet_id" validate:"required,min=1,dive,required"`
+	Before           string   `query:"before" validate:"required"` // RFC 3339 cutoff, exclusive
+}
+
+// SettleTransactionsRequest represents the request for marking entries as settled
+type SettleTransactionsRequest struct {
+	SettlementID   string `param:"settlement_id" validate:"required,max=64"`
+	TransactionIDs []int  `json:"transaction_ids" validate:"required,min=1,dive,gt=0"`
+}
+
+// LastActivityRequest represents the request for the latest activity of wallets
+type LastActivityRequest struct {
+	SubjectWalletIDs []string `query:"subject_wallet_id" validate:"required,min=1,max=500,dive,required"`
+}
+
+// BalancesRequest represents the request for the ledger balances of every wallet
+type BalancesRequest struct {
+	At string `query:"at" validate:"required"` // RFC 3339 time, inclusive
+}
+
 // @Summary	Create a transaction pair (debit and credit)
 // @Tags		transactions
 // @Accept		json
@@ -70,6 +114,9 @@ func (h *transactionHandler) CreateTransactionPair(c echo.Context) error {
 		OperationType:   req.DebitTransaction.OperationType,
 		Amount:          req.DebitTransaction.Amount,
 		Status:          req.DebitTransaction.Status,
+		GroupID:         req.DebitTransaction.GroupID,
+		ActorUserID:     req.DebitTransaction.ActorUserID,
+		IdempotencyKey:  req.IdempotencyKey,
 	}
 
 	creditTxn := &model.Transaction{
@@ -79,10 +126,13 @@ func (h *transactionHandler) CreateTransactionPair(c echo.Context) error {
 		OperationType:   req.CreditTransaction.OperationType,
 		Amount:          req.CreditTransaction.Amount,
 		Status:          req.CreditTransaction.Status,
+		GroupID:         req.CreditTransaction.GroupID,
+		ActorUserID:     req.CreditTransaction.ActorUserID,
+		IdempotencyKey:  req.IdempotencyKey,
 	}
 
 	// Create transaction pair
-	if err := h.service.CreateTransactionPair(debitTxn, creditTxn); err != nil {
+	if err := h.service.CreateTransactionPair(c.Request().Context(), debitTxn, creditTxn); err != nil {
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
@@ -105,7 +155,54 @@ func (h *transactionHandler) GetTransactions(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	transactions, err := h.service.GetTransactions(req.SubjectWalletID)
+	transactions, err := h.service.GetTransactions(c.Request().Context(), req.SubjectWalletID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: transactions})
+}
+
+// @Summary	Get a page of the transactions of a wallet
+// @Tags		transactions
+// @Produce	json
+// @Description	Returns the entries of a wallet oldest first, by creation time then ID. To get the next page, pass the creation time and ID of the last entry as after and after_id; a page shorter than the limit is the last one.
+// @Param		subject_wallet_id	path		string	true	"Subject Wallet ID"
+// @Param		after				query		string	false	"RFC 3339 time, exclusive"
+// @Param		after_id			query		int		false	"Entries created at exactly after are included if their ID is above this"
+// @Param		before				query		string	false	"RFC 3339 time, exclusive"
+// @Param		limit				query		int		false	"Page size, at most 1000; 100 by default"
+// @Success	200					{object}	ResponseData{data=[]model.Transaction}
+// @Failure	400					{object}	ResponseError
+// @Failure	500					{object}	ResponseError
+// @Router		/transactions/{subject_wallet_id}/entries [get]
+func (h *transactionHandler) GetTransactionPage(c echo.Context) error {
+	var req TransactionPageRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	page := model.TransactionPage{AfterID: req.AfterID, Limit: req.Limit}
+	if page.Limit == 0 {
+		page.Limit = defaultPageLimit
+	}
+	var err error
+	if req.After != "" {
+		if page.After, err = time.Parse(time.RFC3339, req.After); err != nil {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "after must be an RFC 3339 timestamp"}}})
+		}
+	}
+	if req.Before != "" {
+		if page.Before, err = time.Parse(time.RFC3339, req.Before); err != nil {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "before must be an RFC 3339 timestamp"}}})
+		}
+	}
+
+	transactions, err := h.service.GetTransactionPage(c.Request().Context(), req.SubjectWalletID, page)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
@@ -113,3 +210,121 @@ func (h *transactionHandler) GetTransactions(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, ResponseData{Data: transactions})
 }
+
+// @Summary	Get the deposit and withdrawal entries of provider wallets not settled yet
+// @Tags		settlements
+// @Produce	json
+// @Param		subject_wallet_id	query		[]string	true	"Provider wallet IDs"
+// @Param		before				query		string		true	"RFC 3339 cutoff, exclusive"
+// @Success	200					{object}	ResponseData{data=[]model.Transaction}
+// @Failure	400					{object}	ResponseError
+// @Failure	500					{object}	ResponseError
+// @Router		/settlements/unsettled [get]
+func (h *transactionHandler) GetUnsettledTransactions(c echo.Context) error {
+	var req UnsettledTransactionsRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	before, err := time.Parse(time.RFC3339, req.Before)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "before must be an RFC 3339 timestamp"}}})
+	}
+
+	transactions, err := h.service.GetUnsettledTransactions(c.Request().Context(), req.SubjectWalletIDs, before)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: transactions})
+}
+
+// @Summary	Mark entries as settled in a settlement
+// @Tags		settlements
+// @Accept		json
+// @Produce	json
+// @Param		settlement_id	path		string						true	"Settlement ID"
+// @Param		request			body		SettleTransactionsRequest	true	"Settled entries"
+// @Success	200				{object}	ResponseData{data=string}
+// @Failure	400				{object}	ResponseError
+// @Failure	409				{object}	ResponseError
+// @Failure	500				{object}	ResponseError
+// @Router		/settlements/{settlement_id} [put]
+func (h *transactionHandler) SettleTransactions(c echo.Context) error {
+	var req SettleTransactionsRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	err := h.service.SettleTransactions(c.Request().Context(), req.SettlementID, req.TransactionIDs)
+	if err == model.ErrAlreadySettled {
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Some transactions do not exist or were settled in another settlement"}}})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: "Transactions settled successfully"})
+}
+
+// @Summary	Get the latest activity of wallets
+// @Tags		activity
+// @Produce	json
+// @Description	Returns the time of the latest entry of each wallet that has one. Dormancy fees and escheatments are not activity.
+// @Param		subject_wallet_id	query		[]string	true	"Wallet IDs, at most 500"
+// @Success	200					{object}	ResponseData{data=[]model.WalletActivity}
+// @Failure	400					{object}	ResponseError
+// @Failure	500					{object}	ResponseError
+// @Router		/activity [get]
+func (h *transactionHandler) GetLastActivity(c echo.Context) error {
+	var req LastActivityRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	activity, err := h.service.GetLastActivity(c.Request().Context(), req.SubjectWalletIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: activity})
+}
+
+// @Summary	Get the ledger balance of every wallet at a point in time
+// @Tags		balances
+// @Produce	json
+// @Description	Returns, for every wallet with completed entries created at or before the given time, its credits minus its debits.
+// @Param		at	query		string	true	"RFC 3339 time, inclusive"
+// @Success	200	{object}	ResponseData{data=[]model.LedgerBalance}
+// @Failure	400	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/balances [get]
+func (h *transactionHandler) GetBalances(c echo.Context) error {
+	var req BalancesRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	at, err := time.Parse(time.RFC3339, req.At)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "at must be an RFC 3339 timestamp"}}})
+	}
+
+	balances, err := h.service.GetBalances(c.Request().Context(), at)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: balances})
+}
@@ -2,9 +2,14 @@ package controller
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/db"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
@@ -55,6 +60,38 @@ func TestTransactionHandler_CreateTransactionPair(t *testing.T) {
 				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
 			},
 		},
+		{
+			name:       "successful_grouped_transaction_pair",
+			createBody: `{"debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-003","transaction_type":"transfer","operation_type":"debit","amount":500,"status":"completed","group_id":"split-group-1"},"credit_transaction":{"subject_wallet_id":"user-003","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":500,"status":"completed","group_id":"split-group-1"}}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
+			},
+		},
+		{
+			name:       "successful_member_transaction_pair",
+			createBody: `{"debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":700,"status":"completed","actor_user_id":"user-004"},"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":700,"status":"completed","actor_user_id":"user-004"}}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
+			},
+		},
+		{
+			name:       "successful_keyed_transaction_pair",
+			createBody: `{"debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":300,"status":"completed"},"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":300,"status":"completed"},"idempotency_key":"pair-key-1"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
+			},
+		},
+		{
+			name:       "retried_keyed_transaction_pair_is_skipped",
+			createBody: `{"debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":300,"status":"completed"},"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":300,"status":"completed"},"idempotency_key":"pair-key-1"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
+			},
+		},
 		{
 			name:       "missing_debit_transaction",
 			createBody: `{"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":1000,"status":"completed"}}`,
@@ -208,6 +245,376 @@ func TestTransactionHandler_GetTransactions(t *testing.T) {
 	}
 }
 
+func TestTransactionHandler_GetUnsettledTransactions(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewTransactionRepository(dbInstance)
+	service := service.NewTransactionService(repository)
+	handler := NewTransactionHandler(service)
+
+	clearDB(dbInstance, model.Transaction{})
+	createTestTransaction(t, dbInstance, model.DepositProviderID, "user-001", model.Deposit, model.Debit, 5000)
+	createTestTransaction(t, dbInstance, model.WithdrawProviderID, "user-001", model.Withdraw, model.Credit, 2000)
+	createTestTransaction(t, dbInstance, "user-001", model.DepositProviderID, model.Deposit, model.Credit, 5000)
+	createTestTransaction(t, dbInstance, model.DepositProviderID, "user-002", model.Transfer, model.Debit, 100)
+	require.NoError(t, repository.SettleTransactions(context.Background(), "stl-1", []int{settledID(t, dbInstance)}, time.Now()))
+
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		wantIDs    int
+	}{
+		{
+			name:       "both_providers",
+			query:      "?subject_wallet_id=" + model.DepositProviderID + "&subject_wallet_id=" + model.WithdrawProviderID + "&before=" + time.Now().Add(time.Minute).UTC().Format(time.RFC3339),
+			statusCode: http.StatusOK,
+			wantIDs:    1,
+		},
+		{
+			name:       "before_every_entry",
+			query:      "?subject_wallet_id=" + model.WithdrawProviderID + "&before=2020-01-01T00:00:00Z",
+			statusCode: http.StatusOK,
+			wantIDs:    0,
+		},
+		{
+			name:       "missing_cutoff",
+			query:      "?subject_wallet_id=" + model.DepositProviderID,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid_cutoff",
+			query:      "?subject_wallet_id=" + model.DepositProviderID + "&before=yesterday",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/settlements/unsettled"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/settlements/unsettled")
+
+			require.NoError(t, handler.GetUnsettledTransactions(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var got struct {
+				Data []model.Transaction `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Len(t, got.Data, tt.wantIDs)
+			for _, txn := range got.Data {
+				assert.Equal(t, model.WithdrawProviderID, txn.SubjectWalletID, "settled and non-provider entries are left out")
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_SettleTransactions(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewTransactionRepository(dbInstance)
+	service := service.NewTransactionService(repository)
+	handler := NewTransactionHandler(service)
+
+	tests := []struct {
+		name         string
+		settlementID string
+		settledFirst string
+		missingID    bool
+		statusCode   int
+		wantSettled  string
+	}{
+		{
+			name:         "successful_settlement",
+			settlementID: "stl-1",
+			statusCode:   http.StatusOK,
+			wantSettled:  "stl-1",
+		},
+		{
+			name:         "retried_settlement",
+			settlementID: "stl-1",
+			settledFirst: "stl-1",
+			statusCode:   http.StatusOK,
+			wantSettled:  "stl-1",
+		},
+		{
+			name:         "settled_in_another_settlement",
+			settlementID: "stl-2",
+			settledFirst: "stl-1",
+			statusCode:   http.StatusConflict,
+			wantSettled:  "stl-1",
+		},
+		{
+			name:         "unknown_transaction",
+			settlementID: "stl-1",
+			missingID:    true,
+			statusCode:   http.StatusConflict,
+			wantSettled:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.Transaction{})
+			createTestTransaction(t, dbInstance, model.DepositProviderID, "user-001", model.Deposit, model.Debit, 5000)
+			id := settledID(t, dbInstance)
+			if tt.settledFirst != "" {
+				require.NoError(t, repository.SettleTransactions(context.Background(), tt.settledFirst, []int{id}, time.Now()))
+			}
+			ids := strconv.Itoa(id)
+			if tt.missingID {
+				ids += "," + strconv.Itoa(id+1000)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/settlements/"+tt.settlementID, bytes.NewReader([]byte(`{"transaction_ids":[`+ids+`]}`)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/settlements/:settlement_id")
+			c.SetParamNames("settlement_id")
+			c.SetParamValues(tt.settlementID)
+
+			require.NoError(t, handler.SettleTransactions(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			var stored model.Transaction
+			require.NoError(t, dbInstance.Take(&stored, id).Error)
+			assert.Equal(t, tt.wantSettled, stored.SettlementID)
+		})
+	}
+}
+
+func TestTransactionHandler_GetLastActivity(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewTransactionHandler(service.NewTransactionService(repository.NewTransactionRepository(dbInstance)))
+
+	clearDB(dbInstance, model.Transaction{})
+	createTestTransaction(t, dbInstance, "user-001", model.DepositProviderID, model.Deposit, model.Credit, 5000)
+	require.NoError(t, dbInstance.Model(&model.Transaction{}).Where("subject_wallet_id = ?", "user-001").
+		Update("created_at", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)).Error)
+	createTestTransaction(t, dbInstance, "user-001", model.WithdrawProviderID, model.DormancyFee, model.Debit, 100)
+	createTestTransaction(t, dbInstance, "user-002", "user-001", model.Transfer, model.Debit, 100)
+
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		want       map[string]time.Time
+	}{
+		{
+			name:       "dormancy_fee_is_not_activity",
+			query:      "?subject_wallet_id=user-001&subject_wallet_id=user-003",
+			statusCode: http.StatusOK,
+			want:       map[string]time.Time{"user-001": time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:       "missing_wallets",
+			query:      "",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/activity"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/activity")
+
+			require.NoError(t, handler.GetLastActivity(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var got struct {
+				Data []model.WalletActivity `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			require.Len(t, got.Data, len(tt.want))
+			for _, activity := range got.Data {
+				assert.True(t, tt.want[activity.SubjectWalletID].Equal(activity.LastActivityAt), activity.SubjectWalletID)
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_GetTransactionPage(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewTransactionHandler(service.NewTransactionService(repository.NewTransactionRepository(dbInstance)))
+
+	// Three entries, the first two created at the same time
+	clearDB(dbInstance, model.Transaction{})
+	createTestTransaction(t, dbInstance, "user-001", model.DepositProviderID, model.Deposit, model.Credit, 100)
+	createTestTransaction(t, dbInstance, "user-001", model.DepositProviderID, model.Deposit, model.Credit, 200)
+	createTestTransaction(t, dbInstance, "user-001", model.DepositProviderID, model.Deposit, model.Credit, 300)
+	var entries []model.Transaction
+	require.NoError(t, dbInstance.Order("id").Find(&entries).Error)
+	sameTime := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, dbInstance.Model(&model.Transaction{}).Where("id IN ?", []int{entries[0].ID, entries[1].ID}).
+		Update("created_at", sameTime).Error)
+	require.NoError(t, dbInstance.Model(&model.Transaction{}).Where("id = ?", entries[2].ID).
+		Update("created_at", sameTime.Add(time.Hour)).Error)
+
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		want       []int64
+	}{
+		{
+			name:       "first_page",
+			query:      "?limit=2",
+			statusCode: http.StatusOK,
+			want:       []int64{100, 200},
+		},
+		{
+			name:       "next_page_after_tie",
+			query:      fmt.Sprintf("?limit=2&after=%s&after_id=%d", sameTime.Format(time.RFC3339), entries[0].ID),
+			statusCode: http.StatusOK,
+			want:       []int64{200, 300},
+		},
+		{
+			name:       "before_time",
+			query:      "?before=" + sameTime.Add(time.Minute).Format(time.RFC3339),
+			statusCode: http.StatusOK,
+			want:       []int64{100, 200},
+		},
+		{
+			name:       "invalid_after",
+			query:      "?after=yesterday",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "limit_too_large",
+			query:      "?limit=5000",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/transactions/user-001/entries"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/transactions/:subject_wallet_id/entries")
+			c.SetParamNames("subject_wallet_id")
+			c.SetParamValues("user-001")
+
+			require.NoError(t, handler.GetTransactionPage(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var got struct {
+				Data []model.Transaction `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			amounts := []int64{}
+			for _, txn := range got.Data {
+				amounts = append(amounts, txn.Amount)
+			}
+			assert.Equal(t, tt.want, amounts)
+		})
+	}
+}
+
+func TestTransactionHandler_GetBalances(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewTransactionHandler(service.NewTransactionService(repository.NewTransactionRepository(dbInstance)))
+
+	clearDB(dbInstance, model.Transaction{})
+	createTestTransaction(t, dbInstance, "user-001", model.DepositProviderID, model.Deposit, model.Credit, 5000)
+	createTestTransaction(t, dbInstance, "user-001", "user-002", model.Transfer, model.Debit, 1200)
+	require.NoError(t, dbInstance.Model(&model.Transaction{}).Where("subject_wallet_id = ?", "user-001").
+		Update("created_at", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)).Error)
+	createTestTransaction(t, dbInstance, "user-001", model.WithdrawProviderID, model.Withdraw, model.Debit, 300)
+
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		want       map[string]int64
+	}{
+		{
+			name:       "entries_up_to_time",
+			query:      "?at=2024-05-01T00:00:00Z",
+			statusCode: http.StatusOK,
+			want:       map[string]int64{"user-001": 3800},
+		},
+		{
+			name:       "before_first_entry",
+			query:      "?at=2024-04-30T00:00:00Z",
+			statusCode: http.StatusOK,
+			want:       map[string]int64{},
+		},
+		{
+			name:       "missing_time",
+			query:      "",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid_time",
+			query:      "?at=yesterday",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/balances"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/balances")
+
+			require.NoError(t, handler.GetBalances(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var got struct {
+				Data []model.LedgerBalance `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			balances := map[string]int64{}
+			for _, balance := range got.Data {
+				balances[balance.SubjectWalletID] = balance.Balance
+			}
+			assert.Equal(t, tt.want, balances)
+		})
+	}
+}
+
 // Helper functions
 func clearDB(db *gorm.DB, models ...interface{}) {
 	for _, model := range models {
@@ -221,3 +628,10 @@ func createTestTransaction(t *testing.T, db *gorm.DB, subjectWalletID, objectWal
 	err := db.Create(txn).Error
 	require.NoError(t, err)
 }
+
+// settledID returns the ID of the first transaction, the one settled by the tests.
+func settledID(t *testing.T, db *gorm.DB) int {
+	var txn model.Transaction
+	require.NoError(t, db.Order("id").Take(&txn).Error)
+	return txn.ID
+}
@@ -12,11 +12,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// models lists every GORM model whose schema is managed by Migrate
+var models = []interface{}{
+	&model.Transaction{},
+}
+
 // Migrate runs the complete migration process for the database
 // It performs DDL migrations (schema) followed by DML migrations (data)
 func Migrate(db *gorm.DB) error {
 	// Step 1: Run GORM auto-migration for schema creation
-	if err := db.AutoMigrate(&model.Transaction{}); err != nil {
+	if err := db.AutoMigrate(models...); err != nil {
 		fmt.Printf("ERROR: Auto-migration failed: %v\n", err)
 		return fmt.Errorf("failed to run auto-migration: %w", err)
 	}
@@ -96,3 +101,31 @@ func executeSQLFile(db *gorm.DB, filePath string) error {
 	fmt.Printf("Successfully executed migration: %s\n", filePath)
 	return nil
 }
+
+// PendingMigrations reports the tables and columns of the managed models that
+// are missing from the database, i.e. the schema changes Migrate would apply
+func PendingMigrations(db *gorm.DB) ([]string, error) {
+	var pending []string
+	migrator := db.Migrator()
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return nil, fmt.Errorf("failed to parse model schema: %w", err)
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(m) {
+			pending = append(pending, fmt.Sprintf("create table %s", table))
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !migrator.HasColumn(m, field.DBName) {
+				pending = append(pending, fmt.Sprintf("add column %s.%s", table, field.DBName))
+			}
+		}
+	}
+	return pending, nil
+}
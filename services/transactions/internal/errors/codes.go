@@ -10,4 +10,6 @@ const (
 	CodeNotFound = "NOT_FOUND"
 	// CodeBadRequest is a generic error message returned when the request is bad.
 	CodeBadRequest = "BAD_REQUEST"
+	// CodeConflict is returned when the request conflicts with the current state of the resource.
+	CodeConflict = "CONFLICT"
 )
@@ -0,0 +1,104 @@
+// Package metrics provides the Prometheus collectors exposed on /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "transactions"
+
+// Outcome label values for ledger writes.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	ledgerPairs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ledger_pairs_total",
+		Help:      "Number of debit/credit pairs written by transaction type and outcome.",
+	}, []string{"transaction_type", "outcome"})
+)
+
+// Middleware records request count and latency for every route.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			status := c.Response().Status
+			if err != nil {
+				var httpErr *echo.HTTPError
+				if errors.As(err, &httpErr) {
+					status = httpErr.Code
+				} else {
+					status = http.StatusInternalServerError
+				}
+			}
+			// c.Path() is the registered route pattern, which keeps label cardinality bounded.
+			labels := []string{c.Request().Method, c.Path(), strconv.Itoa(status)}
+			httpRequests.WithLabelValues(labels...).Inc()
+			httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// Handler returns the HTTP handler serving the default Prometheus registry.
+func Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.Handler())
+}
+
+var (
+	dbStatsMu        sync.Mutex
+	dbStatsCollector prometheus.Collector
+)
+
+// RegisterDBStats exposes connection pool statistics for db, replacing any
+// previously registered pool.
+func RegisterDBStats(db *sql.DB) error {
+	dbStatsMu.Lock()
+	defer dbStatsMu.Unlock()
+
+	if dbStatsCollector != nil {
+		prometheus.Unregister(dbStatsCollector)
+	}
+	dbStatsCollector = collectors.NewDBStatsCollector(db, namespace)
+	return prometheus.Register(dbStatsCollector)
+}
+
+// ObserveLedgerPair records the outcome of writing a debit/credit pair.
+func ObserveLedgerPair(txnType model.TransactionType, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	ledgerPairs.WithLabelValues(string(txnType), outcome).Inc()
+}
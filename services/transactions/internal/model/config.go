@@ -6,6 +6,7 @@ type Config struct {
 	APIServer     Server
 	SwaggerServer Server
 	PostgreSQL    PostgreSQL
+	Tracing       Tracing
 }
 
 // Server is the configuration for the server.
@@ -23,3 +24,21 @@ type PostgreSQL struct {
 	DBName   string `validate:"required"`
 	SSLMode  string `validate:"required"`
 }
+
+// Trace exporter names accepted by Tracing.Exporter.
+const (
+	// TraceExporterStdout writes spans as JSON to standard output.
+	TraceExporterStdout = "stdout"
+	// TraceExporterFile appends spans as JSON to Tracing.FilePath.
+	TraceExporterFile = "file"
+)
+
+// Tracing is the configuration for OpenTelemetry tracing.
+type Tracing struct {
+	Enable      bool
+	Exporter    string `validate:"omitempty,oneof=stdout file"`
+	FilePath    string `validate:"required_if=Exporter file"`
+	ServiceName string
+	// SampleRatio is the fraction of new traces that are sampled; 0 means all.
+	SampleRatio float64 `validate:"gte=0,lte=1"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// HealthStatus is the aggregated or per-component health state.
+type HealthStatus string
+
+const (
+	// HealthUp means every dependency is reachable.
+	HealthUp = HealthStatus("up")
+	// HealthDegraded means only non-critical dependencies are failing.
+	HealthDegraded = HealthStatus("degraded")
+	// HealthDown means at least one critical dependency is failing.
+	HealthDown = HealthStatus("down")
+)
+
+// ComponentHealth is the result of probing a single dependency.
+type ComponentHealth struct {
+	Status    HealthStatus `json:"status"`
+	Critical  bool         `json:"critical"`
+	LatencyMS int64        `json:"latency_ms"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// Readiness is the aggregated result of all dependency probes.
+type Readiness struct {
+	Status     HealthStatus               `json:"status"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+	Components map[string]ComponentHealth `json:"components"`
+}
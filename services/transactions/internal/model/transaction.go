@@ -1,11 +1,16 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
+// ErrAlreadySettled is the error for settling a transaction already included
+// in another settlement.
+var ErrAlreadySettled = fmt.Errorf("transaction already settled")
+
 // Transaction represents a wallet transaction
 type Transaction struct {
 	ID              int               `gorm:"primaryKey" json:"id"`
@@ -15,10 +20,43 @@ type Transaction struct {
 	OperationType   OperationType     `gorm:"not null" json:"operation_type"`
 	Amount          int64             `gorm:"not null" json:"amount"` // Amount in cents
 	Status          TransactionStatus `gorm:"default:'pending'" json:"status"`
+	GroupID         string            `gorm:"not null;default:''" json:"group_id,omitempty"`      // Shared by the entries of one payment to several wallets
+	ActorUserID     string            `gorm:"not null;default:''" json:"actor_user_id,omitempty"` // Member who acted on a shared wallet; empty when the holder did
+	SettlementID    string            `gorm:"not null;default:''" json:"settlement_id,omitempty"` // Provider settlement batch the entry was settled in; empty until then
+	SettledAt       *time.Time        `json:"settled_at,omitempty"`
+	IdempotencyKey  string            `gorm:"not null;default:''" json:"-"` // Key of the request that wrote the pair, shared by both entries
 	CreatedAt       time.Time         `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+// WalletActivity is the time of the latest entry of a wallet initiated by or
+// for its holder; dormancy fees and escheatments do not count.
+type WalletActivity struct {
+	SubjectWalletID string    `json:"subject_wallet_id"`
+	LastActivityAt  time.Time `json:"last_activity_at"`
+}
+
+// TransactionPage selects a page of the entries of a wallet, oldest first:
+// those created after After and, if set, before Before. Entries created at
+// exactly After are included if their ID is above AfterID, so the creation
+// time and ID of the last entry of a page select the next one.
+type TransactionPage struct {
+	After   time.Time
+	AfterID int
+	Before  time.Time
+	Limit   int
+}
+
+// LedgerBalance is the sum of the completed entries of a wallet, credits
+// minus debits, up to a point in time.
+type LedgerBalance struct {
+	SubjectWalletID string `json:"subject_wallet_id"`
+	Balance         int64  `json:"balance"` // Balance in cents
+}
+
+// DormancyTypes are the transaction types that are not activity of a wallet.
+var DormancyTypes = []TransactionType{DormancyFee, Escheatment}
+
 // NewTransaction returns a new instance of the Transaction model.
 func NewTransaction(subjectWalletID, objectWalletID string, transactionType TransactionType, operationType OperationType, amount int64) *Transaction {
 	return &Transaction{
@@ -61,6 +99,31 @@ const (
 	Withdraw = TransactionType("withdraw")
 	// Transfer transaction type
 	Transfer = TransactionType("transfer")
+	// EscrowFund transaction type, moving funds from the buyer into escrow
+	EscrowFund = TransactionType("escrow_fund")
+	// EscrowRelease transaction type, paying escrowed funds out to the seller
+	EscrowRelease = TransactionType("escrow_release")
+	// EscrowRefund transaction type, returning escrowed funds to the buyer
+	EscrowRefund = TransactionType("escrow_refund")
+	// PocketMove transaction type, moving funds between a user's wallet and
+	// its pockets. It is not a transfer between users, so transfer limits do
+	// not apply to it.
+	PocketMove = TransactionType("pocket_move")
+	// Payment transaction type, a customer paying a merchant's payment intent
+	Payment = TransactionType("payment")
+	// Refund transaction type, a merchant returning part or all of a payment
+	Refund = TransactionType("refund")
+	// TopUp transaction type, an operator adding float to a provider wallet
+	// from the provider's external bank account
+	TopUp = TransactionType("top_up")
+	// DormancyFee transaction type, a fee charged to a wallet when it becomes dormant
+	DormancyFee = TransactionType("dormancy_fee")
+	// Escheatment transaction type, the balance of a dormant wallet handed
+	// over to a provider wallet as unclaimed property
+	Escheatment = TransactionType("escheatment")
+	// Adjustment transaction type, a manual correction of a wallet's balance
+	// by an operator against the adjustments provider wallet
+	Adjustment = TransactionType("adjustment")
 )
 
 // TransactionStatus represents the status of a transaction
@@ -83,7 +146,11 @@ func IsValidTransactionType(fl validator.FieldLevel) bool {
 		return true
 	}
 	txnType := fl.Field().Interface().(TransactionType)
-	return txnType == Deposit || txnType == Withdraw || txnType == Transfer
+	switch txnType {
+	case Deposit, Withdraw, Transfer, EscrowFund, EscrowRelease, EscrowRefund, PocketMove, Payment, Refund, TopUp, DormancyFee, Escheatment, Adjustment:
+		return true
+	}
+	return false
 }
 
 // IsValidTransactionStatus checks if the transaction status is valid
@@ -1,14 +1,23 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TransactionRepository provides database operations for transactions
 type TransactionRepository interface {
-	CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error
-	FindAllTransactions(filters map[string]interface{}) ([]model.Transaction, error)
+	CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) error
+	FindAllTransactions(ctx context.Context, filters map[string]interface{}) ([]model.Transaction, error)
+	FindTransactionPage(ctx context.Context, subjectWalletID string, page model.TransactionPage) ([]model.Transaction, error)
+	FindUnsettledTransactions(ctx context.Context, subjectWalletIDs []string, before time.Time) ([]model.Transaction, error)
+	SettleTransactions(ctx context.Context, settlementID string, ids []int, at time.Time) error
+	FindLastActivity(ctx context.Context, subjectWalletIDs []string) ([]model.WalletActivity, error)
+	SumBalances(ctx context.Context, at time.Time) ([]model.LedgerBalance, error)
 }
 
 type transactionRepository struct {
@@ -20,10 +29,11 @@ func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 	return &transactionRepository{db: db}
 }
 
-// CreateTransactionPair creates both debit and credit transactions atomically
-func (r *transactionRepository) CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error {
+// CreateTransactionPair creates both debit and credit transactions atomically.
+// A pair with the idempotency key of a pair already written is skipped.
+func (r *transactionRepository) CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) error {
 	// Begin database transaction
-	tx := r.db.Begin()
+	tx := r.db.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -35,10 +45,15 @@ func (r *transactionRepository) CreateTransactionPair(debitTxn, creditTxn *model
 	}
 
 	// Insert debit transaction
-	if err := tx.Create(debitTxn).Error; err != nil {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(debitTxn)
+	if err := result.Error; err != nil {
 		tx.Rollback()
 		return err
 	}
+	if result.RowsAffected == 0 {
+		// Only the idempotency key index can conflict: the pair is a retry
+		return tx.Rollback().Error
+	}
 
 	// Insert credit transaction
 	if err := tx.Create(creditTxn).Error; err != nil {
@@ -51,9 +66,9 @@ func (r *transactionRepository) CreateTransactionPair(debitTxn, creditTxn *model
 }
 
 // FindAllTransactions retrieves transactions matching the query filters
-func (r *transactionRepository) FindAllTransactions(filters map[string]interface{}) ([]model.Transaction, error) {
+func (r *transactionRepository) FindAllTransactions(ctx context.Context, filters map[string]interface{}) ([]model.Transaction, error) {
 	var transactions []model.Transaction
-	tx := r.db
+	tx := r.db.WithContext(ctx)
 
 	if len(filters) > 0 {
 		tx = tx.Where(filters)
@@ -66,3 +81,88 @@ func (r *transactionRepository) FindAllTransactions(filters map[string]interface
 
 	return transactions, nil
 }
+
+// FindTransactionPage retrieves a page of the entries of a wallet, in
+// creation time then ID order.
+func (r *transactionRepository) FindTransactionPage(ctx context.Context, subjectWalletID string, page model.TransactionPage) ([]model.Transaction, error) {
+	transactions := []model.Transaction{}
+	query := r.db.WithContext(ctx).
+		Where("subject_wallet_id = ?", subjectWalletID).
+		Where("created_at > ? OR (created_at = ? AND id > ?)", page.After, page.After, page.AfterID)
+	if !page.Before.IsZero() {
+		query = query.Where("created_at < ?", page.Before)
+	}
+	err := query.Order("created_at, id").Limit(page.Limit).Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// FindUnsettledTransactions retrieves the completed deposit and withdrawal
+// entries of the given wallets created before the cutoff and not settled yet,
+// in ID order.
+func (r *transactionRepository) FindUnsettledTransactions(ctx context.Context, subjectWalletIDs []string, before time.Time) ([]model.Transaction, error) {
+	transactions := []model.Transaction{}
+	err := r.db.WithContext(ctx).
+		Where("subject_wallet_id IN ? AND settlement_id = '' AND created_at < ?", subjectWalletIDs, before).
+		Where("transaction_type IN ? AND status = ?", []model.TransactionType{model.Deposit, model.Withdraw}, model.Completed).
+		Order("id").Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// SettleTransactions records that the given entries were settled in a
+// settlement. Either every entry is marked or none is: ErrAlreadySettled is
+// returned if one of them is missing or was settled in another settlement.
+// Settling entries again in the same settlement succeeds, so the call can be
+// retried.
+func (r *transactionRepository) SettleTransactions(ctx context.Context, settlementID string, ids []int, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Transaction{}).
+			Where("id IN ? AND settlement_id IN ?", ids, []string{"", settlementID}).
+			Updates(map[string]interface{}{"settlement_id": settlementID, "settled_at": at})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(ids)) {
+			return model.ErrAlreadySettled
+		}
+		return nil
+	})
+}
+
+// FindLastActivity retrieves the time of the latest entry of each given
+// wallet, ignoring dormancy fees and escheatments. Wallets without entries
+// are left out.
+func (r *transactionRepository) FindLastActivity(ctx context.Context, subjectWalletIDs []string) ([]model.WalletActivity, error) {
+	activity := []model.WalletActivity{}
+	err := r.db.WithContext(ctx).Model(&model.Transaction{}).
+		Select("subject_wallet_id, MAX(created_at) AS last_activity_at").
+		Where("subject_wallet_id IN ? AND transaction_type NOT IN ?", subjectWalletIDs, model.DormancyTypes).
+		Group("subject_wallet_id").
+		Order("subject_wallet_id").
+		Scan(&activity).Error
+	if err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// SumBalances retrieves the balance of every wallet with completed entries
+// created at or before the given time, credits minus debits.
+func (r *transactionRepository) SumBalances(ctx context.Context, at time.Time) ([]model.LedgerBalance, error) {
+	balances := []model.LedgerBalance{}
+	err := r.db.WithContext(ctx).Model(&model.Transaction{}).
+		Select("subject_wallet_id, SUM(CASE WHEN operation_type = ? THEN amount ELSE -amount END) AS balance", model.Credit).
+		Where("status = ? AND created_at <= ?", model.Completed, at).
+		Group("subject_wallet_id").
+		Order("subject_wallet_id").
+		Scan(&balances).Error
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
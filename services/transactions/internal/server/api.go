@@ -2,12 +2,20 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"strings"
+	"time"
+
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/controller"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/repository"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/tracing"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/utils"
 	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/db"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
@@ -34,8 +42,26 @@ func NewAPI(opts TxnAPIServerOpts) (Server, error) {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
+	sqlDB, err := dbInstance.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database handle: %v", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB); err != nil {
+		return nil, fmt.Errorf("failed to register database metrics: %v", err)
+	}
+
 	engine := echo.New()
 
+	// Every request context derives from baseCtx so that shutdown can cancel them.
+	baseCtx, cancel := context.WithCancel(context.Background())
+	engine.Server.BaseContext = func(net.Listener) context.Context { return baseCtx }
+
+	// Start a server span per request, continuing any incoming W3C trace context
+	engine.Use(otelecho.Middleware(tracing.ServiceName(opts.Config.Tracing)))
+
+	// Record request count and latency per route
+	engine.Use(metrics.Middleware())
+
 	// Allow all origins for CORS
 	engine.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
@@ -48,6 +74,7 @@ func NewAPI(opts TxnAPIServerOpts) (Server, error) {
 		engine: engine,
 		log:    logger,
 		db:     dbInstance,
+		cancel: cancel,
 	}
 
 	s.setupRoutes(engine)
@@ -72,15 +99,48 @@ func (s *txnAPIServer) initTransactionController() controller.TransactionHandler
 	return transactionController
 }
 
+// readinessCacheTTL is how long a readiness result is reused before the
+// dependencies are probed again.
+const readinessCacheTTL = 2 * time.Second
+
+// initHealthController creates the health handler with the readiness checks.
+func (s *txnAPIServer) initHealthController() controller.HealthHandler {
+	healthService := service.NewHealthService(readinessCacheTTL,
+		service.Check{Name: "postgres", Critical: true, Probe: func(ctx context.Context) error {
+			sqlDB, err := s.db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}},
+		service.Check{Name: "migrations", Critical: true, Probe: func(ctx context.Context) error {
+			pending, err := db.PendingMigrations(s.db.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending: %s", len(pending), strings.Join(pending, ", "))
+			}
+			return nil
+		}},
+	)
+	return controller.NewHealth(healthService)
+}
+
 // setupRoutes registers the routes for the application.
 func (s *txnAPIServer) setupRoutes(e *echo.Echo) {
 	e.Validator = controller.NewCustomValidator()
 
+	// Prometheus scrape endpoint
+	e.GET("/metrics", metrics.Handler())
+
 	api := e.Group("/api/v1")
 
 	// Health check
-	healthHandler := controller.NewHealth()
+	healthHandler := s.initHealthController()
 	api.GET("/health", healthHandler.Health)
+	api.GET("/health/live", healthHandler.Live)
+	api.GET("/health/ready", healthHandler.Ready)
 
 	transactionHandler := s.initTransactionController()
 
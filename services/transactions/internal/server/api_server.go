@@ -14,6 +14,8 @@ type txnAPIServer struct {
 	engine *echo.Echo
 	log    *log.Entry
 	db     *gorm.DB
+	// cancel aborts the base context shared by all in-flight requests.
+	cancel context.CancelFunc
 }
 
 func (s *txnAPIServer) Name() string {
@@ -29,5 +31,9 @@ func (s *txnAPIServer) Run() error {
 // Shutdown stops the Txn API server
 func (s *txnAPIServer) Shutdown(ctx context.Context) error {
 	log.Infof("shutting down %s serving on port %d", s.Name(), s.port)
-	return s.engine.Shutdown(ctx)
+	err := s.engine.Shutdown(ctx)
+	// Requests still running once the grace period is over have their
+	// contexts cancelled so DB queries and outbound calls stop promptly.
+	s.cancel()
+	return err
 }
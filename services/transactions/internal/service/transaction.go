@@ -1,14 +1,25 @@
 package service
 
 import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // TransactionService provides transaction operations
 type TransactionService interface {
-	CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error
-	GetTransactions(subjectWalletID string) ([]model.Transaction, error)
+	CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) error
+	GetTransactions(ctx context.Context, subjectWalletID string) ([]model.Transaction, error)
+	GetTransactionPage(ctx context.Context, subjectWalletID string, page model.TransactionPage) ([]model.Transaction, error)
+	GetUnsettledTransactions(ctx context.Context, subjectWalletIDs []string, before time.Time) ([]model.Transaction, error)
+	SettleTransactions(ctx context.Context, settlementID string, ids []int) error
+	GetLastActivity(ctx context.Context, subjectWalletIDs []string) ([]model.WalletActivity, error)
+	GetBalances(ctx context.Context, at time.Time) ([]model.LedgerBalance, error)
 }
 
 type transactionService struct {
@@ -21,14 +32,88 @@ func NewTransactionService(repo repository.TransactionRepository) TransactionSer
 }
 
 // CreateTransactionPair creates both debit and credit transactions atomically
-func (s *transactionService) CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error {
-	return s.repo.CreateTransactionPair(debitTxn, creditTxn)
+func (s *transactionService) CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) (err error) {
+	// The customer is on the credit side of deposits, transfers and escrow
+	// payouts and on the debit side of withdrawals and escrow funding; the
+	// other side is a provider, the sender or the escrow wallet.
+	userID := creditTxn.SubjectWalletID
+	if creditTxn.TransactionType == model.Withdraw || creditTxn.TransactionType == model.EscrowFund {
+		userID = debitTxn.SubjectWalletID
+	}
+	ctx, span := tracing.StartSpan(ctx, "service.Transaction.CreateTransactionPair",
+		tracing.AttrUserID.String(userID),
+		tracing.AttrTransactionType.String(string(creditTxn.TransactionType)),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(creditTxn.Amount)),
+	)
+	defer func() {
+		metrics.ObserveLedgerPair(creditTxn.TransactionType, err)
+		tracing.EndSpan(span, err)
+	}()
+
+	return s.repo.CreateTransactionPair(ctx, debitTxn, creditTxn)
 }
 
 // GetTransactions retrieves all transactions for a specific wallet
-func (s *transactionService) GetTransactions(subjectWalletID string) ([]model.Transaction, error) {
+func (s *transactionService) GetTransactions(ctx context.Context, subjectWalletID string) (_ []model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Transaction.GetTransactions",
+		tracing.AttrUserID.String(subjectWalletID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	filters := map[string]interface{}{
 		"subject_wallet_id": subjectWalletID,
 	}
-	return s.repo.FindAllTransactions(filters)
+	return s.repo.FindAllTransactions(ctx, filters)
+}
+
+// GetTransactionPage retrieves a page of the transactions of a wallet, oldest first
+func (s *transactionService) GetTransactionPage(ctx context.Context, subjectWalletID string, page model.TransactionPage) (_ []model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Transaction.GetTransactionPage",
+		tracing.AttrUserID.String(subjectWalletID),
+		attribute.Int("limit", page.Limit),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.repo.FindTransactionPage(ctx, subjectWalletID, page)
+}
+
+// GetUnsettledTransactions retrieves the provider entries still to be settled
+func (s *transactionService) GetUnsettledTransactions(ctx context.Context, subjectWalletIDs []string, before time.Time) (_ []model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Transaction.GetUnsettledTransactions",
+		attribute.StringSlice("subject_wallet_ids", subjectWalletIDs),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.repo.FindUnsettledTransactions(ctx, subjectWalletIDs, before)
+}
+
+// SettleTransactions marks entries as settled in a settlement
+func (s *transactionService) SettleTransactions(ctx context.Context, settlementID string, ids []int) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Transaction.SettleTransactions",
+		attribute.String("settlement_id", settlementID),
+		attribute.Int("transactions", len(ids)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.repo.SettleTransactions(ctx, settlementID, ids, time.Now())
+}
+
+// GetLastActivity retrieves the time of the latest activity of each wallet
+func (s *transactionService) GetLastActivity(ctx context.Context, subjectWalletIDs []string) (_ []model.WalletActivity, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Transaction.GetLastActivity",
+		attribute.Int("wallets", len(subjectWalletIDs)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.repo.FindLastActivity(ctx, subjectWalletIDs)
+}
+
+// GetBalances retrieves the ledger balance of every wallet at a point in time
+func (s *transactionService) GetBalances(ctx context.Context, at time.Time) (_ []model.LedgerBalance, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Transaction.GetBalances",
+		attribute.String("at", at.Format(time.RFC3339)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.repo.SumBalances(ctx, at)
 }
@@ -0,0 +1,134 @@
+// Package tracing provides OpenTelemetry tracing setup and instrumentation helpers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentationName identifies spans created by this service.
+	instrumentationName = "github.com/fardinabir/digital-wallet-demo/services/transactions"
+	// DefaultServiceName is the service.name resource attribute used when none is configured.
+	DefaultServiceName = "transactions"
+)
+
+// Span attribute keys shared across the service.
+const (
+	AttrUserID          = attribute.Key("user_id")
+	AttrTransactionType = attribute.Key("transaction_type")
+	AttrAmountBucket    = attribute.Key("amount_bucket")
+)
+
+// Init configures the global tracer provider and the W3C trace context propagator.
+// The returned function flushes pending spans and must be called on shutdown.
+func Init(cfg model.Tracing) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enable {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var w io.Writer = os.Stdout
+	var file *os.File
+	switch cfg.Exporter {
+	case "", model.TraceExporterStdout:
+	case model.TraceExporterFile:
+		f, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		w, file = f, f
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	sampleRatio := cfg.SampleRatio
+	if sampleRatio <= 0 {
+		sampleRatio = 1
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(ServiceName(cfg)))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if file != nil {
+			if cerr := file.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+// ServiceName returns the configured service name, or DefaultServiceName if unset.
+func ServiceName(cfg model.Tracing) string {
+	if cfg.ServiceName == "" {
+		return DefaultServiceName
+	}
+	return cfg.ServiceName
+}
+
+// Tracer returns the tracer used for spans created by this service.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartSpan starts an internal span as a child of any span in ctx.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records err on span, if any, and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// AmountBucket maps an amount in cents to a coarse, low-cardinality range
+// expressed in major currency units, so amounts can be grouped without being exposed.
+func AmountBucket(amountCents int64) string {
+	switch {
+	case amountCents < 1_00:
+		return "<1"
+	case amountCents < 10_00:
+		return "1-10"
+	case amountCents < 100_00:
+		return "10-100"
+	case amountCents < 1_000_00:
+		return "100-1k"
+	case amountCents < 10_000_00:
+		return "1k-10k"
+	default:
+		return ">=10k"
+	}
+}
@@ -0,0 +1,8 @@
+-- Escrow Transaction Types
+-- Ledger pairs for funds moved into escrow, released to the seller or refunded to the buyer
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('deposit', 'withdraw', 'transfer', 'escrow_fund', 'escrow_release', 'escrow_refund'));
+
+COMMENT ON COLUMN transactions.transaction_type IS 'Type of transaction: deposit, withdraw, transfer, escrow_fund, escrow_release or escrow_refund';
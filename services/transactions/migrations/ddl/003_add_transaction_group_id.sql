@@ -0,0 +1,9 @@
+-- Transaction Groups
+-- Entries of one payment to several wallets, e.g. a split transfer, share a group ID
+-- so history and statements can show them as a single payment
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS group_id VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_group_id ON transactions(group_id) WHERE group_id <> '';
+
+COMMENT ON COLUMN transactions.group_id IS 'Identifier shared by the entries of one payment to several wallets; empty for single payments';
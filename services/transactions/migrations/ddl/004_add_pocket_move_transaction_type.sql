@@ -0,0 +1,8 @@
+-- Pocket Move Transaction Type
+-- Ledger pairs for funds moved between a user's wallet and its pockets
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('deposit', 'withdraw', 'transfer', 'escrow_fund', 'escrow_release', 'escrow_refund', 'pocket_move'));
+
+COMMENT ON COLUMN transactions.transaction_type IS 'Type of transaction: deposit, withdraw, transfer, escrow_fund, escrow_release, escrow_refund or pocket_move';
@@ -0,0 +1,7 @@
+-- Transaction Actors
+-- Transfers and withdrawals from a shared wallet may be made by one of its members;
+-- both entries of the pair record who acted
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS actor_user_id VARCHAR(255) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN transactions.actor_user_id IS 'User ID of the member who made the transfer or withdrawal on a shared wallet; empty when the wallet holder did';
@@ -0,0 +1,8 @@
+-- Merchant Transaction Types
+-- Ledger pairs for customers paying merchants' payment intents and for merchant refunds
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('deposit', 'withdraw', 'transfer', 'escrow_fund', 'escrow_release', 'escrow_refund', 'pocket_move', 'payment', 'refund'));
+
+COMMENT ON COLUMN transactions.transaction_type IS 'Type of transaction: deposit, withdraw, transfer, escrow_fund, escrow_release, escrow_refund, pocket_move, payment or refund';
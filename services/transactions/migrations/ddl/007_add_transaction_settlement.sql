@@ -0,0 +1,11 @@
+-- Provider Settlement
+-- Deposit and withdrawal entries of provider wallets are settled with the bank in daily
+-- batches; each entry records the batch it was settled in
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS settlement_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS settled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_unsettled ON transactions(subject_wallet_id, created_at) WHERE settlement_id = '';
+
+COMMENT ON COLUMN transactions.settlement_id IS 'Provider settlement batch the entry was settled in; empty until then';
+COMMENT ON COLUMN transactions.settled_at IS 'Time the entry was settled';
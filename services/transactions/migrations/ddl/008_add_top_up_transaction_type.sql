@@ -0,0 +1,8 @@
+-- Provider Top-Up Transaction Type
+-- Ledger pairs for operators adding float to a provider wallet from its external bank account
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('deposit', 'withdraw', 'transfer', 'escrow_fund', 'escrow_release', 'escrow_refund', 'pocket_move', 'payment', 'refund', 'top_up'));
+
+COMMENT ON COLUMN transactions.transaction_type IS 'Type of transaction: deposit, withdraw, transfer, escrow_fund, escrow_release, escrow_refund, pocket_move, payment, refund or top_up';
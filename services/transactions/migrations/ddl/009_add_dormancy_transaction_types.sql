@@ -0,0 +1,12 @@
+-- Dormancy Transaction Types
+-- Ledger pairs for the fee charged to a wallet found dormant, or for its balance handed over
+-- to a provider wallet as unclaimed property (escheatment)
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('deposit', 'withdraw', 'transfer', 'escrow_fund', 'escrow_release', 'escrow_refund', 'pocket_move', 'payment', 'refund', 'top_up', 'dormancy_fee', 'escheatment'));
+
+COMMENT ON COLUMN transactions.transaction_type IS 'Type of transaction: deposit, withdraw, transfer, escrow_fund, escrow_release, escrow_refund, pocket_move, payment, refund, top_up, dormancy_fee or escheatment';
+
+-- Last activity of a wallet
+CREATE INDEX IF NOT EXISTS idx_transactions_subject_created_at ON transactions(subject_wallet_id, created_at);
@@ -0,0 +1,9 @@
+-- Adjustment Transaction Type
+-- Ledger pairs for manual corrections of a wallet's balance by an operator, against the
+-- adjustments provider wallet
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('deposit', 'withdraw', 'transfer', 'escrow_fund', 'escrow_release', 'escrow_refund', 'pocket_move', 'payment', 'refund', 'top_up', 'dormancy_fee', 'escheatment', 'adjustment'));
+
+COMMENT ON COLUMN transactions.transaction_type IS 'Type of transaction: deposit, withdraw, transfer, escrow_fund, escrow_release, escrow_refund, pocket_move, payment, refund, top_up, dormancy_fee, escheatment or adjustment';
@@ -0,0 +1,9 @@
+-- Idempotent Ledger Writes
+-- The wallets service retries a ledger pair until it is written, with the same key on
+-- every attempt; a pair whose key was already written is skipped
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_idempotency_key ON transactions(idempotency_key, operation_type) WHERE idempotency_key <> '';
+
+COMMENT ON COLUMN transactions.idempotency_key IS 'Key of the request that wrote the pair, shared by both entries; empty for pairs written without one';
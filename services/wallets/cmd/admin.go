@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var adminOutput string
+
+// adminCmd groups the operational commands, which work directly on the
+// database and call the transactions service for the ledger
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Operational commands for wallets, providers and transactions",
+	PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+		if adminOutput != outputTable && adminOutput != outputJSON {
+			return fmt.Errorf("invalid output %q: expected table or json", adminOutput)
+		}
+		return nil
+	},
+}
+
+func init() {
+	adminCmd.PersistentFlags().StringVarP(&adminOutput, "output", "o", outputTable, "output format: table or json")
+	rootCmd.AddCommand(adminCmd)
+}
+
+// newAdminRepo connects to the database of cfg and returns the wallet
+// repository and service on top of it.
+func newAdminRepo(cfg model.Config) (repository.Wallet, service.Wallet, error) {
+	dbInstance, err := db.New(cfg.PostgreSQL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	walletRepo := repository.NewWalletRepo(dbInstance)
+	return walletRepo, service.NewWalletService(walletRepo), nil
+}
+
+// printOutput writes v to stdout as indented JSON, or as a table of header
+// and rows if the output is table.
+func printOutput(v interface{}, header []string, rows [][]string) error {
+	if adminOutput == outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+var walletHeader = []string{"ID", "USER ID", "TYPE", "STATUS", "BALANCE", "KYC", "POCKET", "CREATED AT"}
+
+func walletRow(w model.Wallet) []string {
+	return []string{
+		strconv.Itoa(w.ID),
+		w.UserID,
+		string(w.AcntType),
+		string(w.Status),
+		strconv.FormatInt(w.Balance, 10),
+		string(w.KYCLevelOf()),
+		w.PocketName,
+		w.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+var transactionHeader = []string{"ID", "SUBJECT", "OBJECT", "TYPE", "OPERATION", "AMOUNT", "STATUS", "GROUP", "CREATED AT"}
+
+func transactionRow(t model.Transaction) []string {
+	return []string{
+		strconv.Itoa(t.ID),
+		t.SubjectWalletID,
+		t.ObjectWalletID,
+		string(t.TransactionType),
+		string(t.OperationType),
+		strconv.FormatInt(t.Amount, 10),
+		string(t.Status),
+		t.GroupID,
+		t.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// adminProviderCmd groups the provider commands
+var adminProviderCmd = &cobra.Command{
+	Use:   "provider",
+	Short: "Inspect provider wallets",
+}
+
+var adminProviderListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List provider wallets with their balance and status",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runProviderList(cfg); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	adminProviderCmd.AddCommand(adminProviderListCmd)
+	adminCmd.AddCommand(adminProviderCmd)
+}
+
+func runProviderList(cfg model.Config) error {
+	_, walletService, err := newAdminRepo(cfg)
+	if err != nil {
+		return err
+	}
+
+	providers, err := walletService.ListProviders(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list providers: %w", err)
+	}
+	header := []string{"USER ID", "NAME", "STATUS", "BALANCE", "LOW FLOAT", "DEPOSIT BPS", "WITHDRAWAL BPS", "FIXED FEE"}
+	rows := make([][]string, 0, len(providers))
+	for _, p := range providers {
+		rows = append(rows, []string{
+			p.UserID,
+			p.DisplayName,
+			string(p.Status),
+			strconv.FormatInt(p.Balance, 10),
+			strconv.FormatBool(p.LowFloat),
+			strconv.Itoa(p.FeeSchedule.DepositBps),
+			strconv.Itoa(p.FeeSchedule.WithdrawalBps),
+			strconv.FormatInt(p.FeeSchedule.Fixed, 10),
+		})
+	}
+	return printOutput(providers, header, rows)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var txnID int
+
+// adminTxnCmd groups the transaction commands
+var adminTxnCmd = &cobra.Command{
+	Use:   "txn",
+	Short: "Inspect ledger entries in the transactions service",
+}
+
+var adminTxnShowCmd = &cobra.Command{
+	Use:   "show <user_id>",
+	Short: "Show the ledger entries of a wallet, or one of them with --id",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runTxnShow(args[0]); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	adminTxnShowCmd.Flags().IntVar(&txnID, "id", 0, "only show the entry with this ID")
+	adminTxnCmd.AddCommand(adminTxnShowCmd)
+	adminCmd.AddCommand(adminTxnCmd)
+}
+
+func runTxnShow(userID string) error {
+	transactions, err := client.NewTxnClient().FetchTransactions(context.Background(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch transactions of %s: %w", userID, err)
+	}
+	if txnID > 0 {
+		for _, txn := range transactions {
+			if txn.ID == txnID {
+				return printOutput(txn, transactionHeader, [][]string{transactionRow(txn)})
+			}
+		}
+		return fmt.Errorf("transaction %d of %s not found", txnID, userID)
+	}
+
+	rows := make([][]string, 0, len(transactions))
+	for _, txn := range transactions {
+		rows = append(rows, transactionRow(txn))
+	}
+	if transactions == nil {
+		transactions = []model.Transaction{}
+	}
+	return printOutput(transactions, transactionHeader, rows)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	walletAcntType string
+	walletStatus   string
+)
+
+// adminWalletCmd groups the wallet commands
+var adminWalletCmd = &cobra.Command{
+	Use:   "wallet",
+	Short: "Create, inspect, suspend and activate wallets",
+}
+
+var adminWalletCreateCmd = &cobra.Command{
+	Use:   "create <user_id>",
+	Short: "Create a user or provider wallet",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runWalletCreate(cfg, args[0]); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var adminWalletShowCmd = &cobra.Command{
+	Use:   "show <user_id>",
+	Short: "Show a wallet",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runWalletShow(cfg, args[0]); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var adminWalletListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List wallets, optionally of one type or status",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runWalletList(cfg); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var adminWalletSuspendCmd = &cobra.Command{
+	Use:   "suspend <user_id>",
+	Short: "Suspend an active wallet and its pockets",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runWalletStatus(cfg, args[0], model.Suspended); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var adminWalletActivateCmd = &cobra.Command{
+	Use:   "activate <user_id>",
+	Short: "Make a suspended or dormant wallet and its pockets active again",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runWalletStatus(cfg, args[0], model.Active); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	adminWalletCreateCmd.Flags().StringVar(&walletAcntType, "type", string(model.User), "account type: user or provider")
+	adminWalletListCmd.Flags().StringVar(&walletAcntType, "type", "", "only list wallets of this account type")
+	adminWalletListCmd.Flags().StringVar(&walletStatus, "status", "", "only list wallets with this status")
+	adminWalletCmd.AddCommand(adminWalletCreateCmd, adminWalletShowCmd, adminWalletListCmd, adminWalletSuspendCmd, adminWalletActivateCmd)
+	adminCmd.AddCommand(adminWalletCmd)
+}
+
+func runWalletCreate(cfg model.Config, userID string) error {
+	acntType := model.AcntType(walletAcntType)
+	if acntType != model.User && acntType != model.Provider {
+		return fmt.Errorf("invalid type %q: expected user or provider", walletAcntType)
+	}
+	_, walletService, err := newAdminRepo(cfg)
+	if err != nil {
+		return err
+	}
+
+	wallet := model.NewWallet(userID, acntType)
+	if err := walletService.Create(context.Background(), wallet); err != nil {
+		return fmt.Errorf("failed to create wallet: %w", err)
+	}
+	return printOutput(wallet, walletHeader, [][]string{walletRow(*wallet)})
+}
+
+func runWalletShow(cfg model.Config, userID string) error {
+	walletRepo, _, err := newAdminRepo(cfg)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := walletRepo.FindByUserID(context.Background(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to find wallet %s: %w", userID, err)
+	}
+	return printOutput(wallet, walletHeader, [][]string{walletRow(*wallet)})
+}
+
+func runWalletList(cfg model.Config) error {
+	walletRepo, _, err := newAdminRepo(cfg)
+	if err != nil {
+		return err
+	}
+
+	wallets, err := walletRepo.FindAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list wallets: %w", err)
+	}
+	listed := make([]model.Wallet, 0, len(wallets))
+	rows := make([][]string, 0, len(wallets))
+	for _, wallet := range wallets {
+		if walletAcntType != "" && wallet.AcntType != model.AcntType(walletAcntType) {
+			continue
+		}
+		if walletStatus != "" && wallet.Status != model.Status(walletStatus) {
+			continue
+		}
+		listed = append(listed, wallet)
+		rows = append(rows, walletRow(wallet))
+	}
+	return printOutput(listed, walletHeader, rows)
+}
+
+func runWalletStatus(cfg model.Config, userID string, status model.Status) error {
+	_, walletService, err := newAdminRepo(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	var wallet *model.Wallet
+	if status == model.Suspended {
+		wallet, err = walletService.SuspendWallet(ctx, userID)
+	} else {
+		wallet, err = walletService.ActivateWallet(ctx, userID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set wallet %s %s: %w", userID, status, err)
+	}
+	return printOutput(wallet, walletHeader, [][]string{walletRow(*wallet)})
+}
@@ -74,6 +74,8 @@ func initConfig() {
 		log.Fatalf("unable to decode into struct, %v", err)
 	}
 
+	cfg.AdminServer.LoadTokens(os.Getenv)
+
 	validate := validator.New()
 	if err := validate.Struct(&cfg); err != nil {
 		log.Fatalf("config validation failed: %v", err)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/server"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +32,11 @@ var serverCmd = &cobra.Command{
 func runServe(cfg model.Config) error {
 	var servers []server.Server
 
+	shutdownTracing, err := tracing.Init(cfg.Tracing)
+	if err != nil {
+		return err
+	}
+
 	apiOpts := server.WalletAPIServerOpts{
 		ListenPort: cfg.APIServer.Port,
 		Config:     cfg,
@@ -41,6 +47,18 @@ func runServe(cfg model.Config) error {
 	}
 	servers = append(servers, apiServer)
 
+	// The admin endpoints are only served on the internal admin server
+	if cfg.AdminServer.Enable {
+		adminServer, err := server.NewAdmin(server.AdminServerOpts{
+			ListenPort: cfg.AdminServer.Port,
+			Config:     cfg,
+		})
+		if err != nil {
+			return err
+		}
+		servers = append(servers, adminServer)
+	}
+
 	if cfg.SwaggerServer.Enable {
 		SwaggerOpts := server.SwaggerServerOpts{
 			ListenPort: cfg.SwaggerServer.Port,
@@ -73,6 +91,9 @@ func runServe(cfg model.Config) error {
 			return err
 		}
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error("failed to flush traces: ", err)
+	}
 	log.Info("server shutdown gracefully")
 	return nil
 }
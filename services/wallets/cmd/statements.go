@@ -0,0 +1,113 @@
+// Package cmd provides the command line interface for the application.
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/statement"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statementMonth  string
+	statementFormat string
+	statementOutDir string
+)
+
+// statementsCmd generates the statements of every wallet for a month
+var statementsCmd = &cobra.Command{
+	Use:   "statements",
+	Short: "Generate the statements of all wallets for a month into a directory",
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runStatements(cfg); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	statementsCmd.Flags().StringVar(&statementMonth, "month", time.Now().UTC().AddDate(0, -1, 0).Format("2006-01"), "month to generate statements for (YYYY-MM, UTC)")
+	statementsCmd.Flags().StringVar(&statementFormat, "format", string(model.StatementCSV), "statement format: csv, json, ofx or camt053")
+	statementsCmd.Flags().StringVar(&statementOutDir, "out", "statements", "directory to write the statements to")
+	rootCmd.AddCommand(statementsCmd)
+}
+
+func runStatements(cfg model.Config) error {
+	from, err := time.Parse("2006-01", statementMonth)
+	if err != nil {
+		return fmt.Errorf("invalid month %q: expected YYYY-MM", statementMonth)
+	}
+	to := from.AddDate(0, 1, -1)
+	format := model.StatementFormat(statementFormat)
+	if _, err := statement.NewWriter(format, nil); err != nil {
+		return fmt.Errorf("%w: %s", err, statementFormat)
+	}
+
+	if err := os.MkdirAll(statementOutDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	dbInstance, err := db.New(cfg.PostgreSQL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	walletRepo := repository.NewWalletRepo(dbInstance)
+	walletService := service.NewWalletService(walletRepo)
+
+	ctx := context.Background()
+	wallets, err := walletRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list wallets: %w", err)
+	}
+
+	var failed int
+	for _, wallet := range wallets {
+		path := filepath.Join(statementOutDir, statement.FileName(wallet.UserID, statementMonth, format))
+		if err := writeStatementFile(ctx, walletService, wallet.UserID, from, to, format, path); err != nil {
+			log.WithField("user_id", wallet.UserID).Errorf("failed to generate statement: %v", err)
+			failed++
+		}
+	}
+
+	fmt.Printf("Generated %d of %d statements for %s in %s\n", len(wallets)-failed, len(wallets), statementMonth, statementOutDir)
+	if failed > 0 {
+		return fmt.Errorf("%d statements failed", failed)
+	}
+	return nil
+}
+
+// writeStatementFile writes one statement to path, removing the file if it fails.
+func writeStatementFile(ctx context.Context, s service.Wallet, userID string, from, to time.Time, format model.StatementFormat, path string) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	buf := bufio.NewWriter(file)
+	writer, err := statement.NewWriter(format, buf)
+	if err != nil {
+		return err
+	}
+	if err := s.WriteStatement(ctx, userID, from, to, writer); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
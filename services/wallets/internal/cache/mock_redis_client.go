@@ -8,13 +8,15 @@ import (
 
 // MockRedisClient implements RedisClient interface for testing
 type MockRedisClient struct {
-	Transactions map[string][]model.Transaction
+	Transactions      map[string][]model.Transaction
+	StaleTransactions map[string][]model.Transaction
 }
 
 // NewMockRedisClient creates a new mock Redis client
 func NewMockRedisClient() *MockRedisClient {
 	return &MockRedisClient{
-		Transactions: make(map[string][]model.Transaction),
+		Transactions:      make(map[string][]model.Transaction),
+		StaleTransactions: make(map[string][]model.Transaction),
 	}
 }
 
@@ -29,15 +31,29 @@ func (m *MockRedisClient) GetTransactionHistory(ctx context.Context, userID stri
 // SaveTransactionHistory saves mock transaction history
 func (m *MockRedisClient) SaveTransactionHistory(ctx context.Context, userID string, transactions []model.Transaction) error {
 	m.Transactions[userID] = transactions
+	m.StaleTransactions[userID] = transactions
 	return nil
 }
 
+// GetStaleTransactionHistory returns mock last known transaction history
+func (m *MockRedisClient) GetStaleTransactionHistory(ctx context.Context, userID string) ([]model.Transaction, error) {
+	if transactions, exists := m.StaleTransactions[userID]; exists {
+		return transactions, nil
+	}
+	return nil, nil
+}
+
 // DeleteTransactionHistory deletes mock transaction history
 func (m *MockRedisClient) DeleteTransactionHistory(ctx context.Context, userID string) error {
 	delete(m.Transactions, userID)
 	return nil
 }
 
+// Ping always succeeds for mock client
+func (m *MockRedisClient) Ping(ctx context.Context) error {
+	return nil
+}
+
 // Close does nothing for mock client
 func (m *MockRedisClient) Close() error {
 	return nil
@@ -10,6 +10,7 @@ import (
 
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -17,14 +18,18 @@ import (
 type RedisClient interface {
 	GetTransactionHistory(ctx context.Context, userID string) ([]model.Transaction, error)
 	SaveTransactionHistory(ctx context.Context, userID string, transactions []model.Transaction) error
+	GetStaleTransactionHistory(ctx context.Context, userID string) ([]model.Transaction, error)
 	DeleteTransactionHistory(ctx context.Context, userID string) error
+	Ping(ctx context.Context) error
 	Close() error
 }
 
 // redisClient implements RedisClient interface
 type redisClient struct {
-	client *redis.Client
-	ttl    time.Duration
+	client   *redis.Client
+	ttl      time.Duration
+	staleTTL time.Duration
+	timeout  time.Duration
 }
 
 var (
@@ -54,10 +59,13 @@ func NewRedisClient() RedisClient {
 			ReadTimeout:  30 * time.Second,
 			WriteTimeout: 30 * time.Second,
 		})
+		rdb.AddHook(tracing.NewRedisHook())
 
 		redisInstance = &redisClient{
-			client: rdb,
-			ttl:    24 * time.Hour, // Cache for 24 hours
+			client:  rdb,
+			ttl:      24 * time.Hour,     // Cache for 24 hours
+			staleTTL: 7 * 24 * time.Hour, // Keep a fallback copy for a week
+			timeout:  config.GetTimeouts().Cache,
 		}
 	})
 	return redisInstance
@@ -68,9 +76,27 @@ func (r *redisClient) generateKey(userID string) string {
 	return fmt.Sprintf("wallet:transactions:%s", userID)
 }
 
+// generateStaleKey creates the Redis key for the last known transaction history,
+// which survives invalidation so it can be served while the transactions service is unavailable
+func (r *redisClient) generateStaleKey(userID string) string {
+	return fmt.Sprintf("wallet:transactions:stale:%s", userID)
+}
+
 // GetTransactionHistory retrieves cached transaction history for a user
 func (r *redisClient) GetTransactionHistory(ctx context.Context, userID string) ([]model.Transaction, error) {
-	key := r.generateKey(userID)
+	return r.get(ctx, r.generateKey(userID))
+}
+
+// GetStaleTransactionHistory retrieves the last known transaction history for a user,
+// even if it has since been invalidated
+func (r *redisClient) GetStaleTransactionHistory(ctx context.Context, userID string) ([]model.Transaction, error) {
+	return r.get(ctx, r.generateStaleKey(userID))
+}
+
+func (r *redisClient) get(ctx context.Context, key string) ([]model.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
 	val, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
@@ -91,13 +117,20 @@ func (r *redisClient) GetTransactionHistory(ctx context.Context, userID string)
 
 // SaveTransactionHistory caches transaction history for a user
 func (r *redisClient) SaveTransactionHistory(ctx context.Context, userID string, transactions []model.Transaction) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
 	key := r.generateKey(userID)
 	data, err := json.Marshal(transactions)
 	if err != nil {
 		return fmt.Errorf("failed to marshal transaction history: %w", err)
 	}
 
-	err = r.client.Set(ctx, key, data, r.ttl).Err()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, data, r.ttl)
+		pipe.Set(ctx, r.generateStaleKey(userID), data, r.staleTTL)
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to save transaction history to cache: %w", err)
 	}
@@ -107,6 +140,9 @@ func (r *redisClient) SaveTransactionHistory(ctx context.Context, userID string,
 
 // DeleteTransactionHistory removes cached transaction history for a user
 func (r *redisClient) DeleteTransactionHistory(ctx context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
 	key := r.generateKey(userID)
 	err := r.client.Del(ctx, key).Err()
 	if err != nil {
@@ -116,6 +152,17 @@ func (r *redisClient) DeleteTransactionHistory(ctx context.Context, userID strin
 	return nil
 }
 
+// Ping checks that the Redis server is reachable
+func (r *redisClient) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping redis: %w", err)
+	}
+	return nil
+}
+
 // Close closes the Redis client connection
 func (r *redisClient) Close() error {
 	return r.client.Close()
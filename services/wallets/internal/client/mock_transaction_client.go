@@ -1,20 +1,23 @@
 package client
 
 import (
+	"context"
+	"time"
+
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 )
 
 // MockTransactionClient implements the NewTransaction interface for testing
 type MockTransactionClient struct{}
 
-func (m *MockTransactionClient) CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error {
+func (m *MockTransactionClient) CreateTransactionPair(_ context.Context, debitTxn, creditTxn *model.Transaction) error {
 	// Mock successful transaction creation
 	// In a real scenario, this would make HTTP calls to the transaction service
 	// But for testing, we just return success
 	return nil
 }
 
-func (m *MockTransactionClient) FetchTransactions(subjectWalletID string) ([]model.Transaction, error) {
+func (m *MockTransactionClient) FetchTransactions(_ context.Context, subjectWalletID string) ([]model.Transaction, error) {
 	// For test-user-001, return some sample transactions
 	if subjectWalletID == "test-user-001" {
 		return []model.Transaction{
@@ -39,3 +42,38 @@ func (m *MockTransactionClient) FetchTransactions(subjectWalletID string) ([]mod
 	// For other wallet IDs, return empty list
 	return []model.Transaction{}, nil
 }
+
+func (m *MockTransactionClient) FetchTransactionPage(ctx context.Context, subjectWalletID string, page model.TransactionPage) ([]model.Transaction, error) {
+	transactions, err := m.FetchTransactions(ctx, subjectWalletID)
+	if err != nil {
+		return nil, err
+	}
+	selected := []model.Transaction{}
+	for _, txn := range transactions {
+		afterStart := txn.CreatedAt.After(page.After) || (txn.CreatedAt.Equal(page.After) && txn.ID > page.AfterID)
+		if afterStart && (page.Before.IsZero() || txn.CreatedAt.Before(page.Before)) && len(selected) < page.Limit {
+			selected = append(selected, txn)
+		}
+	}
+	return selected, nil
+}
+
+func (m *MockTransactionClient) FetchUnsettledTransactions(_ context.Context, providerIDs []string, before time.Time) ([]model.Transaction, error) {
+	return []model.Transaction{}, nil
+}
+
+func (m *MockTransactionClient) SettleTransactions(_ context.Context, settlementID string, transactionIDs []int) error {
+	return nil
+}
+
+func (m *MockTransactionClient) FetchLastActivity(_ context.Context, subjectWalletIDs []string) (map[string]time.Time, error) {
+	return map[string]time.Time{}, nil
+}
+
+func (m *MockTransactionClient) FetchLedgerBalances(_ context.Context, at time.Time) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
+func (m *MockTransactionClient) Ping(_ context.Context) error {
+	return nil
+}
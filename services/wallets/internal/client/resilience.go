@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen is returned without calling the service while the circuit breaker is open.
+	ErrCircuitOpen = errors.New("transaction service circuit breaker is open")
+	// ErrBulkheadFull is returned when the maximum number of concurrent calls is already in flight.
+	ErrBulkheadFull = errors.New("too many concurrent transaction service calls")
+)
+
+// StatusError is returned when the service responds with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("transaction service returned status %d", e.StatusCode)
+}
+
+// isServiceFailure reports whether err indicates that the service is unhealthy,
+// as opposed to a rejected request or a caller that gave up.
+func isServiceFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError ||
+			statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every call through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every call until the open timeout elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial call through to probe recovery.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuitBreaker opens after a number of consecutive failures and, once the
+// open timeout has elapsed, lets one trial call decide whether to close again.
+type circuitBreaker struct {
+	threshold     int
+	openTimeout   time.Duration
+	now           func() time.Time
+	onStateChange func(from, to CircuitState)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(threshold int, openTimeout time.Duration, onStateChange func(from, to CircuitState)) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:     threshold,
+		openTimeout:   openTimeout,
+		now:           time.Now,
+		onStateChange: onStateChange,
+	}
+}
+
+// allow returns ErrCircuitOpen if the call must not be attempted.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.setState(CircuitHalfOpen)
+		b.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// success records a call that reached a healthy service.
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != CircuitClosed {
+		b.setState(CircuitClosed)
+	}
+}
+
+// failure records a call that failed because the service is unhealthy.
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.failures >= b.threshold) {
+		b.openedAt = b.now()
+		b.setState(CircuitOpen)
+	}
+}
+
+// release gives up a trial slot without judging the service, e.g. when the caller cancelled.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// State returns the current state.
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// setState must be called with b.mu held.
+func (b *circuitBreaker) setState(to CircuitState) {
+	from := b.state
+	b.state = to
+	if b.onStateChange != nil {
+		b.onStateChange(from, to)
+	}
+}
+
+// bulkhead limits the number of concurrent calls; a nil bulkhead is unlimited.
+type bulkhead chan struct{}
+
+func newBulkhead(size int) bulkhead {
+	if size <= 0 {
+		return nil
+	}
+	return make(bulkhead, size)
+}
+
+// tryAcquire takes a slot without waiting and reports whether it succeeded.
+func (b bulkhead) tryAcquire() bool {
+	if b == nil {
+		return true
+	}
+	select {
+	case b <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b bulkhead) release() {
+	if b != nil {
+		<-b
+	}
+}
+
+// backoff returns the delay before retry number attempt (starting at 1), using
+// exponential growth capped at maxDelay with "equal jitter" so that concurrent
+// callers do not retry in lockstep.
+func backoff(attempt int, initial, maxDelay time.Duration) time.Duration {
+	delay := initial << (attempt - 1)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
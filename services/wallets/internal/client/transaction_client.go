@@ -2,26 +2,44 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // NewTransaction interface for communicating with transactions microservice
 type NewTransaction interface {
-	CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error
-	FetchTransactions(subjectWalletID string) ([]model.Transaction, error)
+	CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) error
+	FetchTransactions(ctx context.Context, subjectWalletID string) ([]model.Transaction, error)
+	FetchTransactionPage(ctx context.Context, subjectWalletID string, page model.TransactionPage) ([]model.Transaction, error)
+	FetchUnsettledTransactions(ctx context.Context, providerIDs []string, before time.Time) ([]model.Transaction, error)
+	SettleTransactions(ctx context.Context, settlementID string, transactionIDs []int) error
+	FetchLastActivity(ctx context.Context, subjectWalletIDs []string) (map[string]time.Time, error)
+	FetchLedgerBalances(ctx context.Context, at time.Time) (map[string]int64, error)
+	Ping(ctx context.Context) error
 }
 
 type transactionClient struct {
-	client  *http.Client
-	baseURL string
+	client          *http.Client
+	baseURL         string
+	timeout         time.Duration
+	deliveryTimeout time.Duration
+	retry           model.Retry
+	breaker         *circuitBreaker
+	bulkhead        bulkhead
 }
 
 var (
@@ -38,24 +56,151 @@ func ResetClient() {
 // NewTxnClient is a factory method that returns NewTransaction interface with singleton pattern
 func NewTxnClient() NewTransaction {
 	once.Do(func() {
-		baseURL := "http://localhost:8082" // default fallback
 		globalConfig := config.GetGlobalConfig()
-		baseURL = globalConfig.Services.Transaction.BaseURL
-
-		instance = &transactionClient{
-			client: &http.Client{
-				Timeout: 30 * time.Second,
-			},
-			baseURL: baseURL,
-		}
+		instance = NewTransactionClient(globalConfig.Services.Transaction, config.GetTimeouts().TransactionService)
 	})
 	return instance
 }
 
+// NewTransactionClient creates a client for the transactions service at cfg.BaseURL.
+// Idempotent calls are retried with jittered backoff, every call except ledger
+// writes goes through a circuit breaker and, if cfg.MaxConcurrent is set, a
+// bulkhead. Unset settings fall back to model.DefaultTransactionService.
+func NewTransactionClient(cfg model.Service, timeout time.Duration) NewTransaction {
+	defaults := model.DefaultTransactionService()
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "http://localhost:8082" // default fallback
+	}
+	if cfg.Retry.MaxAttempts <= 0 {
+		cfg.Retry.MaxAttempts = defaults.Retry.MaxAttempts
+	}
+	if cfg.Retry.InitialBackoff <= 0 {
+		cfg.Retry.InitialBackoff = defaults.Retry.InitialBackoff
+	}
+	if cfg.Retry.MaxBackoff <= 0 {
+		cfg.Retry.MaxBackoff = defaults.Retry.MaxBackoff
+	}
+	if cfg.CircuitBreaker.FailureThreshold <= 0 {
+		cfg.CircuitBreaker.FailureThreshold = defaults.CircuitBreaker.FailureThreshold
+	}
+	if cfg.CircuitBreaker.OpenTimeout <= 0 {
+		cfg.CircuitBreaker.OpenTimeout = defaults.CircuitBreaker.OpenTimeout
+	}
+	if cfg.LedgerDeliveryTimeout <= 0 {
+		cfg.LedgerDeliveryTimeout = defaults.LedgerDeliveryTimeout
+	}
+
+	// Deadlines are carried by the request context rather than a fixed
+	// http.Client timeout so callers can cancel in-flight requests.
+	// The otelhttp transport creates a client span per call and injects
+	// the W3C traceparent header.
+	return &transactionClient{
+		client:          &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		baseURL:         cfg.BaseURL,
+		timeout:         timeout,
+		deliveryTimeout: cfg.LedgerDeliveryTimeout,
+		retry:           cfg.Retry,
+		breaker:         newCircuitBreaker(cfg.CircuitBreaker.FailureThreshold, cfg.CircuitBreaker.OpenTimeout, logCircuitTransition),
+		bulkhead:        newBulkhead(cfg.MaxConcurrent),
+	}
+}
+
+// logCircuitTransition reports circuit breaker state changes as log events and metrics
+func logCircuitTransition(from, to CircuitState) {
+	metrics.ObserveCircuitTransition(from.String(), to.String(), int(to))
+	log.WithFields(log.Fields{
+		"component": "transaction_client",
+		"from":      from.String(),
+		"to":        to.String(),
+	}).Warn("circuit breaker state changed")
+}
+
+// callPolicy decides how execute guards and retries a call.
+type callPolicy int
+
+const (
+	// callOnce is attempted a single time, for writes that are not idempotent.
+	callOnce callPolicy = iota
+	// callRetry is retried up to Retry.MaxAttempts, for idempotent calls.
+	callRetry
+	// callDeliver is retried until it succeeds, the service rejects it or
+	// the context is done. It skips the bulkhead and the circuit breaker:
+	// it records a balance change that is already committed, so shedding it
+	// would lose the ledger entry rather than protect the caller.
+	callDeliver
+)
+
+// execute runs call through the bulkhead and circuit breaker. Idempotent calls
+// that fail because the service is unhealthy are retried with backoff.
+func (tc *transactionClient) execute(ctx context.Context, operation string, policy callPolicy, call func(ctx context.Context) error) error {
+	guarded := policy != callDeliver
+	if guarded {
+		if !tc.bulkhead.tryAcquire() {
+			metrics.IncTransactionClientRejection("bulkhead_full")
+			return ErrBulkheadFull
+		}
+		defer tc.bulkhead.release()
+	}
+
+	attempts := 1
+	if policy == callRetry {
+		attempts = tc.retry.MaxAttempts
+	}
+
+	var err error
+	for attempt := 1; policy == callDeliver || attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			metrics.IncTransactionClientRetry(operation)
+			if sleepErr := sleep(ctx, backoff(attempt-1, tc.retry.InitialBackoff, tc.retry.MaxBackoff)); sleepErr != nil {
+				return err
+			}
+		}
+
+		if guarded {
+			if allowErr := tc.breaker.allow(); allowErr != nil {
+				metrics.IncTransactionClientRejection("circuit_open")
+				return allowErr
+			}
+		}
+
+		// Each attempt gets its own deadline; a timed-out attempt counts as a
+		// service failure unless the caller's own context is done.
+		attemptCtx, cancel := context.WithTimeout(ctx, tc.timeout)
+		err = call(attemptCtx)
+		cancel()
+		if !guarded {
+			if err == nil || !isServiceFailure(ctx, err) {
+				return err
+			}
+			continue
+		}
+		switch {
+		case err == nil:
+			tc.breaker.success()
+			return nil
+		case isServiceFailure(ctx, err):
+			tc.breaker.failure()
+		default:
+			// The service answered (e.g. 4xx) or the caller gave up; neither
+			// says anything about the service's health and neither is retryable.
+			if ctx.Err() != nil {
+				tc.breaker.release()
+			} else {
+				tc.breaker.success()
+			}
+			return err
+		}
+	}
+	return err
+}
+
 // TransactionPairRequest represents the request payload for creating transaction pairs
 type TransactionPairRequest struct {
 	DebitTransaction  TransactionRequest `json:"debit_transaction"`
 	CreditTransaction TransactionRequest `json:"credit_transaction"`
+	// IdempotencyKey makes the transactions service write the pair only once
+	// however many times the request is delivered.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 // TransactionRequest represents a single transaction in the request
@@ -66,6 +211,8 @@ type TransactionRequest struct {
 	OperationType   model.OperationType     `json:"operation_type"`
 	Amount          int64                   `json:"amount"`
 	Status          model.TransactionStatus `json:"status"`
+	GroupID         string                  `json:"group_id,omitempty"`
+	ActorUserID     string                  `json:"actor_user_id,omitempty"`
 }
 
 // TransactionResponse represents the API response wrapper for transactions
@@ -74,43 +221,80 @@ type TransactionResponse struct {
 }
 
 // FetchTransactions retrieves transactions for a specific wallet from the transaction service
-func (tc *transactionClient) FetchTransactions(subjectWalletID string) ([]model.Transaction, error) {
-	// Create HTTP request
+func (tc *transactionClient) FetchTransactions(ctx context.Context, subjectWalletID string) (_ []model.Transaction, err error) {
+	defer func(start time.Time) { metrics.ObserveTransactionClient("fetch_transactions", start, err) }(time.Now())
+
 	url := fmt.Sprintf("%s/api/v1/transactions/%s", tc.baseURL, subjectWalletID)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		utils.LogError("Failed to create HTTP request for fetching transactions", err)
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	return tc.getTransactions(ctx, "fetch_transactions", url)
+}
 
-	req.Header.Set("Content-Type", "application/json")
+// FetchTransactionPage retrieves a page of the transactions of a wallet, oldest first
+func (tc *transactionClient) FetchTransactionPage(ctx context.Context, subjectWalletID string, page model.TransactionPage) (_ []model.Transaction, err error) {
+	defer func(start time.Time) { metrics.ObserveTransactionClient("fetch_transaction_page", start, err) }(time.Now())
 
-	// Send the request
-	resp, err := tc.client.Do(req)
-	if err != nil {
-		utils.LogError("Failed to send fetch transactions request", err)
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	query := url.Values{"limit": {strconv.Itoa(page.Limit)}}
+	if !page.After.IsZero() {
+		query.Set("after", page.After.UTC().Format(time.RFC3339Nano))
+		query.Set("after_id", strconv.Itoa(page.AfterID))
 	}
-	defer resp.Body.Close()
-
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
-		return nil, fmt.Errorf("transaction service returned status %d", resp.StatusCode)
+	if !page.Before.IsZero() {
+		query.Set("before", page.Before.UTC().Format(time.RFC3339Nano))
 	}
+	endpoint := fmt.Sprintf("%s/api/v1/transactions/%s/entries?%s", tc.baseURL, url.PathEscape(subjectWalletID), query.Encode())
+	return tc.getTransactions(ctx, "fetch_transaction_page", endpoint)
+}
 
-	// Parse response
+// getTransactions retrieves a list of transactions from the transaction service
+func (tc *transactionClient) getTransactions(ctx context.Context, operation, url string) ([]model.Transaction, error) {
+	// Reads are idempotent and safe to retry
 	var response TransactionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		utils.LogError("Failed to decode transactions response", err)
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	err := tc.execute(ctx, operation, callRetry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			utils.LogError("Failed to create HTTP request for fetching transactions", err)
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		// Send the request
+		resp, err := tc.client.Do(req)
+		if err != nil {
+			utils.LogError("Failed to send fetch transactions request", err)
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		// Check response status
+		if resp.StatusCode != http.StatusOK {
+			utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+			return &StatusError{StatusCode: resp.StatusCode}
+		}
+
+		// Parse response
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			utils.LogError("Failed to decode transactions response", err)
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return response.Data, nil
 }
 
-// CreateTransactionPair sends both debit and credit transactions to the transactions microservice
-func (tc *transactionClient) CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error {
+// CreateTransactionPair sends both debit and credit transactions to the transactions microservice.
+// The pair records a balance change that is already committed, so it is
+// retried until the service accepts or rejects it, for at most the delivery
+// timeout; every attempt carries the same idempotency key.
+func (tc *transactionClient) CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) (err error) {
+	defer func(start time.Time) { metrics.ObserveTransactionClient("create_transaction_pair", start, err) }(time.Now())
+
+	ctx, cancel := context.WithTimeout(ctx, tc.deliveryTimeout)
+	defer cancel()
+
 	// Prepare the request payload
 	request := TransactionPairRequest{
 		DebitTransaction: TransactionRequest{
@@ -120,6 +304,8 @@ func (tc *transactionClient) CreateTransactionPair(debitTxn, creditTxn *model.Tr
 			OperationType:   debitTxn.OperationType,
 			Amount:          debitTxn.Amount,
 			Status:          debitTxn.Status,
+			GroupID:         debitTxn.GroupID,
+			ActorUserID:     debitTxn.ActorUserID,
 		},
 		CreditTransaction: TransactionRequest{
 			SubjectWalletID: creditTxn.SubjectWalletID,
@@ -128,7 +314,10 @@ func (tc *transactionClient) CreateTransactionPair(debitTxn, creditTxn *model.Tr
 			OperationType:   creditTxn.OperationType,
 			Amount:          creditTxn.Amount,
 			Status:          creditTxn.Status,
+			GroupID:         creditTxn.GroupID,
+			ActorUserID:     creditTxn.ActorUserID,
 		},
+		IdempotencyKey: rand.Text(),
 	}
 
 	// Marshal the request to JSON
@@ -140,28 +329,240 @@ func (tc *transactionClient) CreateTransactionPair(debitTxn, creditTxn *model.Tr
 
 	// Create HTTP request
 	url := fmt.Sprintf("%s/api/v1/transactions", tc.baseURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+
+	// A retry after a lost response is safe: the service ignores a pair whose
+	// idempotency key it has already written.
+	return tc.execute(ctx, "create_transaction_pair", callDeliver, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+		if err != nil {
+			utils.LogError("Failed to create HTTP request for transaction pair", err)
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		// Send the request
+		resp, err := tc.client.Do(req)
+		if err != nil {
+			utils.LogError("Failed to send transaction pair request", err)
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		// Check response status
+		if resp.StatusCode != http.StatusCreated {
+			utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+			return &StatusError{StatusCode: resp.StatusCode}
+		}
+
+		// Successfully created transaction pair
+		return nil
+	})
+}
+
+// FetchUnsettledTransactions retrieves the deposit and withdrawal entries of
+// the given provider wallets created before the cutoff and not settled yet
+func (tc *transactionClient) FetchUnsettledTransactions(ctx context.Context, providerIDs []string, before time.Time) (_ []model.Transaction, err error) {
+	defer func(start time.Time) { metrics.ObserveTransactionClient("fetch_unsettled_transactions", start, err) }(time.Now())
+
+	query := url.Values{"subject_wallet_id": providerIDs, "before": {before.UTC().Format(time.RFC3339)}}
+	endpoint := fmt.Sprintf("%s/api/v1/settlements/unsettled?%s", tc.baseURL, query.Encode())
+
+	// Reads are idempotent and safe to retry
+	var response TransactionResponse
+	err = tc.execute(ctx, "fetch_unsettled_transactions", callRetry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := tc.client.Do(req)
+		if err != nil {
+			utils.LogError("Failed to send fetch unsettled transactions request", err)
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+			return &StatusError{StatusCode: resp.StatusCode}
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			utils.LogError("Failed to decode unsettled transactions response", err)
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		utils.LogError("Failed to create HTTP request for transaction pair", err)
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	return response.Data, nil
+}
+
+// SettleTransactionsRequest represents the request payload for marking entries as settled
+type SettleTransactionsRequest struct {
+	TransactionIDs []int `json:"transaction_ids"`
+}
+
+// SettleTransactions marks the given entries as settled under settlementID
+// in the transactions service
+func (tc *transactionClient) SettleTransactions(ctx context.Context, settlementID string, transactionIDs []int) (err error) {
+	defer func(start time.Time) { metrics.ObserveTransactionClient("settle_transactions", start, err) }(time.Now())
+
+	jsonData, err := json.Marshal(SettleTransactionsRequest{TransactionIDs: transactionIDs})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/api/v1/settlements/%s", tc.baseURL, url.PathEscape(settlementID))
+
+	// Settling the same entries again under the same ID succeeds, so the
+	// call is idempotent and safe to retry
+	return tc.execute(ctx, "settle_transactions", callRetry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(jsonData))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := tc.client.Do(req)
+		if err != nil {
+			utils.LogError("Failed to send settle transactions request", err)
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+			return &StatusError{StatusCode: resp.StatusCode}
+		}
+		return nil
+	})
+}
+
+// ActivityResponse represents the API response wrapper for the last activity of wallets
+type ActivityResponse struct {
+	Data []model.WalletActivity `json:"data"`
+}
+
+// FetchLastActivity retrieves the time of the latest ledger entry of each
+// given wallet. Wallets without entries are not in the returned map.
+func (tc *transactionClient) FetchLastActivity(ctx context.Context, subjectWalletIDs []string) (_ map[string]time.Time, err error) {
+	defer func(start time.Time) { metrics.ObserveTransactionClient("fetch_last_activity", start, err) }(time.Now())
+
+	query := url.Values{"subject_wallet_id": subjectWalletIDs}
+	endpoint := fmt.Sprintf("%s/api/v1/activity?%s", tc.baseURL, query.Encode())
+
+	// Reads are idempotent and safe to retry
+	var response ActivityResponse
+	err = tc.execute(ctx, "fetch_last_activity", callRetry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := tc.client.Do(req)
+		if err != nil {
+			utils.LogError("Failed to send fetch last activity request", err)
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+			return &StatusError{StatusCode: resp.StatusCode}
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			utils.LogError("Failed to decode last activity response", err)
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make(map[string]time.Time, len(response.Data))
+	for _, a := range response.Data {
+		activity[a.SubjectWalletID] = a.LastActivityAt
+	}
+	return activity, nil
+}
+
+// LedgerBalancesResponse represents the API response wrapper for the ledger balances of wallets
+type LedgerBalancesResponse struct {
+	Data []model.LedgerBalance `json:"data"`
+}
+
+// FetchLedgerBalances retrieves the ledger balance of every wallet at a
+// point in time: the credits minus the debits of its completed entries
+// created at or before it. Wallets without entries are not in the returned map.
+func (tc *transactionClient) FetchLedgerBalances(ctx context.Context, at time.Time) (_ map[string]int64, err error) {
+	defer func(start time.Time) { metrics.ObserveTransactionClient("fetch_ledger_balances", start, err) }(time.Now())
+
+	query := url.Values{"at": {at.UTC().Format(time.RFC3339Nano)}}
+	endpoint := fmt.Sprintf("%s/api/v1/balances?%s", tc.baseURL, query.Encode())
+
+	// Reads are idempotent and safe to retry
+	var response LedgerBalancesResponse
+	err = tc.execute(ctx, "fetch_ledger_balances", callRetry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := tc.client.Do(req)
+		if err != nil {
+			utils.LogError("Failed to send fetch ledger balances request", err)
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+			return &StatusError{StatusCode: resp.StatusCode}
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			utils.LogError("Failed to decode ledger balances response", err)
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]int64, len(response.Data))
+	for _, b := range response.Data {
+		balances[b.SubjectWalletID] = b.Balance
+	}
+	return balances, nil
+}
+
+// Ping checks that the transactions service is reachable via its health endpoint.
+// It bypasses the circuit breaker so readiness reflects the service itself.
+func (tc *transactionClient) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, tc.timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/v1/health", tc.baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
 
-	// Send the request
 	resp, err := tc.client.Do(req)
 	if err != nil {
-		utils.LogError("Failed to send transaction pair request", err)
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Check response status
-	if resp.StatusCode != http.StatusCreated {
-		utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
-		return fmt.Errorf("transaction service returned status %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode}
 	}
-
-	// Successfully created transaction pair
 	return nil
 }
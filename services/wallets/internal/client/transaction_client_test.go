@@ -0,0 +1,313 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(baseURL string) model.Service {
+	return model.Service{
+		BaseURL: baseURL,
+		Retry: model.Retry{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+		},
+		CircuitBreaker: model.CircuitBreaker{
+			FailureThreshold: 2,
+			OpenTimeout:      time.Minute,
+		},
+	}
+}
+
+func TestFetchTransactions_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"subject_wallet_id":"user-001","amount":500}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := testConfig(srv.URL)
+	cfg.CircuitBreaker.FailureThreshold = 5
+	tc := NewTransactionClient(cfg, time.Second)
+
+	txns, err := tc.FetchTransactions(context.Background(), "user-001")
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, int64(500), txns[0].Amount)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestFetchTransactions_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	tc := NewTransactionClient(testConfig(srv.URL), time.Second)
+
+	_, err := tc.FetchTransactions(context.Background(), "user-001")
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestFetchLastActivity(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"subject_wallet_id":"user-001","last_activity_at":"2024-05-01T10:00:00Z"}]}`))
+	}))
+	defer srv.Close()
+
+	tc := NewTransactionClient(testConfig(srv.URL), time.Second)
+
+	activity, err := tc.FetchLastActivity(context.Background(), []string{"user-001", "user-002"})
+	require.NoError(t, err)
+	assert.Equal(t, "subject_wallet_id=user-001&subject_wallet_id=user-002", query)
+	assert.Equal(t, map[string]time.Time{"user-001": time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}, activity)
+}
+
+func TestFetchTransactionPage(t *testing.T) {
+	var path string
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"subject_wallet_id":"user-001","amount":500}]}`))
+	}))
+	defer srv.Close()
+
+	tc := NewTransactionClient(testConfig(srv.URL), time.Second)
+
+	txns, err := tc.FetchTransactionPage(context.Background(), "user-001", model.TransactionPage{
+		After:   time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC),
+		AfterID: 42,
+		Limit:   500,
+	})
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	assert.Equal(t, "/api/v1/transactions/user-001/entries", path)
+	assert.Equal(t, url.Values{"after": {"2024-05-01T10:00:00.123456Z"}, "after_id": {"42"}, "limit": {"500"}}, query)
+}
+
+func TestFetchLedgerBalances(t *testing.T) {
+	var at string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		at = r.URL.Query().Get("at")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"subject_wallet_id":"user-001","balance":3800},{"subject_wallet_id":"deposit-provider-master","balance":-5000}]}`))
+	}))
+	defer srv.Close()
+
+	tc := NewTransactionClient(testConfig(srv.URL), time.Second)
+
+	balances, err := tc.FetchLedgerBalances(context.Background(), time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-01T10:00:00Z", at)
+	assert.Equal(t, map[string]int64{"user-001": 3800, "deposit-provider-master": -5000}, balances)
+}
+
+func TestCreateTransactionPair_RetriesWithSameIdempotencyKey(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req TransactionPairRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		keys = append(keys, req.IdempotencyKey)
+		if len(keys) < 5 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	tc := NewTransactionClient(testConfig(srv.URL), time.Second)
+
+	// Ledger writes outlast MaxAttempts and the open circuit
+	err := tc.CreateTransactionPair(context.Background(), &model.Transaction{}, &model.Transaction{})
+	require.NoError(t, err)
+	require.Len(t, keys, 5)
+	assert.NotEmpty(t, keys[0])
+	for _, key := range keys {
+		assert.Equal(t, keys[0], key)
+	}
+}
+
+func TestCreateTransactionPair_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	tc := NewTransactionClient(testConfig(srv.URL), time.Second)
+
+	err := tc.CreateTransactionPair(context.Background(), &model.Transaction{}, &model.Transaction{})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCreateTransactionPair_GivesUpAfterDeliveryTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cfg := testConfig(srv.URL)
+	cfg.LedgerDeliveryTimeout = 50 * time.Millisecond
+	tc := NewTransactionClient(cfg, time.Second)
+
+	start := time.Now()
+	err := tc.CreateTransactionPair(context.Background(), &model.Transaction{}, &model.Transaction{})
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	cfg := testConfig(srv.URL)
+	cfg.Retry.MaxAttempts = 1
+	tc := NewTransactionClient(cfg, time.Second).(*transactionClient)
+	now := time.Now()
+	tc.breaker.now = func() time.Time { return now }
+	fetch := func() error {
+		_, err := tc.FetchTransactions(context.Background(), "user-001")
+		return err
+	}
+
+	// Two consecutive failures reach the threshold and open the circuit
+	require.Error(t, fetch())
+	require.Error(t, fetch())
+	assert.Equal(t, CircuitOpen, tc.breaker.State())
+
+	// While open, calls are rejected without reaching the service
+	healthy.Store(true)
+	assert.ErrorIs(t, fetch(), ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Ledger writes are still delivered while the circuit is open
+	require.NoError(t, tc.CreateTransactionPair(context.Background(), &model.Transaction{}, &model.Transaction{}))
+	assert.Equal(t, CircuitOpen, tc.breaker.State())
+	assert.Equal(t, int32(3), calls.Load())
+
+	// After the open timeout a trial call is let through and closes the circuit
+	now = now.Add(time.Minute)
+	require.NoError(t, fetch())
+	assert.Equal(t, CircuitClosed, tc.breaker.State())
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestCircuitBreaker_FailedTrialReopens(t *testing.T) {
+	var transitions []string
+	b := newCircuitBreaker(1, time.Minute, func(from, to CircuitState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	require.NoError(t, b.allow())
+	b.failure()
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, b.allow())
+	// Only one trial call is allowed while half-open
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+	b.failure()
+	assert.Equal(t, CircuitOpen, b.State())
+
+	assert.Equal(t, []string{"closed->open", "open->half_open", "half_open->open"}, transitions)
+}
+
+func TestBulkhead_RejectsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	cfg := testConfig(srv.URL)
+	cfg.MaxConcurrent = 1
+	tc := NewTransactionClient(cfg, time.Second)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := tc.FetchTransactions(context.Background(), "user-001")
+		assert.NoError(t, err)
+	}()
+	<-started
+
+	_, err := tc.FetchTransactions(context.Background(), "user-001")
+	assert.True(t, errors.Is(err, ErrBulkheadFull))
+
+	// Ledger writes do not take a slot
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, tc.CreateTransactionPair(context.Background(), &model.Transaction{}, &model.Transaction{}))
+	}()
+	<-started
+
+	close(release)
+	wg.Wait()
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := backoff(attempt, 100*time.Millisecond, time.Second)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, time.Second)
+	}
+	d := backoff(1, 100*time.Millisecond, time.Second)
+	assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+	assert.LessOrEqual(t, d, 100*time.Millisecond)
+}
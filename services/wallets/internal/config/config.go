@@ -13,3 +13,159 @@ func SetGlobalConfig(cfg *model.Config) {
 func GetGlobalConfig() *model.Config {
 	return globalConfig
 }
+
+// GetTimeouts returns the configured operation deadlines, falling back to
+// model.DefaultTimeouts for any value that is unset.
+func GetTimeouts() model.Timeouts {
+	timeouts := model.DefaultTimeouts()
+	if globalConfig == nil {
+		return timeouts
+	}
+	if globalConfig.Timeouts.Database > 0 {
+		timeouts.Database = globalConfig.Timeouts.Database
+	}
+	if globalConfig.Timeouts.Cache > 0 {
+		timeouts.Cache = globalConfig.Timeouts.Cache
+	}
+	if globalConfig.Timeouts.TransactionService > 0 {
+		timeouts.TransactionService = globalConfig.Timeouts.TransactionService
+	}
+	return timeouts
+}
+
+// GetConcurrency returns the configured concurrency settings, falling back to
+// model.DefaultConcurrency for any value that is unset.
+func GetConcurrency() model.Concurrency {
+	concurrency := model.DefaultConcurrency()
+	if globalConfig == nil {
+		return concurrency
+	}
+	if globalConfig.Concurrency.Mode != "" {
+		concurrency.Mode = globalConfig.Concurrency.Mode
+	}
+	if globalConfig.Concurrency.MaxRetries > 0 {
+		concurrency.MaxRetries = globalConfig.Concurrency.MaxRetries
+	}
+	concurrency.ProviderShards = globalConfig.Concurrency.ProviderShards
+	return concurrency
+}
+
+// GetPaymentRequests returns the configured payment request settings, falling
+// back to model.DefaultPaymentRequests for any value that is unset.
+func GetPaymentRequests() model.PaymentRequests {
+	paymentRequests := model.DefaultPaymentRequests()
+	if globalConfig == nil {
+		return paymentRequests
+	}
+	if globalConfig.PaymentRequests.DefaultExpiry > 0 {
+		paymentRequests.DefaultExpiry = globalConfig.PaymentRequests.DefaultExpiry
+	}
+	if globalConfig.PaymentRequests.MaxExpiry > 0 {
+		paymentRequests.MaxExpiry = globalConfig.PaymentRequests.MaxExpiry
+	}
+	paymentRequests.WebhookURL = globalConfig.PaymentRequests.WebhookURL
+	paymentRequests.WebhookSecret = globalConfig.PaymentRequests.WebhookSecret
+	return paymentRequests
+}
+
+// GetEscrowExpiry returns the configured escrow expiry settings, falling back
+// to model.DefaultEscrowExpiry for any value that is unset.
+func GetEscrowExpiry() model.EscrowExpiry {
+	escrowExpiry := model.DefaultEscrowExpiry()
+	if globalConfig == nil {
+		return escrowExpiry
+	}
+	defaults := escrowExpiry
+	escrowExpiry = globalConfig.EscrowExpiry
+	if escrowExpiry.Interval <= 0 {
+		escrowExpiry.Interval = defaults.Interval
+	}
+	if escrowExpiry.MaxRefundAttempts <= 0 {
+		escrowExpiry.MaxRefundAttempts = defaults.MaxRefundAttempts
+	}
+	return escrowExpiry
+}
+
+// GetSettlement returns the configured settlement settings, falling back to
+// model.DefaultSettlement for any value that is unset.
+func GetSettlement() model.Settlement {
+	settlement := model.DefaultSettlement()
+	if globalConfig == nil {
+		return settlement
+	}
+	interval := settlement.Interval
+	settlement = globalConfig.Settlement
+	if settlement.Interval <= 0 {
+		settlement.Interval = interval
+	}
+	return settlement
+}
+
+// GetDormancy returns the configured dormancy settings, falling back to
+// model.DefaultDormancy for any value that is unset.
+func GetDormancy() model.Dormancy {
+	dormancy := model.DefaultDormancy()
+	if globalConfig == nil {
+		return dormancy
+	}
+	defaults := dormancy
+	dormancy = globalConfig.Dormancy
+	if dormancy.RunAt == "" {
+		dormancy.RunAt = defaults.RunAt
+	}
+	if dormancy.Period <= 0 {
+		dormancy.Period = defaults.Period
+	}
+	if dormancy.Action == "" {
+		dormancy.Action = defaults.Action
+	}
+	if dormancy.ProviderID == "" {
+		dormancy.ProviderID = defaults.ProviderID
+	}
+	return dormancy
+}
+
+// GetKYC returns the configured KYC settings, falling back to
+// model.DefaultKYC for any tier that is unset.
+func GetKYC() model.KYC {
+	kyc := model.DefaultKYC()
+	if globalConfig == nil {
+		return kyc
+	}
+	defaults := kyc
+	kyc = globalConfig.KYC
+	if kyc.None == (model.KYCTier{}) {
+		kyc.None = defaults.None
+	}
+	if kyc.Basic == (model.KYCTier{}) {
+		kyc.Basic = defaults.Basic
+	}
+	if kyc.Full == (model.KYCTier{}) {
+		kyc.Full = defaults.Full
+	}
+	return kyc
+}
+
+// GetBalanceLimits returns the configured balance ceilings, falling back to
+// model.DefaultBalanceLimits if they are not configured.
+func GetBalanceLimits() model.BalanceLimits {
+	if globalConfig == nil {
+		return model.DefaultBalanceLimits()
+	}
+	return globalConfig.BalanceLimits
+}
+
+// GetProviders returns the configured provider settings, falling back to
+// model.DefaultProviders for any value that is unset.
+func GetProviders() model.Providers {
+	providers := model.DefaultProviders()
+	if globalConfig == nil {
+		return providers
+	}
+	interval := providers.Interval
+	providers = globalConfig.Providers
+	if providers.Interval <= 0 {
+		providers.Interval = interval
+	}
+	return providers
+}
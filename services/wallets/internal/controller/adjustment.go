@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// ProposeAdjustmentRequest is the request parameter for proposing a balance adjustment
+type ProposeAdjustmentRequest struct {
+	UserID        string              `json:"user_id" validate:"required"`
+	OperationType model.OperationType `json:"operation_type" validate:"required,oneof=credit debit"`
+	Amount        int                 `json:"amount" validate:"required,gt=0"`
+	Reason        string              `json:"reason" validate:"required,max=500"`
+	Evidence      []string            `json:"evidence,omitempty" validate:"max=10,dive,required,max=500"`
+}
+
+// ListAdjustmentsRequest is the request parameter for listing balance adjustments by status
+type ListAdjustmentsRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending approved rejected"`
+}
+
+// AdjustmentRequest is the request parameter for an existing balance adjustment
+type AdjustmentRequest struct {
+	ID int `param:"id" validate:"required,gt=0"`
+}
+
+// ReviewAdjustmentRequest is the request parameter for approving or rejecting a balance adjustment
+type ReviewAdjustmentRequest struct {
+	ID   int    `param:"id" validate:"required,gt=0"`
+	Note string `json:"note,omitempty" validate:"max=500"`
+}
+
+// @Summary	Propose a balance adjustment
+// @Description	Proposes a manual credit or debit of a wallet against the adjustments provider wallet, with a reason and evidence, on behalf of the authenticated operator. Nothing moves until a different operator approves it.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		request	body		ProposeAdjustmentRequest	true	"Adjustment"
+// @Success	201		{object}	ResponseData{data=model.BalanceAdjustment}
+// @Failure	400		{object}	ResponseError
+// @Failure	401		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/adjustments [post]
+func (t *walletHandler) ProposeAdjustment(c echo.Context) error {
+	var req ProposeAdjustmentRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	operator := operatorID(c)
+	if operator == "" {
+		return unauthorized(c)
+	}
+
+	adjustment, err := t.service.ProposeAdjustment(c.Request().Context(), req.UserID, req.OperationType, req.Amount, req.Reason, req.Evidence, operator)
+	if err != nil {
+		return adjustmentError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: adjustment})
+}
+
+// @Summary	List balance adjustments
+// @Description	Lists the balance adjustments in a status, oldest first; pending ones by default, the review queue.
+// @Tags		admin
+// @Produce	json
+// @Param		status	query		string	false	"pending, approved or rejected"
+// @Success	200		{object}	ResponseData{data=[]model.BalanceAdjustment}
+// @Failure	400		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/adjustments [get]
+func (t *walletHandler) ListAdjustments(c echo.Context) error {
+	var req ListAdjustmentsRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	status := model.AdjustmentPending
+	if req.Status != "" {
+		status = model.AdjustmentStatus(req.Status)
+	}
+
+	adjustments, err := t.service.ListAdjustments(c.Request().Context(), status)
+	if err != nil {
+		return adjustmentError(c, err, "")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: adjustments})
+}
+
+// @Summary	Get a balance adjustment
+// @Tags		admin
+// @Produce	json
+// @Param		id	path		int	true	"Adjustment ID"
+// @Success	200	{object}	ResponseData{data=model.BalanceAdjustment}
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/admin/adjustments/{id} [get]
+func (t *walletHandler) GetAdjustment(c echo.Context) error {
+	var req AdjustmentRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	adjustment, err := t.service.GetAdjustment(c.Request().Context(), req.ID)
+	if err != nil {
+		return adjustmentError(c, err, "Adjustment not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: adjustment})
+}
+
+// @Summary	Approve a balance adjustment
+// @Description	Approves, as the authenticated operator, a pending balance adjustment proposed by another operator, applying it to the wallet and recording an adjustment ledger pair. If the change fails the adjustment stays pending.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int						true	"Adjustment ID"
+// @Param		request	body		ReviewAdjustmentRequest	false	"Note"
+// @Success	200		{object}	ResponseData{data=model.BalanceAdjustment}
+// @Failure	400		{object}	ResponseError
+// @Failure	401		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/adjustments/{id}/approve [post]
+func (t *walletHandler) ApproveAdjustment(c echo.Context) error {
+	return t.reviewAdjustment(c, true)
+}
+
+// @Summary	Reject a balance adjustment
+// @Description	Rejects, as the authenticated operator, a pending balance adjustment proposed by another operator; the wallet is left unchanged.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int						true	"Adjustment ID"
+// @Param		request	body		ReviewAdjustmentRequest	false	"Reason"
+// @Success	200		{object}	ResponseData{data=model.BalanceAdjustment}
+// @Failure	400		{object}	ResponseError
+// @Failure	401		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/adjustments/{id}/reject [post]
+func (t *walletHandler) RejectAdjustment(c echo.Context) error {
+	return t.reviewAdjustment(c, false)
+}
+
+func (t *walletHandler) reviewAdjustment(c echo.Context, approve bool) error {
+	var req ReviewAdjustmentRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	operator := operatorID(c)
+	if operator == "" {
+		return unauthorized(c)
+	}
+
+	adjustment, err := t.service.ReviewAdjustment(c.Request().Context(), req.ID, approve, operator, req.Note)
+	if err != nil {
+		return adjustmentError(c, err, "Adjustment not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: adjustment})
+}
+
+// adjustmentError writes the error response of the adjustment endpoints;
+// notFound is the message for ErrNotFound.
+func adjustmentError(c echo.Context, err error, notFound string) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: notFound}}})
+	case model.ErrEscrowWallet, model.ErrPocketWallet, model.ErrSameWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Escrow wallets, pockets and the adjustments provider wallet cannot be adjusted"}}})
+	case model.ErrSelfReview:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: "Adjustment must be reviewed by an operator other than the one who proposed it"}}})
+	case model.ErrInvalidTransition:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Adjustment was already reviewed"}}})
+	case model.ErrConcurrentUpdate:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+	case model.ErrWalletClosed:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is closed"}}})
+	case model.ErrWalletSuspended:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is suspended"}}})
+	case model.ErrInsufficientFunds:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
+	case model.ErrBalanceLimitExceeded:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBalanceLimitExceeded, Message: "Balance would exceed the wallet's limit"}}})
+	case model.ErrKYCUpgradeRequired:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeKYCUpgradeRequired, Message: "Balance would exceed the limit of the wallet's KYC level"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_Adjustments(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+		clearDB(dbInstance, model.BalanceAdjustment{})
+	}()
+
+	clearDB(dbInstance, model.BalanceAdjustment{}, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 500)
+	createTestWalletWithBalance(t, dbInstance, model.AdjustmentProviderID, model.Provider, 100000)
+
+	call := func(handle echo.HandlerFunc, method, path, id, operator, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		if operator != "" {
+			// As authenticated by AdminAuth
+			c.Set(operatorKey, operator)
+		}
+		if id != "" {
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+		}
+		require.NoError(t, handle(c))
+		return rec
+	}
+	propose := func(body string) *httptest.ResponseRecorder {
+		return call(handler.ProposeAdjustment, http.MethodPost, "/admin/adjustments", "", "ops-1", body)
+	}
+	review := func(handle echo.HandlerFunc, id int, reviewer string) *httptest.ResponseRecorder {
+		return call(handle, http.MethodPost, "/admin/adjustments/:id/review", strconv.Itoa(id), reviewer, `{}`)
+	}
+	decode := func(rec *httptest.ResponseRecorder) model.BalanceAdjustment {
+		var got struct {
+			Data model.BalanceAdjustment `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		return got.Data
+	}
+	balance := func(userID string) int64 {
+		var wallet model.Wallet
+		require.NoError(t, dbInstance.Where("user_id = ?", userID).Take(&wallet).Error)
+		return wallet.Balance
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, call(handler.ProposeAdjustment, http.MethodPost, "/admin/adjustments", "", "",
+		`{"user_id":"test-user-001", "operation_type":"credit", "amount":100, "reason":"x"}`).Code, "the proposer is the authenticated operator")
+	assert.Equal(t, http.StatusBadRequest, propose(`{"user_id":"test-user-001", "operation_type":"credit", "amount":100}`).Code, "reason is mandatory")
+	assert.Equal(t, http.StatusBadRequest, propose(`{"user_id":"test-user-001", "operation_type":"refund", "amount":100, "reason":"x"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, propose(`{"user_id":"`+model.AdjustmentProviderID+`", "operation_type":"credit", "amount":100, "reason":"x"}`).Code)
+	assert.Equal(t, http.StatusNotFound, propose(`{"user_id":"non-existent-user", "operation_type":"credit", "amount":100, "reason":"x"}`).Code)
+
+	// A debit above the balance stays pending until rejected
+	rec := propose(`{"user_id":"test-user-001", "operation_type":"debit", "amount":1000, "reason":"Duplicate deposit", "evidence":["TICKET-101"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	debit := decode(rec)
+	assert.Equal(t, model.AdjustmentPending, debit.Status)
+	assert.Equal(t, "ops-1", debit.ProposedBy)
+	assert.Equal(t, []string{"TICKET-101"}, debit.Evidence)
+	assert.Equal(t, http.StatusForbidden, review(handler.ApproveAdjustment, debit.ID, "ops-1").Code, "maker cannot check")
+	assert.Equal(t, http.StatusUnauthorized, review(handler.ApproveAdjustment, debit.ID, "").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, review(handler.ApproveAdjustment, debit.ID, "ops-2").Code)
+	assert.Equal(t, model.AdjustmentPending, decode(call(handler.GetAdjustment, http.MethodGet, "/admin/adjustments/:id", strconv.Itoa(debit.ID), "", "")).Status)
+	rec = review(handler.RejectAdjustment, debit.ID, "ops-2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, model.AdjustmentRejected, decode(rec).Status)
+	assert.Equal(t, http.StatusConflict, review(handler.ApproveAdjustment, debit.ID, "ops-3").Code)
+	assert.Equal(t, int64(500), balance("test-user-001"))
+
+	// An approved credit moves the funds from the adjustments provider
+	rec = propose(`{"user_id":"test-user-001", "operation_type":"credit", "amount":2500, "reason":"Missed refund"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	credit := decode(rec)
+	rec = review(handler.ApproveAdjustment, credit.ID, "ops-2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	approved := decode(rec)
+	assert.Equal(t, model.AdjustmentApproved, approved.Status)
+	assert.Equal(t, "ops-2", approved.Reviewer)
+	assert.NotNil(t, approved.ReviewedAt)
+	assert.Equal(t, int64(3000), balance("test-user-001"))
+	assert.Equal(t, int64(97500), balance(model.AdjustmentProviderID))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/adjustments?status=approved", nil)
+	rec = httptest.NewRecorder()
+	require.NoError(t, handler.ListAdjustments(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Data []model.BalanceAdjustment `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, credit.ID, list.Data[0].ID)
+	assert.Equal(t, http.StatusNotFound, call(handler.GetAdjustment, http.MethodGet, "/admin/adjustments/:id", "999999", "", "").Code)
+}
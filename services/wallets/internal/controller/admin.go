@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// operatorKey is the echo context key of the operator authenticated by AdminAuth.
+const operatorKey = "operator"
+
+// AdminAuth only lets through requests carrying the bearer token of one of
+// the operators, and records which operator made them for the handlers.
+func AdminAuth(operators []model.Operator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if ok && token != "" {
+				for _, operator := range operators {
+					if subtle.ConstantTimeCompare([]byte(token), []byte(operator.Token)) == 1 {
+						c.Set(operatorKey, operator.Name)
+						return next(c)
+					}
+				}
+			}
+			return unauthorized(c)
+		}
+	}
+}
+
+// operatorID returns the name of the operator authenticated by AdminAuth,
+// empty if the request did not go through it.
+func operatorID(c echo.Context) string {
+	name, _ := c.Get(operatorKey).(string)
+	return name
+}
+
+// unauthorized writes the response for an admin request without a valid
+// operator token.
+func unauthorized(c echo.Context) error {
+	return c.JSON(http.StatusUnauthorized,
+		ResponseError{Errors: []Error{{Code: errors.CodeUnauthorized, Message: "A valid operator token is required"}}})
+}
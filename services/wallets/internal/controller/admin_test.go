@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAuth(t *testing.T) {
+	e := echo.New()
+	e.GET("/admin", func(c echo.Context) error {
+		return c.String(http.StatusOK, operatorID(c))
+	}, AdminAuth([]model.Operator{
+		{Name: "ops-1", Token: "ops-1-token-0123456789"},
+		{Name: "ops-2", Token: "ops-2-token-0123456789"},
+	}))
+
+	tests := []struct {
+		name       string
+		header     string
+		statusCode int
+		operator   string
+	}{
+		{name: "first_operator", header: "Bearer ops-1-token-0123456789", statusCode: http.StatusOK, operator: "ops-1"},
+		{name: "second_operator", header: "Bearer ops-2-token-0123456789", statusCode: http.StatusOK, operator: "ops-2"},
+		{name: "no_token", header: "", statusCode: http.StatusUnauthorized},
+		{name: "empty_token", header: "Bearer ", statusCode: http.StatusUnauthorized},
+		{name: "unknown_token", header: "Bearer ops-3-token-0123456789", statusCode: http.StatusUnauthorized},
+		{name: "not_bearer", header: "ops-1-token-0123456789", statusCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.header != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode == http.StatusOK {
+				assert.Equal(t, tt.operator, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterAliasRequest is the request parameter for registering a wallet alias
+type RegisterAliasRequest struct {
+	UserID      string `param:"user_id" validate:"required"`
+	Alias       string `json:"alias" validate:"required"` // Phone number (E.164), email address or @handle
+	DisplayName string `json:"display_name" validate:"max=100"`
+}
+
+// ChallengeAliasRequest is the request parameter for sending a new verification code to a wallet alias
+type ChallengeAliasRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	Alias  string `json:"alias" validate:"required"`
+}
+
+// VerifyAliasRequest is the request parameter for confirming ownership of a wallet alias
+type VerifyAliasRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	Alias  string `json:"alias" validate:"required"`
+	Code   string `json:"code" validate:"required,len=6,numeric"` // Code sent to the phone number or email address
+}
+
+// ResolveAliasRequest is the request parameter for resolving an alias
+type ResolveAliasRequest struct {
+	Alias string `query:"alias" validate:"required"`
+}
+
+// @Summary	Register a phone, email or handle alias for a wallet
+// @Tags		aliases
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string					true	"User ID"
+// @Param		request	body		RegisterAliasRequest	true	"Alias"
+// @Success	201		{object}	ResponseData{data=model.WalletAlias}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/aliases [post]
+func (t *walletHandler) RegisterAlias(c echo.Context) error {
+	var req RegisterAliasRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	alias, err := t.service.RegisterAlias(c.Request().Context(), req.UserID, req.Alias, req.DisplayName)
+	if err != nil {
+		return aliasError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: alias})
+}
+
+// @Summary	List the aliases of a wallet
+// @Tags		aliases
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID"
+// @Success	200		{object}	ResponseData{data=[]model.WalletAlias}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/aliases [get]
+func (t *walletHandler) ListAliases(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	aliases, err := t.service.ListAliases(c.Request().Context(), req.UserID)
+	if err != nil {
+		return aliasError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: aliases})
+}
+
+// @Summary	Send a new verification code to a pending wallet alias
+// @Description	Registering a phone number or email address already sends a code; this replaces it, at most once a minute. Codes are valid for 15 minutes and 5 attempts.
+// @Tags		aliases
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string					true	"User ID"
+// @Param		request	body		ChallengeAliasRequest	true	"Alias"
+// @Success	202		{object}	ResponseData{data=model.WalletAlias}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	429		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/aliases/challenge [post]
+func (t *walletHandler) ChallengeAlias(c echo.Context) error {
+	var req ChallengeAliasRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	alias, err := t.service.ChallengeAlias(c.Request().Context(), req.UserID, req.Alias)
+	if err != nil {
+		return aliasError(c, err, "Alias not found")
+	}
+	return c.JSON(http.StatusAccepted, ResponseData{Data: alias})
+}
+
+// @Summary	Verify a wallet alias with the code sent to it
+// @Description	Confirms ownership of a phone number or email address with the code sent on registration or by the challenge endpoint. Only verified aliases can receive transfers.
+// @Tags		aliases
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string				true	"User ID"
+// @Param		request	body		VerifyAliasRequest	true	"Alias and code"
+// @Success	200		{object}	ResponseData{data=model.WalletAlias}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/aliases/verify [post]
+func (t *walletHandler) VerifyAlias(c echo.Context) error {
+	var req VerifyAliasRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	alias, err := t.service.VerifyAlias(c.Request().Context(), req.UserID, req.Alias, req.Code)
+	if err != nil {
+		return aliasError(c, err, "Alias not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: alias})
+}
+
+// @Summary	Resolve an alias to the masked name of its owner
+// @Description	Only verified aliases of active wallets resolve. The response never includes the user ID.
+// @Tags		aliases
+// @Produce	json
+// @Param		alias	query		string	true	"Phone number (E.164), email address or @handle"
+// @Success	200		{object}	ResponseData{data=model.ResolvedAlias}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/resolve [get]
+func (t *walletHandler) ResolveAlias(c echo.Context) error {
+	var req ResolveAliasRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	resolved, err := t.service.ResolveAlias(c.Request().Context(), req.Alias)
+	if err != nil {
+		return aliasError(c, err, "Alias not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: resolved})
+}
+
+// aliasError writes the error response of the alias endpoints.
+func aliasError(c echo.Context, err error, notFound string) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: notFound}}})
+	case model.ErrInvalidAlias:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Alias must be an E.164 phone number, an email address or an @handle"}}})
+	case model.ErrAliasTaken:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Alias is already registered"}}})
+	case model.ErrInvalidTransition:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Alias is already verified"}}})
+	case model.ErrInvalidChallengeCode:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Verification code is wrong"}}})
+	case model.ErrExpired:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Verification code has expired; request a new one"}}})
+	case model.ErrChallengeCooldown:
+		return c.JSON(http.StatusTooManyRequests,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Verification code was sent less than a minute ago"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
@@ -0,0 +1,363 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/notify"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_RegisterAlias(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	clearDB(dbInstance, model.WalletAlias{}, model.Wallet{})
+	createTestWallet(t, dbInstance, "test-user-001", model.User)
+	createTestWallet(t, dbInstance, "test-user-002", model.User)
+
+	tests := []struct {
+		name       string
+		userID     string
+		body       string
+		statusCode int
+		wantStatus model.AliasStatus
+	}{
+		{
+			name:       "handle_is_verified",
+			userID:     "test-user-001",
+			body:       `{"alias":"@Jane_Doe", "display_name":"Jane Doe"}`,
+			statusCode: http.StatusCreated,
+			wantStatus: model.AliasVerified,
+		},
+		{
+			name:       "email_is_pending",
+			userID:     "test-user-001",
+			body:       `{"alias":"jane@example.com"}`,
+			statusCode: http.StatusCreated,
+			wantStatus: model.AliasPending,
+		},
+		{
+			name:       "taken_by_another_wallet",
+			userID:     "test-user-002",
+			body:       `{"alias":"@jane_doe"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "invalid_alias",
+			userID:     "test-user-001",
+			body:       `{"alias":"not an alias"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "wallet_not_found",
+			userID:     "non-existent-user",
+			body:       `{"alias":"@nobody"}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/wallets/"+tt.userID+"/aliases", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/aliases")
+			c.SetParamNames("user_id")
+			c.SetParamValues(tt.userID)
+
+			require.NoError(t, handler.RegisterAlias(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+			var got struct {
+				Data model.WalletAlias `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.wantStatus, got.Data.Status)
+		})
+	}
+}
+
+// aliasCodeNotifier records the verification codes sent to aliases.
+type aliasCodeNotifier struct {
+	notify.Notifier
+	codes map[string]string
+}
+
+func (n *aliasCodeNotifier) NotifyAliasChallenge(_ context.Context, event model.AliasChallengeEvent) {
+	n.codes[event.Alias] = event.Code
+}
+
+// captureAliasCodes returns the latest verification code sent to each alias
+// until reset is called.
+func captureAliasCodes() (codes map[string]string, reset func()) {
+	n := &aliasCodeNotifier{Notifier: notify.New(model.PaymentRequests{}), codes: map[string]string{}}
+	patches := gomonkey.ApplyFunc(notify.NewNotifier, func() notify.Notifier { return n })
+	return n.codes, patches.Reset
+}
+
+func TestWalletHandler_VerifyAlias(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletService := service.NewWalletService(repository.NewWalletRepo(dbInstance))
+	handler := NewWalletController(walletService)
+
+	codes, reset := captureAliasCodes()
+	defer reset()
+	clearDB(dbInstance, model.WalletAlias{}, model.Wallet{})
+	createTestWallet(t, dbInstance, "test-user-001", model.User)
+	ctx := context.Background()
+	_, err = walletService.RegisterAlias(ctx, "test-user-001", "jane@example.com", "")
+	require.NoError(t, err)
+	_, err = walletService.RegisterAlias(ctx, "test-user-001", "@jane", "")
+	require.NoError(t, err)
+	code := codes["jane@example.com"]
+	require.Len(t, code, 6)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "000001"
+	}
+
+	post := func(t *testing.T, path string, handle echo.HandlerFunc, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/wallets/test-user-001/aliases/"+path, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/wallets/:user_id/aliases/" + path)
+		c.SetParamNames("user_id")
+		c.SetParamValues("test-user-001")
+		require.NoError(t, handle(c))
+		return rec.Code
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{name: "missing_code", path: "verify", body: `{"alias":"jane@example.com"}`, statusCode: http.StatusBadRequest},
+		{name: "wrong_code", path: "verify", body: `{"alias":"jane@example.com", "code":"` + wrong + `"}`, statusCode: http.StatusBadRequest},
+		{name: "resend_too_soon", path: "challenge", body: `{"alias":"jane@example.com"}`, statusCode: http.StatusTooManyRequests},
+		{name: "unknown_alias", path: "verify", body: `{"alias":"john@example.com", "code":"123456"}`, statusCode: http.StatusNotFound},
+		{name: "right_code", path: "verify", body: `{"alias":"jane@example.com", "code":"` + code + `"}`, statusCode: http.StatusOK},
+		{name: "challenge_verified_alias", path: "challenge", body: `{"alias":"@jane"}`, statusCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle := handler.VerifyAlias
+			if tt.path == "challenge" {
+				handle = handler.ChallengeAlias
+			}
+			assert.Equal(t, tt.statusCode, post(t, tt.path, handle, tt.body))
+		})
+	}
+
+	t.Run("too_many_attempts", func(t *testing.T) {
+		_, err := walletService.RegisterAlias(ctx, "test-user-001", "+14155550100", "")
+		require.NoError(t, err)
+		code := codes["+14155550100"]
+		wrong := "000000"
+		if code == wrong {
+			wrong = "000001"
+		}
+		for i := 0; i < model.MaxAliasChallengeAttempts; i++ {
+			assert.Equal(t, http.StatusBadRequest, post(t, "verify", handler.VerifyAlias, `{"alias":"+14155550100", "code":"`+wrong+`"}`))
+		}
+		assert.Equal(t, http.StatusConflict, post(t, "verify", handler.VerifyAlias, `{"alias":"+14155550100", "code":"`+code+`"}`))
+	})
+}
+
+func TestWalletHandler_ResolveAlias(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletService := service.NewWalletService(repository.NewWalletRepo(dbInstance))
+	handler := NewWalletController(walletService)
+
+	clearDB(dbInstance, model.WalletAlias{}, model.Wallet{})
+	createTestWallet(t, dbInstance, "test-user-001", model.User)
+	ctx := context.Background()
+	_, err = walletService.RegisterAlias(ctx, "test-user-001", "@jane", "Jane Doe")
+	require.NoError(t, err)
+	codes, reset := captureAliasCodes()
+	defer reset()
+	_, err = walletService.RegisterAlias(ctx, "test-user-001", "+14155550100", "")
+	require.NoError(t, err)
+	_, err = walletService.RegisterAlias(ctx, "test-user-001", "jane@example.com", "")
+	require.NoError(t, err)
+	_, err = walletService.VerifyAlias(ctx, "test-user-001", "+1 415 555 0100", codes["+14155550100"])
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		alias      string
+		statusCode int
+		want       model.ResolvedAlias
+	}{
+		{
+			name:       "handle",
+			alias:      "@Jane",
+			statusCode: http.StatusOK,
+			want:       model.ResolvedAlias{Alias: "@jane", Type: model.AliasHandle, DisplayName: "J*** D***"},
+		},
+		{
+			name:       "verified_phone_without_display_name",
+			alias:      "+1-415-555-0100",
+			statusCode: http.StatusOK,
+			want:       model.ResolvedAlias{Alias: "+14155550100", Type: model.AliasPhone, DisplayName: "t***"},
+		},
+		{
+			name:       "unverified_email",
+			alias:      "jane@example.com",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "unknown_alias",
+			alias:      "@nobody",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "invalid_alias",
+			alias:      "nobody",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/wallets/resolve?"+url.Values{"alias": {tt.alias}}.Encode(), nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/resolve")
+
+			require.NoError(t, handler.ResolveAlias(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "test-user-001")
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var got struct {
+				Data model.ResolvedAlias `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got.Data)
+		})
+	}
+}
+
+func TestWalletHandler_TransferToAlias(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletService := service.NewWalletService(repository.NewWalletRepo(dbInstance))
+	handler := NewWalletController(walletService)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "successful_transfer",
+			body:       `{"from_user_id":"test-user-001", "to_alias":"@receiver", "amount":3000}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "unverified_alias",
+			body:       `{"from_user_id":"test-user-001", "to_alias":"receiver@example.com", "amount":3000}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "own_alias",
+			body:       `{"from_user_id":"test-user-001", "to_alias":"@sender", "amount":3000}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "both_user_id_and_alias",
+			body:       `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "to_alias":"@receiver", "amount":3000}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.WalletAlias{}, model.Wallet{})
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			ctx := context.Background()
+			_, err := walletService.RegisterAlias(ctx, "test-user-001", "@sender", "")
+			require.NoError(t, err)
+			_, err = walletService.RegisterAlias(ctx, "test-user-002", "@receiver", "")
+			require.NoError(t, err)
+			_, err = walletService.RegisterAlias(ctx, "test-user-002", "receiver@example.com", "")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/wallets/transfer", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/transfer")
+
+			require.NoError(t, handler.Transfer(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+			receiver, err := repository.NewWalletRepo(dbInstance).FindByUserID(ctx, "test-user-002")
+			require.NoError(t, err)
+			assert.Equal(t, int64(3000), receiver.Balance)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// BalanceAtRequest is the request parameter for the balance of a wallet at a point in time
+type BalanceAtRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	At     string `query:"at"` // RFC 3339 timestamp, defaults to now
+}
+
+// DailyBalancesRequest is the request parameter for the daily balance series of a wallet
+type DailyBalancesRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	From   string `query:"from" validate:"required"` // YYYY-MM-DD
+	To     string `query:"to" validate:"required"`   // YYYY-MM-DD
+}
+
+// @Summary	View wallet balance at a point in time
+// @Tags		wallets
+// @Param		user_id	path		string	true	"User ID"
+// @Param		at		query		string	false	"RFC 3339 timestamp, defaults to now"
+// @Success	200		{object}	ResponseData{data=model.BalanceAt}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/balance [get]
+func (t *walletHandler) BalanceAt(c echo.Context) error {
+	var req BalanceAtRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	at := time.Now()
+	if req.At != "" {
+		parsed, err := time.Parse(time.RFC3339, req.At)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "at must be an RFC 3339 timestamp"}}})
+		}
+		at = parsed
+	}
+
+	balance, err := t.service.GetBalanceAt(c.Request().Context(), req.UserID, at)
+	if err != nil {
+		return balanceHistoryError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: balance})
+}
+
+// @Summary	View daily end-of-day wallet balances
+// @Tags		wallets
+// @Param		user_id	path		string	true	"User ID"
+// @Param		from	query		string	true	"First day (YYYY-MM-DD, UTC)"
+// @Param		to		query		string	true	"Last day (YYYY-MM-DD, UTC)"
+// @Success	200		{object}	ResponseData{data=[]model.DailyBalance}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/balance/daily [get]
+func (t *walletHandler) DailyBalances(c echo.Context) error {
+	var req DailyBalancesRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	from, err := time.Parse(model.DateLayout, req.From)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "from must be a YYYY-MM-DD date"}}})
+	}
+	to, err := time.Parse(model.DateLayout, req.To)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "to must be a YYYY-MM-DD date"}}})
+	}
+
+	series, err := t.service.GetDailyBalances(c.Request().Context(), req.UserID, from, to)
+	if err != nil {
+		return balanceHistoryError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: series})
+}
+
+// balanceHistoryError writes the response for an error from a balance history or statement query
+func balanceHistoryError(c echo.Context, err error) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+	case model.ErrInvalidTimeRange:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Requested time range is reversed, in the future or too long"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_BalanceLimits(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+		config.SetGlobalConfig(nil)
+	}()
+
+	post := func(handle echo.HandlerFunc, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handle(e.NewContext(req, rec)))
+		return rec
+	}
+	balanceOf := func(userID string) int64 {
+		var w model.Wallet
+		require.NoError(t, dbInstance.Where("user_id = ?", userID).Take(&w).Error)
+		return w.Balance
+	}
+
+	for _, mode := range []string{model.ConcurrencyPessimistic, model.ConcurrencyAtomic} {
+		t.Run(mode, func(t *testing.T) {
+			cfg := &model.Config{
+				Concurrency: model.Concurrency{Mode: mode},
+				BalanceLimits: model.BalanceLimits{
+					Enable: true,
+					Limits: []model.BalanceLimit{
+						{AcntType: model.User, MaxBalance: 1000},
+						{AcntType: model.User, KYCLevel: model.KYCFull, MaxBalance: 5000},
+					},
+				},
+			}
+			config.SetGlobalConfig(cfg)
+			handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+			clearDB(dbInstance, model.Wallet{})
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 900)
+			createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, 2000)
+			createTestWalletWithBalance(t, dbInstance, model.DepositProviderID, model.Provider, 100000)
+
+			rec := post(handler.Deposit, `{"user_id":"test-user-001", "amount":101}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+			assert.Contains(t, rec.Body.String(), errors.CodeBalanceLimitExceeded)
+			assert.Equal(t, http.StatusCreated, post(handler.Deposit, `{"user_id":"test-user-001", "amount":100}`).Code)
+
+			rec = post(handler.Transfer, `{"from_user_id":"test-user-002", "to_user_id":"test-user-001", "amount":1}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+			assert.Contains(t, rec.Body.String(), errors.CodeBalanceLimitExceeded)
+			assert.Equal(t, int64(2000), balanceOf("test-user-002"), "a failed transfer moves nothing")
+
+			// A fully verified holder has a higher ceiling
+			require.NoError(t, dbInstance.Model(&model.Wallet{}).Where("user_id = ?", "test-user-001").
+				Update("kyc_level", model.KYCFull).Error)
+			assert.Equal(t, http.StatusCreated, post(handler.Transfer, `{"from_user_id":"test-user-002", "to_user_id":"test-user-001", "amount":1500}`).Code)
+			assert.Equal(t, int64(2500), balanceOf("test-user-001"))
+
+			// The ceiling covers the wallet and its pockets together
+			var owner model.Wallet
+			require.NoError(t, dbInstance.Where("user_id = ?", "test-user-001").Take(&owner).Error)
+			pocket := model.NewPocket(&owner, "savings")
+			pocket.Balance = 2000
+			require.NoError(t, dbInstance.Create(pocket).Error)
+			rec = post(handler.Deposit, `{"user_id":"test-user-001", "amount":501}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+			assert.Contains(t, rec.Body.String(), errors.CodeBalanceLimitExceeded)
+			assert.Equal(t, http.StatusCreated, post(handler.Deposit, `{"user_id":"test-user-001", "amount":500}`).Code)
+		})
+	}
+
+	t.Run("auto_bounce", func(t *testing.T) {
+		config.SetGlobalConfig(&model.Config{BalanceLimits: model.BalanceLimits{
+			Enable:     true,
+			AutoBounce: true,
+			Limits:     []model.BalanceLimit{{AcntType: model.User, MaxBalance: 1000}},
+		}})
+		handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+		clearDB(dbInstance, model.Wallet{})
+		createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 900)
+		createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, 1000)
+
+		rec := post(handler.Transfer, `{"from_user_id":"test-user-002", "to_user_id":"test-user-001", "amount":300}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"group_id":"bounce-`)
+		assert.Equal(t, int64(1000), balanceOf("test-user-001"))
+		assert.Equal(t, int64(900), balanceOf("test-user-002"), "the excess went back to the sender")
+
+		rec = post(handler.Transfer, `{"from_user_id":"test-user-002", "to_user_id":"test-user-001", "amount":100}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, "nothing fits below the ceiling")
+		assert.Contains(t, rec.Body.String(), errors.CodeBalanceLimitExceeded)
+	})
+}
@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_BalanceAt(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Balance    int64
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewWalletRepo(dbInstance)
+	service := service.NewWalletService(repository)
+	handler := NewWalletController(service)
+
+	// The snapshot holds a ledger balance of 10000. The mock ledger has a 5000
+	// credit and a 2000 debit recorded long before it, so they are not added to
+	// it, and replaying them from zero gives 3000.
+	snapshotAt := time.Now().Add(-time.Hour).UTC()
+
+	tests := []struct {
+		name   string
+		userID string
+		at     string
+		want   want
+	}{
+		{
+			name:   "from_snapshot",
+			userID: "test-user-001",
+			want:   want{StatusCode: http.StatusOK, Balance: 10000},
+		},
+		{
+			name:   "before_first_snapshot",
+			userID: "test-user-001",
+			at:     snapshotAt.Add(-time.Minute).Format(time.RFC3339),
+			want:   want{StatusCode: http.StatusOK, Balance: 3000},
+		},
+		{
+			name:   "future_time",
+			userID: "test-user-001",
+			at:     time.Now().Add(time.Hour).Format(time.RFC3339),
+			want:   want{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name:   "invalid_time",
+			userID: "test-user-001",
+			at:     "yesterday",
+			want:   want{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name:   "wallet_not_found",
+			userID: "non-existent-user",
+			want:   want{StatusCode: http.StatusNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.ResetClient()
+			txnPatches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+				return &client.MockTransactionClient{}
+			})
+			defer func() {
+				txnPatches.Reset()
+				client.ResetClient()
+			}()
+
+			clearDB(dbInstance, model.BalanceSnapshot{}, model.Wallet{})
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			_, err := repository.CreateSnapshots(context.Background(), snapshotAt, map[string]int64{"test-user-001": 10000})
+			require.NoError(t, err)
+
+			query := url.Values{}
+			if tt.at != "" {
+				query.Set("at", tt.at)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/wallets/"+tt.userID+"/balance?"+query.Encode(), nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/balance")
+			c.SetParamNames("user_id")
+			c.SetParamValues(tt.userID)
+
+			require.NoError(t, handler.BalanceAt(c))
+
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+			if tt.want.StatusCode != http.StatusOK {
+				return
+			}
+			var got struct {
+				Data model.BalanceAt `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.want.Balance, got.Data.Balance)
+		})
+	}
+}
+
+func TestWalletHandler_DailyBalances(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	client.ResetClient()
+	txnPatches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	defer func() {
+		txnPatches.Reset()
+		client.ResetClient()
+	}()
+
+	clearDB(dbInstance, model.BalanceSnapshot{}, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+
+	today := time.Now().UTC()
+	tests := []struct {
+		name       string
+		from, to   string
+		statusCode int
+		want       []model.DailyBalance
+	}{
+		{
+			name:       "last_three_days",
+			from:       today.AddDate(0, 0, -2).Format(model.DateLayout),
+			to:         today.Format(model.DateLayout),
+			statusCode: http.StatusOK,
+			want: []model.DailyBalance{
+				{Date: today.AddDate(0, 0, -2).Format(model.DateLayout), Balance: 3000},
+				{Date: today.AddDate(0, 0, -1).Format(model.DateLayout), Balance: 3000},
+				{Date: today.Format(model.DateLayout), Balance: 3000},
+			},
+		},
+		{
+			name:       "reversed_range",
+			from:       today.Format(model.DateLayout),
+			to:         today.AddDate(0, 0, -1).Format(model.DateLayout),
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "range_too_long",
+			from:       today.AddDate(-2, 0, 0).Format(model.DateLayout),
+			to:         today.Format(model.DateLayout),
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid_date",
+			from:       "01/02/2024",
+			to:         today.Format(model.DateLayout),
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{"from": {tt.from}, "to": {tt.to}}
+			req := httptest.NewRequest(http.MethodGet, "/wallets/test-user-001/balance/daily?"+query.Encode(), nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/balance/daily")
+			c.SetParamNames("user_id")
+			c.SetParamValues("test-user-001")
+
+			require.NoError(t, handler.DailyBalances(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.want == nil {
+				return
+			}
+			var got struct {
+				Data []model.DailyBalance `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			if diff := cmp.Diff(got.Data, tt.want); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// CloseWalletRequest is the request parameter for closing a wallet. The
+// balance is withdrawn through the provider, the default withdrawal provider
+// if neither is given, or transferred to the wallet ToUserID.
+// ActingUserID is the owner member of a shared wallet closing it, empty for
+// the holder.
+type CloseWalletRequest struct {
+	UserID       string  `param:"user_id" validate:"required"`
+	ToProviderID *string `json:"to_provider_id,omitempty" validate:"excluded_with=ToUserID"`
+	ToUserID     string  `json:"to_user_id,omitempty"`
+	ActingUserID string  `json:"acting_user_id,omitempty"`
+}
+
+// @Summary	Close a wallet and sweep its balance
+// @Tags		wallets
+// @Accept		json
+// @Produce	json
+// @Description	Sweeps the balance of the wallet and its pockets to a withdrawal provider or another wallet, then closes them. Closed wallets keep their history but no money moves in or out of them. Only the holder or an owner member may close a wallet, and the closure of a shared wallet requiring approvals is held until enough owners approve it.
+// @Param		user_id	path		string				true	"User ID"
+// @Param		request	body		CloseWalletRequest	false	"Where the balance goes"
+// @Success	200		{object}	ResponseData{data=model.WalletClosure}
+// @Success	202		{object}	ResponseData{data=model.WalletApproval}
+// @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/close [post]
+func (t *walletHandler) CloseWallet(c echo.Context) error {
+	var req CloseWalletRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	closure, approval, err := t.service.CloseWallet(c.Request().Context(), req.ActingUserID, req.UserID, req.ToProviderID, req.ToUserID)
+	if err != nil {
+		return closureError(c, err)
+	}
+	if approval != nil {
+		return c.JSON(http.StatusAccepted, ResponseData{Data: approval})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: closure})
+}
+
+// @Summary	Reopen a closed wallet
+// @Tags		admin
+// @Produce	json
+// @Description	Admin override making a closed wallet and its pockets active again.
+// @Param		user_id	path		string	true	"User ID"
+// @Success	200		{object}	ResponseData{data=model.Wallet}
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/wallets/{user_id}/reopen [post]
+func (t *walletHandler) ReopenWallet(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	wallet, err := t.service.ReopenWallet(c.Request().Context(), req.UserID)
+	if err != nil {
+		if err == model.ErrInvalidTransition {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is not closed"}}})
+		}
+		return closureError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: wallet})
+}
+
+// closureError writes the error response of the wallet closure endpoints.
+func closureError(c echo.Context, err error) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+	case model.ErrWalletNotClosable:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Only user and merchant wallets can be closed"}}})
+	case model.ErrSameWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Cannot sweep the balance to the wallet or its pockets"}}})
+	case model.ErrEscrowWallet, model.ErrPocketWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Cannot sweep the balance to an escrow wallet or a pocket"}}})
+	case model.ErrUnknownProvider:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Provider ID is not a provider wallet"}}})
+	case model.ErrWalletClosed:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet or destination wallet is already closed"}}})
+	case model.ErrWalletSuspended:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet or destination wallet is suspended"}}})
+	case model.ErrBalanceLimitExceeded:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBalanceLimitExceeded, Message: "Balance would exceed the destination wallet's limit"}}})
+	case model.ErrOpenHolds:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet has operations awaiting approval or funded escrows"}}})
+	case model.ErrProviderDisabled:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Provider is disabled"}}})
+	case model.ErrNotMember:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: "Acting user is not a member of the wallet"}}})
+	case model.ErrMemberForbidden:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: "Only owners can close the wallet"}}})
+	case model.ErrKYCUpgradeRequired:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeKYCUpgradeRequired, Message: "KYC level does not allow this operation; verify the wallet to a higher level"}}})
+	case model.ErrConcurrentUpdate:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
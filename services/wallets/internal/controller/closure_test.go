@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWalletHandler_CloseWallet(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	tests := []struct {
+		name        string
+		userID      string
+		body        string
+		setup       func(t *testing.T, db *gorm.DB)
+		statusCode  int
+		swept       int64
+		sweptTo     string
+		destBalance int64
+	}{
+		{
+			name:        "sweep_to_default_provider",
+			userID:      "test-user-001",
+			statusCode:  http.StatusOK,
+			swept:       1500,
+			sweptTo:     model.WithdrawProviderID,
+			destBalance: 1500,
+		},
+		{
+			name:        "sweep_to_another_wallet",
+			userID:      "test-user-001",
+			body:        `{"to_user_id":"test-user-002"}`,
+			statusCode:  http.StatusOK,
+			swept:       1500,
+			sweptTo:     "test-user-002",
+			destBalance: 1500,
+		},
+		{
+			name:       "already_closed",
+			userID:     "test-user-001",
+			setup:      func(t *testing.T, db *gorm.DB) { closeTestWallet(t, db, "test-user-001") },
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:   "funded_escrow",
+			userID: "test-user-001",
+			setup: func(t *testing.T, db *gorm.DB) {
+				require.NoError(t, db.Create(&model.EscrowAgreement{
+					WalletUserID: "escrow-1", BuyerID: "test-user-002", SellerID: "test-user-001",
+					Amount: 100, Status: model.EscrowFunded, Deadline: time.Now().Add(time.Hour),
+				}).Error)
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "provider_wallet",
+			userID:     model.WithdrawProviderID,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "sweep_to_itself",
+			userID:     "test-user-001",
+			body:       `{"to_user_id":"test-user-001"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "both_destinations",
+			userID:     "test-user-001",
+			body:       `{"to_user_id":"test-user-002", "to_provider_id":"withdraw-provider-master"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "wallet_not_found",
+			userID:     "non-existent-user",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:   "owner_member",
+			userID: "test-user-001",
+			body:   `{"to_user_id":"test-user-002", "acting_user_id":"test-owner"}`,
+			setup: func(t *testing.T, db *gorm.DB) {
+				addTestMember(t, db, "test-user-001", "test-owner", model.MemberOwner)
+			},
+			statusCode:  http.StatusOK,
+			swept:       1500,
+			sweptTo:     "test-user-002",
+			destBalance: 1500,
+		},
+		{
+			name:   "spender_member",
+			userID: "test-user-001",
+			body:   `{"to_user_id":"test-user-002", "acting_user_id":"test-spender"}`,
+			setup: func(t *testing.T, db *gorm.DB) {
+				addTestMember(t, db, "test-user-001", "test-spender", model.MemberSpender)
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "not_a_member",
+			userID:     "test-user-001",
+			body:       `{"to_user_id":"test-user-002", "acting_user_id":"test-user-002"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:   "shared_wallet_held_for_approval",
+			userID: "test-user-001",
+			body:   `{"to_user_id":"test-user-002"}`,
+			setup: func(t *testing.T, db *gorm.DB) {
+				addTestMember(t, db, "test-user-001", "test-owner", model.MemberOwner)
+				require.NoError(t, db.Model(&model.Wallet{}).Where("user_id = ?", "test-user-001").Update("required_approvals", 2).Error)
+			},
+			statusCode: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.WalletApprovalVote{}, model.WalletApproval{}, model.WalletMember{}, model.EscrowAgreement{}, model.Wallet{})
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 1500)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			createTestWallet(t, dbInstance, model.WithdrawProviderID, model.Provider)
+			if tt.setup != nil {
+				tt.setup(t, dbInstance)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(tt.body)))
+			if tt.body != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/close")
+			c.SetParamNames("user_id")
+			c.SetParamValues(tt.userID)
+
+			require.NoError(t, handler.CloseWallet(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode == http.StatusAccepted {
+				var held struct {
+					Data model.WalletApproval `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &held))
+				assert.Equal(t, model.Closure, held.Data.TransactionType)
+				assert.Equal(t, int64(1500), held.Data.Amount)
+				assert.Equal(t, []string{tt.userID}, held.Data.ApprovedBy)
+				var wallet model.Wallet
+				require.NoError(t, dbInstance.Where("user_id = ?", tt.userID).Take(&wallet).Error)
+				assert.Equal(t, model.Active, wallet.Status, "the closure waits for the other owner")
+
+				// The other owner's approval carries it out
+				req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"member_user_id":"test-owner"}`)))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+				c.SetParamNames("user_id", "id")
+				c.SetParamValues(tt.userID, strconv.Itoa(held.Data.ID))
+				require.NoError(t, handler.ApproveOperation(c))
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &held))
+				assert.Equal(t, model.ApprovalExecuted, held.Data.Status, held.Data.Reason)
+				require.NoError(t, dbInstance.Where("user_id = ?", tt.userID).Take(&wallet).Error)
+				assert.Equal(t, model.Closed, wallet.Status)
+				return
+			}
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var got struct {
+				Data model.WalletClosure `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, model.Closed, got.Data.Status)
+			assert.Equal(t, tt.swept, got.Data.SweptAmount)
+			assert.Equal(t, tt.sweptTo, got.Data.SweptTo)
+
+			var closed, dest model.Wallet
+			require.NoError(t, dbInstance.Where("user_id = ?", tt.userID).Take(&closed).Error)
+			require.NoError(t, dbInstance.Where("user_id = ?", tt.sweptTo).Take(&dest).Error)
+			assert.Equal(t, model.Closed, closed.Status)
+			assert.NotNil(t, closed.ClosedAt)
+			assert.Zero(t, closed.Balance)
+			assert.Equal(t, tt.destBalance, dest.Balance)
+		})
+	}
+}
+
+func TestWalletHandler_ClosedWalletMoney(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	clearDB(dbInstance, model.EscrowAgreement{}, model.Wallet{})
+	createTestWallet(t, dbInstance, "test-user-001", model.User)
+	createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, 1000)
+	createTestWalletWithBalance(t, dbInstance, model.DepositProviderID, model.Provider, 1000)
+	closeTestWallet(t, dbInstance, "test-user-001")
+
+	post := func(handle echo.HandlerFunc, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handle(e.NewContext(req, rec)))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnprocessableEntity, post(handler.Deposit, `{"user_id":"test-user-001", "amount":100}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, post(handler.Transfer, `{"from_user_id":"test-user-002", "to_user_id":"test-user-001", "amount":100}`))
+
+	// History stays readable
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/wallets/:user_id")
+	c.SetParamNames("user_id")
+	c.SetParamValues("test-user-001")
+	require.NoError(t, handler.FetchTransactions(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"closed"`)
+
+	reopen := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/admin/wallets/:user_id/reopen")
+		c.SetParamNames("user_id")
+		c.SetParamValues("test-user-001")
+		require.NoError(t, handler.ReopenWallet(c))
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, reopen())
+	assert.Equal(t, http.StatusConflict, reopen(), "an active wallet cannot be reopened")
+	assert.Equal(t, http.StatusCreated, post(handler.Deposit, `{"user_id":"test-user-001", "amount":100}`))
+}
+
+// addTestMember authorizes memberID on the wallet of holderID, creating its wallet.
+func addTestMember(t *testing.T, db *gorm.DB, holderID, memberID string, role model.MemberRole) {
+	createTestWallet(t, db, memberID, model.User)
+	var holder model.Wallet
+	require.NoError(t, db.Where("user_id = ?", holderID).Take(&holder).Error)
+	member := model.WalletMember{WalletID: holder.ID, MemberID: memberID, Role: role}
+	if role == model.MemberSpender {
+		member.UnlimitedSpend = true
+	}
+	require.NoError(t, db.Create(&member).Error)
+}
+
+func closeTestWallet(t *testing.T, db *gorm.DB, userID string) {
+	require.NoError(t, db.Model(&model.Wallet{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"status": model.Closed, "closed_at": time.Now()}).Error)
+}
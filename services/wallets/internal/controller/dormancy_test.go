@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_DormantWallet(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletService := service.NewWalletService(repository.NewWalletRepo(dbInstance))
+	handler := NewWalletController(walletService)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	config.SetGlobalConfig(&model.Config{Dormancy: model.Dormancy{
+		Period:     24 * time.Hour,
+		Action:     model.DormancyActionFee,
+		Fee:        500,
+		ProviderID: model.WithdrawProviderID,
+	}})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+		config.SetGlobalConfig(nil)
+	}()
+
+	clearDB(dbInstance, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 300)
+	createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, 1000)
+	createTestWalletWithBalance(t, dbInstance, model.DepositProviderID, model.Provider, 1000)
+	createTestWallet(t, dbInstance, model.WithdrawProviderID, model.Provider)
+	// Only the first wallet has been idle for longer than the period
+	idle := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, dbInstance.Exec("UPDATE wallets SET created_at = ?, updated_at = ? WHERE user_id = ?",
+		idle, idle, "test-user-001").Error)
+
+	dormant, err := walletService.RunDormancy(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, dormant)
+
+	balanceOf := func(userID string) (model.Status, int64) {
+		var w model.Wallet
+		require.NoError(t, dbInstance.Where("user_id = ?", userID).Take(&w).Error)
+		return w.Status, w.Balance
+	}
+	status, balance := balanceOf("test-user-001")
+	assert.Equal(t, model.Inactive, status)
+	assert.Zero(t, balance, "the fee is capped at the balance")
+	_, balance = balanceOf(model.WithdrawProviderID)
+	assert.Equal(t, int64(300), balance)
+	status, _ = balanceOf("test-user-002")
+	assert.Equal(t, model.Active, status)
+
+	dormant, err = walletService.RunDormancy(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, dormant, "an inactive wallet is not charged again")
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"user_id":"test-user-001", "amount":100}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	require.NoError(t, handler.Deposit(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	status, balance = balanceOf("test-user-001")
+	assert.Equal(t, model.Active, status, "a deposit reactivates the wallet")
+	assert.Equal(t, int64(100), balance)
+}
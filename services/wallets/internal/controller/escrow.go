@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateEscrowRequest is the request parameter for funding an escrow from a buyer
+type CreateEscrowRequest struct {
+	BuyerUserID  string `json:"buyer_user_id" validate:"required"`
+	SellerUserID string `json:"seller_user_id" validate:"required"`
+	// ArbiterUserID can release, refund or split the escrow to settle a dispute
+	ArbiterUserID string    `json:"arbiter_user_id,omitempty"`
+	Amount        int       `json:"amount" validate:"required,gt=0"`
+	Deadline      time.Time `json:"deadline" validate:"required"` // RFC 3339; the escrow is refunded if still funded then
+	Description   string    `json:"description" validate:"max=500"`
+}
+
+// EscrowRequest is the request parameter for an existing escrow
+type EscrowRequest struct {
+	ID int `param:"id" validate:"required,gt=0"`
+}
+
+// SettleEscrowRequest is the request parameter for releasing or refunding an
+// escrow. ActingUserID is the buyer to release it, the seller to refund it,
+// or the arbiter to do either.
+type SettleEscrowRequest struct {
+	ID           int    `param:"id" validate:"required,gt=0"`
+	ActingUserID string `json:"acting_user_id" validate:"required"`
+}
+
+// SplitEscrowRequest is the request parameter for splitting an escrow between
+// seller and buyer, which only the arbiter can do
+type SplitEscrowRequest struct {
+	ID           int    `param:"id" validate:"required,gt=0"`
+	ActingUserID string `json:"acting_user_id" validate:"required"`
+	SellerAmount int    `json:"seller_amount" validate:"required,gt=0"` // Paid to the seller; the rest is refunded to the buyer
+}
+
+// @Summary	Fund an escrow from a buyer's wallet
+// @Tags		escrows
+// @Accept		json
+// @Produce	json
+// @Param		request	body		CreateEscrowRequest	true	"Escrow"
+// @Success	201		{object}	ResponseData{data=model.EscrowAgreement}
+// @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/escrows [post]
+func (t *walletHandler) CreateEscrow(c echo.Context) error {
+	var req CreateEscrowRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	escrow, err := t.service.CreateEscrow(c.Request().Context(),
+		req.BuyerUserID, req.SellerUserID, req.ArbiterUserID, req.Amount, req.Deadline, req.Description)
+	if err != nil {
+		return escrowError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: escrow})
+}
+
+// @Summary	View an escrow
+// @Tags		escrows
+// @Produce	json
+// @Param		id	path		int	true	"Escrow ID"
+// @Success	200	{object}	ResponseData{data=model.EscrowAgreement}
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/escrows/{id} [get]
+func (t *walletHandler) GetEscrow(c echo.Context) error {
+	var req EscrowRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	escrow, err := t.service.GetEscrow(c.Request().Context(), req.ID)
+	if err != nil {
+		return escrowError(c, err, "Escrow not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: escrow})
+}
+
+// @Summary	Release an escrow in full to the seller
+// @Description	Only the buyer or the arbiter can release an escrow.
+// @Tags		escrows
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int					true	"Escrow ID"
+// @Param		request	body		SettleEscrowRequest	true	"Acting user"
+// @Success	200		{object}	ResponseData{data=model.EscrowAgreement}
+// @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/escrows/{id}/release [post]
+func (t *walletHandler) ReleaseEscrow(c echo.Context) error {
+	var req SettleEscrowRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	escrow, err := t.service.ReleaseEscrow(c.Request().Context(), req.ID, req.ActingUserID)
+	if err != nil {
+		return escrowError(c, err, "Escrow not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: escrow})
+}
+
+// @Summary	Refund an escrow in full to the buyer
+// @Description	Only the seller or the arbiter can refund an escrow.
+// @Tags		escrows
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int					true	"Escrow ID"
+// @Param		request	body		SettleEscrowRequest	true	"Acting user"
+// @Success	200		{object}	ResponseData{data=model.EscrowAgreement}
+// @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/escrows/{id}/refund [post]
+func (t *walletHandler) RefundEscrow(c echo.Context) error {
+	var req SettleEscrowRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	escrow, err := t.service.RefundEscrow(c.Request().Context(), req.ID, req.ActingUserID)
+	if err != nil {
+		return escrowError(c, err, "Escrow not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: escrow})
+}
+
+// @Summary	Split an escrow between the seller and the buyer
+// @Description	Only the arbiter can split an escrow.
+// @Tags		escrows
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int					true	"Escrow ID"
+// @Param		request	body		SplitEscrowRequest	true	"Acting user and amount paid to the seller"
+// @Success	200		{object}	ResponseData{data=model.EscrowAgreement}
+// @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/escrows/{id}/split [post]
+func (t *walletHandler) SplitEscrow(c echo.Context) error {
+	var req SplitEscrowRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	escrow, err := t.service.SplitEscrow(c.Request().Context(), req.ID, req.ActingUserID, req.SellerAmount)
+	if err != nil {
+		return escrowError(c, err, "Escrow not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: escrow})
+}
+
+// escrowError writes the error response of the escrow endpoints.
+func escrowError(c echo.Context, err error, notFound string) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: notFound}}})
+	case model.ErrInvalidAmount:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Seller amount must be more than zero and less than the escrow amount"}}})
+	case model.ErrSameWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Buyer, seller and arbiter must be different wallets"}}})
+	case model.ErrKYCUpgradeRequired:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeKYCUpgradeRequired, Message: "KYC level does not allow this operation; verify the wallet to a higher level"}}})
+	case model.ErrNotEscrowParty:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: "Only the buyer can release, the seller refund and the arbiter split the escrow"}}})
+	case model.ErrEscrowWallet, model.ErrPocketWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Buyer and seller cannot be escrow wallets or pockets"}}})
+	case model.ErrInvalidTimeRange:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Deadline must be in the future"}}})
+	case model.ErrInvalidTransition:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Escrow is already settled"}}})
+	case model.ErrExpired:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Escrow deadline has passed; it can only be refunded"}}})
+	case model.ErrInsufficientFunds:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
+	case model.ErrApprovalRequired:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Amount needs approval by the buyer's owners"}}})
+	case model.ErrWalletClosed:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is closed"}}})
+	case model.ErrWalletSuspended:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is suspended"}}})
+	case model.ErrBalanceLimitExceeded:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBalanceLimitExceeded, Message: "Balance would exceed the wallet's limit"}}})
+	case model.ErrConcurrentUpdate:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
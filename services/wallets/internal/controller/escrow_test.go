@@ -0,0 +1,429 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_CreateEscrow(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	handler := NewWalletController(service.NewWalletService(walletRepository))
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	deadline := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name         string
+		body         string
+		statusCode   int
+		buyerBalance int64
+	}{
+		{
+			name:         "successful_funding",
+			body:         `{"buyer_user_id":"test-user-001", "seller_user_id":"test-user-002", "amount":4000, "deadline":"` + deadline + `"}`,
+			statusCode:   http.StatusCreated,
+			buyerBalance: 6000,
+		},
+		{
+			name:         "insufficient_funds",
+			body:         `{"buyer_user_id":"test-user-001", "seller_user_id":"test-user-002", "amount":20000, "deadline":"` + deadline + `"}`,
+			statusCode:   http.StatusUnprocessableEntity,
+			buyerBalance: 10000,
+		},
+		{
+			name:         "deadline_in_the_past",
+			body:         `{"buyer_user_id":"test-user-001", "seller_user_id":"test-user-002", "amount":4000, "deadline":"2020-01-01T00:00:00Z"}`,
+			statusCode:   http.StatusBadRequest,
+			buyerBalance: 10000,
+		},
+		{
+			name:         "buyer_is_seller",
+			body:         `{"buyer_user_id":"test-user-001", "seller_user_id":"test-user-001", "amount":4000, "deadline":"` + deadline + `"}`,
+			statusCode:   http.StatusBadRequest,
+			buyerBalance: 10000,
+		},
+		{
+			name:         "with_arbiter",
+			body:         `{"buyer_user_id":"test-user-001", "seller_user_id":"test-user-002", "arbiter_user_id":"test-user-003", "amount":4000, "deadline":"` + deadline + `"}`,
+			statusCode:   http.StatusCreated,
+			buyerBalance: 6000,
+		},
+		{
+			name:         "arbiter_is_seller",
+			body:         `{"buyer_user_id":"test-user-001", "seller_user_id":"test-user-002", "arbiter_user_id":"test-user-002", "amount":4000, "deadline":"` + deadline + `"}`,
+			statusCode:   http.StatusBadRequest,
+			buyerBalance: 10000,
+		},
+		{
+			name:         "seller_not_found",
+			body:         `{"buyer_user_id":"test-user-001", "seller_user_id":"non-existent-user", "amount":4000, "deadline":"` + deadline + `"}`,
+			statusCode:   http.StatusNotFound,
+			buyerBalance: 10000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.EscrowAgreement{}, model.Wallet{})
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			createTestWallet(t, dbInstance, "test-user-003", model.User)
+
+			req := httptest.NewRequest(http.MethodPost, "/escrows", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/escrows")
+
+			require.NoError(t, handler.CreateEscrow(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			buyer, err := walletRepository.FindByUserID(context.Background(), "test-user-001")
+			require.NoError(t, err)
+			assert.Equal(t, tt.buyerBalance, buyer.Balance)
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+
+			var got struct {
+				Data model.EscrowAgreement `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, model.EscrowFunded, got.Data.Status)
+			escrowWallet, err := walletRepository.FindByUserID(context.Background(), got.Data.WalletUserID)
+			require.NoError(t, err)
+			assert.Equal(t, model.Escrow, escrowWallet.AcntType)
+			assert.Equal(t, int64(4000), escrowWallet.Balance)
+		})
+	}
+}
+
+func TestWalletHandler_SettleEscrow(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	walletService := service.NewWalletService(walletRepository)
+	handler := NewWalletController(walletService)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	tests := []struct {
+		name          string
+		action        string
+		body          string
+		settleTwice   bool
+		pastDeadline  bool
+		statusCode    int
+		wantStatus    model.EscrowStatus
+		buyerBalance  int64
+		sellerBalance int64
+	}{
+		{
+			name:          "release",
+			action:        "release",
+			body:          `{"acting_user_id":"test-user-001"}`,
+			statusCode:    http.StatusOK,
+			wantStatus:    model.EscrowReleased,
+			buyerBalance:  6000,
+			sellerBalance: 4000,
+		},
+		{
+			name:          "refund",
+			action:        "refund",
+			body:          `{"acting_user_id":"test-user-002"}`,
+			statusCode:    http.StatusOK,
+			wantStatus:    model.EscrowRefunded,
+			buyerBalance:  10000,
+			sellerBalance: 0,
+		},
+		{
+			name:          "split",
+			action:        "split",
+			body:          `{"acting_user_id":"test-user-003", "seller_amount":2500}`,
+			statusCode:    http.StatusOK,
+			wantStatus:    model.EscrowSplit,
+			buyerBalance:  7500,
+			sellerBalance: 2500,
+		},
+		{
+			name:          "split_whole_amount",
+			action:        "split",
+			body:          `{"acting_user_id":"test-user-003", "seller_amount":4000}`,
+			statusCode:    http.StatusBadRequest,
+			wantStatus:    model.EscrowFunded,
+			buyerBalance:  6000,
+			sellerBalance: 0,
+		},
+		{
+			name:          "already_settled",
+			action:        "release",
+			body:          `{"acting_user_id":"test-user-001"}`,
+			settleTwice:   true,
+			statusCode:    http.StatusConflict,
+			wantStatus:    model.EscrowRefunded,
+			buyerBalance:  10000,
+			sellerBalance: 0,
+		},
+		{
+			name:          "release_after_deadline",
+			action:        "release",
+			body:          `{"acting_user_id":"test-user-003"}`,
+			pastDeadline:  true,
+			statusCode:    http.StatusConflict,
+			wantStatus:    model.EscrowFunded,
+			buyerBalance:  6000,
+			sellerBalance: 0,
+		},
+		{
+			name:          "seller_cannot_release",
+			action:        "release",
+			body:          `{"acting_user_id":"test-user-002"}`,
+			statusCode:    http.StatusForbidden,
+			wantStatus:    model.EscrowFunded,
+			buyerBalance:  6000,
+			sellerBalance: 0,
+		},
+		{
+			name:          "buyer_cannot_refund",
+			action:        "refund",
+			body:          `{"acting_user_id":"test-user-001"}`,
+			statusCode:    http.StatusForbidden,
+			wantStatus:    model.EscrowFunded,
+			buyerBalance:  6000,
+			sellerBalance: 0,
+		},
+		{
+			name:          "arbiter_refunds",
+			action:        "refund",
+			body:          `{"acting_user_id":"test-user-003"}`,
+			statusCode:    http.StatusOK,
+			wantStatus:    model.EscrowRefunded,
+			buyerBalance:  10000,
+			sellerBalance: 0,
+		},
+		{
+			name:          "buyer_cannot_split",
+			action:        "split",
+			body:          `{"acting_user_id":"test-user-001", "seller_amount":2500}`,
+			statusCode:    http.StatusForbidden,
+			wantStatus:    model.EscrowFunded,
+			buyerBalance:  6000,
+			sellerBalance: 0,
+		},
+		{
+			name:          "missing_actor",
+			action:        "release",
+			statusCode:    http.StatusBadRequest,
+			wantStatus:    model.EscrowFunded,
+			buyerBalance:  6000,
+			sellerBalance: 0,
+		},
+	}
+
+	actions := map[string]echo.HandlerFunc{
+		"release": handler.ReleaseEscrow,
+		"refund":  handler.RefundEscrow,
+		"split":   handler.SplitEscrow,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.EscrowAgreement{}, model.Wallet{})
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			createTestWallet(t, dbInstance, "test-user-003", model.User)
+
+			ctx := context.Background()
+			escrow, err := walletService.CreateEscrow(ctx, "test-user-001", "test-user-002", "test-user-003", 4000, time.Now().Add(time.Hour), "")
+			require.NoError(t, err)
+			if tt.settleTwice {
+				_, err := walletService.RefundEscrow(ctx, escrow.ID, "test-user-002")
+				require.NoError(t, err)
+			}
+			if tt.pastDeadline {
+				require.NoError(t, dbInstance.Model(escrow).Update("deadline", time.Now().Add(-time.Minute)).Error)
+			}
+
+			id := strconv.Itoa(escrow.ID)
+			req := httptest.NewRequest(http.MethodPost, "/escrows/"+id+"/"+tt.action, bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/escrows/:id/" + tt.action)
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+
+			require.NoError(t, actions[tt.action](c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			stored, err := walletRepository.FindEscrow(ctx, escrow.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, stored.Status)
+
+			for userID, want := range map[string]int64{"test-user-001": tt.buyerBalance, "test-user-002": tt.sellerBalance} {
+				wallet, err := walletRepository.FindByUserID(ctx, userID)
+				require.NoError(t, err)
+				assert.Equal(t, want, wallet.Balance, userID)
+			}
+		})
+	}
+}
+
+func TestWalletService_RefundExpiredEscrows(t *testing.T) {
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	walletService := service.NewWalletService(walletRepository)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	clearDB(dbInstance, model.EscrowAgreement{}, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+	createTestWallet(t, dbInstance, "test-user-002", model.User)
+
+	ctx := context.Background()
+	expired, err := walletService.CreateEscrow(ctx, "test-user-001", "test-user-002", "", 3000, time.Now().Add(time.Hour), "")
+	require.NoError(t, err)
+	open, err := walletService.CreateEscrow(ctx, "test-user-001", "test-user-002", "", 2000, time.Now().Add(time.Hour), "")
+	require.NoError(t, err)
+	require.NoError(t, dbInstance.Model(expired).Update("deadline", time.Now().Add(-time.Minute)).Error)
+
+	refunded, err := walletService.RefundExpiredEscrows(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, refunded)
+
+	stored, err := walletRepository.FindEscrow(ctx, expired.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.EscrowRefunded, stored.Status)
+	stored, err = walletRepository.FindEscrow(ctx, open.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.EscrowFunded, stored.Status)
+
+	buyer, err := walletRepository.FindByUserID(ctx, "test-user-001")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8000), buyer.Balance)
+}
+
+func TestWalletService_RefundExpiredEscrows_SuspendedBuyer(t *testing.T) {
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	walletService := service.NewWalletService(walletRepository)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	config.SetGlobalConfig(&model.Config{EscrowExpiry: model.EscrowExpiry{MaxRefundAttempts: 2}})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+		config.SetGlobalConfig(nil)
+	}()
+
+	clearDB(dbInstance, model.EscrowAgreement{}, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+	createTestWallet(t, dbInstance, "test-user-002", model.User)
+
+	ctx := context.Background()
+	escrow, err := walletService.CreateEscrow(ctx, "test-user-001", "test-user-002", "", 3000, time.Now().Add(time.Hour), "")
+	require.NoError(t, err)
+	require.NoError(t, dbInstance.Model(escrow).Update("deadline", time.Now().Add(-time.Minute)).Error)
+	_, err = walletService.SuspendWallet(ctx, "test-user-001")
+	require.NoError(t, err)
+
+	// The refund is refused on every run until the attempts run out, then
+	// the escrow is no longer retried
+	for attempts := 1; attempts <= 3; attempts++ {
+		refunded, err := walletService.RefundExpiredEscrows(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, refunded)
+
+		stored, err := walletRepository.FindEscrow(ctx, escrow.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.EscrowFunded, stored.Status)
+		assert.Equal(t, min(attempts, 2), stored.RefundAttempts)
+		assert.Equal(t, model.ErrWalletSuspended.Error(), stored.RefundError)
+	}
+
+	buyer, err := walletRepository.FindByUserID(ctx, "test-user-001")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7000), buyer.Balance, "the funds stay in escrow")
+}
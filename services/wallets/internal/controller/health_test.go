@@ -1,10 +1,16 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,10 +22,106 @@ func TestHealth(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
-	h := NewHealth()
+	h := NewHealth(service.NewHealthService(0))
 
 	err := h.Health(c)
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusOK, rec.Code)
 	assert.NotEmpty(t, rec.Body.String())
 }
+
+func TestLive(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/health/live", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Liveness must not depend on any check, even a failing one
+	h := NewHealth(service.NewHealthService(0, service.Check{
+		Name: "postgres", Critical: true,
+		Probe: func(context.Context) error { return errors.New("down") },
+	}))
+
+	require.NoError(t, h.Live(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestReady(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name           string
+		checks         []service.Check
+		expectedCode   int
+		expectedStatus model.HealthStatus
+	}{
+		{
+			name: "All_dependencies_up",
+			checks: []service.Check{
+				{Name: "postgres", Critical: true, Probe: up},
+				{Name: "redis", Critical: false, Probe: up},
+			},
+			expectedCode:   http.StatusOK,
+			expectedStatus: model.HealthUp,
+		},
+		{
+			name: "Only_redis_down_is_degraded",
+			checks: []service.Check{
+				{Name: "postgres", Critical: true, Probe: up},
+				{Name: "redis", Critical: false, Probe: down},
+			},
+			expectedCode:   http.StatusOK,
+			expectedStatus: model.HealthDegraded,
+		},
+		{
+			name: "Postgres_down_is_unavailable",
+			checks: []service.Check{
+				{Name: "postgres", Critical: true, Probe: down},
+				{Name: "redis", Critical: false, Probe: down},
+			},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: model.HealthDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			h := NewHealth(service.NewHealthService(0, tt.checks...))
+			require.NoError(t, h.Ready(c))
+			assert.Equal(t, tt.expectedCode, rec.Code)
+
+			var body struct {
+				Data model.Readiness `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedStatus, body.Data.Status)
+			assert.Len(t, body.Data.Components, len(tt.checks))
+			for _, check := range tt.checks {
+				assert.Contains(t, body.Data.Components, check.Name)
+			}
+		})
+	}
+}
+
+func TestReady_CachesResult(t *testing.T) {
+	calls := 0
+	h := NewHealth(service.NewHealthService(time.Minute, service.Check{
+		Name: "postgres", Critical: true,
+		Probe: func(context.Context) error { calls++; return nil },
+	}))
+
+	e := echo.New()
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.Ready(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	assert.Equal(t, 1, calls)
+}
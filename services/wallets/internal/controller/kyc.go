@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// SubmitKYCRequest is the request parameter for submitting a KYC verification
+type SubmitKYCRequest struct {
+	UserID    string              `param:"user_id" validate:"required"`
+	Level     model.KYCLevel      `json:"level" validate:"required,oneof=basic full"`
+	Documents []model.KYCDocument `json:"documents" validate:"required,min=1,max=10,dive"`
+}
+
+// ListKYCRequest is the request parameter for listing KYC verifications by status
+type ListKYCRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending approved rejected"`
+}
+
+// ReviewKYCRequest is the request parameter for approving or rejecting a KYC verification
+type ReviewKYCRequest struct {
+	ID   int    `param:"id" validate:"required,gt=0"`
+	Note string `json:"note,omitempty" validate:"max=500"`
+}
+
+// @Summary	Submit a KYC verification
+// @Description	Submits the metadata of the documents verifying the wallet holder to a level above the wallet's current one. The wallet is raised to the level once a reviewer approves the verification.
+// @Tags		kyc
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string				true	"User ID"
+// @Param		request	body		SubmitKYCRequest	true	"Level and documents"
+// @Success	201		{object}	ResponseData{data=model.KYCVerification}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/kyc [post]
+func (t *walletHandler) SubmitKYCVerification(c echo.Context) error {
+	var req SubmitKYCRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	verification, err := t.service.SubmitKYCVerification(c.Request().Context(), req.UserID, req.Level, req.Documents)
+	if err != nil {
+		return kycError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: verification})
+}
+
+// @Summary	Get the KYC level of a wallet
+// @Description	Returns the wallet's KYC level, the limits it sets and the verifications submitted for the wallet, newest first. Zero maximums are unlimited.
+// @Tags		kyc
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID"
+// @Success	200		{object}	ResponseData{data=model.KYCProfile}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/kyc [get]
+func (t *walletHandler) GetKYCProfile(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	profile, err := t.service.GetKYCProfile(c.Request().Context(), req.UserID)
+	if err != nil {
+		return kycError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: profile})
+}
+
+// @Summary	List KYC verifications
+// @Description	Lists the KYC verifications in a status, oldest first; pending ones by default, the review queue.
+// @Tags		admin
+// @Produce	json
+// @Param		status	query		string	false	"pending, approved or rejected"
+// @Success	200		{object}	ResponseData{data=[]model.KYCVerification}
+// @Failure	400		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/kyc [get]
+func (t *walletHandler) ListKYCVerifications(c echo.Context) error {
+	var req ListKYCRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	status := model.KYCPending
+	if req.Status != "" {
+		status = model.KYCStatus(req.Status)
+	}
+
+	verifications, err := t.service.ListKYCVerifications(c.Request().Context(), status)
+	if err != nil {
+		return kycError(c, err, "")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: verifications})
+}
+
+// @Summary	Approve a KYC verification
+// @Description	Approves a pending KYC verification as the authenticated operator, raising the wallet to the verified level.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int					true	"Verification ID"
+// @Param		request	body		ReviewKYCRequest	false	"Note"
+// @Success	200		{object}	ResponseData{data=model.KYCVerification}
+// @Failure	400		{object}	ResponseError
+// @Failure	401		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/kyc/{id}/approve [post]
+func (t *walletHandler) ApproveKYCVerification(c echo.Context) error {
+	return t.reviewKYCVerification(c, true)
+}
+
+// @Summary	Reject a KYC verification
+// @Description	Rejects a pending KYC verification as the authenticated operator; the wallet keeps its level and the holder may submit again.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int					true	"Verification ID"
+// @Param		request	body		ReviewKYCRequest	false	"Reason"
+// @Success	200		{object}	ResponseData{data=model.KYCVerification}
+// @Failure	400		{object}	ResponseError
+// @Failure	401		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/kyc/{id}/reject [post]
+func (t *walletHandler) RejectKYCVerification(c echo.Context) error {
+	return t.reviewKYCVerification(c, false)
+}
+
+func (t *walletHandler) reviewKYCVerification(c echo.Context, approve bool) error {
+	var req ReviewKYCRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	operator := operatorID(c)
+	if operator == "" {
+		return unauthorized(c)
+	}
+
+	verification, err := t.service.ReviewKYCVerification(c.Request().Context(), req.ID, approve, operator, req.Note)
+	if err != nil {
+		return kycError(c, err, "KYC verification not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: verification})
+}
+
+// kycError writes the error response of the KYC endpoints; notFound is the
+// message for ErrNotFound.
+func kycError(c echo.Context, err error, notFound string) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: notFound}}})
+	case model.ErrNotUserWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Only user wallets have a KYC level"}}})
+	case model.ErrInvalidKYCLevel:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Level must be above the wallet's current KYC level"}}})
+	case model.ErrKYCPending:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "A KYC verification of the wallet is already awaiting review"}}})
+	case model.ErrInvalidTransition:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "KYC verification was already reviewed"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_KYC(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	config.SetGlobalConfig(&model.Config{
+		KYC: model.KYC{
+			Enable: true,
+			None:   model.KYCTier{MaxTransfer: 100},
+			Basic:  model.KYCTier{MaxTransfer: 1000, Withdrawals: true},
+		},
+		BalanceLimits: model.BalanceLimits{
+			Enable: true,
+			Limits: []model.BalanceLimit{
+				{AcntType: model.User, KYCLevel: model.KYCNone, MaxBalance: 1000},
+				{AcntType: model.User, KYCLevel: model.KYCBasic, MaxBalance: 10000},
+			},
+		},
+	})
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+		config.SetGlobalConfig(nil)
+		clearDB(dbInstance, model.KYCVerification{})
+	}()
+
+	clearDB(dbInstance, model.KYCVerification{}, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 500)
+	createTestWallet(t, dbInstance, "test-user-002", model.User)
+	createTestWalletWithBalance(t, dbInstance, model.DepositProviderID, model.Provider, 100000)
+	createTestWallet(t, dbInstance, model.WithdrawProviderID, model.Provider)
+
+	call := func(handle echo.HandlerFunc, path, param, value, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		if param != "" {
+			c.SetParamNames(param)
+			c.SetParamValues(value)
+		}
+		require.NoError(t, handle(c))
+		return rec
+	}
+	review := func(handle echo.HandlerFunc, path, id, operator string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{}`)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		if operator != "" {
+			// As authenticated by AdminAuth
+			c.Set(operatorKey, operator)
+		}
+		require.NoError(t, handle(c))
+		return rec
+	}
+	upgradeRequired := func(rec *httptest.ResponseRecorder) {
+		t.Helper()
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), errors.CodeKYCUpgradeRequired)
+	}
+
+	// Unverified: a small balance and transfers only, no withdrawals
+	assert.Equal(t, http.StatusCreated, call(handler.Deposit, "/wallets/deposit", "", "", `{"user_id":"test-user-001", "amount":500}`).Code)
+	upgradeRequired(call(handler.Deposit, "/wallets/deposit", "", "", `{"user_id":"test-user-001", "amount":1}`))
+	upgradeRequired(call(handler.Transfer, "/wallets/transfer", "", "", `{"from_user_id":"`+model.DepositProviderID+`", "to_user_id":"test-user-001", "amount":1}`))
+	upgradeRequired(call(handler.Withdraw, "/wallets/withdraw", "", "", `{"user_id":"test-user-001", "amount":100}`))
+	upgradeRequired(call(handler.Transfer, "/wallets/transfer", "", "", `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":101}`))
+	deadline := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	upgradeRequired(call(handler.CreateEscrow, "/escrows", "", "", `{"buyer_user_id":"test-user-001", "seller_user_id":"test-user-002", "amount":101, "deadline":"`+deadline+`"}`))
+	// Closing sweeps the balance to another wallet as a transfer, within the same limit
+	upgradeRequired(call(handler.CloseWallet, "/wallets/:user_id/close", "user_id", "test-user-001", `{"to_user_id":"test-user-002"}`))
+	assert.Equal(t, http.StatusCreated, call(handler.Transfer, "/wallets/transfer", "", "", `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":100}`).Code)
+
+	submit := `{"level":"basic", "documents":[{"type":"passport", "number":"X1234567", "country":"DE"}]}`
+	assert.Equal(t, http.StatusBadRequest, call(handler.SubmitKYCVerification, "/wallets/:user_id/kyc", "user_id", "test-user-001", `{"level":"basic", "documents":[]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call(handler.SubmitKYCVerification, "/wallets/:user_id/kyc", "user_id", model.DepositProviderID, submit).Code)
+	rec := call(handler.SubmitKYCVerification, "/wallets/:user_id/kyc", "user_id", "test-user-001", submit)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var verification struct {
+		Data model.KYCVerification `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &verification))
+	assert.Equal(t, model.KYCPending, verification.Data.Status)
+	assert.Equal(t, http.StatusConflict, call(handler.SubmitKYCVerification, "/wallets/:user_id/kyc", "user_id", "test-user-001", submit).Code)
+
+	id := strconv.Itoa(verification.Data.ID)
+	assert.Equal(t, http.StatusUnauthorized, review(handler.ApproveKYCVerification, "/admin/kyc/:id/approve", id, "").Code)
+	rec = review(handler.ApproveKYCVerification, "/admin/kyc/:id/approve", id, "compliance-1")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"approved"`)
+	assert.Contains(t, rec.Body.String(), `"reviewer":"compliance-1"`)
+	assert.Equal(t, http.StatusConflict, review(handler.RejectKYCVerification, "/admin/kyc/:id/reject", id, "compliance-1").Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/wallets/:user_id/kyc")
+	c.SetParamNames("user_id")
+	c.SetParamValues("test-user-001")
+	require.NoError(t, handler.GetKYCProfile(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var profile struct {
+		Data model.KYCProfile `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &profile))
+	assert.Equal(t, model.KYCBasic, profile.Data.Level)
+	assert.Equal(t, int64(10000), profile.Data.Limits.MaxBalance)
+	require.Len(t, profile.Data.Verifications, 1)
+
+	// Verified to basic: withdrawals and larger transfers are allowed
+	assert.Equal(t, http.StatusCreated, call(handler.Withdraw, "/wallets/withdraw", "", "", `{"user_id":"test-user-001", "amount":100}`).Code)
+	assert.Equal(t, http.StatusCreated, call(handler.Transfer, "/wallets/transfer", "", "", `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":200}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call(handler.SubmitKYCVerification, "/wallets/:user_id/kyc", "user_id", "test-user-001", submit).Code,
+		"the wallet is already at the level")
+
+	// Still unverified, test-user-002 cannot withdraw its balance but can close
+	// its wallet, sweeping it to a provider
+	upgradeRequired(call(handler.Withdraw, "/wallets/withdraw", "", "", `{"user_id":"test-user-002", "amount":300}`))
+	upgradeRequired(call(handler.CloseWallet, "/wallets/:user_id/close", "user_id", "test-user-002", `{"to_user_id":"test-user-001"}`))
+	assert.Equal(t, http.StatusOK, call(handler.CloseWallet, "/wallets/:user_id/close", "user_id", "test-user-002", `{}`).Code)
+}
@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// AddMemberRequest is the request parameter for authorizing a user on a shared wallet
+type AddMemberRequest struct {
+	UserID       string           `param:"user_id" validate:"required"`
+	MemberUserID string           `json:"member_user_id" validate:"required"`
+	Role         model.MemberRole `json:"role" validate:"required,oneof=owner spender viewer"`
+	// A spender needs either a spend limit, the most it may transfer and
+	// withdraw in total per spend window in cents, or unlimited spending
+	SpendLimit     int               `json:"spend_limit" validate:"gte=0"`
+	UnlimitedSpend bool              `json:"unlimited_spend"`
+	SpendWindow    model.SpendWindow `json:"spend_window" validate:"omitempty,oneof=day week month"` // Defaults to day
+}
+
+// MemberRequest is the request parameter for a single member of a wallet
+type MemberRequest struct {
+	UserID       string `param:"user_id" validate:"required"`
+	MemberUserID string `param:"member_id" validate:"required"`
+}
+
+// ApprovalPolicyRequest is the request parameter for setting when transfers
+// and withdrawals of a shared wallet need approval
+type ApprovalPolicyRequest struct {
+	UserID            string `param:"user_id" validate:"required"`
+	Threshold         int    `json:"threshold" validate:"gte=0"`          // Amounts above it in cents need approval
+	RequiredApprovals int    `json:"required_approvals" validate:"gte=0"` // 0 turns approvals off
+}
+
+// ListApprovalsRequest is the request parameter for listing the held operations of a wallet
+type ListApprovalsRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	Status string `query:"status" validate:"omitempty,oneof=pending approved executed rejected failed"`
+}
+
+// ApprovalActionRequest is the request parameter for approving or rejecting a held operation
+type ApprovalActionRequest struct {
+	UserID       string `param:"user_id" validate:"required"`
+	ID           int    `param:"id" validate:"required,gt=0"`
+	MemberUserID string `json:"member_user_id" validate:"required"` // The holder or member acting
+}
+
+// @Summary	Authorize a user on a shared wallet
+// @Description	Owners may spend, manage the wallet and approve held operations; spenders may transfer and withdraw up to their spend limit per day, week or month, or without limit if unlimited_spend is set; viewers may only view the wallet.
+// @Tags		members
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string				true	"User ID of the wallet holder"
+// @Param		request	body		AddMemberRequest	true	"Member"
+// @Success	201		{object}	ResponseData{data=model.WalletMember}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/members [post]
+func (t *walletHandler) AddMember(c echo.Context) error {
+	var req AddMemberRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	member, err := t.service.AddMember(c.Request().Context(), req.UserID, req.MemberUserID, req.Role, req.SpendLimit, req.UnlimitedSpend, req.SpendWindow)
+	if err != nil {
+		return memberError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: member})
+}
+
+// @Summary	List the members of a shared wallet
+// @Tags		members
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the wallet holder"
+// @Success	200		{object}	ResponseData{data=[]model.WalletMember}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/members [get]
+func (t *walletHandler) ListMembers(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	members, err := t.service.ListMembers(c.Request().Context(), req.UserID)
+	if err != nil {
+		return memberError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: members})
+}
+
+// @Summary	Remove a member from a shared wallet
+// @Tags		members
+// @Param		user_id		path	string	true	"User ID of the wallet holder"
+// @Param		member_id	path	string	true	"User ID of the member"
+// @Success	204
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/wallets/{user_id}/members/{member_id} [delete]
+func (t *walletHandler) RemoveMember(c echo.Context) error {
+	var req MemberRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if err := t.service.RemoveMember(c.Request().Context(), req.UserID, req.MemberUserID); err != nil {
+		return memberError(c, err, "Wallet or member not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary	Set when transfers and withdrawals of a shared wallet need approval
+// @Description	Transfers and withdrawals above threshold are held until required_approvals owners, the holder included, approve them. required_approvals cannot exceed the number of owners; 0 turns approvals off.
+// @Tags		members
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string					true	"User ID of the wallet holder"
+// @Param		request	body		ApprovalPolicyRequest	true	"Approval policy"
+// @Success	200		{object}	ResponseData{data=model.Wallet}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/approval-policy [put]
+func (t *walletHandler) SetApprovalPolicy(c echo.Context) error {
+	var req ApprovalPolicyRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	wallet, err := t.service.SetApprovalPolicy(c.Request().Context(), req.UserID, req.Threshold, req.RequiredApprovals)
+	if err != nil {
+		return memberError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: wallet})
+}
+
+// @Summary	List the transfers and withdrawals of a shared wallet held for approval
+// @Tags		members
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the wallet holder"
+// @Param		status	query		string	false	"pending, approved, executed, rejected or failed"
+// @Success	200		{object}	ResponseData{data=[]model.WalletApproval}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/approvals [get]
+func (t *walletHandler) ListApprovals(c echo.Context) error {
+	var req ListApprovalsRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	approvals, err := t.service.ListApprovals(c.Request().Context(), req.UserID, model.ApprovalStatus(req.Status))
+	if err != nil {
+		return memberError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: approvals})
+}
+
+// @Summary	Approve a held transfer or withdrawal
+// @Description	Once the operation has the approvals it requires it is carried out and its status becomes executed, or failed with a reason if it could not be, e.g. for lack of funds.
+// @Tags		members
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string					true	"User ID of the wallet holder"
+// @Param		id		path		int						true	"Approval ID"
+// @Param		request	body		ApprovalActionRequest	true	"Approving owner"
+// @Success	200		{object}	ResponseData{data=model.WalletApproval}
+// @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/approvals/{id}/approve [post]
+func (t *walletHandler) ApproveOperation(c echo.Context) error {
+	var req ApprovalActionRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	approval, err := t.service.ApproveOperation(c.Request().Context(), req.UserID, req.ID, req.MemberUserID)
+	if err != nil {
+		return memberError(c, err, "Approval not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: approval})
+}
+
+// @Summary	Reject a held transfer or withdrawal
+// @Description	Any owner may reject the operation, and its initiator may withdraw it.
+// @Tags		members
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string					true	"User ID of the wallet holder"
+// @Param		id		path		int						true	"Approval ID"
+// @Param		request	body		ApprovalActionRequest	true	"Rejecting owner or initiator"
+// @Success	200		{object}	ResponseData{data=model.WalletApproval}
+// @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/approvals/{id}/reject [post]
+func (t *walletHandler) RejectOperation(c echo.Context) error {
+	var req ApprovalActionRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	approval, err := t.service.RejectOperation(c.Request().Context(), req.UserID, req.ID, req.MemberUserID)
+	if err != nil {
+		return memberError(c, err, "Approval not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: approval})
+}
+
+// isMemberDebitError reports whether err is a transfer or withdrawal the
+// acting member is not allowed to make.
+func isMemberDebitError(err error) bool {
+	return err == model.ErrNotMember || err == model.ErrMemberForbidden || err == model.ErrSpendLimitExceeded
+}
+
+// memberDebitError writes the error response for a transfer or withdrawal
+// the acting member is not allowed to make.
+func memberDebitError(c echo.Context, err error) error {
+	switch err {
+	case model.ErrNotMember:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: "Acting user is not a member of the wallet"}}})
+	case model.ErrMemberForbidden:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: "Member role does not allow transfers or withdrawals"}}})
+	}
+	return c.JSON(http.StatusUnprocessableEntity,
+		ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Amount exceeds the member's spend limit"}}})
+}
+
+// memberError writes the error response of the member and approval endpoints.
+func memberError(c echo.Context, err error, notFound string) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: notFound}}})
+	case model.ErrNotUserWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Only user wallets can be shared or be members"}}})
+	case model.ErrInvalidSpendLimit:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Spenders need either a positive spend limit or unlimited spending"}}})
+	case model.ErrInvalidApprovalPolicy:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Required approvals cannot exceed the number of owners"}}})
+	case model.ErrMemberExists:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "User is already a member of the wallet"}}})
+	case model.ErrNotMember:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: "User is not a member of the wallet"}}})
+	case model.ErrMemberForbidden:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: "Only owners can approve or reject held operations"}}})
+	case model.ErrAlreadyApproved:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Owner already approved the operation"}}})
+	case model.ErrInvalidTransition:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Operation is no longer pending"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
@@ -0,0 +1,288 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_MemberTransfer(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	handler := NewWalletController(service.NewWalletService(walletRepository))
+	// Members reference their wallet, so other tests can only clear wallets once they are gone
+	defer clearDB(dbInstance, model.WalletApprovalVote{}, model.WalletApproval{}, model.WalletMember{})
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		balance    int64
+		spent      int64 // Spent by the spender earlier today
+		wantSpent  int64 // Spent by the spender today after the transfer
+	}{
+		{name: "spender_within_limit", body: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":3000, "acting_user_id":"test-spender"}`, statusCode: http.StatusCreated, balance: 7000, wantSpent: 3000},
+		{name: "spender_over_limit", body: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":3001, "acting_user_id":"test-spender"}`, statusCode: http.StatusUnprocessableEntity, balance: 10000},
+		{name: "spender_within_rest_of_day", body: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":1000, "acting_user_id":"test-spender"}`, statusCode: http.StatusCreated, balance: 9000, spent: 2000, wantSpent: 3000},
+		{name: "spender_over_rest_of_day", body: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":1001, "acting_user_id":"test-spender"}`, statusCode: http.StatusUnprocessableEntity, balance: 10000, spent: 2000, wantSpent: 2000},
+		{name: "owner_member", body: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":9000, "acting_user_id":"test-owner"}`, statusCode: http.StatusCreated, balance: 1000},
+		{name: "viewer", body: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":100, "acting_user_id":"test-viewer"}`, statusCode: http.StatusForbidden, balance: 10000},
+		{name: "not_a_member", body: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":100, "acting_user_id":"test-user-002"}`, statusCode: http.StatusForbidden, balance: 10000},
+		{name: "member_to_alias", body: `{"from_user_id":"test-user-001", "to_alias":"@someone", "amount":100, "acting_user_id":"test-spender"}`, statusCode: http.StatusBadRequest, balance: 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.WalletMember{}, model.Wallet{})
+			ctx := context.Background()
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			holder, err := walletRepository.FindByUserID(ctx, "test-user-001")
+			require.NoError(t, err)
+			today := model.SpendDaily.Start(time.Now())
+			for _, member := range []model.WalletMember{
+				{MemberID: "test-owner", Role: model.MemberOwner},
+				{MemberID: "test-spender", Role: model.MemberSpender, SpendLimit: 3000, SpendWindow: model.SpendDaily, WindowSpent: tt.spent, WindowStart: &today},
+				{MemberID: "test-viewer", Role: model.MemberViewer},
+			} {
+				createTestWallet(t, dbInstance, member.MemberID, model.User)
+				member.WalletID = holder.ID
+				require.NoError(t, walletRepository.CreateMember(ctx, &member))
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/wallets/transfer", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, handler.Transfer(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			wallet, err := walletRepository.FindByUserID(ctx, "test-user-001")
+			require.NoError(t, err)
+			assert.Equal(t, tt.balance, wallet.Balance)
+			spender, err := walletRepository.FindMember(ctx, holder.ID, "test-spender")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSpent, spender.WindowSpent)
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+			var got struct {
+				Data model.Transaction `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			var want struct {
+				ActingUserID string `json:"acting_user_id"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &want))
+			assert.Equal(t, want.ActingUserID, got.Data.ActorUserID)
+		})
+	}
+}
+
+func TestWalletHandler_ApproveOperation(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	handler := NewWalletController(service.NewWalletService(walletRepository))
+	// Members reference their wallet, so other tests can only clear wallets once they are gone
+	defer clearDB(dbInstance, model.WalletApprovalVote{}, model.WalletApproval{}, model.WalletMember{})
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	ctx := context.Background()
+	clearDB(dbInstance, model.WalletApprovalVote{}, model.WalletApproval{}, model.WalletMember{}, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+	createTestWallet(t, dbInstance, "test-user-002", model.User)
+	holder, err := walletRepository.FindByUserID(ctx, "test-user-001")
+	require.NoError(t, err)
+	for _, member := range []model.WalletMember{
+		{MemberID: "test-owner", Role: model.MemberOwner},
+		{MemberID: "test-spender", Role: model.MemberSpender, UnlimitedSpend: true},
+	} {
+		createTestWallet(t, dbInstance, member.MemberID, model.User)
+		member.WalletID = holder.ID
+		require.NoError(t, walletRepository.CreateMember(ctx, &member))
+	}
+	require.NoError(t, walletRepository.UpdateApprovalPolicy(ctx, holder.ID, 5000, 2))
+
+	post := func(path string, body string, handle func(echo.Context) error, params ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if len(params) > 0 {
+			c.SetParamNames("user_id", "id")
+			c.SetParamValues(params...)
+		}
+		require.NoError(t, handle(c))
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) model.WalletApproval {
+		var got struct {
+			Data model.WalletApproval `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		return got.Data
+	}
+	balance := func() int64 {
+		wallet, err := walletRepository.FindByUserID(ctx, "test-user-001")
+		require.NoError(t, err)
+		return wallet.Balance
+	}
+
+	// Up to the threshold the transfer goes through at once
+	rec := post("/wallets/transfer", `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":5000, "acting_user_id":"test-spender"}`, handler.Transfer)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, int64(5000), balance())
+
+	// Above it, a spender's transfer is held without approvals
+	rec = post("/wallets/transfer", `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":5001, "acting_user_id":"test-spender"}`, handler.Transfer)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	held := decode(rec)
+	assert.Equal(t, model.ApprovalPending, held.Status)
+	assert.Equal(t, "test-spender", held.InitiatedBy)
+	assert.Empty(t, held.ApprovedBy)
+	id := fmt.Sprint(held.ID)
+
+	// Spenders cannot approve
+	rec = post("/wallets/test-user-001/approvals/"+id+"/approve", `{"member_user_id":"test-spender"}`, handler.ApproveOperation, "test-user-001", id)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = post("/wallets/test-user-001/approvals/"+id+"/approve", `{"member_user_id":"test-owner"}`, handler.ApproveOperation, "test-user-001", id)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"test-owner"}, decode(rec).ApprovedBy)
+	assert.Equal(t, int64(5000), balance())
+
+	rec = post("/wallets/test-user-001/approvals/"+id+"/approve", `{"member_user_id":"test-owner"}`, handler.ApproveOperation, "test-user-001", id)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// The second owner's approval carries out the transfer, which fails for lack of funds
+	rec = post("/wallets/test-user-001/approvals/"+id+"/approve", `{"member_user_id":"test-user-001"}`, handler.ApproveOperation, "test-user-001", id)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	approval := decode(rec)
+	assert.Equal(t, model.ApprovalFailed, approval.Status)
+	assert.Equal(t, model.ErrInsufficientFunds.Error(), approval.Reason)
+	assert.Equal(t, int64(5000), balance())
+
+	// An owner's transfer counts its own approval
+	rec = post("/wallets/transfer", `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":4000}`, handler.Transfer)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	require.NoError(t, walletRepository.UpdateApprovalPolicy(ctx, holder.ID, 500, 2))
+	rec = post("/wallets/transfer", `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":600, "acting_user_id":"test-owner"}`, handler.Transfer)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	held = decode(rec)
+	assert.Equal(t, []string{"test-owner"}, held.ApprovedBy)
+	id = fmt.Sprint(held.ID)
+
+	rec = post("/wallets/test-user-001/approvals/"+id+"/approve", `{"member_user_id":"test-user-001"}`, handler.ApproveOperation, "test-user-001", id)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	approval = decode(rec)
+	assert.Equal(t, model.ApprovalExecuted, approval.Status)
+	assert.Equal(t, []string{"test-owner", "test-user-001"}, approval.ApprovedBy)
+	assert.Equal(t, int64(400), balance())
+
+	// Executed operations can no longer be rejected
+	rec = post("/wallets/test-user-001/approvals/"+id+"/reject", `{"member_user_id":"test-user-001"}`, handler.RejectOperation, "test-user-001", id)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestWalletHandler_SetApprovalPolicy(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	handler := NewWalletController(service.NewWalletService(walletRepository))
+	// Members reference their wallet, so other tests can only clear wallets once they are gone
+	defer clearDB(dbInstance, model.WalletApprovalVote{}, model.WalletApproval{}, model.WalletMember{})
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{name: "two_of_two_owners", body: `{"threshold":5000, "required_approvals":2}`, statusCode: http.StatusOK},
+		{name: "more_approvals_than_owners", body: `{"threshold":5000, "required_approvals":3}`, statusCode: http.StatusBadRequest},
+		{name: "turn_off", body: `{"threshold":0, "required_approvals":0}`, statusCode: http.StatusOK},
+		{name: "negative_threshold", body: `{"threshold":-1, "required_approvals":1}`, statusCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.WalletMember{}, model.Wallet{})
+			ctx := context.Background()
+			createTestWallet(t, dbInstance, "test-user-001", model.User)
+			createTestWallet(t, dbInstance, "test-owner", model.User)
+			holder, err := walletRepository.FindByUserID(ctx, "test-user-001")
+			require.NoError(t, err)
+			require.NoError(t, walletRepository.CreateMember(ctx, &model.WalletMember{WalletID: holder.ID, MemberID: "test-owner", Role: model.MemberOwner}))
+
+			req := httptest.NewRequest(http.MethodPut, "/wallets/test-user-001/approval-policy", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("user_id")
+			c.SetParamValues("test-user-001")
+
+			require.NoError(t, handler.SetApprovalPolicy(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+		})
+	}
+}
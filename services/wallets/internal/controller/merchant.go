@@ -0,0 +1,362 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateMerchantRequest is the request parameter for registering a merchant with its wallet
+type CreateMerchantRequest struct {
+	UserID       string `json:"user_id" validate:"required"`
+	DisplayName  string `json:"display_name" validate:"required,max=100"`
+	Category     string `json:"category" validate:"max=50"`
+	Website      string `json:"website" validate:"omitempty,url,max=255"`
+	SupportEmail string `json:"support_email" validate:"omitempty,email,max=255"`
+}
+
+// MerchantRequest is the request parameter for an existing merchant
+type MerchantRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+}
+
+// CreatePaymentIntentRequest is the request parameter for a merchant asking to be paid for an order
+type CreatePaymentIntentRequest struct {
+	UserID      string `param:"user_id" validate:"required"`
+	Amount      int    `json:"amount" validate:"required,gt=0"`
+	Reference   string `json:"reference" validate:"required,max=100"` // Merchant's order reference, unique per merchant
+	Description string `json:"description" validate:"max=500"`
+}
+
+// ListPaymentIntentsRequest is the request parameter for listing the payment intents of a merchant
+type ListPaymentIntentsRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	Status string `query:"status" validate:"omitempty,oneof=pending succeeded cancelled"`
+}
+
+// SettlementReportRequest is the request parameter for the settlement report of a merchant
+type SettlementReportRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	From   string `query:"from" validate:"required"` // YYYY-MM-DD
+	To     string `query:"to" validate:"required"`   // YYYY-MM-DD
+}
+
+// PaymentIntentRequest is the request parameter for an existing payment intent
+type PaymentIntentRequest struct {
+	ID int `param:"id" validate:"required,gt=0"`
+}
+
+// ConfirmPaymentIntentRequest is the request parameter for a customer paying a payment intent
+type ConfirmPaymentIntentRequest struct {
+	ID             int    `param:"id" validate:"required,gt=0"`
+	CustomerUserID string `json:"customer_user_id" validate:"required"`
+}
+
+// RefundPaymentIntentRequest is the request parameter for a merchant refunding a paid intent
+type RefundPaymentIntentRequest struct {
+	ID     int    `param:"id" validate:"required,gt=0"`
+	Amount int    `json:"amount" validate:"required,gt=0"` // At most what is left after earlier refunds
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// @Summary	Register a merchant and open its wallet
+// @Tags		merchants
+// @Accept		json
+// @Produce	json
+// @Param		request	body		CreateMerchantRequest	true	"Merchant"
+// @Success	201		{object}	ResponseData{data=model.MerchantProfile}
+// @Failure	400		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/merchants [post]
+func (t *walletHandler) CreateMerchant(c echo.Context) error {
+	var req CreateMerchantRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	profile, err := t.service.CreateMerchant(c.Request().Context(), &model.MerchantProfile{
+		UserID:       req.UserID,
+		DisplayName:  req.DisplayName,
+		Category:     req.Category,
+		Website:      req.Website,
+		SupportEmail: req.SupportEmail,
+	})
+	if err != nil {
+		return merchantError(c, err, "Merchant not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: profile})
+}
+
+// @Summary	View a merchant's profile
+// @Tags		merchants
+// @Produce	json
+// @Param		user_id	path		string	true	"Merchant user ID"
+// @Success	200		{object}	ResponseData{data=model.MerchantProfile}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/merchants/{user_id} [get]
+func (t *walletHandler) GetMerchant(c echo.Context) error {
+	var req MerchantRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	profile, err := t.service.GetMerchant(c.Request().Context(), req.UserID)
+	if err != nil {
+		return merchantError(c, err, "Merchant not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: profile})
+}
+
+// @Summary	Create a payment intent for a merchant's order
+// @Tags		merchants
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string						true	"Merchant user ID"
+// @Param		request	body		CreatePaymentIntentRequest	true	"Payment intent"
+// @Success	201		{object}	ResponseData{data=model.PaymentIntent}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/merchants/{user_id}/payment-intents [post]
+func (t *walletHandler) CreatePaymentIntent(c echo.Context) error {
+	var req CreatePaymentIntentRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	intent, err := t.service.CreatePaymentIntent(c.Request().Context(),
+		req.UserID, req.Amount, req.Reference, req.Description)
+	if err != nil {
+		return merchantError(c, err, "Merchant not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: intent})
+}
+
+// @Summary	List a merchant's payment intents
+// @Tags		merchants
+// @Produce	json
+// @Param		user_id	path		string	true	"Merchant user ID"
+// @Param		status	query		string	false	"pending, succeeded or cancelled"
+// @Success	200		{object}	ResponseData{data=[]model.PaymentIntent}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/merchants/{user_id}/payment-intents [get]
+func (t *walletHandler) ListPaymentIntents(c echo.Context) error {
+	var req ListPaymentIntentsRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	intents, err := t.service.ListPaymentIntents(c.Request().Context(),
+		req.UserID, model.PaymentIntentStatus(req.Status))
+	if err != nil {
+		return merchantError(c, err, "Merchant not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: intents})
+}
+
+// @Summary	View a merchant's payments and refunds per day
+// @Tags		merchants
+// @Produce	json
+// @Param		user_id	path		string	true	"Merchant user ID"
+// @Param		from	query		string	true	"First day (YYYY-MM-DD, UTC)"
+// @Param		to		query		string	true	"Last day (YYYY-MM-DD, UTC)"
+// @Success	200		{object}	ResponseData{data=model.SettlementReport}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/merchants/{user_id}/settlement [get]
+func (t *walletHandler) SettlementReport(c echo.Context) error {
+	var req SettlementReportRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	from, err := time.Parse(model.DateLayout, req.From)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "from must be a YYYY-MM-DD date"}}})
+	}
+	to, err := time.Parse(model.DateLayout, req.To)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "to must be a YYYY-MM-DD date"}}})
+	}
+
+	report, err := t.service.GetSettlementReport(c.Request().Context(), req.UserID, from, to)
+	if err != nil {
+		return merchantError(c, err, "Merchant not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: report})
+}
+
+// @Summary	View a payment intent
+// @Tags		payment-intents
+// @Produce	json
+// @Param		id	path		int	true	"Payment intent ID"
+// @Success	200	{object}	ResponseData{data=model.PaymentIntent}
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/payment-intents/{id} [get]
+func (t *walletHandler) GetPaymentIntent(c echo.Context) error {
+	var req PaymentIntentRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	intent, err := t.service.GetPaymentIntent(c.Request().Context(), req.ID)
+	if err != nil {
+		return merchantError(c, err, "Payment intent not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: intent})
+}
+
+// @Summary	Pay a payment intent from a customer's wallet
+// @Tags		payment-intents
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int							true	"Payment intent ID"
+// @Param		request	body		ConfirmPaymentIntentRequest	true	"Paying customer"
+// @Success	200		{object}	ResponseData{data=model.PaymentIntent}
+// @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/payment-intents/{id}/confirm [post]
+func (t *walletHandler) ConfirmPaymentIntent(c echo.Context) error {
+	var req ConfirmPaymentIntentRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	intent, err := t.service.ConfirmPaymentIntent(c.Request().Context(), req.ID, req.CustomerUserID)
+	if err != nil {
+		return merchantError(c, err, "Payment intent or wallet not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: intent})
+}
+
+// @Summary	Cancel a payment intent before it is paid
+// @Tags		payment-intents
+// @Produce	json
+// @Param		id	path		int	true	"Payment intent ID"
+// @Success	200	{object}	ResponseData{data=model.PaymentIntent}
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	409	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/payment-intents/{id}/cancel [post]
+func (t *walletHandler) CancelPaymentIntent(c echo.Context) error {
+	var req PaymentIntentRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	intent, err := t.service.CancelPaymentIntent(c.Request().Context(), req.ID)
+	if err != nil {
+		return merchantError(c, err, "Payment intent not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: intent})
+}
+
+// @Summary	Refund part or all of a paid intent to its customer
+// @Tags		payment-intents
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int							true	"Payment intent ID"
+// @Param		request	body		RefundPaymentIntentRequest	true	"Refund"
+// @Success	201		{object}	ResponseData{data=model.PaymentRefund}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/payment-intents/{id}/refunds [post]
+func (t *walletHandler) RefundPaymentIntent(c echo.Context) error {
+	var req RefundPaymentIntentRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	refund, err := t.service.RefundPaymentIntent(c.Request().Context(), req.ID, req.Amount, req.Reason)
+	if err != nil {
+		return merchantError(c, err, "Payment intent not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: refund})
+}
+
+// merchantError writes the error response of the merchant and payment intent endpoints.
+func merchantError(c echo.Context, err error, notFound string) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: notFound}}})
+	case model.ErrNotMerchant:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is not a merchant wallet"}}})
+	case model.ErrNotUserWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Payment intents can only be paid from user wallets"}}})
+	case model.ErrInvalidAmount:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Amount must be more than zero"}}})
+	case model.ErrInvalidTimeRange:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Range must be in the past, from not after to, at most 366 days"}}})
+	case model.ErrMerchantExists:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "User ID already has a wallet"}}})
+	case model.ErrReferenceTaken:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Reference already used by the merchant"}}})
+	case model.ErrInvalidTransition:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Payment intent is not in a state allowing this"}}})
+	case model.ErrRefundExceedsPayment:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Refund exceeds what is left of the payment"}}})
+	case model.ErrInsufficientFunds:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
+	case model.ErrApprovalRequired:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Amount needs approval by the customer's owners"}}})
+	case model.ErrWalletClosed:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is closed"}}})
+	case model.ErrWalletSuspended:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is suspended"}}})
+	case model.ErrBalanceLimitExceeded:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBalanceLimitExceeded, Message: "Balance would exceed the wallet's limit"}}})
+	case model.ErrKYCUpgradeRequired:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeKYCUpgradeRequired, Message: "KYC level does not allow this operation; verify the wallet to a higher level"}}})
+	case model.ErrConcurrentUpdate:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
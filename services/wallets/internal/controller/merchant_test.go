@@ -0,0 +1,333 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_CreateMerchant(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	handler := NewWalletController(service.NewWalletService(walletRepository))
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "successful_registration",
+			body:       `{"user_id":"shop-001", "display_name":"Corner Shop", "category":"grocery", "support_email":"help@corner.example"}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "user_id_has_a_wallet",
+			body:       `{"user_id":"test-user-001", "display_name":"Corner Shop"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "invalid_support_email",
+			body:       `{"user_id":"shop-001", "display_name":"Corner Shop", "support_email":"not-an-email"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing_display_name",
+			body:       `{"user_id":"shop-001"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.MerchantProfile{}, model.Wallet{})
+			createTestWallet(t, dbInstance, "test-user-001", model.User)
+
+			req := httptest.NewRequest(http.MethodPost, "/merchants", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/merchants")
+
+			require.NoError(t, handler.CreateMerchant(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+			wallet, err := walletRepository.FindByUserID(context.Background(), "shop-001")
+			require.NoError(t, err)
+			assert.Equal(t, model.Merchant, wallet.AcntType)
+			profile, err := walletRepository.FindMerchant(context.Background(), "shop-001")
+			require.NoError(t, err)
+			assert.Equal(t, wallet.ID, profile.WalletID)
+			assert.Equal(t, "Corner Shop", profile.DisplayName)
+		})
+	}
+}
+
+func TestWalletHandler_PaymentIntents(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	walletService := service.NewWalletService(walletRepository)
+	handler := NewWalletController(walletService)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+		clearDB(dbInstance, model.PaymentRefund{}, model.PaymentIntent{}, model.MerchantProfile{})
+	}()
+
+	tests := []struct {
+		name            string
+		action          string
+		body            string
+		confirmFirst    bool
+		refundFirst     int
+		statusCode      int
+		wantStatus      model.PaymentIntentStatus
+		customerBalance int64
+		merchantBalance int64
+	}{
+		{
+			name:            "confirm",
+			action:          "confirm",
+			body:            `{"customer_user_id":"test-user-001"}`,
+			statusCode:      http.StatusOK,
+			wantStatus:      model.IntentSucceeded,
+			customerBalance: 6000,
+			merchantBalance: 4000,
+		},
+		{
+			name:            "confirm_twice",
+			action:          "confirm",
+			body:            `{"customer_user_id":"test-user-001"}`,
+			confirmFirst:    true,
+			statusCode:      http.StatusConflict,
+			wantStatus:      model.IntentSucceeded,
+			customerBalance: 6000,
+			merchantBalance: 4000,
+		},
+		{
+			name:            "confirm_insufficient_funds",
+			action:          "confirm",
+			body:            `{"customer_user_id":"test-user-002"}`,
+			statusCode:      http.StatusUnprocessableEntity,
+			wantStatus:      model.IntentPending,
+			customerBalance: 10000,
+			merchantBalance: 0,
+		},
+		{
+			name:            "confirm_from_merchant_wallet",
+			action:          "confirm",
+			body:            `{"customer_user_id":"shop-001"}`,
+			statusCode:      http.StatusBadRequest,
+			wantStatus:      model.IntentPending,
+			customerBalance: 10000,
+			merchantBalance: 0,
+		},
+		{
+			name:            "cancel",
+			action:          "cancel",
+			statusCode:      http.StatusOK,
+			wantStatus:      model.IntentCancelled,
+			customerBalance: 10000,
+			merchantBalance: 0,
+		},
+		{
+			name:            "cancel_after_payment",
+			action:          "cancel",
+			confirmFirst:    true,
+			statusCode:      http.StatusConflict,
+			wantStatus:      model.IntentSucceeded,
+			customerBalance: 6000,
+			merchantBalance: 4000,
+		},
+		{
+			name:            "partial_refund",
+			action:          "refunds",
+			body:            `{"amount":1500, "reason":"damaged item"}`,
+			confirmFirst:    true,
+			statusCode:      http.StatusCreated,
+			wantStatus:      model.IntentSucceeded,
+			customerBalance: 7500,
+			merchantBalance: 2500,
+		},
+		{
+			name:            "refund_exceeding_what_is_left",
+			action:          "refunds",
+			body:            `{"amount":1500}`,
+			confirmFirst:    true,
+			refundFirst:     3000,
+			statusCode:      http.StatusUnprocessableEntity,
+			wantStatus:      model.IntentSucceeded,
+			customerBalance: 9000,
+			merchantBalance: 1000,
+		},
+		{
+			name:            "refund_unpaid_intent",
+			action:          "refunds",
+			body:            `{"amount":1000}`,
+			statusCode:      http.StatusConflict,
+			wantStatus:      model.IntentPending,
+			customerBalance: 10000,
+			merchantBalance: 0,
+		},
+	}
+
+	actions := map[string]echo.HandlerFunc{
+		"confirm": handler.ConfirmPaymentIntent,
+		"cancel":  handler.CancelPaymentIntent,
+		"refunds": handler.RefundPaymentIntent,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.PaymentRefund{}, model.PaymentIntent{}, model.MerchantProfile{}, model.Wallet{})
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, 1000)
+
+			ctx := context.Background()
+			_, err := walletService.CreateMerchant(ctx, &model.MerchantProfile{UserID: "shop-001", DisplayName: "Corner Shop"})
+			require.NoError(t, err)
+			intent, err := walletService.CreatePaymentIntent(ctx, "shop-001", 4000, "order-1", "")
+			require.NoError(t, err)
+			if tt.confirmFirst {
+				_, err := walletService.ConfirmPaymentIntent(ctx, intent.ID, "test-user-001")
+				require.NoError(t, err)
+			}
+			if tt.refundFirst > 0 {
+				_, err := walletService.RefundPaymentIntent(ctx, intent.ID, tt.refundFirst, "")
+				require.NoError(t, err)
+			}
+
+			id := strconv.Itoa(intent.ID)
+			req := httptest.NewRequest(http.MethodPost, "/payment-intents/"+id+"/"+tt.action, bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/payment-intents/:id/" + tt.action)
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+
+			require.NoError(t, actions[tt.action](c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			stored, err := walletRepository.FindPaymentIntent(ctx, intent.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, stored.Status)
+
+			for userID, want := range map[string]int64{"test-user-001": tt.customerBalance, "shop-001": tt.merchantBalance} {
+				wallet, err := walletRepository.FindByUserID(ctx, userID)
+				require.NoError(t, err)
+				assert.Equal(t, want, wallet.Balance, userID)
+			}
+		})
+	}
+}
+
+func TestWalletHandler_SettlementReport(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	walletService := service.NewWalletService(walletRepository)
+	handler := NewWalletController(walletService)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+		clearDB(dbInstance, model.PaymentRefund{}, model.PaymentIntent{}, model.MerchantProfile{})
+	}()
+
+	clearDB(dbInstance, model.PaymentRefund{}, model.PaymentIntent{}, model.MerchantProfile{}, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+
+	ctx := context.Background()
+	_, err = walletService.CreateMerchant(ctx, &model.MerchantProfile{UserID: "shop-001", DisplayName: "Corner Shop"})
+	require.NoError(t, err)
+	for i, amount := range []int{4000, 2000} {
+		intent, err := walletService.CreatePaymentIntent(ctx, "shop-001", amount, "order-"+strconv.Itoa(i), "")
+		require.NoError(t, err)
+		_, err = walletService.ConfirmPaymentIntent(ctx, intent.ID, "test-user-001")
+		require.NoError(t, err)
+		if i == 0 {
+			_, err = walletService.RefundPaymentIntent(ctx, intent.ID, 500, "")
+			require.NoError(t, err)
+		}
+	}
+	// Never paid, so not part of the report
+	_, err = walletService.CreatePaymentIntent(ctx, "shop-001", 9000, "order-unpaid", "")
+	require.NoError(t, err)
+
+	today := time.Now().UTC().Format(model.DateLayout)
+	req := httptest.NewRequest(http.MethodGet, "/merchants/shop-001/settlement?from="+today+"&to="+today, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/merchants/:user_id/settlement")
+	c.SetParamNames("user_id")
+	c.SetParamValues("shop-001")
+
+	require.NoError(t, handler.SettlementReport(c))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got struct {
+		Data model.SettlementReport `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Len(t, got.Data.Days, 1)
+	assert.Len(t, got.Data.Days[0].Payments, 2)
+	assert.Len(t, got.Data.Days[0].Refunds, 1)
+	assert.Equal(t, int64(6000), got.Data.PaymentsAmount)
+	assert.Equal(t, int64(500), got.Data.RefundsAmount)
+	assert.Equal(t, int64(5500), got.Data.NetAmount)
+}
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// sseKeepAlive is the interval of the comments sent on an idle event stream
+// so proxies do not close it.
+const sseKeepAlive = 15 * time.Second
+
+// CreatePaymentRequestRequest is the request parameter for asking another user for money
+type CreatePaymentRequestRequest struct {
+	UserID      string     `param:"user_id" validate:"required"`
+	PayerUserID string     `json:"payer_user_id" validate:"required"`
+	Amount      int        `json:"amount" validate:"required,gt=0"`
+	Note        string     `json:"note" validate:"max=280"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // RFC 3339, defaults to the configured expiry
+}
+
+// ListPaymentRequestsRequest is the request parameter for listing the payment requests of a user
+type ListPaymentRequestsRequest struct {
+	UserID    string `param:"user_id" validate:"required"`
+	Direction string `query:"direction" validate:"omitempty,oneof=incoming outgoing"` // defaults to incoming
+	Status    string `query:"status" validate:"omitempty,oneof=pending accepted declined cancelled expired"`
+}
+
+// PaymentRequestActionRequest is the request parameter for accepting, declining or cancelling a payment request
+type PaymentRequestActionRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	ID     int    `param:"id" validate:"required,gt=0"`
+}
+
+// @Summary	Request money from another user
+// @Tags		payment-requests
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string						true	"User ID of the requester"
+// @Param		request	body		CreatePaymentRequestRequest	true	"Payment request"
+// @Success	201		{object}	ResponseData{data=model.PaymentRequest}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/payment-requests [post]
+func (t *walletHandler) CreatePaymentRequest(c echo.Context) error {
+	var req CreatePaymentRequestRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	request, err := t.service.CreatePaymentRequest(c.Request().Context(),
+		req.UserID, req.PayerUserID, req.Amount, req.Note, req.ExpiresAt)
+	if err != nil {
+		return paymentRequestError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: request})
+}
+
+// @Summary	List incoming or outgoing payment requests
+// @Tags		payment-requests
+// @Produce	json
+// @Param		user_id		path		string	true	"User ID"
+// @Param		direction	query		string	false	"incoming (default) or outgoing"
+// @Param		status		query		string	false	"pending, accepted, declined, cancelled or expired"
+// @Success	200			{object}	ResponseData{data=[]model.PaymentRequest}
+// @Failure	400			{object}	ResponseError
+// @Failure	404			{object}	ResponseError
+// @Failure	500			{object}	ResponseError
+// @Router		/wallets/{user_id}/payment-requests [get]
+func (t *walletHandler) ListPaymentRequests(c echo.Context) error {
+	var req ListPaymentRequestsRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	requests, err := t.service.ListPaymentRequests(c.Request().Context(), model.PaymentRequestFilter{
+		UserID:   req.UserID,
+		Incoming: req.Direction != "outgoing",
+		Status:   model.PaymentRequestStatus(req.Status),
+	})
+	if err != nil {
+		return paymentRequestError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: requests})
+}
+
+// @Summary	Accept and pay an incoming payment request
+// @Tags		payment-requests
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the payer"
+// @Param		id		path		int		true	"Payment request ID"
+// @Success	200		{object}	ResponseData{data=model.PaymentRequest}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/payment-requests/{id}/accept [post]
+func (t *walletHandler) AcceptPaymentRequest(c echo.Context) error {
+	var req PaymentRequestActionRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	request, err := t.service.AcceptPaymentRequest(c.Request().Context(), req.UserID, req.ID)
+	if err != nil {
+		return paymentRequestError(c, err, "Payment request not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: request})
+}
+
+// @Summary	Decline an incoming payment request
+// @Tags		payment-requests
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the payer"
+// @Param		id		path		int		true	"Payment request ID"
+// @Success	200		{object}	ResponseData{data=model.PaymentRequest}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/payment-requests/{id}/decline [post]
+func (t *walletHandler) DeclinePaymentRequest(c echo.Context) error {
+	var req PaymentRequestActionRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	request, err := t.service.DeclinePaymentRequest(c.Request().Context(), req.UserID, req.ID)
+	if err != nil {
+		return paymentRequestError(c, err, "Payment request not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: request})
+}
+
+// @Summary	Cancel an outgoing payment request
+// @Tags		payment-requests
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the requester"
+// @Param		id		path		int		true	"Payment request ID"
+// @Success	200		{object}	ResponseData{data=model.PaymentRequest}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/payment-requests/{id}/cancel [post]
+func (t *walletHandler) CancelPaymentRequest(c echo.Context) error {
+	var req PaymentRequestActionRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	request, err := t.service.CancelPaymentRequest(c.Request().Context(), req.UserID, req.ID)
+	if err != nil {
+		return paymentRequestError(c, err, "Payment request not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: request})
+}
+
+// @Summary	Stream payment request events as server-sent events
+// @Description	Emits an event whenever a request sent or received by the user is created or changes state. Events are not replayed; list the requests after connecting to catch up.
+// @Tags		payment-requests
+// @Produce	text/event-stream
+// @Param		user_id	path		string	true	"User ID"
+// @Success	200		{object}	model.PaymentRequestEvent
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/payment-requests/events [get]
+func (t *walletHandler) PaymentRequestEvents(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	ctx := c.Request().Context()
+	events, err := t.service.SubscribePaymentRequests(ctx, req.UserID)
+	if err != nil {
+		return paymentRequestError(c, err, "Wallet not found")
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Event, data); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// paymentRequestError writes the error response of the payment request endpoints.
+func paymentRequestError(c echo.Context, err error, notFound string) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: notFound}}})
+	case model.ErrSameWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Cannot request money from the same wallet"}}})
+	case model.ErrInvalidTimeRange:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "expires_at must be in the future and within the maximum expiry"}}})
+	case model.ErrInvalidTransition:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Payment request is no longer pending"}}})
+	case model.ErrExpired:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Payment request has expired"}}})
+	case model.ErrInsufficientFunds:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
+	case model.ErrApprovalRequired:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Amount needs approval by the wallet's owners; transfer by user ID to request it"}}})
+	case model.ErrWalletClosed:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is closed"}}})
+	case model.ErrWalletSuspended:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is suspended"}}})
+	case model.ErrBalanceLimitExceeded:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBalanceLimitExceeded, Message: "Balance would exceed the wallet's limit"}}})
+	case model.ErrKYCUpgradeRequired:
+		return c.JSON(http.StatusForbidden,
+			ResponseError{Errors: []Error{{Code: errors.CodeKYCUpgradeRequired, Message: "KYC level does not allow this operation; verify the wallet to a higher level"}}})
+	case model.ErrConcurrentUpdate:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_CreatePaymentRequest(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	clearDB(dbInstance, model.PaymentRequest{}, model.Wallet{})
+	createTestWallet(t, dbInstance, "test-user-001", model.User)
+	createTestWallet(t, dbInstance, "test-user-002", model.User)
+
+	tests := []struct {
+		name       string
+		userID     string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "successful_request",
+			userID:     "test-user-001",
+			body:       `{"payer_user_id":"test-user-002", "amount":1500, "note":"Dinner"}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "expiry_in_the_past",
+			userID:     "test-user-001",
+			body:       `{"payer_user_id":"test-user-002", "amount":1500, "expires_at":"2020-01-01T00:00:00Z"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "request_from_self",
+			userID:     "test-user-001",
+			body:       `{"payer_user_id":"test-user-001", "amount":1500}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "payer_not_found",
+			userID:     "test-user-001",
+			body:       `{"payer_user_id":"non-existent-user", "amount":1500}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "invalid_amount",
+			userID:     "test-user-001",
+			body:       `{"payer_user_id":"test-user-002", "amount":0}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/wallets/"+tt.userID+"/payment-requests", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/payment-requests")
+			c.SetParamNames("user_id")
+			c.SetParamValues(tt.userID)
+
+			require.NoError(t, handler.CreatePaymentRequest(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+			var got struct {
+				Data model.PaymentRequest `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, model.RequestPending, got.Data.Status)
+			assert.Equal(t, "test-user-002", got.Data.PayerID)
+			assert.True(t, got.Data.ExpiresAt.After(time.Now()))
+		})
+	}
+}
+
+func TestWalletHandler_ResolvePaymentRequest(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	walletService := service.NewWalletService(walletRepository)
+	handler := NewWalletController(walletService)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	tests := []struct {
+		name          string
+		action        string
+		userID        string
+		payerBalance  int64
+		expired       bool
+		statusCode    int
+		wantStatus    model.PaymentRequestStatus
+		wantRequester int64
+	}{
+		{
+			name:          "payer_accepts",
+			action:        "accept",
+			userID:        "test-user-002",
+			payerBalance:  5000,
+			statusCode:    http.StatusOK,
+			wantStatus:    model.RequestAccepted,
+			wantRequester: 1500,
+		},
+		{
+			name:         "accept_with_insufficient_funds_stays_pending",
+			action:       "accept",
+			userID:       "test-user-002",
+			payerBalance: 1000,
+			statusCode:   http.StatusUnprocessableEntity,
+			wantStatus:   model.RequestPending,
+		},
+		{
+			name:         "accept_expired_request",
+			action:       "accept",
+			userID:       "test-user-002",
+			payerBalance: 5000,
+			expired:      true,
+			statusCode:   http.StatusConflict,
+			wantStatus:   model.RequestExpired,
+		},
+		{
+			name:         "requester_cannot_accept",
+			action:       "accept",
+			userID:       "test-user-001",
+			payerBalance: 5000,
+			statusCode:   http.StatusNotFound,
+			wantStatus:   model.RequestPending,
+		},
+		{
+			name:         "payer_declines",
+			action:       "decline",
+			userID:       "test-user-002",
+			payerBalance: 5000,
+			statusCode:   http.StatusOK,
+			wantStatus:   model.RequestDeclined,
+		},
+		{
+			name:         "requester_cancels",
+			action:       "cancel",
+			userID:       "test-user-001",
+			payerBalance: 5000,
+			statusCode:   http.StatusOK,
+			wantStatus:   model.RequestCancelled,
+		},
+		{
+			name:         "payer_cannot_cancel",
+			action:       "cancel",
+			userID:       "test-user-002",
+			payerBalance: 5000,
+			statusCode:   http.StatusNotFound,
+			wantStatus:   model.RequestPending,
+		},
+	}
+
+	actions := map[string]echo.HandlerFunc{
+		"accept":  handler.AcceptPaymentRequest,
+		"decline": handler.DeclinePaymentRequest,
+		"cancel":  handler.CancelPaymentRequest,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.PaymentRequest{}, model.Wallet{})
+			createTestWallet(t, dbInstance, "test-user-001", model.User)
+			createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, tt.payerBalance)
+
+			ctx := context.Background()
+			request, err := walletService.CreatePaymentRequest(ctx, "test-user-001", "test-user-002", 1500, "", nil)
+			require.NoError(t, err)
+			if tt.expired {
+				require.NoError(t, dbInstance.Model(request).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+			}
+
+			id := strconv.Itoa(request.ID)
+			req := httptest.NewRequest(http.MethodPost, "/wallets/"+tt.userID+"/payment-requests/"+id+"/"+tt.action, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/payment-requests/:id/" + tt.action)
+			c.SetParamNames("user_id", "id")
+			c.SetParamValues(tt.userID, id)
+
+			require.NoError(t, actions[tt.action](c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			stored, err := walletRepository.FindPaymentRequest(ctx, request.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, stored.Status)
+
+			requester, err := walletRepository.FindByUserID(ctx, "test-user-001")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRequester, requester.Balance)
+		})
+	}
+}
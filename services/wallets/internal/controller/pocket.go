@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// CreatePocketRequest is the request parameter for opening a pocket under a user wallet
+type CreatePocketRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	Name   string `json:"name" validate:"required"`
+}
+
+// PocketRequest is the request parameter for a single pocket
+type PocketRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	Name   string `param:"name" validate:"required"`
+}
+
+// MovePocketFundsRequest is the request parameter for moving funds between a
+// user's wallet and its pockets. "main" names the user's wallet itself.
+type MovePocketFundsRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	From   string `json:"from" validate:"required"`
+	To     string `json:"to" validate:"required"`
+	Amount int    `json:"amount" validate:"required,gt=0"`
+}
+
+// @Summary	Open a named pocket under a user wallet
+// @Tags		pockets
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string				true	"User ID of the owner"
+// @Param		request	body		CreatePocketRequest	true	"Pocket"
+// @Success	201		{object}	ResponseData{data=model.Wallet}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/pockets [post]
+func (t *walletHandler) CreatePocket(c echo.Context) error {
+	var req CreatePocketRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	pocket, err := t.service.CreatePocket(c.Request().Context(), req.UserID, req.Name)
+	if err != nil {
+		return pocketError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: pocket})
+}
+
+// @Summary	List the pockets of a user wallet
+// @Tags		pockets
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the owner"
+// @Success	200		{object}	ResponseData{data=[]model.Wallet}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/pockets [get]
+func (t *walletHandler) ListPockets(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	pockets, err := t.service.ListPockets(c.Request().Context(), req.UserID)
+	if err != nil {
+		return pocketError(c, err, "Wallet not found")
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: pockets})
+}
+
+// @Summary	View pocket balance & transaction history
+// @Tags		pockets
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the owner"
+// @Param		name	path		string	true	"Pocket name"
+// @Success	200		{object}	ResponseData{data=WalletResponse}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/pockets/{name} [get]
+func (t *walletHandler) GetPocket(c echo.Context) error {
+	var req PocketRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	pocket, transactions, err := t.service.GetPocketWithTransactions(c.Request().Context(), req.UserID, req.Name)
+	if err != nil {
+		return pocketError(c, err, "Pocket not found")
+	}
+
+	response := WalletResponse{
+		Wallet:       newWalletSummary(pocket),
+		Transactions: transactions,
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: response})
+}
+
+// @Summary	Move funds between a user wallet and its pockets
+// @Description	"main" names the user wallet itself. Moves are recorded as pocket_move transactions and are not transfers between users.
+// @Tags		pockets
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string					true	"User ID of the owner"
+// @Param		request	body		MovePocketFundsRequest	true	"Move"
+// @Success	201		{object}	ResponseData{data=model.Transaction}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/pockets/move [post]
+func (t *walletHandler) MovePocketFunds(c echo.Context) error {
+	var req MovePocketFundsRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	transaction, err := t.service.MovePocketFunds(c.Request().Context(), req.UserID, req.From, req.To, req.Amount)
+	if err != nil {
+		return pocketError(c, err, "Wallet or pocket not found")
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: transaction})
+}
+
+// pocketError writes the error response of the pocket endpoints.
+func pocketError(c echo.Context, err error, notFound string) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: notFound}}})
+	case model.ErrInvalidPocketName:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Pocket name must be 1 to 30 letters, digits, dashes or underscores and not \"main\""}}})
+	case model.ErrNotUserWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Only user wallets have pockets"}}})
+	case model.ErrSameWallet:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Cannot move funds to the same pocket"}}})
+	case model.ErrPocketExists:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Pocket already exists"}}})
+	case model.ErrInsufficientFunds:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
+	case model.ErrWalletClosed:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is closed"}}})
+	case model.ErrWalletSuspended:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is suspended"}}})
+	case model.ErrBalanceLimitExceeded:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBalanceLimitExceeded, Message: "Balance would exceed the wallet's limit"}}})
+	case model.ErrConcurrentUpdate:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_CreatePocket(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	handler := NewWalletController(service.NewWalletService(walletRepository))
+
+	tests := []struct {
+		name       string
+		userID     string
+		body       string
+		existing   bool
+		statusCode int
+	}{
+		{name: "successful_create", userID: "test-user-001", body: `{"name":"Savings"}`, statusCode: http.StatusCreated},
+		{name: "duplicate_name", userID: "test-user-001", body: `{"name":"savings"}`, existing: true, statusCode: http.StatusConflict},
+		{name: "reserved_name", userID: "test-user-001", body: `{"name":"main"}`, statusCode: http.StatusBadRequest},
+		{name: "provider_wallet", userID: model.DepositProviderID, body: `{"name":"savings"}`, statusCode: http.StatusBadRequest},
+		{name: "wallet_not_found", userID: "non-existent-user", body: `{"name":"savings"}`, statusCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.Wallet{})
+			createTestWallet(t, dbInstance, "test-user-001", model.User)
+			createTestWallet(t, dbInstance, model.DepositProviderID, model.Provider)
+			owner, err := walletRepository.FindByUserID(context.Background(), "test-user-001")
+			require.NoError(t, err)
+			if tt.existing {
+				require.NoError(t, walletRepository.CreatePocket(context.Background(), model.NewPocket(owner, "savings")))
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/wallets/"+tt.userID+"/pockets", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/pockets")
+			c.SetParamNames("user_id")
+			c.SetParamValues(tt.userID)
+
+			require.NoError(t, handler.CreatePocket(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+			var got struct {
+				Data model.Wallet `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, model.Pocket, got.Data.AcntType)
+			assert.Equal(t, "savings", got.Data.PocketName)
+			assert.Equal(t, owner.ID, *got.Data.ParentID)
+		})
+	}
+}
+
+func TestWalletHandler_MovePocketFunds(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	handler := NewWalletController(service.NewWalletService(walletRepository))
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		main       int64
+		savings    int64
+		travel     int64
+	}{
+		{name: "main_to_pocket", body: `{"from":"main", "to":"savings", "amount":4000}`, statusCode: http.StatusCreated, main: 6000, savings: 5000, travel: 0},
+		{name: "pocket_to_main", body: `{"from":"savings", "to":"main", "amount":1000}`, statusCode: http.StatusCreated, main: 11000, savings: 0, travel: 0},
+		{name: "pocket_to_pocket", body: `{"from":"savings", "to":"travel", "amount":600}`, statusCode: http.StatusCreated, main: 10000, savings: 400, travel: 600},
+		{name: "insufficient_funds", body: `{"from":"savings", "to":"main", "amount":1001}`, statusCode: http.StatusUnprocessableEntity, main: 10000, savings: 1000, travel: 0},
+		{name: "same_pocket", body: `{"from":"savings", "to":"Savings", "amount":100}`, statusCode: http.StatusBadRequest, main: 10000, savings: 1000, travel: 0},
+		{name: "pocket_not_found", body: `{"from":"main", "to":"holidays", "amount":100}`, statusCode: http.StatusNotFound, main: 10000, savings: 1000, travel: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.Wallet{})
+			ctx := context.Background()
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			owner, err := walletRepository.FindByUserID(ctx, "test-user-001")
+			require.NoError(t, err)
+			savings := model.NewPocket(owner, "savings")
+			savings.Balance = 1000
+			require.NoError(t, walletRepository.CreatePocket(ctx, savings))
+			travel := model.NewPocket(owner, "travel")
+			require.NoError(t, walletRepository.CreatePocket(ctx, travel))
+
+			req := httptest.NewRequest(http.MethodPost, "/wallets/test-user-001/pockets/move", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/pockets/move")
+			c.SetParamNames("user_id")
+			c.SetParamValues("test-user-001")
+
+			require.NoError(t, handler.MovePocketFunds(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			for userID, want := range map[string]int64{owner.UserID: tt.main, savings.UserID: tt.savings, travel.UserID: tt.travel} {
+				wallet, err := walletRepository.FindByUserID(ctx, userID)
+				require.NoError(t, err)
+				assert.Equal(t, want, wallet.Balance, userID)
+			}
+		})
+	}
+}
+
+func TestWalletHandler_FetchTransactionsWithPockets(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	handler := NewWalletController(service.NewWalletService(walletRepository))
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	clearDB(dbInstance, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+	owner, err := walletRepository.FindByUserID(context.Background(), "test-user-001")
+	require.NoError(t, err)
+	for name, balance := range map[string]int64{"savings": 2500, "travel": 500} {
+		pocket := model.NewPocket(owner, name)
+		pocket.Balance = balance
+		require.NoError(t, walletRepository.CreatePocket(context.Background(), pocket))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/test-user-001", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/wallets/:user_id")
+	c.SetParamNames("user_id")
+	c.SetParamValues("test-user-001")
+
+	require.NoError(t, handler.FetchTransactions(c))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var got struct {
+		Data WalletResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, int64(10000), got.Data.Wallet.Balance)
+	assert.Equal(t, int64(13000), got.Data.Wallet.TotalBalance)
+	assert.Equal(t, []PocketSummary{{Name: "savings", Balance: 2500}, {Name: "travel", Balance: 500}}, got.Data.Wallet.Pockets)
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterProviderRequest is the request parameter for registering a provider with its wallet
+type RegisterProviderRequest struct {
+	UserID              string             `json:"user_id" validate:"required"`
+	DisplayName         string             `json:"display_name" validate:"max=100"`
+	ExternalBank        string             `json:"external_bank" validate:"max=100"`
+	ExternalAccount     string             `json:"external_account" validate:"max=34"`
+	FeeSchedule         FeeScheduleRequest `json:"fee_schedule"`
+	SupportedCurrencies []string           `json:"supported_currencies" validate:"dive,len=3,uppercase"` // ISO 4217 codes, USD if empty
+	LowFloatThreshold   int64              `json:"low_float_threshold" validate:"gte=0"`                 // In cents; 0 uses the configured default
+}
+
+// FeeScheduleRequest is what a provider charges for moving funds in and out
+type FeeScheduleRequest struct {
+	DepositBps    int   `json:"deposit_bps" validate:"gte=0,lte=10000"`
+	WithdrawalBps int   `json:"withdrawal_bps" validate:"gte=0,lte=10000"`
+	Fixed         int64 `json:"fixed" validate:"gte=0"` // In cents
+}
+
+// ProviderRequest is the request parameter for an existing provider
+type ProviderRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+}
+
+// TopUpProviderRequest is the request parameter for adding float to a provider wallet
+type TopUpProviderRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	Amount int    `json:"amount" validate:"required,gt=0"`
+}
+
+// @Summary	Register a provider and open its wallet
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		request	body		RegisterProviderRequest	true	"Provider"
+// @Success	201		{object}	ResponseData{data=model.ProviderProfile}
+// @Failure	400		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/providers [post]
+func (t *walletHandler) RegisterProvider(c echo.Context) error {
+	var req RegisterProviderRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	profile, err := t.service.RegisterProvider(c.Request().Context(), &model.ProviderProfile{
+		UserID:          req.UserID,
+		DisplayName:     req.DisplayName,
+		ExternalBank:    req.ExternalBank,
+		ExternalAccount: req.ExternalAccount,
+		FeeSchedule: model.FeeSchedule{
+			DepositBps:    req.FeeSchedule.DepositBps,
+			WithdrawalBps: req.FeeSchedule.WithdrawalBps,
+			Fixed:         req.FeeSchedule.Fixed,
+		},
+		SupportedCurrencies: req.SupportedCurrencies,
+		LowFloatThreshold:   req.LowFloatThreshold,
+	})
+	if err != nil {
+		return providerError(c, err)
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: profile})
+}
+
+// @Summary	List provider wallets
+// @Tags		admin
+// @Produce	json
+// @Success	200	{object}	ResponseData{data=[]model.ProviderProfile}
+// @Failure	500	{object}	ResponseError
+// @Router		/admin/providers [get]
+func (t *walletHandler) ListProviders(c echo.Context) error {
+	providers, err := t.service.ListProviders(c.Request().Context())
+	if err != nil {
+		return providerError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: providers})
+}
+
+// @Summary	View a provider wallet
+// @Tags		admin
+// @Produce	json
+// @Param		user_id	path		string	true	"Provider user ID"
+// @Success	200		{object}	ResponseData{data=model.ProviderProfile}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/providers/{user_id} [get]
+func (t *walletHandler) GetProvider(c echo.Context) error {
+	var req ProviderRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	profile, err := t.service.GetProvider(c.Request().Context(), req.UserID)
+	if err != nil {
+		return providerError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: profile})
+}
+
+// @Summary	Disable deposits and withdrawals through a provider
+// @Tags		admin
+// @Produce	json
+// @Param		user_id	path		string	true	"Provider user ID"
+// @Success	200		{object}	ResponseData{data=model.ProviderProfile}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/providers/{user_id}/disable [post]
+func (t *walletHandler) DisableProvider(c echo.Context) error {
+	return t.setProviderEnabled(c, false)
+}
+
+// @Summary	Enable deposits and withdrawals through a provider
+// @Tags		admin
+// @Produce	json
+// @Param		user_id	path		string	true	"Provider user ID"
+// @Success	200		{object}	ResponseData{data=model.ProviderProfile}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/providers/{user_id}/enable [post]
+func (t *walletHandler) EnableProvider(c echo.Context) error {
+	return t.setProviderEnabled(c, true)
+}
+
+func (t *walletHandler) setProviderEnabled(c echo.Context, enabled bool) error {
+	var req ProviderRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	profile, err := t.service.SetProviderEnabled(c.Request().Context(), req.UserID, enabled)
+	if err != nil {
+		return providerError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: profile})
+}
+
+// @Summary	Add float to a provider wallet from its external bank account
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string					true	"Provider user ID"
+// @Param		request	body		TopUpProviderRequest	true	"Top-up"
+// @Success	201		{object}	ResponseData{data=model.Transaction}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/providers/{user_id}/top-up [post]
+func (t *walletHandler) TopUpProvider(c echo.Context) error {
+	var req TopUpProviderRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	transaction, err := t.service.TopUpProvider(c.Request().Context(), req.UserID, req.Amount)
+	if err != nil {
+		return providerError(c, err)
+	}
+	return c.JSON(http.StatusCreated, ResponseData{Data: transaction})
+}
+
+// providerError writes the error response of the provider admin endpoints.
+func providerError(c echo.Context, err error) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Provider not found"}}})
+	case model.ErrInvalidAmount:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Amount must be more than zero"}}})
+	case model.ErrProviderExists:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "User ID already has a wallet"}}})
+	case model.ErrConcurrentUpdate:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
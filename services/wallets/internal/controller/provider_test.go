@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_RegisterProvider(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "successful_registration",
+			body:       `{"user_id":"acme-bank", "display_name":"ACME Bank", "external_bank":"ACME", "fee_schedule":{"deposit_bps":25, "fixed":30}, "supported_currencies":["USD", "EUR"], "low_float_threshold":50000}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "user_id_has_a_wallet",
+			body:       `{"user_id":"test-user-001"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "invalid_currency",
+			body:       `{"user_id":"acme-bank", "supported_currencies":["usd"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "fee_above_100_percent",
+			body:       `{"user_id":"acme-bank", "fee_schedule":{"withdrawal_bps":10001}}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.ProviderProfile{}, model.Wallet{})
+			createTestWallet(t, dbInstance, "test-user-001", model.User)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/providers", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, handler.RegisterProvider(e.NewContext(req, rec)))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+			var got struct {
+				Data model.ProviderProfile `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, "acme-bank", got.Data.UserID)
+			assert.Equal(t, model.Active, got.Data.Status)
+			assert.Equal(t, []string{"USD", "EUR"}, got.Data.SupportedCurrencies)
+			assert.Equal(t, model.FeeSchedule{DepositBps: 25, Fixed: 30}, got.Data.FeeSchedule)
+			assert.True(t, got.Data.LowFloat, "a new provider has no float")
+		})
+	}
+}
+
+func TestWalletHandler_ProviderLifecycle(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+		clearDB(dbInstance, model.ProviderProfile{})
+	}()
+
+	clearDB(dbInstance, model.ProviderProfile{}, model.Wallet{})
+	createTestWallet(t, dbInstance, "test-user-001", model.User)
+	createTestWalletWithBalance(t, dbInstance, model.DepositProviderID, model.Provider, 1000)
+
+	call := func(handle echo.HandlerFunc, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		c.SetParamNames("user_id")
+		c.SetParamValues(model.DepositProviderID)
+		require.NoError(t, handle(c))
+		return rec
+	}
+	deposit := func() int {
+		return call(handler.Deposit, "/wallets/deposit", `{"user_id":"test-user-001", "amount":500}`).Code
+	}
+
+	// The seeded provider has no profile of its own and is listed all the same
+	req := httptest.NewRequest(http.MethodGet, "/admin/providers", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, handler.ListProviders(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Data []model.ProviderProfile `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, model.DepositProviderID, list.Data[0].UserID)
+	assert.Equal(t, int64(1000), list.Data[0].Balance)
+
+	rec = call(handler.DisableProvider, "/admin/providers/:user_id/disable", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"suspended"`)
+	assert.Equal(t, http.StatusUnprocessableEntity, deposit())
+
+	rec = call(handler.EnableProvider, "/admin/providers/:user_id/enable", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, http.StatusCreated, deposit())
+
+	rec = call(handler.TopUpProvider, "/admin/providers/:user_id/top-up", `{"amount":2500}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"transaction_type":"top_up"`)
+
+	rec = call(handler.GetProvider, "/admin/providers/:user_id", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got struct {
+		Data model.ProviderProfile `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, int64(3000), got.Data.Balance)
+}
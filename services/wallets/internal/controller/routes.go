@@ -11,6 +11,85 @@ func InitRoutes(api *echo.Group, controller WalletHandler) {
 		wallet.POST("/deposit", controller.Deposit)
 		wallet.POST("/withdraw", controller.Withdraw)
 		wallet.POST("/transfer", controller.Transfer)
+		wallet.POST("/transfer/split", controller.TransferSplit)
+		wallet.GET("/resolve", controller.ResolveAlias)
 		wallet.GET("/:user_id", controller.FetchTransactions)
+		wallet.GET("/:user_id/balance", controller.BalanceAt)
+		wallet.GET("/:user_id/balance/daily", controller.DailyBalances)
+		wallet.GET("/:user_id/statement", controller.Statement)
+		wallet.POST("/:user_id/aliases", controller.RegisterAlias)
+		wallet.GET("/:user_id/aliases", controller.ListAliases)
+		wallet.POST("/:user_id/aliases/challenge", controller.ChallengeAlias)
+		wallet.POST("/:user_id/aliases/verify", controller.VerifyAlias)
+		wallet.POST("/:user_id/payment-requests", controller.CreatePaymentRequest)
+		wallet.GET("/:user_id/payment-requests", controller.ListPaymentRequests)
+		wallet.GET("/:user_id/payment-requests/events", controller.PaymentRequestEvents)
+		wallet.POST("/:user_id/payment-requests/:id/accept", controller.AcceptPaymentRequest)
+		wallet.POST("/:user_id/payment-requests/:id/decline", controller.DeclinePaymentRequest)
+		wallet.POST("/:user_id/payment-requests/:id/cancel", controller.CancelPaymentRequest)
+		wallet.POST("/:user_id/pockets", controller.CreatePocket)
+		wallet.GET("/:user_id/pockets", controller.ListPockets)
+		wallet.POST("/:user_id/pockets/move", controller.MovePocketFunds)
+		wallet.GET("/:user_id/pockets/:name", controller.GetPocket)
+		wallet.POST("/:user_id/members", controller.AddMember)
+		wallet.GET("/:user_id/members", controller.ListMembers)
+		wallet.DELETE("/:user_id/members/:member_id", controller.RemoveMember)
+		wallet.PUT("/:user_id/approval-policy", controller.SetApprovalPolicy)
+		wallet.GET("/:user_id/approvals", controller.ListApprovals)
+		wallet.POST("/:user_id/approvals/:id/approve", controller.ApproveOperation)
+		wallet.POST("/:user_id/approvals/:id/reject", controller.RejectOperation)
+		wallet.POST("/:user_id/close", controller.CloseWallet)
+		wallet.POST("/:user_id/kyc", controller.SubmitKYCVerification)
+		wallet.GET("/:user_id/kyc", controller.GetKYCProfile)
 	}
+
+	escrow := api.Group("/escrows")
+	{
+		escrow.POST("", controller.CreateEscrow)
+		escrow.GET("/:id", controller.GetEscrow)
+		escrow.POST("/:id/release", controller.ReleaseEscrow)
+		escrow.POST("/:id/refund", controller.RefundEscrow)
+		escrow.POST("/:id/split", controller.SplitEscrow)
+	}
+
+	merchant := api.Group("/merchants")
+	{
+		merchant.POST("", controller.CreateMerchant)
+		merchant.GET("/:user_id", controller.GetMerchant)
+		merchant.POST("/:user_id/payment-intents", controller.CreatePaymentIntent)
+		merchant.GET("/:user_id/payment-intents", controller.ListPaymentIntents)
+		merchant.GET("/:user_id/settlement", controller.SettlementReport)
+	}
+
+	intent := api.Group("/payment-intents")
+	{
+		intent.GET("/:id", controller.GetPaymentIntent)
+		intent.POST("/:id/confirm", controller.ConfirmPaymentIntent)
+		intent.POST("/:id/cancel", controller.CancelPaymentIntent)
+		intent.POST("/:id/refunds", controller.RefundPaymentIntent)
+	}
+}
+
+// InitAdminRoutes registers the operator endpoints on the admin group, which
+// must only be served behind AdminAuth on the internal admin server.
+func InitAdminRoutes(admin *echo.Group, controller WalletHandler) {
+	admin.POST("/providers", controller.RegisterProvider)
+	admin.GET("/providers", controller.ListProviders)
+	admin.GET("/providers/:user_id", controller.GetProvider)
+	admin.POST("/providers/:user_id/disable", controller.DisableProvider)
+	admin.POST("/providers/:user_id/enable", controller.EnableProvider)
+	admin.POST("/providers/:user_id/top-up", controller.TopUpProvider)
+	admin.POST("/wallets/:user_id/reopen", controller.ReopenWallet)
+	admin.GET("/kyc", controller.ListKYCVerifications)
+	admin.POST("/kyc/:id/approve", controller.ApproveKYCVerification)
+	admin.POST("/kyc/:id/reject", controller.RejectKYCVerification)
+	admin.POST("/adjustments", controller.ProposeAdjustment)
+	admin.GET("/adjustments", controller.ListAdjustments)
+	admin.GET("/adjustments/:id", controller.GetAdjustment)
+	admin.POST("/adjustments/:id/approve", controller.ApproveAdjustment)
+	admin.POST("/adjustments/:id/reject", controller.RejectAdjustment)
+	admin.POST("/settlements/run", controller.RunSettlement)
+	admin.GET("/settlements", controller.ListSettlementBatches)
+	admin.GET("/settlements/export", controller.ExportSettlement)
+	admin.GET("/settlements/:id", controller.GetSettlementBatch)
 }
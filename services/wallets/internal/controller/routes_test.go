@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
 	"github.com/labstack/echo/v4"
@@ -31,11 +32,34 @@ func TestRegister(t *testing.T) {
 		expectedCode int
 	}{
 		{"Health_Check", http.MethodGet, "/api/v1/health", http.StatusOK},
+		{"Liveness_Check", http.MethodGet, "/api/v1/health/live", http.StatusOK},
+		{"Readiness_Check", http.MethodGet, "/api/v1/health/ready", http.StatusOK},
 		{"Create_Wallet_without_body", http.MethodPost, "/api/v1/wallets", http.StatusBadRequest},             // Assuming no body is sent, should return BadRequest
 		{"Get_non-existent_Wallet", http.MethodGet, "/api/v1/wallets/non-existent-user", http.StatusNotFound}, // Assuming no wallet with this user_id exists
 		{"Deposit_without_body", http.MethodPost, "/api/v1/wallets/deposit", http.StatusBadRequest},           // Assuming no body is sent, should return BadRequest
 		{"Withdraw_without_body", http.MethodPost, "/api/v1/wallets/withdraw", http.StatusBadRequest},         // Assuming no body is sent, should return BadRequest
 		{"Transfer_without_body", http.MethodPost, "/api/v1/wallets/transfer", http.StatusBadRequest},         // Assuming no body is sent, should return BadRequest
+		{"Split_transfer_without_body", http.MethodPost, "/api/v1/wallets/transfer/split", http.StatusBadRequest},
+		{"Pockets_of_non-existent_Wallet", http.MethodGet, "/api/v1/wallets/non-existent-user/pockets", http.StatusNotFound},
+		{"Move_pocket_funds_without_body", http.MethodPost, "/api/v1/wallets/non-existent-user/pockets/move", http.StatusBadRequest},
+		{"Members_of_non-existent_Wallet", http.MethodGet, "/api/v1/wallets/non-existent-user/members", http.StatusNotFound},
+		{"Approve_without_body", http.MethodPost, "/api/v1/wallets/non-existent-user/approvals/1/approve", http.StatusBadRequest},
+		{"Balance_of_non-existent_Wallet", http.MethodGet, "/api/v1/wallets/non-existent-user/balance", http.StatusNotFound},
+		{"Daily_balances_without_range", http.MethodGet, "/api/v1/wallets/non-existent-user/balance/daily", http.StatusBadRequest},
+		{"Statement_without_range", http.MethodGet, "/api/v1/wallets/non-existent-user/statement", http.StatusBadRequest},
+		{"Resolve_without_alias", http.MethodGet, "/api/v1/wallets/resolve", http.StatusBadRequest},
+		{"Aliases_of_non-existent_Wallet", http.MethodGet, "/api/v1/wallets/non-existent-user/aliases", http.StatusNotFound},
+		{"Payment_requests_of_non-existent_Wallet", http.MethodGet, "/api/v1/wallets/non-existent-user/payment-requests", http.StatusNotFound},
+		{"Accept_invalid_payment_request_ID", http.MethodPost, "/api/v1/wallets/non-existent-user/payment-requests/abc/accept", http.StatusBadRequest},
+		{"Escrow_not_found", http.MethodGet, "/api/v1/escrows/999999", http.StatusNotFound},
+		{"Create_escrow_without_body", http.MethodPost, "/api/v1/escrows", http.StatusBadRequest},
+		{"Merchant_not_found", http.MethodGet, "/api/v1/merchants/non-existent-user", http.StatusNotFound},
+		{"Create_merchant_without_body", http.MethodPost, "/api/v1/merchants", http.StatusBadRequest},
+		{"Payment_intent_not_found", http.MethodGet, "/api/v1/payment-intents/999999", http.StatusNotFound},
+		{"Refund_without_body", http.MethodPost, "/api/v1/payment-intents/1/refunds", http.StatusBadRequest},
+		{"Close_non_existent_wallet", http.MethodPost, "/api/v1/wallets/non-existent-user/close", http.StatusNotFound},
+		{"KYC_of_non_existent_wallet", http.MethodGet, "/api/v1/wallets/non-existent-user/kyc", http.StatusNotFound},
+		{"Submit_KYC_without_body", http.MethodPost, "/api/v1/wallets/non-existent-user/kyc", http.StatusBadRequest},
 	}
 
 	for _, tt := range tests {
@@ -48,6 +72,65 @@ func TestRegister(t *testing.T) {
 	}
 }
 
+func TestRegisterAdmin(t *testing.T) {
+	// Setup
+	e := echo.New()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	setupTestRoutes(e, dbInstance)
+
+	// Test cases
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		token        string
+		expectedCode int
+	}{
+		{"List_providers", http.MethodGet, "/api/v1/admin/providers", testOperatorToken, http.StatusOK},
+		{"Provider_not_found", http.MethodGet, "/api/v1/admin/providers/non-existent-provider", testOperatorToken, http.StatusNotFound},
+		{"Register_provider_without_body", http.MethodPost, "/api/v1/admin/providers", testOperatorToken, http.StatusBadRequest},
+		{"Top_up_without_body", http.MethodPost, "/api/v1/admin/providers/non-existent-provider/top-up", testOperatorToken, http.StatusBadRequest},
+		{"Reopen_non_existent_wallet", http.MethodPost, "/api/v1/admin/wallets/non-existent-user/reopen", testOperatorToken, http.StatusNotFound},
+		{"Approve_KYC_without_body", http.MethodPost, "/api/v1/admin/kyc/1/approve", testOperatorToken, http.StatusBadRequest},
+		{"Non_existent_adjustment", http.MethodGet, "/api/v1/admin/adjustments/999999", testOperatorToken, http.StatusNotFound},
+		{"Propose_adjustment_without_body", http.MethodPost, "/api/v1/admin/adjustments", testOperatorToken, http.StatusBadRequest},
+		{"Approve_adjustment_without_body", http.MethodPost, "/api/v1/admin/adjustments/1/approve", testOperatorToken, http.StatusBadRequest},
+		{"Settlement_batch_not_found", http.MethodGet, "/api/v1/admin/settlements/999999", testOperatorToken, http.StatusNotFound},
+		{"Run_settlement_without_body", http.MethodPost, "/api/v1/admin/settlements/run", testOperatorToken, http.StatusBadRequest},
+		{"Export_settlement_without_date", http.MethodGet, "/api/v1/admin/settlements/export", testOperatorToken, http.StatusBadRequest},
+		{"Export_settlement_without_token", http.MethodGet, "/api/v1/admin/settlements/export?date=2024-05-31", "", http.StatusUnauthorized},
+		{"Without_token", http.MethodGet, "/api/v1/admin/providers", "", http.StatusUnauthorized},
+		{"Unknown_token", http.MethodGet, "/api/v1/admin/providers", "not-an-operator-token", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestInitRoutes_WithoutAdmin(t *testing.T) {
+	// The public API, routed through the gateway, must not serve the admin endpoints
+	e := echo.New()
+	InitRoutes(e.Group("/api/v1"), NewWalletController(nil))
+	for _, route := range e.Routes() {
+		assert.NotContains(t, route.Path, "/admin", route.Method)
+	}
+}
+
+// testOperatorToken is the token of the operator of the admin routes in tests
+const testOperatorToken = "test-operator-token"
+
 // setupTestRoutes configures routes for testing with the same pattern as the server
 func setupTestRoutes(e *echo.Echo, db *gorm.DB) {
 	// Set up request validation
@@ -57,8 +140,10 @@ func setupTestRoutes(e *echo.Echo, db *gorm.DB) {
 	api := e.Group("/api/v1")
 
 	// Register health check endpoint
-	healthHandler := NewHealth()
+	healthHandler := NewHealth(service.NewHealthService(0))
 	api.GET("/health", healthHandler.Health)
+	api.GET("/health/live", healthHandler.Live)
+	api.GET("/health/ready", healthHandler.Ready)
 
 	// Initialize wallet handler with dependencies
 	walletRepo := repository.NewWalletRepo(db)
@@ -67,4 +152,8 @@ func setupTestRoutes(e *echo.Echo, db *gorm.DB) {
 
 	// Register wallet routes
 	InitRoutes(api, walletHandler)
+
+	// Register admin routes as the admin server does
+	admin := e.Group("/api/v1/admin", AdminAuth([]model.Operator{{Name: "test-operator", Token: testOperatorToken}}))
+	InitAdminRoutes(admin, walletHandler)
 }
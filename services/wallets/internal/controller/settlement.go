@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/settlement"
+	"github.com/labstack/echo/v4"
+)
+
+// RunSettlementRequest is the request parameter for settling the provider wallets for a day
+type RunSettlementRequest struct {
+	Date string `json:"date" validate:"required"` // YYYY-MM-DD
+}
+
+// ListSettlementBatchesRequest is the request parameter for listing settlement batches
+type ListSettlementBatchesRequest struct {
+	Date       string `query:"date"` // YYYY-MM-DD
+	ProviderID string `query:"provider_id"`
+}
+
+// SettlementBatchRequest is the request parameter for an existing settlement batch
+type SettlementBatchRequest struct {
+	ID int `param:"id" validate:"required,gt=0"`
+}
+
+// ExportSettlementRequest is the request parameter for the settlement file of a day
+type ExportSettlementRequest struct {
+	Date   string                 `query:"date" validate:"required"` // YYYY-MM-DD
+	Format model.SettlementFormat `query:"format" validate:"omitempty,oneof=csv nacha"`
+}
+
+// @Summary	Settle the provider wallets for a day
+// @Tags		settlements
+// @Accept		json
+// @Produce	json
+// @Param		request	body		RunSettlementRequest	true	"Settlement day"
+// @Success	200		{object}	ResponseData{data=[]model.SettlementBatch}
+// @Failure	400		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/settlements/run [post]
+func (t *walletHandler) RunSettlement(c echo.Context) error {
+	var req RunSettlementRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	date, err := time.Parse(model.DateLayout, req.Date)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "date must be a YYYY-MM-DD date"}}})
+	}
+
+	batches, err := t.service.RunSettlement(c.Request().Context(), date)
+	if err != nil {
+		return settlementError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: batches})
+}
+
+// @Summary	List settlement batches
+// @Tags		settlements
+// @Produce	json
+// @Param		date		query		string	false	"Settlement day (YYYY-MM-DD)"
+// @Param		provider_id	query		string	false	"Provider wallet user ID"
+// @Success	200			{object}	ResponseData{data=[]model.SettlementBatch}
+// @Failure	400			{object}	ResponseError
+// @Failure	500			{object}	ResponseError
+// @Router		/admin/settlements [get]
+func (t *walletHandler) ListSettlementBatches(c echo.Context) error {
+	var req ListSettlementBatchesRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	if req.Date != "" {
+		if _, err := time.Parse(model.DateLayout, req.Date); err != nil {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "date must be a YYYY-MM-DD date"}}})
+		}
+	}
+
+	batches, err := t.service.ListSettlementBatches(c.Request().Context(), req.Date, req.ProviderID)
+	if err != nil {
+		return settlementError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: batches})
+}
+
+// @Summary	View a settlement batch
+// @Tags		settlements
+// @Produce	json
+// @Param		id	path		int	true	"Settlement batch ID"
+// @Success	200	{object}	ResponseData{data=model.SettlementBatch}
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/admin/settlements/{id} [get]
+func (t *walletHandler) GetSettlementBatch(c echo.Context) error {
+	var req SettlementBatchRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	batch, err := t.service.GetSettlementBatch(c.Request().Context(), req.ID)
+	if err != nil {
+		return settlementError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: batch})
+}
+
+// @Summary	Download the settlement file of a day
+// @Tags		settlements
+// @Produce	text/csv,text/plain
+// @Param		date	query		string	true	"Settlement day (YYYY-MM-DD)"
+// @Param		format	query		string	false	"csv (default) or nacha"
+// @Success	200		{file}		file
+// @Failure	400		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/admin/settlements/export [get]
+func (t *walletHandler) ExportSettlement(c echo.Context) error {
+	var req ExportSettlementRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	date, err := time.Parse(model.DateLayout, req.Date)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "date must be a YYYY-MM-DD date"}}})
+	}
+	if req.Format == "" {
+		req.Format = model.SettlementCSV
+	}
+
+	// A day has one batch per provider, so the file is small enough to be
+	// rendered in full before anything is sent
+	var buf bytes.Buffer
+	writer, err := settlement.NewWriter(req.Format, &buf, config.GetSettlement())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	if err := t.service.WriteSettlement(c.Request().Context(), date, writer); err != nil {
+		return settlementError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="%s"`, settlement.FileName(date, req.Format)))
+	return c.Blob(http.StatusOK, settlement.ContentType(req.Format), buf.Bytes())
+}
+
+// settlementError writes the error response of the settlement endpoints.
+func settlementError(c echo.Context, err error) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Settlement batch not found"}}})
+	case model.ErrInvalidTimeRange:
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Only days that have ended can be settled"}}})
+	case model.ErrNoSettlementAccount:
+		return c.JSON(http.StatusUnprocessableEntity,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "A provider in the settlement has no bank account configured"}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
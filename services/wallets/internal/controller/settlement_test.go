@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// settlementTxnClient serves fixed unsettled entries and records the settle calls.
+type settlementTxnClient struct {
+	client.MockTransactionClient
+	unsettled []model.Transaction
+	settled   map[string][]int
+}
+
+func (s *settlementTxnClient) FetchUnsettledTransactions(_ context.Context, providerIDs []string, before time.Time) ([]model.Transaction, error) {
+	return s.unsettled, nil
+}
+
+func (s *settlementTxnClient) SettleTransactions(_ context.Context, settlementID string, transactionIDs []int) error {
+	s.settled[settlementID] = transactionIDs
+	return nil
+}
+
+func TestWalletHandler_Settlement(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	txnClient := &settlementTxnClient{
+		unsettled: []model.Transaction{
+			{ID: 11, SubjectWalletID: model.DepositProviderID, TransactionType: model.Deposit, Amount: 5000},
+			{ID: 12, SubjectWalletID: model.DepositProviderID, TransactionType: model.Withdraw, Amount: 1500},
+		},
+		settled: make(map[string][]int),
+	}
+	client.ResetClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return txnClient
+	})
+	defer func() {
+		patches.Reset()
+		client.ResetClient()
+		clearDB(dbInstance, model.SettlementEntry{}, model.SettlementBatch{})
+	}()
+
+	clearDB(dbInstance, model.SettlementEntry{}, model.SettlementBatch{}, model.Wallet{})
+	createTestWallet(t, dbInstance, model.DepositProviderID, model.Provider)
+	createTestWallet(t, dbInstance, "test-user-001", model.User)
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(model.DateLayout)
+	run := func(date string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/settlements/run", bytes.NewReader([]byte(`{"date":"`+date+`"}`)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler.RunSettlement(e.NewContext(req, rec)))
+		return rec
+	}
+
+	t.Run("day_not_ended", func(t *testing.T) {
+		rec := run(time.Now().UTC().Format(model.DateLayout))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	var batch model.SettlementBatch
+	t.Run("settles_providers", func(t *testing.T) {
+		rec := run(yesterday)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var got struct {
+			Data []model.SettlementBatch `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Len(t, got.Data, 1)
+		batch = got.Data[0]
+		assert.Equal(t, model.DepositProviderID, batch.ProviderID)
+		assert.Equal(t, model.SettlementSettled, batch.Status)
+		assert.Equal(t, int64(3500), batch.NetAmount)
+		assert.Equal(t, []int{11, 12}, batch.TransactionIDs)
+		assert.Equal(t, map[string][]int{batch.Reference(): {11, 12}}, txnClient.settled)
+	})
+
+	t.Run("rerun_is_a_no_op", func(t *testing.T) {
+		txnClient.settled = make(map[string][]int)
+		rec := run(yesterday)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, txnClient.settled)
+	})
+
+	t.Run("get_batch", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/settlements/"+strconv.Itoa(batch.ID), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/settlements/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(strconv.Itoa(batch.ID))
+		require.NoError(t, handler.GetSettlementBatch(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("export_csv", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/settlements/export?date="+yesterday, nil)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler.ExportSettlement(e.NewContext(req, rec)))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "settlement_"+yesterday+".csv")
+		assert.Contains(t, rec.Body.String(), batch.Reference()+","+model.DepositProviderID+",settled")
+	})
+
+	t.Run("export_nacha_without_account", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/settlements/export?date="+yesterday+"&format=nacha", nil)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler.ExportSettlement(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+}
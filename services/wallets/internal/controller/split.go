@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// SplitTransferRequest represents the request for paying several wallets at once.
+// Splitting by amount pays every recipient its amount; splitting by percentage
+// divides amount between the recipients.
+type SplitTransferRequest struct {
+	FromUserID string                  `json:"from_user_id" validate:"required"`
+	Mode       model.SplitMode         `json:"mode" validate:"required,oneof=amount percentage"`
+	Amount     int                     `json:"amount,omitempty" validate:"required_if=Mode percentage,gte=0"` // Total; optional when splitting by amount
+	Recipients []SplitRecipientRequest `json:"recipients" validate:"required,min=2,max=50,dive"`
+}
+
+// SplitRecipientRequest is one recipient of a split transfer
+type SplitRecipientRequest struct {
+	UserID     string  `json:"user_id" validate:"required"`
+	Amount     int     `json:"amount,omitempty" validate:"gte=0"`             // Cents, when splitting by amount
+	Percentage float64 `json:"percentage,omitempty" validate:"gte=0,lte=100"` // At most two decimals, when splitting by percentage
+}
+
+// @Summary	Pay several wallets in one transfer
+// @Description	Every recipient is credited its share in the same database transaction. When splitting by percentage, cents left over after rounding down go to the recipients with the largest remainders. All resulting transactions share a group ID.
+// @Tags		wallets
+// @Accept		json
+// @Produce	json
+// @Param		request	body		SplitTransferRequest	true	"Split transfer request"
+// @Success	201		{object}	ResponseData{data=model.SplitTransfer}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/transfer/split [post]
+func (t *walletHandler) TransferSplit(c echo.Context) error {
+	var req SplitTransferRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	recipients := make([]model.SplitRecipient, len(req.Recipients))
+	for i, recipient := range req.Recipients {
+		recipients[i] = model.SplitRecipient{
+			UserID:     recipient.UserID,
+			Amount:     int64(recipient.Amount),
+			Percentage: recipient.Percentage,
+		}
+	}
+
+	split, err := t.service.TransferSplit(c.Request().Context(), req.FromUserID, req.Mode, req.Amount, recipients)
+	if err != nil {
+		switch err {
+		case model.ErrInvalidSplit:
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Recipients must be distinct, and amounts must add up to the total or percentages to 100 with at most two decimals"}}})
+		case model.ErrInvalidAmount:
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Every recipient must receive at least one cent"}}})
+		case model.ErrSameWallet:
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Cannot transfer to the same wallet"}}})
+		case model.ErrEscrowWallet:
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Escrow wallets can only be used through the escrow endpoints"}}})
+		case model.ErrPocketWallet:
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Pockets can only be used through the pocket endpoints"}}})
+		case model.ErrNotFound:
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		case model.ErrInsufficientFunds:
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
+		case model.ErrApprovalRequired:
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Amount needs approval by the sender's owners; transfer to each recipient to request it"}}})
+		case model.ErrWalletClosed:
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is closed"}}})
+		case model.ErrWalletSuspended:
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is suspended"}}})
+		case model.ErrBalanceLimitExceeded:
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBalanceLimitExceeded, Message: "Balance would exceed the wallet's limit"}}})
+		case model.ErrKYCUpgradeRequired:
+			return c.JSON(http.StatusForbidden,
+				ResponseError{Errors: []Error{{Code: errors.CodeKYCUpgradeRequired, Message: "KYC level does not allow this operation; verify the wallet to a higher level"}}})
+		case model.ErrConcurrentUpdate:
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: split})
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_TransferSplit(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletRepository := repository.NewWalletRepo(dbInstance)
+	handler := NewWalletController(service.NewWalletService(walletRepository))
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		balances   map[string]int64
+	}{
+		{
+			name:       "by_amount",
+			body:       `{"from_user_id":"test-user-001", "mode":"amount", "recipients":[{"user_id":"test-user-002", "amount":1500}, {"user_id":"test-user-003", "amount":500}]}`,
+			statusCode: http.StatusCreated,
+			balances:   map[string]int64{"test-user-001": 8000, "test-user-002": 1500, "test-user-003": 500},
+		},
+		{
+			name:       "by_percentage_with_remainder",
+			body:       `{"from_user_id":"test-user-001", "mode":"percentage", "amount":1001, "recipients":[{"user_id":"test-user-002", "percentage":66.67}, {"user_id":"test-user-003", "percentage":33.33}]}`,
+			statusCode: http.StatusCreated,
+			balances:   map[string]int64{"test-user-001": 8999, "test-user-002": 667, "test-user-003": 334},
+		},
+		{
+			name:       "percentages_not_adding_up",
+			body:       `{"from_user_id":"test-user-001", "mode":"percentage", "amount":1000, "recipients":[{"user_id":"test-user-002", "percentage":50}, {"user_id":"test-user-003", "percentage":40}]}`,
+			statusCode: http.StatusBadRequest,
+			balances:   map[string]int64{"test-user-001": 10000, "test-user-002": 0, "test-user-003": 0},
+		},
+		{
+			name:       "percentage_without_amount",
+			body:       `{"from_user_id":"test-user-001", "mode":"percentage", "recipients":[{"user_id":"test-user-002", "percentage":50}, {"user_id":"test-user-003", "percentage":50}]}`,
+			statusCode: http.StatusBadRequest,
+			balances:   map[string]int64{"test-user-001": 10000, "test-user-002": 0, "test-user-003": 0},
+		},
+		{
+			name:       "single_recipient",
+			body:       `{"from_user_id":"test-user-001", "mode":"amount", "recipients":[{"user_id":"test-user-002", "amount":1500}]}`,
+			statusCode: http.StatusBadRequest,
+			balances:   map[string]int64{"test-user-001": 10000, "test-user-002": 0, "test-user-003": 0},
+		},
+		{
+			name:       "sender_among_recipients",
+			body:       `{"from_user_id":"test-user-001", "mode":"amount", "recipients":[{"user_id":"test-user-001", "amount":1500}, {"user_id":"test-user-003", "amount":500}]}`,
+			statusCode: http.StatusBadRequest,
+			balances:   map[string]int64{"test-user-001": 10000, "test-user-002": 0, "test-user-003": 0},
+		},
+		{
+			name:       "recipient_not_found",
+			body:       `{"from_user_id":"test-user-001", "mode":"amount", "recipients":[{"user_id":"test-user-002", "amount":1500}, {"user_id":"non-existent-user", "amount":500}]}`,
+			statusCode: http.StatusNotFound,
+			balances:   map[string]int64{"test-user-001": 10000, "test-user-002": 0, "test-user-003": 0},
+		},
+		{
+			name:       "insufficient_funds_moves_nothing",
+			body:       `{"from_user_id":"test-user-001", "mode":"amount", "recipients":[{"user_id":"test-user-002", "amount":6000}, {"user_id":"test-user-003", "amount":6000}]}`,
+			statusCode: http.StatusUnprocessableEntity,
+			balances:   map[string]int64{"test-user-001": 10000, "test-user-002": 0, "test-user-003": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearDB(dbInstance, model.Wallet{})
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			createTestWallet(t, dbInstance, "test-user-003", model.User)
+
+			req := httptest.NewRequest(http.MethodPost, "/wallets/transfer/split", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/transfer/split")
+
+			require.NoError(t, handler.TransferSplit(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			for userID, want := range tt.balances {
+				wallet, err := walletRepository.FindByUserID(context.Background(), userID)
+				require.NoError(t, err)
+				assert.Equal(t, want, wallet.Balance, userID)
+			}
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+
+			var got struct {
+				Data model.SplitTransfer `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.NotEmpty(t, got.Data.GroupID)
+			assert.Equal(t, 10000-tt.balances["test-user-001"], got.Data.Amount)
+			require.Len(t, got.Data.Transactions, 2)
+			for _, txn := range got.Data.Transactions {
+				assert.Equal(t, got.Data.GroupID, txn.GroupID)
+				assert.Equal(t, model.Debit, txn.OperationType)
+				assert.Equal(t, tt.balances[txn.ObjectWalletID], txn.Amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/statement"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// statementBufferSize is how much of a statement is buffered before it is
+// flushed to the client.
+const statementBufferSize = 32 << 10
+
+// StatementRequest is the request parameter for an account statement
+type StatementRequest struct {
+	UserID string                `param:"user_id" validate:"required"`
+	From   string                `query:"from" validate:"required"` // YYYY-MM-DD
+	To     string                `query:"to" validate:"required"`   // YYYY-MM-DD
+	Format model.StatementFormat `query:"format" validate:"omitempty,oneof=csv json ofx camt053"`
+}
+
+// @Summary	Download an account statement
+// @Tags		wallets
+// @Produce	text/csv,application/json,application/x-ofx,application/xml
+// @Param		user_id	path		string	true	"User ID"
+// @Param		from	query		string	true	"First day (YYYY-MM-DD, UTC)"
+// @Param		to		query		string	true	"Last day (YYYY-MM-DD, UTC)"
+// @Param		format	query		string	false	"csv (default), json, ofx or camt053"
+// @Success	200		{file}		file
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/wallets/{user_id}/statement [get]
+func (t *walletHandler) Statement(c echo.Context) error {
+	var req StatementRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	from, err := time.Parse(model.DateLayout, req.From)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "from must be a YYYY-MM-DD date"}}})
+	}
+	to, err := time.Parse(model.DateLayout, req.To)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "to must be a YYYY-MM-DD date"}}})
+	}
+	if req.Format == "" {
+		req.Format = model.StatementCSV
+	}
+
+	// Output is buffered and flushed in chunks, so headers are only sent once
+	// the statement has started rendering; failures before that still get a
+	// regular error response.
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, statement.ContentType(req.Format))
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`,
+		statement.FileName(req.UserID, req.From+"_"+req.To, req.Format)))
+	buf := bufio.NewWriterSize(flushWriter{res}, statementBufferSize)
+	writer, err := statement.NewWriter(req.Format, buf)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	err = t.service.WriteStatement(c.Request().Context(), req.UserID, from, to, writer)
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		if res.Committed {
+			// Part of the statement has been sent; all we can do is cut it short
+			utils.LogError("Failed to stream statement", err)
+			return nil
+		}
+		res.Header().Del(echo.HeaderContentType)
+		res.Header().Del(echo.HeaderContentDisposition)
+		return balanceHistoryError(c, err)
+	}
+	return nil
+}
+
+// flushWriter writes to the response and flushes it to the client straight away.
+type flushWriter struct {
+	res *echo.Response
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.res.Write(p)
+	f.res.Flush()
+	return n, err
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_Statement(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+
+	client.ResetClient()
+	txnPatches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	defer func() {
+		txnPatches.Reset()
+		client.ResetClient()
+	}()
+
+	clearDB(dbInstance, model.BalanceSnapshot{}, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+
+	today := time.Now().UTC().Format(model.DateLayout)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(model.DateLayout)
+	tests := []struct {
+		name        string
+		userID      string
+		query       url.Values
+		statusCode  int
+		contentType string
+	}{
+		{
+			name:        "csv_by_default",
+			userID:      "test-user-001",
+			query:       url.Values{"from": {yesterday}, "to": {today}},
+			statusCode:  http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:        "json",
+			userID:      "test-user-001",
+			query:       url.Values{"from": {yesterday}, "to": {today}, "format": {"json"}},
+			statusCode:  http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+		},
+		{
+			name:        "ofx",
+			userID:      "test-user-001",
+			query:       url.Values{"from": {yesterday}, "to": {today}, "format": {"ofx"}},
+			statusCode:  http.StatusOK,
+			contentType: "application/x-ofx",
+		},
+		{
+			name:        "camt053",
+			userID:      "test-user-001",
+			query:       url.Values{"from": {yesterday}, "to": {today}, "format": {"camt053"}},
+			statusCode:  http.StatusOK,
+			contentType: "application/xml; charset=utf-8",
+		},
+		{
+			name:       "unsupported_format",
+			userID:     "test-user-001",
+			query:      url.Values{"from": {yesterday}, "to": {today}, "format": {"pdf"}},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "reversed_range",
+			userID:     "test-user-001",
+			query:      url.Values{"from": {today}, "to": {yesterday}},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing_range",
+			userID:     "test-user-001",
+			query:      url.Values{},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "wallet_not_found",
+			userID:     "non-existent-user",
+			query:      url.Values{"from": {yesterday}, "to": {today}},
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/wallets/"+tt.userID+"/statement?"+tt.query.Encode(), nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/statement")
+			c.SetParamNames("user_id")
+			c.SetParamValues(tt.userID)
+
+			require.NoError(t, handler.Statement(c))
+
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusOK {
+				assert.Empty(t, rec.Header().Get(echo.HeaderContentDisposition))
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+				return
+			}
+			assert.Equal(t, tt.contentType, rec.Header().Get(echo.HeaderContentType))
+			assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "test-user-001_"+yesterday+"_"+today)
+		})
+	}
+
+	t.Run("json_balances", func(t *testing.T) {
+		// The mock ledger is older than the period, so it all goes into the opening balance
+		query := url.Values{"from": {yesterday}, "to": {today}, "format": {"json"}}
+		req := httptest.NewRequest(http.MethodGet, "/wallets/test-user-001/statement?"+query.Encode(), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("user_id")
+		c.SetParamValues("test-user-001")
+		require.NoError(t, handler.Statement(c))
+
+		var got struct {
+			OpeningBalance int64                 `json:"opening_balance"`
+			Transactions   []model.StatementLine `json:"transactions"`
+			ClosingBalance int64                 `json:"closing_balance"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, int64(3000), got.OpeningBalance)
+		assert.Empty(t, got.Transactions)
+		assert.Equal(t, int64(3000), got.ClosingBalance)
+	})
+}
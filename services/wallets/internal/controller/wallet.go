@@ -15,7 +15,69 @@ type WalletHandler interface {
 	Deposit(c echo.Context) error
 	Withdraw(c echo.Context) error
 	Transfer(c echo.Context) error
+	TransferSplit(c echo.Context) error
 	FetchTransactions(c echo.Context) error
+	BalanceAt(c echo.Context) error
+	DailyBalances(c echo.Context) error
+	Statement(c echo.Context) error
+	RegisterAlias(c echo.Context) error
+	ListAliases(c echo.Context) error
+	ChallengeAlias(c echo.Context) error
+	VerifyAlias(c echo.Context) error
+	ResolveAlias(c echo.Context) error
+	CreatePaymentRequest(c echo.Context) error
+	ListPaymentRequests(c echo.Context) error
+	AcceptPaymentRequest(c echo.Context) error
+	DeclinePaymentRequest(c echo.Context) error
+	CancelPaymentRequest(c echo.Context) error
+	PaymentRequestEvents(c echo.Context) error
+	CreateEscrow(c echo.Context) error
+	GetEscrow(c echo.Context) error
+	ReleaseEscrow(c echo.Context) error
+	RefundEscrow(c echo.Context) error
+	SplitEscrow(c echo.Context) error
+	CreatePocket(c echo.Context) error
+	ListPockets(c echo.Context) error
+	GetPocket(c echo.Context) error
+	MovePocketFunds(c echo.Context) error
+	AddMember(c echo.Context) error
+	ListMembers(c echo.Context) error
+	RemoveMember(c echo.Context) error
+	SetApprovalPolicy(c echo.Context) error
+	ListApprovals(c echo.Context) error
+	ApproveOperation(c echo.Context) error
+	RejectOperation(c echo.Context) error
+	CreateMerchant(c echo.Context) error
+	GetMerchant(c echo.Context) error
+	CreatePaymentIntent(c echo.Context) error
+	ListPaymentIntents(c echo.Context) error
+	SettlementReport(c echo.Context) error
+	GetPaymentIntent(c echo.Context) error
+	ConfirmPaymentIntent(c echo.Context) error
+	CancelPaymentIntent(c echo.Context) error
+	RefundPaymentIntent(c echo.Context) error
+	RunSettlement(c echo.Context) error
+	ListSettlementBatches(c echo.Context) error
+	GetSettlementBatch(c echo.Context) error
+	ExportSettlement(c echo.Context) error
+	RegisterProvider(c echo.Context) error
+	ListProviders(c echo.Context) error
+	GetProvider(c echo.Context) error
+	DisableProvider(c echo.Context) error
+	EnableProvider(c echo.Context) error
+	TopUpProvider(c echo.Context) error
+	CloseWallet(c echo.Context) error
+	ReopenWallet(c echo.Context) error
+	SubmitKYCVerification(c echo.Context) error
+	GetKYCProfile(c echo.Context) error
+	ListKYCVerifications(c echo.Context) error
+	ApproveKYCVerification(c echo.Context) error
+	RejectKYCVerification(c echo.Context) error
+	ProposeAdjustment(c echo.Context) error
+	ListAdjustments(c echo.Context) error
+	GetAdjustment(c echo.Context) error
+	ApproveAdjustment(c echo.Context) error
+	RejectAdjustment(c echo.Context) error
 }
 
 type walletHandler struct {
@@ -41,25 +103,56 @@ type DepositRequest struct {
 	ProviderID *string `json:"provider_id,omitempty"`
 }
 
-// WithdrawRequest represents the request for withdraw operation
+// WithdrawRequest represents the request for withdraw operation.
+// ActingUserID is the member of a shared wallet making the withdrawal,
+// empty for the holder.
 type WithdrawRequest struct {
-	UserID     string  `json:"user_id" validate:"required"`
-	Amount     int     `json:"amount" validate:"required,gt=0"`
-	ProviderID *string `json:"provider_id,omitempty"`
+	UserID       string  `json:"user_id" validate:"required"`
+	Amount       int     `json:"amount" validate:"required,gt=0"`
+	ProviderID   *string `json:"provider_id,omitempty"`
+	ActingUserID string  `json:"acting_user_id,omitempty"`
 }
 
-// TransferRequest represents the request for transfer operation
+// TransferRequest represents the request for transfer operation.
+// The receiver is given either by user ID or by a verified alias.
+// ActingUserID is the member of a shared wallet making the transfer, empty
+// for the holder; members can only transfer by user ID.
 type TransferRequest struct {
-	FromUserID string `json:"from_user_id" validate:"required"`
-	ToUserID   string `json:"to_user_id" validate:"required"`
-	Amount     int    `json:"amount" validate:"required,gt=0"`
+	FromUserID   string `json:"from_user_id" validate:"required"`
+	ToUserID     string `json:"to_user_id,omitempty" validate:"required_without=ToAlias,excluded_with=ToAlias"`
+	ToAlias      string `json:"to_alias,omitempty" validate:"required_without=ToUserID"`
+	Amount       int    `json:"amount" validate:"required,gt=0"`
+	ActingUserID string `json:"acting_user_id,omitempty" validate:"excluded_with=ToAlias"`
 }
 
-// WalletSummary represents essential wallet information for API responses
+// WalletSummary represents essential wallet information for API responses.
+// Balance is the wallet's own balance; TotalBalance adds that of its pockets.
 type WalletSummary struct {
-	Balance  int64          `json:"balance"`
-	AcntType model.AcntType `json:"acnt_type"`
-	Status   model.Status   `json:"status"`
+	Balance      int64           `json:"balance"`
+	TotalBalance int64           `json:"total_balance"`
+	AcntType     model.AcntType  `json:"acnt_type"`
+	Status       model.Status    `json:"status"`
+	Pockets      []PocketSummary `json:"pockets,omitempty"`
+}
+
+// PocketSummary represents a pocket in the summary of its owner's wallet
+type PocketSummary struct {
+	Name    string `json:"name"`
+	Balance int64  `json:"balance"`
+}
+
+// newWalletSummary returns the summary of a wallet and its loaded pockets.
+func newWalletSummary(wallet *model.Wallet) WalletSummary {
+	summary := WalletSummary{
+		Balance:      wallet.Balance,
+		TotalBalance: wallet.TotalBalance(),
+		AcntType:     wallet.AcntType,
+		Status:       wallet.Status,
+	}
+	for _, pocket := range wallet.Pockets {
+		summary.Pockets = append(summary.Pockets, PocketSummary{Name: pocket.PocketName, Balance: pocket.Balance})
+	}
+	return summary
 }
 
 // WalletResponse represents wallet with transaction history
@@ -85,7 +178,7 @@ func (t *walletHandler) Create(c echo.Context) error {
 	}
 
 	wallet := model.NewWallet(req.UserID, req.AcntType)
-	if err := t.service.Create(wallet); err != nil {
+	if err := t.service.Create(c.Request().Context(), wallet); err != nil {
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
@@ -100,7 +193,10 @@ func (t *walletHandler) Create(c echo.Context) error {
 // @Param		request	body		DepositRequest	true	"Deposit request"
 // @Success	201		{object}	ResponseData{data=model.Transaction}
 // @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
 // @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
 // @Router		/wallets/deposit [post]
 func (t *walletHandler) Deposit(c echo.Context) error {
@@ -110,12 +206,48 @@ func (t *walletHandler) Deposit(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	transaction, err := t.service.Deposit(req.UserID, req.Amount, req.ProviderID)
+	transaction, err := t.service.Deposit(c.Request().Context(), req.UserID, req.Amount, req.ProviderID)
 	if err != nil {
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
 				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
 		}
+		if err == model.ErrEscrowWallet {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Escrow wallets can only be used through the escrow endpoints"}}})
+		}
+		if err == model.ErrPocketWallet {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Pockets can only be used through the pocket endpoints"}}})
+		}
+		if err == model.ErrUnknownProvider {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Provider ID is not a provider wallet"}}})
+		}
+		if err == model.ErrProviderDisabled {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Provider is disabled"}}})
+		}
+		if err == model.ErrWalletClosed {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is closed"}}})
+		}
+		if err == model.ErrWalletSuspended {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is suspended"}}})
+		}
+		if err == model.ErrBalanceLimitExceeded {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBalanceLimitExceeded, Message: "Balance would exceed the wallet's limit"}}})
+		}
+		if err == model.ErrKYCUpgradeRequired {
+			return c.JSON(http.StatusForbidden,
+				ResponseError{Errors: []Error{{Code: errors.CodeKYCUpgradeRequired, Message: "KYC level does not allow this operation; verify the wallet to a higher level"}}})
+		}
+		if err == model.ErrConcurrentUpdate {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
@@ -127,11 +259,15 @@ func (t *walletHandler) Deposit(c echo.Context) error {
 // @Tags		wallets
 // @Accept		json
 // @Produce	json
+// @Description	Withdrawals above a shared wallet's approval threshold are held until enough owners approve them; the held withdrawal is returned with 202.
 // @Param		request	body		WithdrawRequest	true	"Withdraw request"
 // @Success	201		{object}	ResponseData{data=model.Transaction}
+// @Success	202		{object}	ResponseData{data=model.WalletApproval}
 // @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
 // @Failure	404		{object}	ResponseError
 // @Failure	422		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
 // @Router		/wallets/withdraw [post]
 func (t *walletHandler) Withdraw(c echo.Context) error {
@@ -141,19 +277,57 @@ func (t *walletHandler) Withdraw(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	transaction, err := t.service.Withdraw(req.UserID, req.Amount, req.ProviderID)
+	transaction, approval, err := t.service.InitiateWithdraw(c.Request().Context(), req.ActingUserID, req.UserID, req.Amount, req.ProviderID)
 	if err != nil {
+		if isMemberDebitError(err) {
+			return memberDebitError(c, err)
+		}
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
 				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
 		}
+		if err == model.ErrEscrowWallet {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Escrow wallets can only be used through the escrow endpoints"}}})
+		}
+		if err == model.ErrPocketWallet {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Pockets can only be used through the pocket endpoints"}}})
+		}
+		if err == model.ErrUnknownProvider {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Provider ID is not a provider wallet"}}})
+		}
+		if err == model.ErrProviderDisabled {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Provider is disabled"}}})
+		}
 		if err == model.ErrInsufficientFunds {
 			return c.JSON(http.StatusUnprocessableEntity,
 				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
 		}
+		if err == model.ErrWalletClosed {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is closed"}}})
+		}
+		if err == model.ErrWalletSuspended {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is suspended"}}})
+		}
+		if err == model.ErrKYCUpgradeRequired {
+			return c.JSON(http.StatusForbidden,
+				ResponseError{Errors: []Error{{Code: errors.CodeKYCUpgradeRequired, Message: "KYC level does not allow this operation; verify the wallet to a higher level"}}})
+		}
+		if err == model.ErrConcurrentUpdate {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
+	if approval != nil {
+		return c.JSON(http.StatusAccepted, ResponseData{Data: approval})
+	}
 
 	return c.JSON(http.StatusCreated, ResponseData{Data: transaction})
 }
@@ -162,11 +336,15 @@ func (t *walletHandler) Withdraw(c echo.Context) error {
 // @Tags		wallets
 // @Accept		json
 // @Produce	json
+// @Description	Transfers above a shared wallet's approval threshold are held until enough owners approve them; the held transfer is returned with 202.
 // @Param		request	body		TransferRequest	true	"Transfer request"
 // @Success	201		{object}	ResponseData{data=model.Transaction}
+// @Success	202		{object}	ResponseData{data=model.WalletApproval}
 // @Failure	400		{object}	ResponseError
+// @Failure	403		{object}	ResponseError
 // @Failure	404		{object}	ResponseError
 // @Failure	422		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
 // @Router		/wallets/transfer [post]
 func (t *walletHandler) Transfer(c echo.Context) error {
@@ -182,19 +360,72 @@ func (t *walletHandler) Transfer(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Cannot transfer to the same wallet"}}})
 	}
 
-	transaction, err := t.service.Transfer(req.FromUserID, req.ToUserID, req.Amount)
+	var transaction *model.Transaction
+	var approval *model.WalletApproval
+	var err error
+	if req.ToAlias != "" {
+		transaction, err = t.service.TransferToAlias(c.Request().Context(), req.FromUserID, req.ToAlias, req.Amount)
+	} else {
+		transaction, approval, err = t.service.InitiateTransfer(c.Request().Context(), req.ActingUserID, req.FromUserID, req.ToUserID, req.Amount)
+	}
 	if err != nil {
+		if isMemberDebitError(err) {
+			return memberDebitError(c, err)
+		}
+		if err == model.ErrApprovalRequired {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Amount needs approval by the wallet's owners; transfer by user ID to request it"}}})
+		}
+		if err == model.ErrSameWallet {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Cannot transfer to the same wallet"}}})
+		}
+		if err == model.ErrInvalidAlias {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Alias must be an E.164 phone number, an email address or an @handle"}}})
+		}
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
 				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
 		}
+		if err == model.ErrEscrowWallet {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Escrow wallets can only be used through the escrow endpoints"}}})
+		}
+		if err == model.ErrPocketWallet {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Pockets can only be used through the pocket endpoints"}}})
+		}
 		if err == model.ErrInsufficientFunds {
 			return c.JSON(http.StatusUnprocessableEntity,
 				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
 		}
+		if err == model.ErrWalletClosed {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is closed"}}})
+		}
+		if err == model.ErrWalletSuspended {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Wallet is suspended"}}})
+		}
+		if err == model.ErrBalanceLimitExceeded {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBalanceLimitExceeded, Message: "Balance would exceed the wallet's limit"}}})
+		}
+		if err == model.ErrKYCUpgradeRequired {
+			return c.JSON(http.StatusForbidden,
+				ResponseError{Errors: []Error{{Code: errors.CodeKYCUpgradeRequired, Message: "KYC level does not allow this operation; verify the wallet to a higher level"}}})
+		}
+		if err == model.ErrConcurrentUpdate {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "Wallet is busy, please retry"}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
+	if approval != nil {
+		return c.JSON(http.StatusAccepted, ResponseData{Data: approval})
+	}
 
 	return c.JSON(http.StatusCreated, ResponseData{Data: transaction})
 }
@@ -219,7 +450,7 @@ func (t *walletHandler) FetchTransactions(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	wallet, transactions, err := t.service.GetWalletWithTransactions(req.UserID)
+	wallet, transactions, err := t.service.GetWalletWithTransactions(c.Request().Context(), req.UserID)
 	if err != nil {
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
@@ -230,11 +461,7 @@ func (t *walletHandler) FetchTransactions(c echo.Context) error {
 	}
 
 	response := WalletResponse{
-		Wallet: WalletSummary{
-			Balance:  wallet.Balance,
-			AcntType: wallet.AcntType,
-			Status:   wallet.Status,
-		},
+		Wallet:       newWalletSummary(wallet),
 		Transactions: transactions,
 	}
 	return c.JSON(http.StatusOK, ResponseData{Data: response})
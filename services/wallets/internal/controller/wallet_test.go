@@ -2,6 +2,7 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -203,6 +204,14 @@ func TestWalletHandler_Deposit(t *testing.T) {
 				StatusCode: http.StatusNotFound,
 			},
 		},
+		{
+			name:        "unknown_provider",
+			setupWallet: true,
+			depositBody: `{"user_id":"test-user-001", "amount":5000, "provider_id":"test-user-001"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -580,7 +589,7 @@ func TestWalletHandler_Find(t *testing.T) {
 
 	// Test the mock directly to ensure it's working as expected
 	mockClient := &client.MockTransactionClient{}
-	txns, err := mockClient.FetchTransactions("test-user-001")
+	txns, err := mockClient.FetchTransactions(context.Background(), "test-user-001")
 	require.NoError(t, err)
 	require.Len(t, txns, 2, "Expected 2 transactions from mock")
 	require.Equal(t, "test-user-001", txns[0].SubjectWalletID)
@@ -598,7 +607,7 @@ func TestWalletHandler_Find(t *testing.T) {
 			userID:      "test-user-001",
 			want: want{
 				StatusCode: http.StatusOK,
-				Response:   []byte(`{"data":{"wallet":{"balance":10000, "acnt_type":"user", "status":"active"}, "transactions":[{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":5000, "status":"completed"}, {"subject_wallet_id":"test-user-001", "object_wallet_id":"withdraw-provider-master", "transaction_type":"withdraw", "operation_type":"debit", "amount":2000, "status":"completed"}]}}`),
+				Response:   []byte(`{"data":{"wallet":{"balance":10000, "total_balance":10000, "acnt_type":"user", "status":"active"}, "transactions":[{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":5000, "status":"completed"}, {"subject_wallet_id":"test-user-001", "object_wallet_id":"withdraw-provider-master", "transaction_type":"withdraw", "operation_type":"debit", "amount":2000, "status":"completed"}]}}`),
 			},
 		},
 		{
@@ -683,6 +692,65 @@ func TestWalletHandler_Find(t *testing.T) {
 }
 
 // Helper functions
+func TestWalletHandler_SuspendedWalletMoney(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	walletService := service.NewWalletService(repository.NewWalletRepo(dbInstance))
+	handler := NewWalletController(walletService)
+
+	client.ResetClient()
+	cache.ResetRedisClient()
+	patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
+		return &client.MockTransactionClient{}
+	})
+	redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
+		return cache.NewMockRedisClient()
+	})
+	defer func() {
+		patches.Reset()
+		redisPatches.Reset()
+		client.ResetClient()
+		cache.ResetRedisClient()
+	}()
+
+	clearDB(dbInstance, model.Wallet{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 1000)
+	createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, 1000)
+	createTestWalletWithBalance(t, dbInstance, model.DepositProviderID, model.Provider, 1000)
+	createTestWallet(t, dbInstance, model.WithdrawProviderID, model.Provider)
+	_, err = walletService.SuspendWallet(context.Background(), "test-user-001")
+	require.NoError(t, err)
+
+	post := func(handle echo.HandlerFunc, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handle(e.NewContext(req, rec)))
+		return rec
+	}
+	for name, rec := range map[string]*httptest.ResponseRecorder{
+		"deposit":      post(handler.Deposit, `{"user_id":"test-user-001", "amount":100}`),
+		"withdraw":     post(handler.Withdraw, `{"user_id":"test-user-001", "amount":100}`),
+		"transfer_out": post(handler.Transfer, `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":100}`),
+		"transfer_in":  post(handler.Transfer, `{"from_user_id":"test-user-002", "to_user_id":"test-user-001", "amount":100}`),
+	} {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, name)
+		assert.Contains(t, rec.Body.String(), "Wallet is suspended", name)
+	}
+	var balances []int64
+	require.NoError(t, dbInstance.Model(&model.Wallet{}).Where("user_id IN ?", []string{"test-user-001", "test-user-002"}).
+		Order("user_id").Pluck("balance", &balances).Error)
+	assert.Equal(t, []int64{1000, 1000}, balances, "nothing moved")
+
+	_, err = walletService.ActivateWallet(context.Background(), "test-user-001")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, post(handler.Deposit, `{"user_id":"test-user-001", "amount":100}`).Code)
+}
+
 func clearDB(db *gorm.DB, models ...interface{}) {
 	for _, model := range models {
 		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(model)
@@ -4,6 +4,7 @@ package db
 import (
 	"fmt"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	"github.com/spf13/viper"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -18,6 +19,9 @@ func New(cfg model.PostgreSQL) (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		return nil, err
+	}
 	err = Migrate(db)
 	if err != nil {
 		return nil, err
@@ -10,4 +10,14 @@ const (
 	CodeNotFound = "NOT_FOUND"
 	// CodeBadRequest is a generic error message returned when the request is bad.
 	CodeBadRequest = "BAD_REQUEST"
+	// CodeConflict is returned when a concurrent update prevented the request from completing.
+	CodeConflict = "CONFLICT"
+	// CodeUnauthorized is returned when an admin request does not carry the token of a known operator.
+	CodeUnauthorized = "UNAUTHORIZED"
+	// CodeForbidden is returned when the acting user is not allowed to perform the operation on the wallet.
+	CodeForbidden = "FORBIDDEN"
+	// CodeKYCUpgradeRequired is returned when the wallet's KYC level does not allow the operation and the holder needs to be verified to a higher level.
+	CodeKYCUpgradeRequired = "KYC_UPGRADE_REQUIRED"
+	// CodeBalanceLimitExceeded is returned when a credit would take a wallet above the regulatory ceiling on its balance.
+	CodeBalanceLimitExceeded = "BALANCE_LIMIT_EXCEEDED"
 )
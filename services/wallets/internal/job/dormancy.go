@@ -0,0 +1,61 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// Dormancy marks the user wallets without activity for the dormancy period
+// inactive, once a day at the configured time.
+type Dormancy struct {
+	walletService service.Wallet
+	cfg           model.Dormancy
+}
+
+// NewDormancyJob creates the dormant wallet job. Unset settings fall back to
+// model.DefaultDormancy.
+func NewDormancyJob(ws service.Wallet, cfg model.Dormancy) *Dormancy {
+	if cfg.RunAt == "" {
+		cfg.RunAt = model.DefaultDormancy().RunAt
+	}
+	return &Dormancy{
+		walletService: ws,
+		cfg:           cfg,
+	}
+}
+
+// Run marks dormant wallets every day at the configured time until ctx is
+// cancelled.
+func (j *Dormancy) Run(ctx context.Context) {
+	log.Infof("dormancy job running daily at %s UTC", j.cfg.RunAt)
+	for {
+		timer := time.NewTimer(time.Until(j.cfg.NextRun(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Info("dormancy job stopped")
+			return
+		case <-timer.C:
+			if err := j.RunOnce(ctx); err != nil {
+				utils.LogError("Failed to mark dormant wallets", err)
+			}
+		}
+	}
+}
+
+// RunOnce marks the wallets that became dormant since the last run.
+func (j *Dormancy) RunOnce(ctx context.Context) error {
+	dormant, err := j.walletService.RunDormancy(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if dormant > 0 {
+		log.WithField("wallets", dormant).Info("dormant wallets marked inactive")
+	}
+	return nil
+}
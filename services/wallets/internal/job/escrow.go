@@ -0,0 +1,60 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// EscrowExpiry periodically refunds funded escrows whose deadline has passed.
+type EscrowExpiry struct {
+	walletService service.Wallet
+	interval      time.Duration
+}
+
+// NewEscrowExpiryJob creates the escrow expiry job. Unset settings fall back
+// to model.DefaultEscrowExpiry.
+func NewEscrowExpiryJob(ws service.Wallet, cfg model.EscrowExpiry) *EscrowExpiry {
+	if cfg.Interval <= 0 {
+		cfg.Interval = model.DefaultEscrowExpiry().Interval
+	}
+	return &EscrowExpiry{
+		walletService: ws,
+		interval:      cfg.Interval,
+	}
+}
+
+// Run refunds expired escrows every interval until ctx is cancelled.
+func (j *EscrowExpiry) Run(ctx context.Context) {
+	log.Infof("escrow expiry job running every %s", j.interval)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("escrow expiry job stopped")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				utils.LogError("Failed to refund expired escrows", err)
+			}
+		}
+	}
+}
+
+// RunOnce refunds the escrows that expired since the last run.
+func (j *EscrowExpiry) RunOnce(ctx context.Context) error {
+	refunded, err := j.walletService.RefundExpiredEscrows(ctx)
+	if err != nil {
+		return err
+	}
+	if refunded > 0 {
+		log.WithField("escrows", refunded).Info("expired escrows refunded")
+	}
+	return nil
+}
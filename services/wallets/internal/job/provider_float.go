@@ -0,0 +1,78 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProviderFloat periodically checks the balance of every provider wallet
+// against its low-float threshold. A provider falling below it raises a
+// warning once, until it is topped up again; the balances and flags are also
+// exported as metrics to alert on.
+type ProviderFloat struct {
+	walletService    service.Wallet
+	interval         time.Duration
+	defaultThreshold int64
+	low              map[string]bool // Providers low on float at the last check
+}
+
+// NewProviderFloatJob creates the low-float check job. Unset settings fall
+// back to model.DefaultProviders.
+func NewProviderFloatJob(ws service.Wallet, cfg model.Providers) *ProviderFloat {
+	if cfg.Interval <= 0 {
+		cfg.Interval = model.DefaultProviders().Interval
+	}
+	return &ProviderFloat{
+		walletService:    ws,
+		interval:         cfg.Interval,
+		defaultThreshold: cfg.LowFloatThreshold,
+		low:              make(map[string]bool),
+	}
+}
+
+// Run checks the provider balances every interval until ctx is cancelled.
+func (j *ProviderFloat) Run(ctx context.Context) {
+	log.Infof("provider float job running every %s", j.interval)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("provider float job stopped")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				utils.LogError("Failed to check provider float", err)
+			}
+		}
+	}
+}
+
+// RunOnce checks every provider balance, warning about the providers that
+// fell below their threshold since the last check.
+func (j *ProviderFloat) RunOnce(ctx context.Context) error {
+	providers, err := j.walletService.ListProviders(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, provider := range providers {
+		metrics.ObserveProviderFloat(provider.UserID, provider.Balance, provider.LowFloat)
+		fields := log.Fields{"provider": provider.UserID, "balance": provider.Balance, "threshold": provider.Threshold(j.defaultThreshold)}
+		switch {
+		case provider.LowFloat && !j.low[provider.UserID]:
+			log.WithFields(fields).Warn("provider balance below low-float threshold")
+		case !provider.LowFloat && j.low[provider.UserID]:
+			log.WithFields(fields).Info("provider balance back above low-float threshold")
+		}
+		j.low[provider.UserID] = provider.LowFloat
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// Settlement periodically settles the provider wallets for the previous UTC
+// day. Settling a day again only retries batches left pending, so the job can
+// check more often than once a day.
+type Settlement struct {
+	walletService service.Wallet
+	interval      time.Duration
+}
+
+// NewSettlementJob creates the provider settlement job. Unset settings fall
+// back to model.DefaultSettlement.
+func NewSettlementJob(ws service.Wallet, cfg model.Settlement) *Settlement {
+	if cfg.Interval <= 0 {
+		cfg.Interval = model.DefaultSettlement().Interval
+	}
+	return &Settlement{
+		walletService: ws,
+		interval:      cfg.Interval,
+	}
+}
+
+// Run settles the previous day every interval until ctx is cancelled.
+func (j *Settlement) Run(ctx context.Context) {
+	log.Infof("settlement job running every %s", j.interval)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("settlement job stopped")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				utils.LogError("Failed to run settlement", err)
+			}
+		}
+	}
+}
+
+// RunOnce settles the provider wallets for the previous UTC day.
+func (j *Settlement) RunOnce(ctx context.Context) error {
+	date := time.Now().UTC().AddDate(0, 0, -1)
+	batches, err := j.walletService.RunSettlement(ctx, date)
+	if err != nil {
+		return err
+	}
+	pending := 0
+	for _, batch := range batches {
+		if batch.Status == model.SettlementPending {
+			pending++
+		}
+	}
+	log.WithFields(log.Fields{
+		"date":    date.Format(model.DateLayout),
+		"batches": len(batches),
+		"pending": pending,
+	}).Info("settlement run")
+	return nil
+}
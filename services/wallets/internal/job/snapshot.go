@@ -0,0 +1,88 @@
+// Package job provides the background jobs run alongside the API server.
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// ledgerSettleDelay is how far behind the current time snapshots are taken.
+// A ledger entry is timestamped just before it commits, so entries from the
+// last moments may not be readable yet.
+const ledgerSettleDelay = time.Minute
+
+// Snapshot periodically records the ledger balance of every wallet so that
+// historical balances only need to replay the ledger since the last snapshot.
+type Snapshot struct {
+	walletRepository repository.Wallet
+	interval         time.Duration
+	retention        time.Duration
+}
+
+// NewSnapshotJob creates the balance snapshot job. Unset settings fall back
+// to model.DefaultSnapshots.
+func NewSnapshotJob(wr repository.Wallet, cfg model.Snapshots) *Snapshot {
+	if cfg.Interval <= 0 {
+		cfg.Interval = model.DefaultSnapshots().Interval
+	}
+	return &Snapshot{
+		walletRepository: wr,
+		interval:         cfg.Interval,
+		retention:        cfg.Retention,
+	}
+}
+
+// Run takes a snapshot every interval until ctx is cancelled.
+func (j *Snapshot) Run(ctx context.Context) {
+	log.Infof("balance snapshot job running every %s", j.interval)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("balance snapshot job stopped")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				utils.LogError("Failed to take balance snapshots", err)
+			}
+		}
+	}
+}
+
+// RunOnce snapshots the ledger balance of every wallet and removes snapshots
+// past retention.
+func (j *Snapshot) RunOnce(ctx context.Context) error {
+	now := time.Now().UTC()
+	takenAt := now.Add(-ledgerSettleDelay).Truncate(time.Microsecond)
+	balances, err := client.NewTxnClient().FetchLedgerBalances(ctx, takenAt)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	count, err := j.walletRepository.CreateSnapshots(ctx, takenAt, balances)
+	if err != nil {
+		return err
+	}
+
+	var pruned int64
+	if j.retention > 0 {
+		if pruned, err = j.walletRepository.DeleteSnapshotsBefore(ctx, now.Add(-j.retention)); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{"wallets": count, "pruned": pruned}).Info("balance snapshots taken")
+	return nil
+}
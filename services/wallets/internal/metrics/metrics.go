@@ -0,0 +1,252 @@
+// Package metrics provides the Prometheus collectors exposed on /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wallets"
+
+// Outcome label values for money movements.
+const (
+	OutcomeSuccess           = "success"
+	OutcomeInsufficientFunds = "insufficient_funds"
+	OutcomeNotFound          = "not_found"
+	OutcomeApprovalRequired  = "approval_required" // Held until the wallet's owners approve it
+	OutcomeError             = "error"
+)
+
+// Cache result label values for transaction history lookups.
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+	// CacheStale is an invalidated history served while the transactions service is unavailable.
+	CacheStale = "stale"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	operations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operations_total",
+		Help:      "Number of deposits, withdrawals and transfers by outcome.",
+	}, []string{"transaction_type", "outcome"})
+
+	operationAmount = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "operation_amount_cents",
+		Help:      "Amount of successful deposits, withdrawals and transfers in cents.",
+		Buckets:   prometheus.ExponentialBuckets(100, 10, 7), // 1 to 1M in major units
+	}, []string{"transaction_type"})
+
+	historyCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transaction_history_cache_total",
+		Help:      "Transaction history cache lookups by result (hit, miss, error, stale).",
+	}, []string{"result"})
+
+	txnClientDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transaction_client_request_duration_seconds",
+		Help:      "Latency of calls to the transactions service by operation and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "outcome"})
+
+	txnClientErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transaction_client_errors_total",
+		Help:      "Number of failed calls to the transactions service by operation.",
+	}, []string{"operation"})
+
+	txnClientRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transaction_client_retries_total",
+		Help:      "Number of retried calls to the transactions service by operation.",
+	}, []string{"operation"})
+
+	txnClientRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transaction_client_rejections_total",
+		Help:      "Number of calls to the transactions service rejected locally, by reason (circuit_open, bulkhead_full).",
+	}, []string{"reason"})
+
+	circuitState = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "transaction_client_circuit_state",
+		Help:      "Transactions service circuit breaker state (0 closed, 1 open, 2 half-open).",
+	})
+
+	circuitTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transaction_client_circuit_transitions_total",
+		Help:      "Number of transactions service circuit breaker state changes.",
+	}, []string{"from", "to"})
+
+	asyncFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "async_failures_total",
+		Help:      "Number of background tasks that failed after the request returned, by task.",
+	}, []string{"task"})
+
+	escrowRefundFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "escrow_refund_failures_total",
+		Help:      "Number of automatic refunds of expired escrows refused by the buyer's wallet, by whether it was the last attempt.",
+	}, []string{"final"})
+
+	providerBalance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "provider_balance_cents",
+		Help:      "Balance of each provider wallet in cents, as of the last float check.",
+	}, []string{"provider"})
+
+	providerLowFloat = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "provider_low_float",
+		Help:      "Whether each provider wallet is below its low-float threshold (1) or not (0).",
+	}, []string{"provider"})
+)
+
+// Middleware records request count and latency for every route.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			status := c.Response().Status
+			if err != nil {
+				var httpErr *echo.HTTPError
+				if errors.As(err, &httpErr) {
+					status = httpErr.Code
+				} else {
+					status = http.StatusInternalServerError
+				}
+			}
+			// c.Path() is the registered route pattern, which keeps label cardinality bounded.
+			labels := []string{c.Request().Method, c.Path(), strconv.Itoa(status)}
+			httpRequests.WithLabelValues(labels...).Inc()
+			httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// Handler returns the HTTP handler serving the default Prometheus registry.
+func Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.Handler())
+}
+
+var (
+	dbStatsMu        sync.Mutex
+	dbStatsCollector prometheus.Collector
+)
+
+// RegisterDBStats exposes connection pool statistics for db, replacing any
+// previously registered pool.
+func RegisterDBStats(db *sql.DB) error {
+	dbStatsMu.Lock()
+	defer dbStatsMu.Unlock()
+
+	if dbStatsCollector != nil {
+		prometheus.Unregister(dbStatsCollector)
+	}
+	dbStatsCollector = collectors.NewDBStatsCollector(db, namespace)
+	return prometheus.Register(dbStatsCollector)
+}
+
+// ObserveOperation records the outcome of a deposit, withdrawal or transfer.
+func ObserveOperation(txnType model.TransactionType, amountCents int64, err error) {
+	outcome := OutcomeSuccess
+	switch {
+	case err == nil:
+		operationAmount.WithLabelValues(string(txnType)).Observe(float64(amountCents))
+	case errors.Is(err, model.ErrInsufficientFunds):
+		outcome = OutcomeInsufficientFunds
+	case errors.Is(err, model.ErrNotFound):
+		outcome = OutcomeNotFound
+	case errors.Is(err, model.ErrApprovalRequired):
+		outcome = OutcomeApprovalRequired
+	default:
+		outcome = OutcomeError
+	}
+	operations.WithLabelValues(string(txnType), outcome).Inc()
+}
+
+// ObserveCacheLookup records a transaction history cache lookup result.
+func ObserveCacheLookup(result string) {
+	historyCache.WithLabelValues(result).Inc()
+}
+
+// ObserveTransactionClient records the latency and outcome of a transactions service call.
+func ObserveTransactionClient(operation string, start time.Time, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+		txnClientErrors.WithLabelValues(operation).Inc()
+	}
+	txnClientDuration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+// IncTransactionClientRetry records a retried transactions service call.
+func IncTransactionClientRetry(operation string) {
+	txnClientRetries.WithLabelValues(operation).Inc()
+}
+
+// IncTransactionClientRejection records a call rejected before reaching the transactions service.
+func IncTransactionClientRejection(reason string) {
+	txnClientRejections.WithLabelValues(reason).Inc()
+}
+
+// ObserveCircuitTransition records a circuit breaker state change; state is
+// the numeric value of the new state.
+func ObserveCircuitTransition(from, to string, state int) {
+	circuitTransitions.WithLabelValues(from, to).Inc()
+	circuitState.Set(float64(state))
+}
+
+// IncAsyncFailure records a failed background task such as an asynchronous ledger write.
+func IncAsyncFailure(task string) {
+	asyncFailures.WithLabelValues(task).Inc()
+}
+
+// IncEscrowRefundFailure records a refused refund of an expired escrow; final
+// is set when the escrow will not be retried and needs an operator.
+func IncEscrowRefundFailure(final bool) {
+	escrowRefundFailures.WithLabelValues(strconv.FormatBool(final)).Inc()
+}
+
+// ObserveProviderFloat records the balance of a provider wallet and whether it is low on float.
+func ObserveProviderFloat(providerID string, balanceCents int64, low bool) {
+	providerBalance.WithLabelValues(providerID).Set(float64(balanceCents))
+	lowFloat := 0.0
+	if low {
+		lowFloat = 1
+	}
+	providerLowFloat.WithLabelValues(providerID).Set(lowFloat)
+}
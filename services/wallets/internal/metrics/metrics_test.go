@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware())
+	e.GET("/wallets/:user_id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/metrics", Handler())
+
+	before := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/wallets/:user_id", "200"))
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/user-001", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	after := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/wallets/:user_id", "200"))
+	assert.Equal(t, before+1, after)
+
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.Contains(rec.Body.String(), `wallets_http_requests_total{method="GET",route="/wallets/:user_id",status="200"}`))
+}
+
+func TestObserveOperation(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		outcome string
+	}{
+		{"Success", nil, OutcomeSuccess},
+		{"Insufficient_funds", model.ErrInsufficientFunds, OutcomeInsufficientFunds},
+		{"Not_found", model.ErrNotFound, OutcomeNotFound},
+		{"Approval_required", model.ErrApprovalRequired, OutcomeApprovalRequired},
+		{"Other_error", errors.New("boom"), OutcomeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := operations.WithLabelValues(string(model.Transfer), tt.outcome)
+			before := testutil.ToFloat64(counter)
+			ObserveOperation(model.Transfer, 5000, tt.err)
+			assert.Equal(t, before+1, testutil.ToFloat64(counter))
+		})
+	}
+}
+
+func TestObserveProviderFloat(t *testing.T) {
+	ObserveProviderFloat("test-provider", 2500, true)
+	assert.Equal(t, 2500.0, testutil.ToFloat64(providerBalance.WithLabelValues("test-provider")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(providerLowFloat.WithLabelValues("test-provider")))
+
+	ObserveProviderFloat("test-provider", 90000, false)
+	assert.Equal(t, 90000.0, testutil.ToFloat64(providerBalance.WithLabelValues("test-provider")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(providerLowFloat.WithLabelValues("test-provider")))
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// BalanceAdjustment is a manual correction of a wallet's balance against the
+// adjustments provider wallet, proposed by one operator with a reason and
+// evidence. A different operator approves or rejects it; approval applies the
+// change and records an adjustment ledger pair.
+type BalanceAdjustment struct {
+	ID            int              `gorm:"primaryKey" json:"id"`
+	WalletID      int              `gorm:"not null;index" json:"-"`
+	UserID        string           `gorm:"not null" json:"user_id"`
+	OperationType OperationType    `gorm:"not null" json:"operation_type"` // Credit or debit of the wallet
+	Amount        int64            `gorm:"not null" json:"amount"`         // In cents
+	Reason        string           `gorm:"not null" json:"reason"`
+	Evidence      []string         `gorm:"type:text;serializer:json" json:"evidence"` // References backing the correction: tickets, statements, files
+	Status        AdjustmentStatus `gorm:"not null;index" json:"status"`
+	ProposedBy    string           `gorm:"not null" json:"proposed_by"`
+	Reviewer      string           `json:"reviewer,omitempty"`
+	ReviewNote    string           `json:"review_note,omitempty"`
+	ReviewedAt    *time.Time       `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// AdjustmentStatus is the review state of a balance adjustment.
+type AdjustmentStatus string
+
+const (
+	// AdjustmentPending is the status of an adjustment awaiting review.
+	AdjustmentPending = AdjustmentStatus("pending")
+	// AdjustmentApproved is the status of an adjustment applied to the wallet.
+	AdjustmentApproved = AdjustmentStatus("approved")
+	// AdjustmentRejected is the status of an adjustment turned down by the reviewer.
+	AdjustmentRejected = AdjustmentStatus("rejected")
+)
+
+// SignedAmount returns the amount as it affects the wallet's balance:
+// positive for credits, negative for debits.
+func (a *BalanceAdjustment) SignedAmount() int64 {
+	if a.OperationType == Debit {
+		return -a.Amount
+	}
+	return a.Amount
+}
+
+// GroupID returns the group ID of the ledger entries of the adjustment, which
+// links them back to it.
+func (a *BalanceAdjustment) GroupID() string {
+	return "adjustment-" + strconv.Itoa(a.ID)
+}
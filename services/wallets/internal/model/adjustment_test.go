@@ -0,0 +1,16 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceAdjustment(t *testing.T) {
+	credit := BalanceAdjustment{ID: 7, OperationType: Credit, Amount: 2500}
+	debit := BalanceAdjustment{ID: 8, OperationType: Debit, Amount: 2500}
+
+	assert.Equal(t, int64(2500), credit.SignedAmount())
+	assert.Equal(t, int64(-2500), debit.SignedAmount())
+	assert.Equal(t, "adjustment-7", credit.GroupID())
+}
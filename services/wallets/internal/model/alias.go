@@ -0,0 +1,196 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// WalletAlias is a phone number, email address or @handle that identifies a
+// wallet without exposing its user ID. Alias values are unique across all
+// wallets and stored in normalized form.
+type WalletAlias struct {
+	ID          int         `gorm:"primaryKey" json:"id"`
+	WalletID    int         `gorm:"not null;index" json:"-"`
+	Type        AliasType   `gorm:"not null" json:"type"`
+	Value       string      `gorm:"not null;uniqueIndex" json:"alias"`
+	DisplayName string      `json:"display_name,omitempty"`
+	Status      AliasStatus `gorm:"not null" json:"status"`
+	VerifiedAt  *time.Time  `json:"verified_at,omitempty"`
+	// ChallengeHash is the hash of the code sent to a pending phone number or
+	// email address to prove ownership; the code itself is never stored
+	ChallengeHash     string     `gorm:"not null;default:''" json:"-"`
+	ChallengeSentAt   *time.Time `json:"challenge_sent_at,omitempty"`
+	ChallengeAttempts int        `gorm:"not null;default:0" json:"-"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// AliasType is the kind of a wallet alias.
+type AliasType string
+
+const (
+	// AliasPhone is an E.164 phone number alias.
+	AliasPhone = AliasType("phone")
+	// AliasEmail is an email address alias.
+	AliasEmail = AliasType("email")
+	// AliasHandle is an @handle alias.
+	AliasHandle = AliasType("handle")
+)
+
+// AliasStatus is the verification status of a wallet alias.
+type AliasStatus string
+
+const (
+	// AliasPending is the status of an alias whose ownership is not yet confirmed.
+	AliasPending = AliasStatus("pending")
+	// AliasVerified is the status of an alias that can be used to receive transfers.
+	AliasVerified = AliasStatus("verified")
+)
+
+const (
+	// AliasChallengeTTL is how long an alias verification code stays valid.
+	AliasChallengeTTL = 15 * time.Minute
+	// AliasChallengeCooldown is the time before a new code can be sent for an alias.
+	AliasChallengeCooldown = time.Minute
+	// MaxAliasChallengeAttempts is the number of wrong codes after which a
+	// challenge is void and a new code has to be sent.
+	MaxAliasChallengeAttempts = 5
+	// AliasChallengeEventName is the event delivering a verification code.
+	AliasChallengeEventName = "alias.challenge"
+)
+
+// AliasChallengeEvent asks the delivery service behind the webhook to send a
+// verification code to a phone number or email address.
+type AliasChallengeEvent struct {
+	Event     string    `json:"event"`
+	Type      AliasType `json:"type"`
+	Alias     string    `json:"alias"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ResolvedAlias is the public view of an alias: enough for a payer to
+// recognise the recipient, without revealing who owns the wallet.
+type ResolvedAlias struct {
+	Alias       string    `json:"alias"`
+	Type        AliasType `json:"type"`
+	DisplayName string    `json:"display_name"`
+}
+
+var (
+	handlePattern = regexp.MustCompile(`^@[a-z0-9_]{3,30}$`)
+	phonePattern  = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	// phoneSeparators are the characters commonly used to group phone digits.
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+)
+
+// NewWalletAlias parses a raw alias and returns a new alias record for the
+// wallet. Handles belong to whoever claims them first and are verified right
+// away; phone numbers and email addresses start out pending until the code of
+// a challenge is confirmed.
+func NewWalletAlias(walletID int, raw, displayName string) (*WalletAlias, error) {
+	aliasType, value, err := ParseAlias(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	alias := &WalletAlias{
+		WalletID:    walletID,
+		Type:        aliasType,
+		Value:       value,
+		DisplayName: strings.TrimSpace(displayName),
+		Status:      AliasPending,
+	}
+	if aliasType == AliasHandle {
+		now := time.Now()
+		alias.Status, alias.VerifiedAt = AliasVerified, &now
+	}
+	return alias, nil
+}
+
+// NewChallenge starts a new ownership challenge for the alias at now,
+// replacing any earlier one, and returns the six digit code to send to it.
+// It returns ErrChallengeCooldown if the previous code was sent too recently.
+func (a *WalletAlias) NewChallenge(now time.Time) (string, error) {
+	if a.ChallengeSentAt != nil && now.Before(a.ChallengeSentAt.Add(AliasChallengeCooldown)) {
+		return "", ErrChallengeCooldown
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	a.ChallengeHash, a.ChallengeSentAt, a.ChallengeAttempts = hashChallengeCode(a.Value, code), &now, 0
+	return code, nil
+}
+
+// CheckChallenge compares code with the current challenge at now. A wrong
+// code counts as an attempt and returns ErrInvalidChallengeCode; without a
+// live challenge, because none was sent, it expired or too many wrong codes
+// were given, it returns ErrExpired.
+func (a *WalletAlias) CheckChallenge(code string, now time.Time) error {
+	if a.ChallengeHash == "" || a.ChallengeSentAt == nil ||
+		!now.Before(a.ChallengeSentAt.Add(AliasChallengeTTL)) || a.ChallengeAttempts >= MaxAliasChallengeAttempts {
+		return ErrExpired
+	}
+	if subtle.ConstantTimeCompare([]byte(hashChallengeCode(a.Value, code)), []byte(a.ChallengeHash)) != 1 {
+		a.ChallengeAttempts++
+		return ErrInvalidChallengeCode
+	}
+	return nil
+}
+
+// hashChallengeCode hashes a code together with the alias it was sent to.
+func hashChallengeCode(value, code string) string {
+	sum := sha256.Sum256([]byte(value + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseAlias detects the type of a raw alias and returns it in normalized
+// form: handles and email addresses are lower-cased and phone numbers are
+// stripped of separators. It returns ErrInvalidAlias if the alias is not a
+// valid @handle, email address or E.164 phone number.
+func ParseAlias(raw string) (AliasType, string, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(raw, "@"):
+		value := strings.ToLower(raw)
+		if !handlePattern.MatchString(value) {
+			return "", "", ErrInvalidAlias
+		}
+		return AliasHandle, value, nil
+	case strings.Contains(raw, "@"):
+		address, err := mail.ParseAddress(raw)
+		if err != nil || address.Address != raw {
+			return "", "", ErrInvalidAlias
+		}
+		return AliasEmail, strings.ToLower(raw), nil
+	default:
+		value := phoneSeparators.Replace(raw)
+		if !phonePattern.MatchString(value) {
+			return "", "", ErrInvalidAlias
+		}
+		return AliasPhone, value, nil
+	}
+}
+
+// MaskDisplayName keeps only the first letter of every word of a name, e.g.
+// "Jane Doe" becomes "J*** D***". The mask has a fixed length so it does not
+// leak the length of the name either.
+func MaskDisplayName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		first, _ := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + "***"
+	}
+	return strings.Join(words, " ")
+}
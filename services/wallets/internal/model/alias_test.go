@@ -0,0 +1,91 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAlias(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		wantType  AliasType
+		wantValue string
+		wantErr   error
+	}{
+		{name: "handle", raw: "@Jane_Doe", wantType: AliasHandle, wantValue: "@jane_doe"},
+		{name: "handle_too_short", raw: "@jd", wantErr: ErrInvalidAlias},
+		{name: "handle_invalid_character", raw: "@jane.doe", wantErr: ErrInvalidAlias},
+		{name: "email", raw: " Jane.Doe@Example.com ", wantType: AliasEmail, wantValue: "jane.doe@example.com"},
+		{name: "email_with_name", raw: "Jane <jane@example.com>", wantErr: ErrInvalidAlias},
+		{name: "email_without_domain", raw: "jane@", wantErr: ErrInvalidAlias},
+		{name: "phone", raw: "+1 (415) 555-0100", wantType: AliasPhone, wantValue: "+14155550100"},
+		{name: "phone_without_country_code", raw: "4155550100", wantErr: ErrInvalidAlias},
+		{name: "phone_too_long", raw: "+1234567890123456", wantErr: ErrInvalidAlias},
+		{name: "empty", raw: "", wantErr: ErrInvalidAlias},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotValue, err := ParseAlias(tt.raw)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantType, gotType)
+			assert.Equal(t, tt.wantValue, gotValue)
+		})
+	}
+}
+
+func TestNewWalletAlias(t *testing.T) {
+	handle, err := NewWalletAlias(1, "@jane", "Jane Doe")
+	assert.NoError(t, err)
+	assert.Equal(t, AliasVerified, handle.Status)
+	assert.NotNil(t, handle.VerifiedAt)
+
+	email, err := NewWalletAlias(1, "jane@example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, AliasPending, email.Status)
+	assert.Nil(t, email.VerifiedAt)
+}
+
+func TestWalletAliasChallenge(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	alias, err := NewWalletAlias(1, "jane@example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, ErrExpired, alias.CheckChallenge("000000", now), "no challenge sent")
+
+	code, err := alias.NewChallenge(now)
+	assert.NoError(t, err)
+	assert.Len(t, code, 6)
+	assert.NotContains(t, alias.ChallengeHash, code)
+
+	_, err = alias.NewChallenge(now.Add(AliasChallengeCooldown - time.Second))
+	assert.Equal(t, ErrChallengeCooldown, err)
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "000001"
+	}
+	assert.Equal(t, ErrInvalidChallengeCode, alias.CheckChallenge(wrong, now))
+	assert.Equal(t, 1, alias.ChallengeAttempts)
+	assert.NoError(t, alias.CheckChallenge(code, now.Add(time.Minute)))
+	assert.Equal(t, ErrExpired, alias.CheckChallenge(code, now.Add(AliasChallengeTTL)), "expired")
+
+	for alias.ChallengeAttempts < MaxAliasChallengeAttempts {
+		assert.Equal(t, ErrInvalidChallengeCode, alias.CheckChallenge(wrong, now))
+	}
+	assert.Equal(t, ErrExpired, alias.CheckChallenge(code, now), "too many attempts")
+
+	code, err = alias.NewChallenge(now.Add(AliasChallengeCooldown))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, alias.ChallengeAttempts)
+	assert.NoError(t, alias.CheckChallenge(code, now.Add(AliasChallengeCooldown)))
+}
+
+func TestMaskDisplayName(t *testing.T) {
+	assert.Equal(t, "J*** D***", MaskDisplayName("Jane Doe"))
+	assert.Equal(t, "J*** D***", MaskDisplayName("  Jonathan   Doe-Smith "))
+	assert.Equal(t, "Ö***", MaskDisplayName("Östen"))
+	assert.Equal(t, "", MaskDisplayName(""))
+}
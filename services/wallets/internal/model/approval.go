@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// WalletApproval is a transfer or withdrawal from a shared wallet above its
+// approval threshold, or a closure of a shared wallet, held until enough
+// owners approve it. The initiator's approval counts if it is an owner.
+type WalletApproval struct {
+	ID                int             `gorm:"primaryKey" json:"id"`
+	WalletID          int             `gorm:"not null;index" json:"-"`
+	WalletUserID      string          `gorm:"not null" json:"wallet_user_id"`
+	TransactionType   TransactionType `gorm:"not null" json:"transaction_type"` // transfer, withdraw or closure
+	InitiatedBy       string          `gorm:"not null" json:"initiated_by"`
+	ToUserID          string          `json:"to_user_id,omitempty"`   // Receiver of a transfer or of a closure's sweep
+	ProviderID        *string         `json:"provider_id,omitempty"`  // Provider of a withdrawal or of a closure's sweep
+	Amount            int64           `gorm:"not null" json:"amount"` // Amount in cents; for a closure, the balance when it was requested
+	RequiredApprovals int             `gorm:"not null" json:"required_approvals"`
+	ApprovedBy        []string        `gorm:"-" json:"approved_by"`
+	Status            ApprovalStatus  `gorm:"not null;index" json:"status"`
+	Reason            string          `json:"reason,omitempty"` // Why a failed approval could not be carried out
+	ResolvedAt        *time.Time      `json:"resolved_at,omitempty"`
+	CreatedAt         time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Closure is the transaction type of a held closure of a shared wallet. It is
+// not a ledger transaction type: the sweep is recorded as withdrawals or
+// transfers.
+const Closure = TransactionType("closure")
+
+// WalletApprovalVote is one owner's approval of a held transfer or withdrawal.
+type WalletApprovalVote struct {
+	ID         int       `gorm:"primaryKey"`
+	ApprovalID int       `gorm:"not null;uniqueIndex:idx_wallet_approval_votes_approval_member"`
+	MemberID   string    `gorm:"not null;uniqueIndex:idx_wallet_approval_votes_approval_member"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// ApprovalStatus is the state of a held transfer or withdrawal.
+type ApprovalStatus string
+
+const (
+	// ApprovalPending is the status of an operation awaiting approvals.
+	ApprovalPending = ApprovalStatus("pending")
+	// ApprovalExecuted is the status of an approved operation carried out. It
+	// is set in the database transaction that moves the funds.
+	ApprovalExecuted = ApprovalStatus("executed")
+	// ApprovalRejected is the status of an operation refused by an owner or
+	// withdrawn by its initiator.
+	ApprovalRejected = ApprovalStatus("rejected")
+	// ApprovalFailed is the status of an approved operation that could not be
+	// carried out, e.g. for lack of funds.
+	ApprovalFailed = ApprovalStatus("failed")
+)
+
+// approvalTransitions lists the states each state can move to.
+var approvalTransitions = map[ApprovalStatus][]ApprovalStatus{
+	ApprovalPending: {ApprovalExecuted, ApprovalRejected, ApprovalFailed},
+}
+
+// CanTransition reports whether an approval may move from one state to another.
+func (s ApprovalStatus) CanTransition(to ApprovalStatus) bool {
+	for _, next := range approvalTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// BalanceSnapshot is the ledger balance of a wallet at a point in time.
+// Historical balances are computed from the latest snapshot before the
+// requested time plus the ledger entries recorded after it. Snapshots are
+// taken from the ledger rather than from the wallet balance because ledger
+// entries are written after the balance change commits: an entry recorded
+// after a snapshot is then never part of it.
+type BalanceSnapshot struct {
+	ID       int       `gorm:"primaryKey" json:"id"`
+	WalletID int       `gorm:"not null;index:idx_balance_snapshots_wallet_taken_at" json:"wallet_id"`
+	Balance  int64     `gorm:"not null" json:"balance"` // Ledger balance in cents at TakenAt
+	TakenAt  time.Time `gorm:"not null;index:idx_balance_snapshots_wallet_taken_at" json:"taken_at"`
+}
+
+// BalanceAt is the balance of a wallet at a point in time.
+type BalanceAt struct {
+	UserID  string    `json:"user_id"`
+	Balance int64     `json:"balance"` // Balance in cents
+	At      time.Time `json:"at"`
+}
+
+// DailyBalance is the end-of-day balance of a wallet for a UTC calendar day.
+type DailyBalance struct {
+	Date    string `json:"date"`    // YYYY-MM-DD
+	Balance int64  `json:"balance"` // Balance in cents
+}
+
+// DateLayout is the layout of calendar dates in requests and responses.
+const DateLayout = "2006-01-02"
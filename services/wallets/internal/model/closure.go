@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// WalletClosure is the outcome of closing a wallet: where its balance and
+// that of its pockets went, and the ledger entries debiting them.
+type WalletClosure struct {
+	UserID       string        `json:"user_id"`
+	Status       Status        `json:"status"`
+	ClosedAt     time.Time     `json:"closed_at"`
+	SweptAmount  int64         `json:"swept_amount"` // In cents, pockets included
+	SweptTo      string        `json:"swept_to"`     // User ID of the provider or wallet credited
+	Transactions []Transaction `json:"transactions"` // One debit per wallet or pocket with a balance
+}
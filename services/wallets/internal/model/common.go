@@ -7,3 +7,171 @@ var ErrNotFound = fmt.Errorf("not found")
 
 // ErrInsufficientFunds is the error for insufficient funds.
 var ErrInsufficientFunds = fmt.Errorf("insufficient funds")
+
+// ErrConcurrentUpdate is the error for a wallet modified by another transaction
+// since it was read, detected by the optimistic concurrency version check.
+var ErrConcurrentUpdate = fmt.Errorf("wallet was concurrently modified")
+
+// ErrInvalidTimeRange is the error for a requested time or date range that
+// is in the future, reversed or too long.
+var ErrInvalidTimeRange = fmt.Errorf("invalid time range")
+
+// ErrInvalidAlias is the error for an alias that is not a valid phone number,
+// email address or @handle.
+var ErrInvalidAlias = fmt.Errorf("invalid alias")
+
+// ErrAliasTaken is the error for an alias already registered to a wallet.
+var ErrAliasTaken = fmt.Errorf("alias already registered")
+
+// ErrInvalidChallengeCode is the error for a wrong alias verification code.
+var ErrInvalidChallengeCode = fmt.Errorf("invalid verification code")
+
+// ErrChallengeCooldown is the error for an alias verification code requested
+// too soon after the previous one.
+var ErrChallengeCooldown = fmt.Errorf("verification code sent too recently")
+
+// ErrSameWallet is the error for a transfer whose sender and receiver are the same wallet.
+var ErrSameWallet = fmt.Errorf("cannot transfer to the same wallet")
+
+// ErrInvalidTransition is the error for a state change not allowed from the
+// current state, e.g. accepting a payment request that was already declined.
+var ErrInvalidTransition = fmt.Errorf("invalid state transition")
+
+// ErrExpired is the error for acting on a payment request past its expiry.
+var ErrExpired = fmt.Errorf("expired")
+
+// ErrInvalidAmount is the error for an amount that is not positive or out of range.
+var ErrInvalidAmount = fmt.Errorf("invalid amount")
+
+// ErrEscrowWallet is the error for a deposit, withdrawal or transfer touching
+// an escrow wallet, whose funds only move through the escrow operations.
+var ErrEscrowWallet = fmt.Errorf("escrow wallets cannot be used directly")
+
+// ErrNotEscrowParty is the error for settling an escrow by someone other than
+// the party giving up their claim or the arbiter.
+var ErrNotEscrowParty = fmt.Errorf("not allowed to settle the escrow")
+
+// ErrInvalidSplit is the error for a split transfer whose recipients are
+// repeated or whose percentages do not add up to 100.
+var ErrInvalidSplit = fmt.Errorf("invalid split")
+
+// ErrPocketWallet is the error for a deposit, withdrawal or transfer touching
+// a pocket, whose funds only move to and from the wallets of its owner.
+var ErrPocketWallet = fmt.Errorf("pockets cannot be used directly")
+
+// ErrInvalidPocketName is the error for a pocket name that is not 1 to 30
+// lowercase letters, digits, dashes or underscores, or is reserved.
+var ErrInvalidPocketName = fmt.Errorf("invalid pocket name")
+
+// ErrPocketExists is the error for a pocket name already used by the owner.
+var ErrPocketExists = fmt.Errorf("pocket already exists")
+
+// ErrNotUserWallet is the error for an operation only available to user
+// wallets, e.g. opening a pocket under a provider wallet.
+var ErrNotUserWallet = fmt.Errorf("not a user wallet")
+
+// ErrNotMember is the error for a user acting on a wallet it is neither the
+// holder nor a member of.
+var ErrNotMember = fmt.Errorf("not a member of the wallet")
+
+// ErrMemberForbidden is the error for a member whose role does not allow the
+// operation, e.g. a viewer transferring funds.
+var ErrMemberForbidden = fmt.Errorf("member role does not allow the operation")
+
+// ErrSpendLimitExceeded is the error for a spender moving more than its spend limit.
+var ErrSpendLimitExceeded = fmt.Errorf("spend limit exceeded")
+
+// ErrInvalidSpendLimit is the error for a spender given neither a positive
+// spend limit nor unlimited spending, or both, or an unknown spend window.
+var ErrInvalidSpendLimit = fmt.Errorf("invalid spend limit")
+
+// ErrMemberExists is the error for adding a user already a member of the wallet.
+var ErrMemberExists = fmt.Errorf("member already exists")
+
+// ErrInvalidApprovalPolicy is the error for an approval policy requiring
+// more approvals than the wallet has owners.
+var ErrInvalidApprovalPolicy = fmt.Errorf("invalid approval policy")
+
+// ErrApprovalRequired is the error for a transfer or withdrawal above the
+// wallet's approval threshold made without going through approval.
+var ErrApprovalRequired = fmt.Errorf("approval required")
+
+// ErrAlreadyApproved is the error for an owner approving the same operation twice.
+var ErrAlreadyApproved = fmt.Errorf("already approved")
+
+// ErrNotMerchant is the error for a merchant operation on a wallet that is
+// not a merchant wallet.
+var ErrNotMerchant = fmt.Errorf("not a merchant wallet")
+
+// ErrMerchantExists is the error for registering a merchant whose user ID
+// already has a wallet.
+var ErrMerchantExists = fmt.Errorf("merchant already exists")
+
+// ErrReferenceTaken is the error for a payment intent whose reference the
+// merchant already used.
+var ErrReferenceTaken = fmt.Errorf("payment intent reference already used")
+
+// ErrRefundExceedsPayment is the error for a refund larger than what is left
+// of the payment after earlier refunds.
+var ErrRefundExceedsPayment = fmt.Errorf("refund exceeds the refundable amount")
+
+// ErrSettlementExists is the error for creating a settlement batch for a
+// provider and day already settled, or with entries already in another batch.
+var ErrSettlementExists = fmt.Errorf("settlement batch already exists")
+
+// ErrNoSettlementAccount is the error for exporting a NACHA file with a batch
+// of a provider that has no bank account configured.
+var ErrNoSettlementAccount = fmt.Errorf("no settlement account for provider")
+
+// ErrProviderExists is the error for registering a provider whose user ID
+// already has a wallet.
+var ErrProviderExists = fmt.Errorf("provider already exists")
+
+// ErrUnknownProvider is the error for a deposit or withdrawal through a
+// provider ID that is not a provider wallet.
+var ErrUnknownProvider = fmt.Errorf("unknown provider")
+
+// ErrProviderDisabled is the error for a deposit or withdrawal through a
+// provider that has been disabled.
+var ErrProviderDisabled = fmt.Errorf("provider disabled")
+
+// ErrWalletClosed is the error for moving money in or out of a closed wallet,
+// or closing it again.
+var ErrWalletClosed = fmt.Errorf("wallet is closed")
+
+// ErrWalletSuspended is the error for moving money in or out of a suspended wallet.
+var ErrWalletSuspended = fmt.Errorf("wallet is suspended")
+
+// ErrWalletNotClosable is the error for closing a wallet other than a user or
+// merchant wallet.
+var ErrWalletNotClosable = fmt.Errorf("wallet cannot be closed")
+
+// ErrOpenHolds is the error for closing a wallet with funds still held for
+// it: transfers or withdrawals awaiting approval, or funded escrows it is the
+// buyer or seller of.
+var ErrOpenHolds = fmt.Errorf("wallet has pending holds")
+
+// ErrKYCUpgradeRequired is the error for a withdrawal or transfer beyond the
+// limits of the wallet's KYC level, or a credit above the balance ceiling of
+// that level; the holder needs to be verified to a higher level first.
+var ErrKYCUpgradeRequired = fmt.Errorf("kyc upgrade required")
+
+// ErrInvalidKYCLevel is the error for a verification to a level that is not
+// above the wallet's current one.
+var ErrInvalidKYCLevel = fmt.Errorf("invalid kyc level")
+
+// ErrKYCPending is the error for submitting a verification while another one
+// of the wallet awaits review.
+var ErrKYCPending = fmt.Errorf("kyc verification already pending")
+
+// ErrBalanceLimitExceeded is the error for a credit that would take a wallet
+// above the regulatory ceiling on its balance.
+var ErrBalanceLimitExceeded = fmt.Errorf("balance limit exceeded")
+
+// ErrReasonRequired is the error for a manual balance adjustment without a
+// reason.
+var ErrReasonRequired = fmt.Errorf("reason required")
+
+// ErrSelfReview is the error for an operator approving or rejecting a balance
+// adjustment they proposed; a different operator has to review it.
+var ErrSelfReview = fmt.Errorf("adjustment must be reviewed by another operator")
@@ -1,13 +1,31 @@
 // Package model provides the data models for the application.
 package model
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // Config is the configuration for the application.
 type Config struct {
-	APIServer     Server
-	SwaggerServer Server
-	PostgreSQL    PostgreSQL
-	Redis         Redis
-	Services      Services
+	APIServer       Server
+	SwaggerServer   Server
+	AdminServer     AdminServer
+	PostgreSQL      PostgreSQL
+	Redis           Redis
+	Services        Services
+	Timeouts        Timeouts
+	Tracing         Tracing
+	Concurrency     Concurrency
+	Snapshots       Snapshots
+	PaymentRequests PaymentRequests
+	EscrowExpiry    EscrowExpiry
+	Settlement      Settlement
+	Providers       Providers
+	Dormancy        Dormancy
+	KYC             KYC
+	BalanceLimits   BalanceLimits
 }
 
 // Services is the configuration for external services.
@@ -17,7 +35,48 @@ type Services struct {
 
 // Service is the configuration for the transaction service.
 type Service struct {
-	BaseURL string `yaml:"baseURL"`
+	BaseURL        string `yaml:"baseURL"`
+	Retry          Retry
+	CircuitBreaker CircuitBreaker
+	// MaxConcurrent caps in-flight calls to the service (bulkhead); 0 means unlimited.
+	// Ledger writes are not counted.
+	MaxConcurrent int `validate:"gte=0"`
+	// LedgerDeliveryTimeout is how long a ledger write is retried before it
+	// is reported as failed.
+	LedgerDeliveryTimeout time.Duration
+}
+
+// Retry is the retry policy for idempotent calls to a service.
+type Retry struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts    int `validate:"gte=0"`
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// CircuitBreaker is the configuration for a service circuit breaker.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit.
+	FailureThreshold int `validate:"gte=0"`
+	// OpenTimeout is how long the circuit stays open before a trial call is let through.
+	OpenTimeout time.Duration
+}
+
+// DefaultTransactionService returns the resilience settings used for any
+// value that is not configured.
+func DefaultTransactionService() Service {
+	return Service{
+		Retry: Retry{
+			MaxAttempts:    3,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     2 * time.Second,
+		},
+		CircuitBreaker: CircuitBreaker{
+			FailureThreshold: 5,
+			OpenTimeout:      30 * time.Second,
+		},
+		LedgerDeliveryTimeout: 15 * time.Minute,
+	}
 }
 
 // Server is the configuration for the server.
@@ -26,6 +85,49 @@ type Server struct {
 	Port   int
 }
 
+// AdminServer is the configuration for the internal server of the /admin
+// endpoints. It listens on a port of its own, which is not routed through the
+// gateway, and only serves operators presenting one of the configured tokens.
+type AdminServer struct {
+	Enable    bool
+	Port      int
+	Operators []Operator `validate:"unique=Name,dive"` // Names are unique, as they tell the maker from the checker
+}
+
+// Operator is an operator allowed to use the admin endpoints, identified by
+// the bearer token it presents. The token is not kept in the config file but
+// read at startup from the environment variable named by TokenEnv.
+type Operator struct {
+	Name     string `validate:"required"`
+	TokenEnv string `validate:"required"`
+	Token    string `mapstructure:"-"` // Set by LoadTokens
+}
+
+// LoadTokens sets the token of every operator from its environment variable,
+// looked up with getenv.
+func (a *AdminServer) LoadTokens(getenv func(string) string) {
+	for i := range a.Operators {
+		a.Operators[i].Token = getenv(a.Operators[i].TokenEnv)
+	}
+}
+
+// TokenError returns an error naming the first operator whose token is unset,
+// shorter than 16 characters or still a change-me placeholder, or nil if all
+// of them can be used.
+func (a AdminServer) TokenError() error {
+	for _, operator := range a.Operators {
+		switch {
+		case operator.Token == "":
+			return fmt.Errorf("token of operator %s is not set in %s", operator.Name, operator.TokenEnv)
+		case len(operator.Token) < 16:
+			return fmt.Errorf("token of operator %s in %s is shorter than 16 characters", operator.Name, operator.TokenEnv)
+		case strings.Contains(strings.ToLower(operator.Token), "change-me"):
+			return fmt.Errorf("token of operator %s in %s is still a placeholder", operator.Name, operator.TokenEnv)
+		}
+	}
+	return nil
+}
+
 // PostgreSQL is the configuration for the PostgreSQL database.
 type PostgreSQL struct {
 	Host     string `validate:"required"`
@@ -45,3 +147,337 @@ type Redis struct {
 	MaxRetries int
 	PoolSize   int
 }
+
+// Timeouts holds the per-operation deadlines applied to outbound calls.
+type Timeouts struct {
+	// Database bounds a single wallet operation, including its DB transaction.
+	Database time.Duration
+	// Cache bounds each Redis command.
+	Cache time.Duration
+	// TransactionService bounds each attempt of a call to the transactions microservice.
+	TransactionService time.Duration
+}
+
+// DefaultTimeouts returns the deadlines used when none are configured.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Database:           10 * time.Second,
+		Cache:              2 * time.Second,
+		TransactionService: 30 * time.Second,
+	}
+}
+
+// Trace exporter names accepted by Tracing.Exporter.
+const (
+	// TraceExporterStdout writes spans as JSON to standard output.
+	TraceExporterStdout = "stdout"
+	// TraceExporterFile appends spans as JSON to Tracing.FilePath.
+	TraceExporterFile = "file"
+)
+
+// Tracing is the configuration for OpenTelemetry tracing.
+type Tracing struct {
+	Enable      bool
+	Exporter    string `validate:"omitempty,oneof=stdout file"`
+	FilePath    string `validate:"required_if=Exporter file"`
+	ServiceName string
+	// SampleRatio is the fraction of new traces that are sampled; 0 means all.
+	SampleRatio float64 `validate:"gte=0,lte=1"`
+}
+
+// Concurrency control modes accepted by Concurrency.Mode.
+const (
+	// ConcurrencyPessimistic locks wallet rows with SELECT ... FOR UPDATE.
+	ConcurrencyPessimistic = "pessimistic"
+	// ConcurrencyOptimistic reads without locks and rejects stale writes via the version column.
+	ConcurrencyOptimistic = "optimistic"
+	// ConcurrencyAtomic applies each balance change as a single conditional UPDATE without reading the row first.
+	ConcurrencyAtomic = "atomic"
+)
+
+// Concurrency is the configuration for concurrent balance updates.
+type Concurrency struct {
+	Mode string `validate:"omitempty,oneof=pessimistic optimistic atomic"`
+	// MaxRetries is how many times a transaction is retried after a
+	// deadlock, serialization failure or optimistic version conflict.
+	MaxRetries int `validate:"gte=0"`
+	// ProviderShards splits each provider wallet into this many sub-accounts
+	// at startup; 0 or 1 leaves provider wallets unsharded.
+	ProviderShards int `validate:"gte=0"`
+}
+
+// DefaultConcurrency returns the concurrency settings used when none are configured.
+func DefaultConcurrency() Concurrency {
+	return Concurrency{
+		Mode:       ConcurrencyPessimistic,
+		MaxRetries: 3,
+	}
+}
+
+// Snapshots is the configuration for the background balance snapshot job.
+type Snapshots struct {
+	Enable bool
+	// Interval is the time between two snapshots of every wallet balance.
+	Interval time.Duration
+	// Retention is how long snapshots are kept; 0 keeps them forever.
+	Retention time.Duration
+}
+
+// DefaultSnapshots returns the snapshot settings used for any value that is not configured.
+func DefaultSnapshots() Snapshots {
+	return Snapshots{
+		Interval: time.Hour,
+	}
+}
+
+// PaymentRequests is the configuration for peer-to-peer payment requests.
+type PaymentRequests struct {
+	// DefaultExpiry is how long a request stays open when the requester does not set an expiry.
+	DefaultExpiry time.Duration
+	// MaxExpiry is the longest a request may stay open.
+	MaxExpiry time.Duration
+	// WebhookURL receives a POST of every payment request event; empty disables webhooks.
+	WebhookURL string `yaml:"webhookURL" validate:"omitempty,url"`
+	// WebhookSecret signs webhook bodies with HMAC-SHA256 in the X-Signature header; empty sends them unsigned.
+	WebhookSecret string
+}
+
+// DefaultPaymentRequests returns the payment request settings used for any value that is not configured.
+func DefaultPaymentRequests() PaymentRequests {
+	return PaymentRequests{
+		DefaultExpiry: 7 * 24 * time.Hour,
+		MaxExpiry:     30 * 24 * time.Hour,
+	}
+}
+
+// EscrowExpiry is the configuration for the job refunding escrows past their deadline.
+type EscrowExpiry struct {
+	Enable bool
+	// Interval is the time between two checks for expired escrows.
+	Interval time.Duration
+	// MaxRefundAttempts is how many times the refund of an expired escrow is
+	// tried while the buyer's wallet refuses it, e.g. because it is closed or
+	// suspended, before the escrow is left funded for an operator.
+	MaxRefundAttempts int `validate:"gte=0"`
+}
+
+// DefaultEscrowExpiry returns the escrow expiry settings used for any value that is not configured.
+func DefaultEscrowExpiry() EscrowExpiry {
+	return EscrowExpiry{
+		Interval:          time.Minute,
+		MaxRefundAttempts: 10,
+	}
+}
+
+// Settlement is the configuration for the daily settlement of provider wallets
+// and the NACHA-style file sent to the bank.
+type Settlement struct {
+	Enable bool
+	// Interval is the time between two checks for a day left to settle.
+	Interval time.Duration
+	// CompanyName and CompanyID identify the platform as the originator of the file.
+	CompanyName string `validate:"max=16"`
+	CompanyID   string `validate:"max=10"`
+	// OriginRouting and DestinationRouting are the 9-digit routing numbers of
+	// the platform's bank and of the bank receiving the file.
+	OriginRouting      string `validate:"omitempty,len=9,numeric"`
+	OriginName         string `validate:"max=23"`
+	DestinationRouting string `validate:"omitempty,len=9,numeric"`
+	DestinationName    string `validate:"max=23"`
+	// Accounts are the bank accounts the provider wallets settle with.
+	Accounts []SettlementAccount `validate:"dive"`
+}
+
+// SettlementAccount is the bank account a provider wallet settles with.
+type SettlementAccount struct {
+	ProviderID    string `yaml:"providerID" validate:"required"`
+	RoutingNumber string `validate:"len=9,numeric"`
+	AccountNumber string `validate:"required,max=17"`
+}
+
+// DefaultSettlement returns the settlement settings used for any value that is not configured.
+func DefaultSettlement() Settlement {
+	return Settlement{
+		Interval: time.Hour,
+	}
+}
+
+// Account returns the bank account of a provider and whether one is configured.
+func (s Settlement) Account(providerID string) (SettlementAccount, bool) {
+	for _, account := range s.Accounts {
+		if account.ProviderID == providerID {
+			return account, true
+		}
+	}
+	return SettlementAccount{}, false
+}
+
+// Providers is the configuration for provider wallets and the job alerting
+// when their balance runs low.
+type Providers struct {
+	Enable bool
+	// Interval is the time between two checks of the provider balances.
+	Interval time.Duration
+	// LowFloatThreshold is the balance in cents below which a provider
+	// without a threshold of its own is low on float; 0 turns alerts off for them.
+	LowFloatThreshold int64 `validate:"gte=0"`
+}
+
+// DefaultProviders returns the provider settings used for any value that is not configured.
+func DefaultProviders() Providers {
+	return Providers{
+		Interval: 5 * time.Minute,
+	}
+}
+
+// Dormancy actions accepted by Dormancy.Action.
+const (
+	// DormancyActionNone only marks dormant wallets inactive.
+	DormancyActionNone = "none"
+	// DormancyActionFee charges dormant wallets Dormancy.Fee, or their balance if lower.
+	DormancyActionFee = "fee"
+	// DormancyActionEscheat hands the whole balance of dormant wallets over to Dormancy.ProviderID.
+	DormancyActionEscheat = "escheat"
+)
+
+// Dormancy is the configuration for the nightly job marking user wallets
+// without activity as inactive.
+type Dormancy struct {
+	Enable bool
+	// RunAt is the time of day, in UTC and as HH:MM, at which the job runs.
+	RunAt string `yaml:"runAt" validate:"omitempty,datetime=15:04"`
+	// Period is how long a wallet must go without activity to become dormant.
+	Period time.Duration
+	// Action is what happens to the balance of a dormant wallet: none, fee or escheat.
+	Action string `validate:"omitempty,oneof=none fee escheat"`
+	// Fee is the dormancy fee in cents, capped at the wallet's balance.
+	Fee int64 `validate:"gte=0"`
+	// ProviderID is the provider wallet credited with fees and escheated balances.
+	ProviderID string `yaml:"providerID"`
+}
+
+// DefaultDormancy returns the dormancy settings used for any value that is not configured.
+func DefaultDormancy() Dormancy {
+	return Dormancy{
+		RunAt:      "02:00",
+		Period:     365 * 24 * time.Hour,
+		Action:     DormancyActionNone,
+		ProviderID: WithdrawProviderID,
+	}
+}
+
+// NextRun returns the first time after now at which the dormancy job runs,
+// at RunAt in UTC or at the default time of day if RunAt is not valid.
+func (d Dormancy) NextRun(now time.Time) time.Time {
+	at, err := time.Parse("15:04", d.RunAt)
+	if err != nil {
+		at, _ = time.Parse("15:04", DefaultDormancy().RunAt)
+	}
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// KYC is the configuration of the limits set by each KYC level. The limits
+// are only enforced when Enable is set.
+type KYC struct {
+	Enable bool
+	None   KYCTier
+	Basic  KYCTier
+	Full   KYCTier
+}
+
+// KYCTier is what a wallet at a KYC level may do. Zero maximums are unlimited.
+// The ceiling on its balance is set per KYC level in BalanceLimits.
+type KYCTier struct {
+	MaxTransfer int64 `json:"max_transfer" validate:"gte=0"` // Largest single transfer in cents
+	Withdrawals bool  `json:"withdrawals"`                   // Whether the wallet may withdraw to a provider
+}
+
+// DefaultKYC returns the KYC settings used for any value that is not configured.
+func DefaultKYC() KYC {
+	return KYC{
+		None:  KYCTier{MaxTransfer: 10000},
+		Basic: KYCTier{MaxTransfer: 100000, Withdrawals: true},
+		Full:  KYCTier{Withdrawals: true},
+	}
+}
+
+// Tier returns the limits of a KYC level; an unknown level has those of none.
+func (k KYC) Tier(level KYCLevel) KYCTier {
+	switch level {
+	case KYCBasic:
+		return k.Basic
+	case KYCFull:
+		return k.Full
+	}
+	return k.None
+}
+
+// BalanceLimits is the configuration of the regulatory ceilings on stored
+// value. A credit taking a wallet above its ceiling fails, unless AutoBounce
+// is set and the credit is a transfer: then the part above the ceiling goes
+// back to the sender.
+type BalanceLimits struct {
+	Enable     bool
+	AutoBounce bool
+	Limits     []BalanceLimit `validate:"dive"`
+}
+
+// BalanceLimit is the ceiling on the balance of the wallets of an account
+// type, or only of those at a KYC level if KYCLevel is set.
+type BalanceLimit struct {
+	AcntType   AcntType `validate:"required"`
+	KYCLevel   KYCLevel
+	MaxBalance int64 `validate:"gt=0"` // In cents
+}
+
+// DefaultBalanceLimits returns the balance limit settings used when none are configured.
+func DefaultBalanceLimits() BalanceLimits {
+	return BalanceLimits{}
+}
+
+// MaxBalance returns the ceiling on the balance of the wallet, 0 if it has
+// none. A limit for the wallet's account type and KYC level takes precedence
+// over one for its account type alone.
+func (b BalanceLimits) MaxBalance(wallet *Wallet) int64 {
+	if !b.Enable {
+		return 0
+	}
+	var general int64
+	for _, limit := range b.Limits {
+		if limit.AcntType != wallet.AcntType {
+			continue
+		}
+		if limit.KYCLevel == "" {
+			if general == 0 {
+				general = limit.MaxBalance
+			}
+			continue
+		}
+		if limit.KYCLevel == wallet.KYCLevelOf() {
+			return limit.MaxBalance
+		}
+	}
+	return general
+}
+
+// CeilingError returns the error for a credit taking the wallet above its
+// ceiling: ErrKYCUpgradeRequired if the ceiling is set for the wallet's KYC
+// level and verifying to a higher level may lift it, ErrBalanceLimitExceeded
+// otherwise.
+func (b BalanceLimits) CeilingError(wallet *Wallet) error {
+	level := wallet.KYCLevelOf()
+	if level == KYCFull {
+		return ErrBalanceLimitExceeded
+	}
+	for _, limit := range b.Limits {
+		if limit.AcntType == wallet.AcntType && limit.KYCLevel == level {
+			return ErrKYCUpgradeRequired
+		}
+	}
+	return ErrBalanceLimitExceeded
+}
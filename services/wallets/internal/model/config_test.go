@@ -0,0 +1,127 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceLimits_MaxBalance(t *testing.T) {
+	limits := BalanceLimits{
+		Enable: true,
+		Limits: []BalanceLimit{
+			{AcntType: User, MaxBalance: 10000},
+			{AcntType: User, KYCLevel: KYCNone, MaxBalance: 500},
+			{AcntType: Merchant, MaxBalance: 50000},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		wallet Wallet
+		want   int64
+	}{
+		{"level_specific_wins", Wallet{AcntType: User, KYCLevel: KYCNone}, 500},
+		{"unset_level_is_none", Wallet{AcntType: User}, 500},
+		{"account_type_fallback", Wallet{AcntType: User, KYCLevel: KYCFull}, 10000},
+		{"merchant", Wallet{AcntType: Merchant}, 50000},
+		{"no_limit", Wallet{AcntType: Provider}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, limits.MaxBalance(&tt.wallet))
+		})
+	}
+
+	limits.Enable = false
+	assert.Zero(t, limits.MaxBalance(&Wallet{AcntType: User}), "disabled limits cap nothing")
+}
+
+func TestBalanceLimits_CeilingError(t *testing.T) {
+	limits := BalanceLimits{
+		Enable: true,
+		Limits: []BalanceLimit{
+			{AcntType: User, MaxBalance: 10000},
+			{AcntType: User, KYCLevel: KYCNone, MaxBalance: 500},
+			{AcntType: User, KYCLevel: KYCFull, MaxBalance: 50000},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		wallet Wallet
+		want   error
+	}{
+		{"level_ceiling", Wallet{AcntType: User, KYCLevel: KYCNone}, ErrKYCUpgradeRequired},
+		{"unset_level_is_none", Wallet{AcntType: User}, ErrKYCUpgradeRequired},
+		{"account_type_ceiling", Wallet{AcntType: User, KYCLevel: KYCBasic}, ErrBalanceLimitExceeded},
+		{"highest_level", Wallet{AcntType: User, KYCLevel: KYCFull}, ErrBalanceLimitExceeded},
+		{"merchant", Wallet{AcntType: Merchant}, ErrBalanceLimitExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, limits.CeilingError(&tt.wallet))
+		})
+	}
+}
+
+func TestAdminServer_TokenError(t *testing.T) {
+	env := map[string]string{
+		"TOKEN_OPS_1": "ops-1-token-0123456789",
+		"TOKEN_OPS_2": "ops-2-token-0123456789",
+		"TOKEN_SHORT": "short",
+		"TOKEN_DEV":   "dev-ops-1-token-change-me",
+	}
+	getenv := func(key string) string { return env[key] }
+
+	tests := []struct {
+		name    string
+		envs    []string
+		wantErr bool
+	}{
+		{"all_set", []string{"TOKEN_OPS_1", "TOKEN_OPS_2"}, false},
+		{"unset", []string{"TOKEN_OPS_1", "TOKEN_MISSING"}, true},
+		{"too_short", []string{"TOKEN_SHORT"}, true},
+		{"placeholder", []string{"TOKEN_OPS_1", "TOKEN_DEV"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin := AdminServer{Enable: true}
+			for i, key := range tt.envs {
+				admin.Operators = append(admin.Operators, Operator{Name: fmt.Sprintf("ops-%d", i+1), TokenEnv: key})
+			}
+			admin.LoadTokens(getenv)
+
+			assert.Equal(t, env[tt.envs[0]], admin.Operators[0].Token)
+			if tt.wantErr {
+				assert.Error(t, admin.TokenError())
+			} else {
+				assert.NoError(t, admin.TokenError())
+			}
+		})
+	}
+}
+
+func TestDormancy_NextRun(t *testing.T) {
+	day := func(hour, minute int) time.Time { return time.Date(2025, time.March, 10, hour, minute, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		runAt string
+		now   time.Time
+		want  time.Time
+	}{
+		{"later_today", "02:00", day(1, 30), day(2, 0)},
+		{"passed_today", "02:00", day(2, 0), day(2, 0).AddDate(0, 0, 1)},
+		{"other_time_zone", "02:00", day(1, 30).In(time.FixedZone("UTC+3", 3*3600)), day(2, 0)},
+		{"invalid_falls_back_to_default", "25:00", day(1, 0), day(2, 0)},
+		{"minutes", "23:45", day(23, 0), day(23, 45)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Dormancy{RunAt: tt.runAt}.NextRun(tt.now))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// Wallet events published by the notifier.
+const (
+	// WalletEventDormant is published when a wallet without activity is marked inactive.
+	WalletEventDormant = "wallet.dormant"
+	// WalletEventReactivated is published when a deposit makes an inactive wallet active again.
+	WalletEventReactivated = "wallet.reactivated"
+)
+
+// WalletEvent is published when the status of a wallet changes without its
+// holder asking for it.
+type WalletEvent struct {
+	Event          string     `json:"event"`
+	UserID         string     `json:"user_id"`
+	Status         Status     `json:"status"`
+	LastActivityAt *time.Time `json:"last_activity_at,omitempty"` // Latest activity of a dormant wallet; nil if it never had any
+	// Charged is the dormancy fee or escheated balance in cents taken from a
+	// dormant wallet, credited to ChargedTo
+	Charged    int64     `json:"charged,omitempty"`
+	ChargedTo  string    `json:"charged_to,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// IsDormant reports whether a wallet whose latest activity, or creation if
+// it never had any, was at lastActivity has been idle for period at now.
+func IsDormant(lastActivity time.Time, period time.Duration, now time.Time) bool {
+	return !lastActivity.After(now.Add(-period))
+}
+
+// DormancyCharges returns the debits taken from a dormant wallet and its
+// pockets under the configured action, and the type of their ledger entries.
+// The fee is taken from the wallet's own balance and capped at it;
+// escheatment takes every balance. wallets[0] is the wallet itself.
+func DormancyCharges(cfg Dormancy, wallets []Wallet) ([]BalanceChange, TransactionType) {
+	var changes []BalanceChange
+	switch cfg.Action {
+	case DormancyActionFee:
+		if fee := min(cfg.Fee, wallets[0].Balance); fee > 0 {
+			changes = append(changes, BalanceChange{WalletID: wallets[0].ID, Amount: -fee})
+		}
+		return changes, DormancyFee
+	case DormancyActionEscheat:
+		for _, w := range wallets {
+			if w.Balance > 0 {
+				changes = append(changes, BalanceChange{WalletID: w.ID, Amount: -w.Balance})
+			}
+		}
+		return changes, Escheatment
+	}
+	return nil, ""
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDormant(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	period := 30 * 24 * time.Hour
+
+	assert.True(t, IsDormant(now.Add(-period), period, now), "idle for exactly the period")
+	assert.True(t, IsDormant(now.AddDate(-1, 0, 0), period, now))
+	assert.False(t, IsDormant(now.Add(-period+time.Second), period, now))
+}
+
+func TestDormancyCharges(t *testing.T) {
+	wallets := []Wallet{{ID: 1, Balance: 300}, {ID: 2, Balance: 1000}, {ID: 3}}
+
+	tests := []struct {
+		name     string
+		cfg      Dormancy
+		want     []BalanceChange
+		wantType TransactionType
+	}{
+		{name: "none", cfg: Dormancy{Action: DormancyActionNone, Fee: 500}},
+		{name: "fee", cfg: Dormancy{Action: DormancyActionFee, Fee: 200}, want: []BalanceChange{{WalletID: 1, Amount: -200}}, wantType: DormancyFee},
+		{name: "fee_capped_at_own_balance", cfg: Dormancy{Action: DormancyActionFee, Fee: 500}, want: []BalanceChange{{WalletID: 1, Amount: -300}}, wantType: DormancyFee},
+		{name: "escheat_pockets_too", cfg: Dormancy{Action: DormancyActionEscheat}, want: []BalanceChange{{WalletID: 1, Amount: -300}, {WalletID: 2, Amount: -1000}}, wantType: Escheatment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotType := DormancyCharges(tt.cfg, wallets)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantType, gotType)
+		})
+	}
+
+	got, _ := DormancyCharges(Dormancy{Action: DormancyActionFee, Fee: 500}, []Wallet{{ID: 1}})
+	assert.Empty(t, got, "no fee on an empty wallet")
+}
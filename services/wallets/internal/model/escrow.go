@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// EscrowAgreement holds funds from a buyer in a dedicated escrow wallet until
+// they are released to the seller, refunded to the buyer or split between the
+// two. Funded escrows still open at their deadline are refunded automatically.
+// The buyer can release an escrow and the seller refund it, each giving up
+// their own claim; an optional arbiter can do either and split it.
+type EscrowAgreement struct {
+	ID             int          `gorm:"primaryKey" json:"id"`
+	WalletUserID   string       `gorm:"not null;uniqueIndex" json:"escrow_wallet_id"` // UserID of the escrow wallet holding the funds
+	BuyerID        string       `gorm:"not null;index" json:"buyer_user_id"`
+	SellerID       string       `gorm:"not null;index" json:"seller_user_id"`
+	ArbiterID      string       `gorm:"not null;default:''" json:"arbiter_user_id,omitempty"` // Settles disputes; empty if none was named
+	Amount         int64        `gorm:"not null" json:"amount"`                               // Amount in cents
+	Description    string       `json:"description,omitempty"`
+	Status         EscrowStatus `gorm:"not null;index" json:"status"`
+	Deadline       time.Time    `gorm:"not null" json:"deadline"`
+	ReleasedAmount int64        `gorm:"not null;default:0" json:"released_amount"` // Paid out to the seller
+	RefundedAmount int64        `gorm:"not null;default:0" json:"refunded_amount"` // Returned to the buyer
+	SettledAt      *time.Time   `json:"settled_at,omitempty"`
+	RefundAttempts int          `gorm:"not null;default:0" json:"refund_attempts,omitempty"` // Automatic refunds after the deadline refused by the buyer's wallet
+	RefundError    string       `gorm:"not null;default:''" json:"refund_error,omitempty"`   // Why the last automatic refund was refused
+	CreatedAt      time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// EscrowStatus is the state of an escrow.
+type EscrowStatus string
+
+const (
+	// EscrowFunded is the status of an escrow holding the buyer's funds.
+	EscrowFunded = EscrowStatus("funded")
+	// EscrowReleased is the status of an escrow paid out in full to the seller.
+	EscrowReleased = EscrowStatus("released")
+	// EscrowRefunded is the status of an escrow returned in full to the buyer.
+	EscrowRefunded = EscrowStatus("refunded")
+	// EscrowSplit is the status of an escrow divided between the seller and the buyer.
+	EscrowSplit = EscrowStatus("split")
+)
+
+// EscrowSettlementStatus returns the status of an escrow of the given amount
+// once sellerAmount of it is paid to the seller and the rest to the buyer.
+func EscrowSettlementStatus(amount, sellerAmount int64) EscrowStatus {
+	switch sellerAmount {
+	case amount:
+		return EscrowReleased
+	case 0:
+		return EscrowRefunded
+	}
+	return EscrowSplit
+}
+
+// SettlementError returns ErrNotEscrowParty unless actorID may settle the
+// escrow paying sellerAmount to the seller: in full by the buyer, nothing by
+// the seller, and any split by the arbiter.
+func (e *EscrowAgreement) SettlementError(actorID string, sellerAmount int64) error {
+	switch {
+	case e.ArbiterID != "" && actorID == e.ArbiterID:
+		return nil
+	case actorID == e.BuyerID && sellerAmount == e.Amount:
+		return nil
+	case actorID == e.SellerID && sellerAmount == 0:
+		return nil
+	}
+	return ErrNotEscrowParty
+}
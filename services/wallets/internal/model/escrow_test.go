@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscrowSettlementStatus(t *testing.T) {
+	assert.Equal(t, EscrowReleased, EscrowSettlementStatus(1000, 1000))
+	assert.Equal(t, EscrowRefunded, EscrowSettlementStatus(1000, 0))
+	assert.Equal(t, EscrowSplit, EscrowSettlementStatus(1000, 600))
+}
+
+func TestEscrowSettlementError(t *testing.T) {
+	escrow := &EscrowAgreement{BuyerID: "buyer", SellerID: "seller", ArbiterID: "arbiter", Amount: 1000}
+	assert.NoError(t, escrow.SettlementError("buyer", 1000))
+	assert.NoError(t, escrow.SettlementError("seller", 0))
+	assert.NoError(t, escrow.SettlementError("arbiter", 1000))
+	assert.NoError(t, escrow.SettlementError("arbiter", 0))
+	assert.NoError(t, escrow.SettlementError("arbiter", 600))
+	assert.Equal(t, ErrNotEscrowParty, escrow.SettlementError("buyer", 0))
+	assert.Equal(t, ErrNotEscrowParty, escrow.SettlementError("seller", 1000))
+	assert.Equal(t, ErrNotEscrowParty, escrow.SettlementError("buyer", 600))
+	assert.Equal(t, ErrNotEscrowParty, escrow.SettlementError("someone-else", 1000))
+
+	escrow.ArbiterID = ""
+	assert.Equal(t, ErrNotEscrowParty, escrow.SettlementError("", 600))
+}
@@ -0,0 +1,115 @@
+package model
+
+import "time"
+
+// KYCLevel is how far the holder of a wallet has been verified. It sets the
+// limits of the wallet; see KYC.
+type KYCLevel string
+
+const (
+	// KYCNone is the level of a wallet whose holder has not been verified.
+	KYCNone = KYCLevel("none")
+	// KYCBasic is the level of a holder verified with an identity document.
+	KYCBasic = KYCLevel("basic")
+	// KYCFull is the level of a holder whose identity and address are verified.
+	KYCFull = KYCLevel("full")
+)
+
+// kycRanks orders the KYC levels from least to most verified.
+var kycRanks = map[KYCLevel]int{
+	KYCNone:  0,
+	KYCBasic: 1,
+	KYCFull:  2,
+}
+
+// IsValidKYCLevel checks if the KYC level is one of none, basic or full.
+func IsValidKYCLevel(level KYCLevel) bool {
+	_, ok := kycRanks[level]
+	return ok
+}
+
+// Above reports whether the level is more verified than other. An unset
+// level counts as none.
+func (l KYCLevel) Above(other KYCLevel) bool {
+	return kycRanks[l] > kycRanks[other]
+}
+
+// KYCVerification is a request by the holder of a user wallet to be verified
+// to a KYC level, with the metadata of the documents supporting it. A
+// reviewer approves or rejects it; approval raises the wallet to the level.
+type KYCVerification struct {
+	ID         int           `gorm:"primaryKey" json:"id"`
+	WalletID   int           `gorm:"not null;index" json:"-"`
+	UserID     string        `gorm:"not null" json:"user_id"`
+	Level      KYCLevel      `gorm:"not null" json:"level"` // Level applied for
+	Documents  []KYCDocument `gorm:"type:text;serializer:json" json:"documents"`
+	Status     KYCStatus     `gorm:"not null;index" json:"status"`
+	Reviewer   string        `json:"reviewer,omitempty"`
+	ReviewNote string        `json:"review_note,omitempty"`
+	ReviewedAt *time.Time    `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// KYCDocument is the metadata of a document submitted for verification. The
+// document itself is kept by the verification provider, not by the wallet service.
+type KYCDocument struct {
+	Type      string     `json:"type" validate:"required,oneof=passport national_id drivers_license proof_of_address"`
+	Number    string     `json:"number" validate:"required,max=64"`
+	Country   string     `json:"country" validate:"required,iso3166_1_alpha2"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// KYCStatus is the review state of a KYC verification.
+type KYCStatus string
+
+const (
+	// KYCPending is the status of a verification awaiting review.
+	KYCPending = KYCStatus("pending")
+	// KYCApproved is the status of a verification that raised the wallet's level.
+	KYCApproved = KYCStatus("approved")
+	// KYCRejected is the status of a verification turned down by the reviewer.
+	KYCRejected = KYCStatus("rejected")
+)
+
+// KYCProfile is the KYC level of a wallet, the limits it sets and the
+// verifications submitted for the wallet, newest first.
+type KYCProfile struct {
+	UserID        string            `json:"user_id"`
+	Level         KYCLevel          `json:"level"`
+	Limits        KYCLimits         `json:"limits"`
+	Verifications []KYCVerification `json:"verifications"`
+}
+
+// KYCLimits is what a wallet at its KYC level may do, with the ceiling on the
+// balance of the wallet and its pockets together, 0 if it has none.
+type KYCLimits struct {
+	KYCTier
+	MaxBalance int64 `json:"max_balance"`
+}
+
+// KYCLevelOf returns the KYC level of the wallet, none if it was never set.
+func (w *Wallet) KYCLevelOf() KYCLevel {
+	if w.KYCLevel == "" {
+		return KYCNone
+	}
+	return w.KYCLevel
+}
+
+// TransferError returns ErrKYCUpgradeRequired if a single transfer of amount
+// cents is above the tier's maximum, nil otherwise.
+func (t KYCTier) TransferError(amount int64) error {
+	if t.MaxTransfer > 0 && amount > t.MaxTransfer {
+		return ErrKYCUpgradeRequired
+	}
+	return nil
+}
+
+// WithdrawError returns ErrKYCUpgradeRequired if the tier does not allow
+// withdrawals, nil otherwise.
+func (t KYCTier) WithdrawError() error {
+	if !t.Withdrawals {
+		return ErrKYCUpgradeRequired
+	}
+	return nil
+}
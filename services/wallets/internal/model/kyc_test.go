@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKYCLevel_Above(t *testing.T) {
+	assert.True(t, KYCBasic.Above(KYCNone))
+	assert.True(t, KYCFull.Above(KYCBasic))
+	assert.True(t, KYCBasic.Above(""), "an unset level counts as none")
+	assert.False(t, KYCBasic.Above(KYCBasic))
+	assert.False(t, KYCNone.Above(KYCFull))
+	assert.False(t, IsValidKYCLevel("gold"))
+}
+
+func TestKYC_Tier(t *testing.T) {
+	kyc := DefaultKYC()
+	assert.Equal(t, kyc.Basic, kyc.Tier(KYCBasic))
+	assert.Equal(t, kyc.Full, kyc.Tier(KYCFull))
+	assert.Equal(t, kyc.None, kyc.Tier(""))
+	assert.Equal(t, KYCNone, (&Wallet{}).KYCLevelOf())
+}
+
+func TestKYCTier_Errors(t *testing.T) {
+	tier := KYCTier{MaxTransfer: 500}
+
+	assert.NoError(t, tier.TransferError(500))
+	assert.Equal(t, ErrKYCUpgradeRequired, tier.TransferError(501))
+	assert.Equal(t, ErrKYCUpgradeRequired, tier.WithdrawError())
+
+	unlimited := KYCTier{Withdrawals: true}
+	assert.NoError(t, unlimited.TransferError(1<<40))
+	assert.NoError(t, unlimited.WithdrawError())
+}
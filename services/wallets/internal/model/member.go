@@ -0,0 +1,133 @@
+package model
+
+import "time"
+
+// WalletMember is a user, other than the holder, authorized on a shared
+// wallet. The holder of the wallet, its UserID, is always an owner and is not
+// listed as a member.
+type WalletMember struct {
+	ID       int        `gorm:"primaryKey" json:"id"`
+	WalletID int        `gorm:"not null;uniqueIndex:idx_wallet_members_wallet_member" json:"-"`
+	MemberID string     `gorm:"not null;uniqueIndex:idx_wallet_members_wallet_member;index" json:"member_user_id"`
+	Role     MemberRole `gorm:"not null" json:"role"`
+	// SpendLimit is the most a spender may transfer and withdraw in total per
+	// spend window, in cents; a spender without one must be UnlimitedSpend
+	SpendLimit     int64       `gorm:"not null;default:0" json:"spend_limit,omitempty"`
+	UnlimitedSpend bool        `gorm:"not null;default:false" json:"unlimited_spend,omitempty"`
+	SpendWindow    SpendWindow `gorm:"not null;default:'day'" json:"spend_window,omitempty"`
+	// WindowSpent is what the member spent in the window starting at WindowStart
+	WindowSpent int64      `gorm:"not null;default:0" json:"window_spent"`
+	WindowStart *time.Time `json:"window_start,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// MemberRole is what a member may do with a shared wallet.
+type MemberRole string
+
+const (
+	// MemberOwner may spend without limit, manage members and approve
+	// transfers and withdrawals above the wallet's approval threshold.
+	MemberOwner = MemberRole("owner")
+	// MemberSpender may transfer and withdraw up to its spend limit per window.
+	MemberSpender = MemberRole("spender")
+	// MemberViewer may only view the wallet.
+	MemberViewer = MemberRole("viewer")
+)
+
+// SpendWindow is the calendar period, in UTC, over which the spending of a
+// member is added up against its spend limit.
+type SpendWindow string
+
+const (
+	// SpendDaily limits spending per calendar day.
+	SpendDaily = SpendWindow("day")
+	// SpendWeekly limits spending per ISO week, starting on Monday.
+	SpendWeekly = SpendWindow("week")
+	// SpendMonthly limits spending per calendar month.
+	SpendMonthly = SpendWindow("month")
+)
+
+// IsValid reports whether the window is one of the known windows.
+func (w SpendWindow) IsValid() bool {
+	return w == SpendDaily || w == SpendWeekly || w == SpendMonthly
+}
+
+// Start returns the start of the window containing now.
+func (w SpendWindow) Start(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	switch w {
+	case SpendWeekly:
+		weekday := (int(now.UTC().Weekday()) + 6) % 7 // Days since Monday
+		return time.Date(year, month, day-weekday, 0, 0, 0, 0, time.UTC)
+	case SpendMonthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// IsValid reports whether the role is one of the known roles.
+func (r MemberRole) IsValid() bool {
+	return r == MemberOwner || r == MemberSpender || r == MemberViewer
+}
+
+// CanSpend reports whether members of the role may transfer and withdraw.
+func (r MemberRole) CanSpend() bool {
+	return r == MemberOwner || r == MemberSpender
+}
+
+// CanApprove reports whether members of the role may approve transfers and
+// withdrawals held for approval.
+func (r MemberRole) CanApprove() bool {
+	return r == MemberOwner
+}
+
+// LimitError returns ErrInvalidSpendLimit unless a spender has either a
+// positive spend limit or unlimited spending, and a known spend window.
+func (m *WalletMember) LimitError() error {
+	if m.Role != MemberSpender {
+		return nil
+	}
+	if m.SpendLimit < 0 || (m.SpendLimit > 0) == m.UnlimitedSpend || !m.SpendWindow.IsValid() {
+		return ErrInvalidSpendLimit
+	}
+	return nil
+}
+
+// SpendError returns the error for the member moving amount cents out of the
+// wallet at now, or nil if its role and the rest of its spend limit in the
+// current window allow it.
+func (m *WalletMember) SpendError(amount int64, now time.Time) error {
+	if !m.Role.CanSpend() {
+		return ErrMemberForbidden
+	}
+	if m.Role == MemberSpender && !m.UnlimitedSpend && m.SpentIn(now)+amount > m.SpendLimit {
+		return ErrSpendLimitExceeded
+	}
+	return nil
+}
+
+// SpentIn returns what the member has spent in the window containing now.
+func (m *WalletMember) SpentIn(now time.Time) int64 {
+	if m.WindowStart == nil || !m.WindowStart.Equal(m.SpendWindow.Start(now)) {
+		return 0
+	}
+	return m.WindowSpent
+}
+
+// Spend checks the member may move amount cents out of the wallet at now, as
+// SpendError does, and adds it to what it spent in the current window.
+func (m *WalletMember) Spend(amount int64, now time.Time) error {
+	if err := m.SpendError(amount, now); err != nil {
+		return err
+	}
+	start := m.SpendWindow.Start(now)
+	m.WindowSpent, m.WindowStart = m.SpentIn(now)+amount, &start
+	return nil
+}
+
+// NeedsApproval reports whether moving amount cents out of the wallet must
+// first be approved by its owners.
+func (w *Wallet) NeedsApproval(amount int64) bool {
+	return w.RequiredApprovals > 0 && amount > w.ApprovalThreshold
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletMember_SpendError(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	today, yesterday := SpendDaily.Start(now), SpendDaily.Start(now.AddDate(0, 0, -1))
+	tests := []struct {
+		name   string
+		member WalletMember
+		amount int64
+		want   error
+	}{
+		{"Owner", WalletMember{Role: MemberOwner, SpendLimit: 100}, 5000, nil},
+		{"Spender_within_limit", WalletMember{Role: MemberSpender, SpendLimit: 5000}, 5000, nil},
+		{"Spender_over_limit", WalletMember{Role: MemberSpender, SpendLimit: 5000}, 5001, ErrSpendLimitExceeded},
+		{"Spender_over_rest_of_window", WalletMember{Role: MemberSpender, SpendLimit: 5000, SpendWindow: SpendDaily, WindowSpent: 4000, WindowStart: &today}, 1001, ErrSpendLimitExceeded},
+		{"Spender_new_window", WalletMember{Role: MemberSpender, SpendLimit: 5000, SpendWindow: SpendDaily, WindowSpent: 5000, WindowStart: &yesterday}, 5000, nil},
+		{"Spender_without_limit", WalletMember{Role: MemberSpender}, 1, ErrSpendLimitExceeded},
+		{"Spender_unlimited", WalletMember{Role: MemberSpender, UnlimitedSpend: true}, 1000000, nil},
+		{"Viewer", WalletMember{Role: MemberViewer}, 1, ErrMemberForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.member.SpendError(tt.amount, now))
+		})
+	}
+}
+
+func TestWalletMember_Spend(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	member := WalletMember{Role: MemberSpender, SpendLimit: 5000, SpendWindow: SpendWeekly}
+
+	assert.NoError(t, member.Spend(3000, now))
+	assert.NoError(t, member.Spend(2000, now.Add(24*time.Hour)))
+	assert.Equal(t, ErrSpendLimitExceeded, member.Spend(1, now.Add(48*time.Hour)))
+	assert.Equal(t, int64(5000), member.WindowSpent)
+
+	// The next week starts on Monday
+	assert.NoError(t, member.Spend(1, time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, int64(1), member.WindowSpent)
+}
+
+func TestSpendWindow_Start(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 30, 0, 0, time.UTC) // A Wednesday
+	assert.Equal(t, time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC), SpendDaily.Start(now))
+	assert.Equal(t, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), SpendWeekly.Start(now))
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), SpendMonthly.Start(now))
+	assert.Equal(t, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), SpendWeekly.Start(time.Date(2024, 5, 19, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestWalletMember_LimitError(t *testing.T) {
+	assert.NoError(t, (&WalletMember{Role: MemberSpender, SpendLimit: 5000, SpendWindow: SpendDaily}).LimitError())
+	assert.NoError(t, (&WalletMember{Role: MemberSpender, UnlimitedSpend: true, SpendWindow: SpendDaily}).LimitError())
+	assert.NoError(t, (&WalletMember{Role: MemberViewer}).LimitError())
+	assert.Equal(t, ErrInvalidSpendLimit, (&WalletMember{Role: MemberSpender, SpendWindow: SpendDaily}).LimitError())
+	assert.Equal(t, ErrInvalidSpendLimit, (&WalletMember{Role: MemberSpender, SpendLimit: 5000, UnlimitedSpend: true, SpendWindow: SpendDaily}).LimitError())
+	assert.Equal(t, ErrInvalidSpendLimit, (&WalletMember{Role: MemberSpender, SpendLimit: 5000, SpendWindow: "year"}).LimitError())
+}
+
+func TestWallet_NeedsApproval(t *testing.T) {
+	assert.False(t, (&Wallet{ApprovalThreshold: 0}).NeedsApproval(1000000), "approvals off")
+	assert.False(t, (&Wallet{ApprovalThreshold: 5000, RequiredApprovals: 2}).NeedsApproval(5000))
+	assert.True(t, (&Wallet{ApprovalThreshold: 5000, RequiredApprovals: 2}).NeedsApproval(5001))
+	assert.True(t, (&Wallet{RequiredApprovals: 1}).NeedsApproval(1))
+}
+
+func TestApprovalStatus_CanTransition(t *testing.T) {
+	assert.True(t, ApprovalPending.CanTransition(ApprovalExecuted))
+	assert.True(t, ApprovalPending.CanTransition(ApprovalRejected))
+	assert.True(t, ApprovalPending.CanTransition(ApprovalFailed))
+	for _, final := range []ApprovalStatus{ApprovalExecuted, ApprovalRejected, ApprovalFailed} {
+		for _, to := range []ApprovalStatus{ApprovalPending, ApprovalExecuted, ApprovalRejected, ApprovalFailed} {
+			assert.False(t, final.CanTransition(to), "%s -> %s", final, to)
+		}
+	}
+}
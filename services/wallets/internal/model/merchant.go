@@ -0,0 +1,150 @@
+package model
+
+import "time"
+
+// MerchantProfile describes the business behind a merchant wallet.
+type MerchantProfile struct {
+	ID           int       `gorm:"primaryKey" json:"id"`
+	WalletID     int       `gorm:"not null;uniqueIndex" json:"-"`
+	UserID       string    `gorm:"not null;uniqueIndex" json:"user_id"` // UserID of the merchant wallet
+	DisplayName  string    `gorm:"not null" json:"display_name"`
+	Category     string    `json:"category,omitempty"`
+	Website      string    `json:"website,omitempty"`
+	SupportEmail string    `json:"support_email,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// PaymentIntent is a merchant's request to be paid an amount for an order,
+// identified by the merchant's reference. A customer confirms it from their
+// wallet, after which the merchant may refund up to the amount paid.
+type PaymentIntent struct {
+	ID             int                 `gorm:"primaryKey" json:"id"`
+	MerchantID     string              `gorm:"not null;uniqueIndex:idx_payment_intents_merchant_reference" json:"merchant_user_id"`
+	Reference      string              `gorm:"not null;uniqueIndex:idx_payment_intents_merchant_reference" json:"reference"` // Merchant's order reference, unique per merchant
+	Amount         int64               `gorm:"not null" json:"amount"`                                                       // Amount in cents
+	Description    string              `json:"description,omitempty"`
+	Status         PaymentIntentStatus `gorm:"not null;index" json:"status"`
+	CustomerID     string              `gorm:"index" json:"customer_user_id,omitempty"` // Set when confirmed
+	RefundedAmount int64               `gorm:"not null;default:0" json:"refunded_amount"`
+	PaidAt         *time.Time          `gorm:"index" json:"paid_at,omitempty"`
+	CancelledAt    *time.Time          `json:"cancelled_at,omitempty"`
+	CreatedAt      time.Time           `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// PaymentIntentStatus is the state of a payment intent.
+type PaymentIntentStatus string
+
+const (
+	// IntentPending is the status of an intent awaiting the customer's confirmation.
+	IntentPending = PaymentIntentStatus("pending")
+	// IntentSucceeded is the status of an intent paid by a customer. It stays
+	// succeeded when refunded; RefundedAmount tells how much was returned.
+	IntentSucceeded = PaymentIntentStatus("succeeded")
+	// IntentCancelled is the status of an intent withdrawn by the merchant before payment.
+	IntentCancelled = PaymentIntentStatus("cancelled")
+)
+
+// paymentIntentTransitions lists the states each state can move to.
+var paymentIntentTransitions = map[PaymentIntentStatus][]PaymentIntentStatus{
+	IntentPending: {IntentSucceeded, IntentCancelled},
+}
+
+// CanTransition reports whether a payment intent may move from one state to another.
+func (s PaymentIntentStatus) CanTransition(to PaymentIntentStatus) bool {
+	for _, next := range paymentIntentTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Refundable returns the part of a paid intent not refunded yet, in cents.
+func (p *PaymentIntent) Refundable() int64 {
+	if p.Status != IntentSucceeded {
+		return 0
+	}
+	return p.Amount - p.RefundedAmount
+}
+
+// PaymentRefund is a merchant returning part or all of a paid intent to its customer.
+type PaymentRefund struct {
+	ID              int       `gorm:"primaryKey" json:"id"`
+	PaymentIntentID int       `gorm:"not null;index" json:"payment_intent_id"`
+	MerchantID      string    `gorm:"not null;index:idx_payment_refunds_merchant_created_at" json:"merchant_user_id"`
+	CustomerID      string    `gorm:"not null" json:"customer_user_id"`
+	Amount          int64     `gorm:"not null" json:"amount"` // Amount in cents
+	Reason          string    `json:"reason,omitempty"`
+	CreatedAt       time.Time `gorm:"autoCreateTime;index:idx_payment_refunds_merchant_created_at" json:"created_at"`
+}
+
+// SettlementReport lists the payments and refunds of a merchant for every
+// UTC calendar day of a range, with their totals.
+type SettlementReport struct {
+	MerchantID     string          `json:"merchant_user_id"`
+	From           string          `json:"from"` // YYYY-MM-DD
+	To             string          `json:"to"`   // YYYY-MM-DD
+	Days           []SettlementDay `json:"days"`
+	PaymentsAmount int64           `json:"payments_amount"` // Paid to the merchant in cents
+	RefundsAmount  int64           `json:"refunds_amount"`  // Refunded by the merchant in cents
+	NetAmount      int64           `json:"net_amount"`      // Payments less refunds in cents
+}
+
+// SettlementDay is the payments and refunds of a merchant on one UTC calendar day.
+type SettlementDay struct {
+	Date           string          `json:"date"` // YYYY-MM-DD
+	Payments       []PaymentIntent `json:"payments"`
+	Refunds        []PaymentRefund `json:"refunds"`
+	PaymentsAmount int64           `json:"payments_amount"`
+	RefundsAmount  int64           `json:"refunds_amount"`
+	NetAmount      int64           `json:"net_amount"`
+}
+
+// NewSettlementReport groups the payments, by the day they were paid, and
+// the refunds, by the day they were made, into every day from from to to.
+// Payments and refunds outside the range are ignored.
+func NewSettlementReport(merchantID string, from, to time.Time, payments []PaymentIntent, refunds []PaymentRefund) *SettlementReport {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	report := &SettlementReport{
+		MerchantID: merchantID,
+		From:       from.Format(DateLayout),
+		To:         to.Format(DateLayout),
+	}
+	index := make(map[string]int)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(DateLayout)
+		index[date] = len(report.Days)
+		report.Days = append(report.Days, SettlementDay{Date: date, Payments: []PaymentIntent{}, Refunds: []PaymentRefund{}})
+	}
+
+	for _, payment := range payments {
+		if payment.PaidAt == nil {
+			continue
+		}
+		i, ok := index[payment.PaidAt.UTC().Format(DateLayout)]
+		if !ok {
+			continue
+		}
+		report.Days[i].Payments = append(report.Days[i].Payments, payment)
+		report.Days[i].PaymentsAmount += payment.Amount
+	}
+	for _, refund := range refunds {
+		i, ok := index[refund.CreatedAt.UTC().Format(DateLayout)]
+		if !ok {
+			continue
+		}
+		report.Days[i].Refunds = append(report.Days[i].Refunds, refund)
+		report.Days[i].RefundsAmount += refund.Amount
+	}
+
+	for i := range report.Days {
+		day := &report.Days[i]
+		day.NetAmount = day.PaymentsAmount - day.RefundsAmount
+		report.PaymentsAmount += day.PaymentsAmount
+		report.RefundsAmount += day.RefundsAmount
+	}
+	report.NetAmount = report.PaymentsAmount - report.RefundsAmount
+	return report
+}
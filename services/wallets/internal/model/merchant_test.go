@@ -0,0 +1,65 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentIntentStatus_CanTransition(t *testing.T) {
+	assert.True(t, IntentPending.CanTransition(IntentSucceeded))
+	assert.True(t, IntentPending.CanTransition(IntentCancelled))
+	assert.False(t, IntentSucceeded.CanTransition(IntentCancelled))
+	assert.False(t, IntentCancelled.CanTransition(IntentSucceeded))
+	assert.False(t, IntentSucceeded.CanTransition(IntentSucceeded))
+}
+
+func TestPaymentIntent_Refundable(t *testing.T) {
+	assert.Equal(t, int64(0), (&PaymentIntent{Status: IntentPending, Amount: 5000}).Refundable())
+	assert.Equal(t, int64(0), (&PaymentIntent{Status: IntentCancelled, Amount: 5000}).Refundable())
+	assert.Equal(t, int64(5000), (&PaymentIntent{Status: IntentSucceeded, Amount: 5000}).Refundable())
+	assert.Equal(t, int64(1500), (&PaymentIntent{Status: IntentSucceeded, Amount: 5000, RefundedAmount: 3500}).Refundable())
+}
+
+func TestNewSettlementReport(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	paid := func(d, h int) *time.Time { at := day(d, h); return &at }
+
+	payments := []PaymentIntent{
+		{ID: 1, Amount: 5000, PaidAt: paid(1, 9)},
+		{ID: 2, Amount: 2000, PaidAt: paid(1, 23)},
+		{ID: 3, Amount: 1000, PaidAt: paid(3, 0)},
+		{ID: 4, Amount: 9999, PaidAt: paid(4, 0)}, // After the range
+		{ID: 5, Amount: 9999},                     // Never paid
+	}
+	refunds := []PaymentRefund{
+		{ID: 1, PaymentIntentID: 1, Amount: 500, CreatedAt: day(3, 12)},
+		{ID: 2, PaymentIntentID: 1, Amount: 9999, CreatedAt: day(1, 0).Add(-time.Hour)}, // Before the range
+	}
+
+	report := NewSettlementReport("shop-1", day(1, 0), day(3, 0), payments, refunds)
+	assert.Equal(t, "shop-1", report.MerchantID)
+	assert.Equal(t, "2026-03-01", report.From)
+	assert.Equal(t, "2026-03-03", report.To)
+	require.Len(t, report.Days, 3)
+
+	assert.Equal(t, "2026-03-01", report.Days[0].Date)
+	assert.Len(t, report.Days[0].Payments, 2)
+	assert.Equal(t, int64(7000), report.Days[0].PaymentsAmount)
+	assert.Equal(t, int64(7000), report.Days[0].NetAmount)
+
+	assert.Equal(t, "2026-03-02", report.Days[1].Date)
+	assert.Empty(t, report.Days[1].Payments)
+	assert.NotNil(t, report.Days[1].Refunds, "empty days list no refunds rather than null")
+	assert.Equal(t, int64(0), report.Days[1].NetAmount)
+
+	assert.Len(t, report.Days[2].Payments, 1)
+	assert.Len(t, report.Days[2].Refunds, 1)
+	assert.Equal(t, int64(500), report.Days[2].NetAmount)
+
+	assert.Equal(t, int64(8000), report.PaymentsAmount)
+	assert.Equal(t, int64(500), report.RefundsAmount)
+	assert.Equal(t, int64(7500), report.NetAmount)
+}
@@ -0,0 +1,80 @@
+package model
+
+import "time"
+
+// PaymentRequest is a request from one user (the requester) for another user
+// (the payer) to send them money. Accepting it transfers the amount from the
+// payer's wallet to the requester's.
+type PaymentRequest struct {
+	ID          int                  `gorm:"primaryKey" json:"id"`
+	RequesterID string               `gorm:"not null;index" json:"requester_user_id"`
+	PayerID     string               `gorm:"not null;index" json:"payer_user_id"`
+	Amount      int64                `gorm:"not null" json:"amount"` // Amount in cents
+	Note        string               `json:"note,omitempty"`
+	Status      PaymentRequestStatus `gorm:"not null" json:"status"`
+	ExpiresAt   time.Time            `gorm:"not null" json:"expires_at"`
+	ResolvedAt  *time.Time           `json:"resolved_at,omitempty"` // When the request left the pending state
+	CreatedAt   time.Time            `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time            `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// PaymentRequestStatus is the state of a payment request.
+type PaymentRequestStatus string
+
+const (
+	// RequestPending is the status of a request awaiting the payer's answer.
+	RequestPending = PaymentRequestStatus("pending")
+	// RequestAccepted is the status of a request the payer paid.
+	RequestAccepted = PaymentRequestStatus("accepted")
+	// RequestDeclined is the status of a request the payer refused.
+	RequestDeclined = PaymentRequestStatus("declined")
+	// RequestCancelled is the status of a request withdrawn by the requester.
+	RequestCancelled = PaymentRequestStatus("cancelled")
+	// RequestExpired is the status of a request left unanswered until its expiry.
+	RequestExpired = PaymentRequestStatus("expired")
+)
+
+// paymentRequestTransitions lists the states each state can move to. Only
+// pending requests change state; every other state is final.
+var paymentRequestTransitions = map[PaymentRequestStatus][]PaymentRequestStatus{
+	RequestPending: {RequestAccepted, RequestDeclined, RequestCancelled, RequestExpired},
+}
+
+// CanTransition reports whether a payment request may move from one state to another.
+func (s PaymentRequestStatus) CanTransition(to PaymentRequestStatus) bool {
+	for _, next := range paymentRequestTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// PaymentRequestFilter selects the payment requests of one user.
+type PaymentRequestFilter struct {
+	UserID string
+	// Incoming selects requests the user is asked to pay, otherwise the
+	// requests the user sent.
+	Incoming bool
+	// Status restricts the result to one state; empty means all.
+	Status PaymentRequestStatus
+}
+
+// PaymentRequestEvent is published to the requester and the payer whenever
+// a payment request is created or changes state.
+type PaymentRequestEvent struct {
+	Event          string         `json:"event"`
+	PaymentRequest PaymentRequest `json:"payment_request"`
+	OccurredAt     time.Time      `json:"occurred_at"`
+}
+
+// NewPaymentRequestEvent returns the event for the current state of a request:
+// "payment_request.created" for a new request, otherwise
+// "payment_request.<status>".
+func NewPaymentRequestEvent(request PaymentRequest) PaymentRequestEvent {
+	event := "payment_request." + string(request.Status)
+	if request.Status == RequestPending {
+		event = "payment_request.created"
+	}
+	return PaymentRequestEvent{Event: event, PaymentRequest: request, OccurredAt: time.Now().UTC()}
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentRequestStatus_CanTransition(t *testing.T) {
+	final := []PaymentRequestStatus{RequestAccepted, RequestDeclined, RequestCancelled, RequestExpired}
+	for _, to := range final {
+		assert.True(t, RequestPending.CanTransition(to), "pending -> %s", to)
+		for _, from := range final {
+			assert.False(t, from.CanTransition(to), "%s -> %s", from, to)
+		}
+		assert.False(t, to.CanTransition(RequestPending), "%s -> pending", to)
+	}
+}
+
+func TestNewPaymentRequestEvent(t *testing.T) {
+	assert.Equal(t, "payment_request.created", NewPaymentRequestEvent(PaymentRequest{Status: RequestPending}).Event)
+	assert.Equal(t, "payment_request.declined", NewPaymentRequestEvent(PaymentRequest{Status: RequestDeclined}).Event)
+}
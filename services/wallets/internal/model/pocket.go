@@ -0,0 +1,43 @@
+package model
+
+import (
+	"crypto/rand"
+	"regexp"
+	"strings"
+)
+
+// MainPocket is the reserved name of a user's primary wallet when moving
+// funds between it and its pockets.
+const MainPocket = "main"
+
+var pocketNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,30}$`)
+
+// ParsePocketName normalizes a pocket name to lowercase and validates it.
+// Names are 1 to 30 letters, digits, dashes or underscores; MainPocket is
+// reserved for the primary wallet.
+func ParsePocketName(raw string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	if !pocketNamePattern.MatchString(name) || name == MainPocket {
+		return "", ErrInvalidPocketName
+	}
+	return name, nil
+}
+
+// NewPocket returns a new, empty pocket of the owner's wallet. Pockets get a
+// generated user ID of their own, so their balance and history are kept
+// apart from the owner's while the owner's user ID stays unique.
+func NewPocket(owner *Wallet, name string) *Wallet {
+	pocket := NewWallet("pocket-"+strings.ToLower(rand.Text()), Pocket)
+	pocket.ParentID = &owner.ID
+	pocket.PocketName = name
+	return pocket
+}
+
+// TotalBalance returns the balance of the wallet plus that of its loaded pockets.
+func (w *Wallet) TotalBalance() int64 {
+	total := w.Balance
+	for _, pocket := range w.Pockets {
+		total += pocket.Balance
+	}
+	return total
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePocketName(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr error
+	}{
+		{name: "lowercased", raw: " Savings ", want: "savings"},
+		{name: "dashes_and_digits", raw: "trip-2025_fund", want: "trip-2025_fund"},
+		{name: "reserved", raw: "Main", wantErr: ErrInvalidPocketName},
+		{name: "space", raw: "rainy day", wantErr: ErrInvalidPocketName},
+		{name: "too_long", raw: strings.Repeat("a", 31), wantErr: ErrInvalidPocketName},
+		{name: "empty", raw: "", wantErr: ErrInvalidPocketName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePocketName(tt.raw)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewPocket(t *testing.T) {
+	owner := &Wallet{ID: 7, UserID: "user-001", AcntType: User, Balance: 1000}
+	pocket := NewPocket(owner, "savings")
+
+	assert.Equal(t, Pocket, pocket.AcntType)
+	assert.Equal(t, Active, pocket.Status)
+	assert.Equal(t, 7, *pocket.ParentID)
+	assert.Equal(t, "savings", pocket.PocketName)
+	assert.True(t, strings.HasPrefix(pocket.UserID, "pocket-"))
+	assert.NotEqual(t, pocket.UserID, NewPocket(owner, "savings").UserID)
+	assert.Equal(t, ErrPocketWallet, pocket.DirectUseError())
+	assert.NoError(t, owner.DirectUseError())
+
+	pocket.Balance = 250
+	owner.Pockets = []Wallet{*pocket, {Balance: 50}}
+	assert.Equal(t, int64(1300), owner.TotalBalance())
+}
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// ProviderProfile describes the external payment provider behind a provider
+// wallet. Balance, Status and LowFloat are read from the wallet and are not
+// stored with the profile.
+type ProviderProfile struct {
+	ID                  int         `gorm:"primaryKey" json:"id"`
+	WalletID            int         `gorm:"not null;uniqueIndex" json:"-"`
+	UserID              string      `gorm:"not null;uniqueIndex" json:"user_id"` // UserID of the provider wallet
+	DisplayName         string      `gorm:"not null;default:''" json:"display_name,omitempty"`
+	ExternalBank        string      `gorm:"not null;default:''" json:"external_bank,omitempty"`    // Bank holding the provider's funds
+	ExternalAccount     string      `gorm:"not null;default:''" json:"external_account,omitempty"` // Account at the external bank
+	FeeSchedule         FeeSchedule `gorm:"embedded;embeddedPrefix:fee_" json:"fee_schedule"`
+	SupportedCurrencies []string    `gorm:"type:text;serializer:json" json:"supported_currencies"` // ISO 4217 codes
+	LowFloatThreshold   int64       `gorm:"not null;default:0" json:"low_float_threshold"`         // In cents; 0 uses the configured default
+	CreatedAt           time.Time   `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time   `gorm:"autoUpdateTime" json:"updated_at"`
+	Balance             int64       `gorm:"-" json:"balance"` // In cents
+	Status              Status      `gorm:"-" json:"status"`
+	LowFloat            bool        `gorm:"-" json:"low_float"` // Balance is below the threshold in effect
+}
+
+// FeeSchedule is what a provider charges for moving funds in and out.
+type FeeSchedule struct {
+	DepositBps    int   `gorm:"not null;default:0" json:"deposit_bps"`    // Percentage of each deposit in basis points
+	WithdrawalBps int   `gorm:"not null;default:0" json:"withdrawal_bps"` // Percentage of each withdrawal in basis points
+	Fixed         int64 `gorm:"not null;default:0" json:"fixed"`          // Flat fee per operation in cents
+}
+
+// NewProviderProfile returns the profile of a provider wallet registered
+// without one, such as the seeded master wallets.
+func NewProviderProfile(wallet *Wallet) ProviderProfile {
+	return ProviderProfile{
+		WalletID:            wallet.ID,
+		UserID:              wallet.UserID,
+		SupportedCurrencies: []string{Currency},
+		CreatedAt:           wallet.CreatedAt,
+		UpdatedAt:           wallet.UpdatedAt,
+	}
+}
+
+// Threshold returns the low-float threshold in effect for the provider: its
+// own if set, otherwise defaultThreshold.
+func (p *ProviderProfile) Threshold(defaultThreshold int64) int64 {
+	if p.LowFloatThreshold > 0 {
+		return p.LowFloatThreshold
+	}
+	return defaultThreshold
+}
+
+// FlagLowFloat sets LowFloat if the balance is below the threshold in effect.
+// A zero threshold never flags the provider.
+func (p *ProviderProfile) FlagLowFloat(defaultThreshold int64) {
+	threshold := p.Threshold(defaultThreshold)
+	p.LowFloat = threshold > 0 && p.Balance < threshold
+}
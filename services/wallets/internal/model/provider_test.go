@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderProfile_Threshold(t *testing.T) {
+	assert.Equal(t, int64(5000), (&ProviderProfile{}).Threshold(5000))
+	assert.Equal(t, int64(200), (&ProviderProfile{LowFloatThreshold: 200}).Threshold(5000))
+}
+
+func TestProviderProfile_FlagLowFloat(t *testing.T) {
+	tests := []struct {
+		name             string
+		profile          ProviderProfile
+		defaultThreshold int64
+		want             bool
+	}{
+		{"below_default", ProviderProfile{Balance: 4999}, 5000, true},
+		{"at_default", ProviderProfile{Balance: 5000}, 5000, false},
+		{"own_threshold_wins", ProviderProfile{Balance: 4999, LowFloatThreshold: 1000}, 5000, false},
+		{"below_own_threshold", ProviderProfile{Balance: 999, LowFloatThreshold: 1000}, 0, true},
+		{"no_threshold", ProviderProfile{Balance: -1}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.profile.FlagLowFloat(tt.defaultThreshold)
+			assert.Equal(t, tt.want, tt.profile.LowFloat)
+		})
+	}
+}
+
+func TestNewProviderProfile(t *testing.T) {
+	profile := NewProviderProfile(&Wallet{ID: 3, UserID: DepositProviderID})
+	assert.Equal(t, 3, profile.WalletID)
+	assert.Equal(t, DepositProviderID, profile.UserID)
+	assert.Equal(t, []string{Currency}, profile.SupportedCurrencies)
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// SettlementBatch nets the deposits and withdrawals of one provider wallet
+// not settled yet at the cutoff of a settlement day. Deposits are funds the
+// provider collected for the platform and withdrawals funds it paid out on
+// the platform's behalf, so a positive net amount is owed by the provider to
+// the platform and a negative one by the platform to the provider.
+type SettlementBatch struct {
+	ID                int              `gorm:"primaryKey" json:"id"`
+	ProviderID        string           `gorm:"not null;uniqueIndex:idx_settlement_batches_provider_date" json:"provider_id"`
+	SettlementDate    string           `gorm:"not null;uniqueIndex:idx_settlement_batches_provider_date;index" json:"settlement_date"` // YYYY-MM-DD
+	Cutoff            time.Time        `gorm:"not null" json:"cutoff"`                                                                 // Entries created before this time are included
+	DepositCount      int              `gorm:"not null;default:0" json:"deposit_count"`
+	DepositsAmount    int64            `gorm:"not null;default:0" json:"deposits_amount"` // In cents
+	WithdrawalCount   int              `gorm:"not null;default:0" json:"withdrawal_count"`
+	WithdrawalsAmount int64            `gorm:"not null;default:0" json:"withdrawals_amount"` // In cents
+	NetAmount         int64            `gorm:"not null;default:0" json:"net_amount"`         // Deposits less withdrawals in cents
+	Status            SettlementStatus `gorm:"not null;index" json:"status"`
+	SettledAt         *time.Time       `json:"settled_at,omitempty"`
+	CreatedAt         time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+	TransactionIDs    []int            `gorm:"-" json:"transaction_ids"` // Ledger entries included in the batch
+}
+
+// SettlementEntry is a provider ledger entry included in a settlement batch.
+// An entry is only ever included in one batch.
+type SettlementEntry struct {
+	ID              int             `gorm:"primaryKey" json:"id"`
+	BatchID         int             `gorm:"not null;index" json:"batch_id"`
+	TransactionID   int             `gorm:"not null;uniqueIndex" json:"transaction_id"`
+	TransactionType TransactionType `gorm:"not null" json:"transaction_type"`
+	Amount          int64           `gorm:"not null" json:"amount"` // In cents
+}
+
+// SettlementStatus is the state of a settlement batch.
+type SettlementStatus string
+
+const (
+	// SettlementPending is the status of a batch whose entries are not yet
+	// marked as settled in the transactions service.
+	SettlementPending = SettlementStatus("pending")
+	// SettlementSettled is the status of a batch whose entries are marked as settled.
+	SettlementSettled = SettlementStatus("settled")
+)
+
+// NewSettlementBatch nets the deposit and withdrawal entries of a provider
+// wallet into a pending batch. Entries of other wallets or types are ignored.
+func NewSettlementBatch(providerID string, date, cutoff time.Time, txns []Transaction) (*SettlementBatch, []SettlementEntry) {
+	batch := &SettlementBatch{
+		ProviderID:     providerID,
+		SettlementDate: date.Format(DateLayout),
+		Cutoff:         cutoff,
+		Status:         SettlementPending,
+		TransactionIDs: []int{},
+	}
+	var entries []SettlementEntry
+	for _, txn := range txns {
+		if txn.SubjectWalletID != providerID {
+			continue
+		}
+		switch txn.TransactionType {
+		case Deposit:
+			batch.DepositCount++
+			batch.DepositsAmount += txn.Amount
+		case Withdraw:
+			batch.WithdrawalCount++
+			batch.WithdrawalsAmount += txn.Amount
+		default:
+			continue
+		}
+		batch.TransactionIDs = append(batch.TransactionIDs, txn.ID)
+		entries = append(entries, SettlementEntry{TransactionID: txn.ID, TransactionType: txn.TransactionType, Amount: txn.Amount})
+	}
+	batch.NetAmount = batch.DepositsAmount - batch.WithdrawalsAmount
+	return batch, entries
+}
+
+// Reference returns the ID the batch's entries are settled under in the
+// transactions service, and its reference in the settlement file.
+func (b *SettlementBatch) Reference() string {
+	return fmt.Sprintf("stl-%d", b.ID)
+}
+
+// SettlementFormat is the file format of a settlement export.
+type SettlementFormat string
+
+const (
+	// SettlementCSV is a CSV file with one row per batch.
+	SettlementCSV = SettlementFormat("csv")
+	// SettlementNACHA is a NACHA-style fixed-width ACH file with one entry per batch.
+	SettlementNACHA = SettlementFormat("nacha")
+)
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSettlementBatch(t *testing.T) {
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	cutoff := date.AddDate(0, 0, 1)
+	txns := []Transaction{
+		{ID: 1, SubjectWalletID: DepositProviderID, TransactionType: Deposit, Amount: 5000},
+		{ID: 2, SubjectWalletID: DepositProviderID, TransactionType: Deposit, Amount: 2500},
+		{ID: 3, SubjectWalletID: DepositProviderID, TransactionType: Withdraw, Amount: 1000},
+		{ID: 4, SubjectWalletID: WithdrawProviderID, TransactionType: Withdraw, Amount: 9999}, // Other provider
+		{ID: 5, SubjectWalletID: DepositProviderID, TransactionType: Transfer, Amount: 9999},  // Not a deposit or withdrawal
+	}
+
+	batch, entries := NewSettlementBatch(DepositProviderID, date, cutoff, txns)
+	assert.Equal(t, DepositProviderID, batch.ProviderID)
+	assert.Equal(t, "2026-03-01", batch.SettlementDate)
+	assert.Equal(t, cutoff, batch.Cutoff)
+	assert.Equal(t, SettlementPending, batch.Status)
+	assert.Equal(t, 2, batch.DepositCount)
+	assert.Equal(t, int64(7500), batch.DepositsAmount)
+	assert.Equal(t, 1, batch.WithdrawalCount)
+	assert.Equal(t, int64(1000), batch.WithdrawalsAmount)
+	assert.Equal(t, int64(6500), batch.NetAmount)
+	assert.Equal(t, []int{1, 2, 3}, batch.TransactionIDs)
+	assert.Equal(t, []SettlementEntry{
+		{TransactionID: 1, TransactionType: Deposit, Amount: 5000},
+		{TransactionID: 2, TransactionType: Deposit, Amount: 2500},
+		{TransactionID: 3, TransactionType: Withdraw, Amount: 1000},
+	}, entries)
+
+	batch, entries = NewSettlementBatch(WithdrawProviderID, date, cutoff, txns)
+	assert.Equal(t, int64(-9999), batch.NetAmount)
+	assert.Len(t, entries, 1)
+
+	batch, entries = NewSettlementBatch("other-provider", date, cutoff, txns)
+	assert.Empty(t, entries)
+	assert.Equal(t, []int{}, batch.TransactionIDs)
+}
+
+func TestSettlementBatch_Reference(t *testing.T) {
+	assert.Equal(t, "stl-42", (&SettlementBatch{ID: 42}).Reference())
+}
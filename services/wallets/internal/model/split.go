@@ -0,0 +1,119 @@
+package model
+
+import (
+	"math"
+	"sort"
+)
+
+// SplitMode is how the amount of a split transfer is divided between its recipients.
+type SplitMode string
+
+const (
+	// SplitByAmount gives every recipient the amount set for it.
+	SplitByAmount = SplitMode("amount")
+	// SplitByPercentage divides a total amount by the percentage set for every recipient.
+	SplitByPercentage = SplitMode("percentage")
+)
+
+// SplitRecipient is one receiver of a split transfer. Amount, in cents, is
+// used when splitting by amount and Percentage, with at most two decimals,
+// when splitting by percentage.
+type SplitRecipient struct {
+	UserID     string
+	Amount     int64
+	Percentage float64
+}
+
+// SplitTransfer is a single payment from one wallet to several. All of its
+// ledger entries carry the same GroupID.
+type SplitTransfer struct {
+	GroupID      string        `json:"group_id"`
+	FromUserID   string        `json:"from_user_id"`
+	Amount       int64         `json:"amount"`       // Total amount in cents
+	Transactions []Transaction `json:"transactions"` // The sender's debit entries, one per recipient
+}
+
+// AllocateSplit returns the share in cents of every recipient, in order.
+//
+// When splitting by amount the shares are the recipients' amounts; amount,
+// if not zero, must equal their sum. When splitting by percentage the
+// percentages must add up to exactly 100 and amount is divided by them,
+// rounding every share down; the cents left over go one each to the
+// recipients with the largest remainders, the earlier recipient first on a
+// tie, so the shares always add up to amount.
+//
+// ErrInvalidSplit is returned for a repeated recipient or invalid
+// percentages, and ErrInvalidAmount if any share would not be positive.
+func AllocateSplit(mode SplitMode, amount int64, recipients []SplitRecipient) ([]int64, error) {
+	if len(recipients) == 0 || amount < 0 {
+		return nil, ErrInvalidSplit
+	}
+	seen := make(map[string]bool, len(recipients))
+	for _, recipient := range recipients {
+		if seen[recipient.UserID] {
+			return nil, ErrInvalidSplit
+		}
+		seen[recipient.UserID] = true
+	}
+
+	var shares []int64
+	switch mode {
+	case SplitByAmount:
+		shares = make([]int64, len(recipients))
+		var total int64
+		for i, recipient := range recipients {
+			shares[i] = recipient.Amount
+			total += recipient.Amount
+		}
+		if amount != 0 && total != amount {
+			return nil, ErrInvalidSplit
+		}
+	case SplitByPercentage:
+		var err error
+		if shares, err = allocateByPercentage(amount, recipients); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidSplit
+	}
+
+	for _, share := range shares {
+		if share <= 0 {
+			return nil, ErrInvalidAmount
+		}
+	}
+	return shares, nil
+}
+
+// allocateByPercentage divides amount in basis points using the largest
+// remainder method.
+func allocateByPercentage(amount int64, recipients []SplitRecipient) ([]int64, error) {
+	const whole = 10000 // 100% in basis points
+
+	shares := make([]int64, len(recipients))
+	remainders := make([]int64, len(recipients))
+	var totalPoints, allocated int64
+	for i, recipient := range recipients {
+		points := math.Round(recipient.Percentage * 100)
+		if recipient.Percentage <= 0 || math.Abs(recipient.Percentage*100-points) > 1e-6 {
+			return nil, ErrInvalidSplit
+		}
+		totalPoints += int64(points)
+		shares[i] = amount * int64(points) / whole
+		remainders[i] = amount * int64(points) % whole
+		allocated += shares[i]
+	}
+	if totalPoints != whole {
+		return nil, ErrInvalidSplit
+	}
+
+	order := make([]int, len(recipients))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := int64(0); i < amount-allocated; i++ {
+		shares[order[i]]++
+	}
+	return shares, nil
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocateSplit(t *testing.T) {
+	byAmount := func(amounts ...int64) []SplitRecipient {
+		recipients := make([]SplitRecipient, len(amounts))
+		for i, amount := range amounts {
+			recipients[i] = SplitRecipient{UserID: string(rune('a' + i)), Amount: amount}
+		}
+		return recipients
+	}
+	byPercentage := func(percentages ...float64) []SplitRecipient {
+		recipients := make([]SplitRecipient, len(percentages))
+		for i, percentage := range percentages {
+			recipients[i] = SplitRecipient{UserID: string(rune('a' + i)), Percentage: percentage}
+		}
+		return recipients
+	}
+
+	tests := []struct {
+		name       string
+		mode       SplitMode
+		amount     int64
+		recipients []SplitRecipient
+		want       []int64
+		wantErr    error
+	}{
+		{name: "by_amount", mode: SplitByAmount, recipients: byAmount(1000, 250, 1), want: []int64{1000, 250, 1}},
+		{name: "by_amount_matching_total", mode: SplitByAmount, amount: 1251, recipients: byAmount(1000, 250, 1), want: []int64{1000, 250, 1}},
+		{name: "by_amount_total_mismatch", mode: SplitByAmount, amount: 1000, recipients: byAmount(1000, 250), wantErr: ErrInvalidSplit},
+		{name: "by_amount_zero_share", mode: SplitByAmount, recipients: byAmount(1000, 0), wantErr: ErrInvalidAmount},
+		{name: "by_percentage_even", mode: SplitByPercentage, amount: 1000, recipients: byPercentage(50, 25, 25), want: []int64{500, 250, 250}},
+		{name: "by_percentage_remainder_to_earlier", mode: SplitByPercentage, amount: 100, recipients: byPercentage(33.33, 33.33, 33.34), want: []int64{33, 33, 34}},
+		{name: "by_percentage_remainder_to_largest", mode: SplitByPercentage, amount: 1001, recipients: byPercentage(33.33, 33.33, 33.34), want: []int64{334, 333, 334}},
+		{name: "by_percentage_fractional", mode: SplitByPercentage, amount: 200, recipients: byPercentage(12.5, 87.5), want: []int64{25, 175}},
+		{name: "by_percentage_not_100", mode: SplitByPercentage, amount: 1000, recipients: byPercentage(50, 49.99), wantErr: ErrInvalidSplit},
+		{name: "by_percentage_too_precise", mode: SplitByPercentage, amount: 1000, recipients: byPercentage(33.333, 66.667), wantErr: ErrInvalidSplit},
+		{name: "by_percentage_share_rounds_to_zero", mode: SplitByPercentage, amount: 1, recipients: byPercentage(50, 50), wantErr: ErrInvalidAmount},
+		{name: "repeated_recipient", mode: SplitByAmount, recipients: []SplitRecipient{{UserID: "a", Amount: 1}, {UserID: "a", Amount: 2}}, wantErr: ErrInvalidSplit},
+		{name: "unknown_mode", mode: "shares", recipients: byAmount(1), wantErr: ErrInvalidSplit},
+		{name: "no_recipients", mode: SplitByAmount, wantErr: ErrInvalidSplit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AllocateSplit(tt.mode, tt.amount, tt.recipients)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
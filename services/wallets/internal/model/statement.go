@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// Currency is the ISO 4217 code of the currency all wallet amounts are held in.
+const Currency = "USD"
+
+// StatementFormat is the output format of an account statement.
+type StatementFormat string
+
+const (
+	// StatementCSV renders statements as comma-separated values.
+	StatementCSV = StatementFormat("csv")
+	// StatementJSON renders statements as a JSON document.
+	StatementJSON = StatementFormat("json")
+	// StatementOFX renders statements as an OFX 2.2 bank statement.
+	StatementOFX = StatementFormat("ofx")
+	// StatementCamt053 renders statements as an ISO 20022 camt.053.001.08 bank-to-customer statement.
+	StatementCamt053 = StatementFormat("camt053")
+)
+
+// StatementHeader opens an account statement covering [From, To).
+type StatementHeader struct {
+	UserID         string    `json:"user_id"`
+	AcntType       AcntType  `json:"acnt_type"`
+	Currency       string    `json:"currency"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	OpeningBalance int64     `json:"opening_balance"` // Balance in cents at From
+	GeneratedAt    time.Time `json:"generated_at"`
+}
+
+// StatementLine is a single ledger entry of an account statement.
+type StatementLine struct {
+	TransactionID   int             `json:"transaction_id"`
+	Date            time.Time       `json:"date"`
+	TransactionType TransactionType `json:"transaction_type"`
+	OperationType   OperationType   `json:"operation_type"`
+	Counterparty    string          `json:"counterparty,omitempty"`
+	Amount          int64           `json:"amount"`             // Signed amount in cents, negative for debits
+	RunningBalance  int64           `json:"running_balance"`    // Balance in cents after this entry
+	GroupID         string          `json:"group_id,omitempty"` // Shared by the entries of one split payment
+}
+
+// StatementFooter closes an account statement.
+type StatementFooter struct {
+	TotalCredits   int64 `json:"total_credits"`   // Sum of credits in cents
+	TotalDebits    int64 `json:"total_debits"`    // Sum of debits in cents, as a positive number
+	ClosingBalance int64 `json:"closing_balance"` // Balance in cents at To
+}
+
+// StatementSummary totals the lines of an account statement ahead of them,
+// for formats that place the totals before the entries.
+type StatementSummary struct {
+	StatementFooter
+	CreditEntries int // Number of lines with a credit (non-negative) amount
+	DebitEntries  int // Number of lines with a debit amount
+}
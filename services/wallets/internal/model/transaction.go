@@ -12,10 +12,49 @@ type Transaction struct {
 	OperationType   OperationType     `json:"operation_type"`
 	Amount          int64             `json:"amount"` // Amount in cents
 	Status          TransactionStatus `json:"status"`
+	GroupID         string            `json:"group_id,omitempty"`      // Shared by the entries of one payment to several wallets
+	ActorUserID     string            `json:"actor_user_id,omitempty"` // Member who acted on a shared wallet; empty when the holder did
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
 }
 
+// WalletActivity is the time of the latest ledger entry of a wallet, as
+// reported by the transaction microservice. Dormancy fees and escheatments
+// do not count as activity.
+type WalletActivity struct {
+	SubjectWalletID string    `json:"subject_wallet_id"`
+	LastActivityAt  time.Time `json:"last_activity_at"`
+}
+
+// TransactionPage selects a page of the ledger entries of a wallet, oldest
+// first: those created after After and, if set, before Before. Entries
+// created at exactly After are included if their ID is above AfterID, so all
+// of them are with an AfterID of 0, and the creation time and ID of the last
+// entry of a page select the next one.
+type TransactionPage struct {
+	After   time.Time
+	AfterID int
+	Before  time.Time
+	Limit   int
+}
+
+// LedgerBalance is the balance of a wallet according to the ledger, the
+// credits minus the debits of its completed entries up to a point in time, as
+// reported by the transaction microservice.
+type LedgerBalance struct {
+	SubjectWalletID string `json:"subject_wallet_id"`
+	Balance         int64  `json:"balance"` // Balance in cents
+}
+
+// SignedAmount returns the amount as it affects the subject wallet's balance:
+// positive for credits, negative for debits.
+func (t Transaction) SignedAmount() int64 {
+	if t.OperationType == Debit {
+		return -t.Amount
+	}
+	return t.Amount
+}
+
 // OperationType represents the operation type for transactions
 type OperationType string
 
@@ -36,6 +75,31 @@ const (
 	Withdraw = TransactionType("withdraw")
 	// Transfer transaction type
 	Transfer = TransactionType("transfer")
+	// EscrowFund transaction type, moving funds from the buyer into escrow
+	EscrowFund = TransactionType("escrow_fund")
+	// EscrowRelease transaction type, paying escrowed funds out to the seller
+	EscrowRelease = TransactionType("escrow_release")
+	// EscrowRefund transaction type, returning escrowed funds to the buyer
+	EscrowRefund = TransactionType("escrow_refund")
+	// PocketMove transaction type, moving funds between a user's wallet and
+	// its pockets. It is not a transfer between users, so transfer limits do
+	// not apply to it.
+	PocketMove = TransactionType("pocket_move")
+	// Payment transaction type, a customer paying a merchant's payment intent
+	Payment = TransactionType("payment")
+	// Refund transaction type, a merchant returning part or all of a payment
+	Refund = TransactionType("refund")
+	// TopUp transaction type, an operator adding float to a provider wallet
+	// from the provider's external bank account
+	TopUp = TransactionType("top_up")
+	// DormancyFee transaction type, a fee charged to a wallet when it becomes dormant
+	DormancyFee = TransactionType("dormancy_fee")
+	// Escheatment transaction type, the balance of a dormant wallet handed
+	// over to a provider wallet as unclaimed property
+	Escheatment = TransactionType("escheatment")
+	// Adjustment transaction type, a manual correction of a wallet's balance
+	// by an operator against the adjustments provider wallet
+	Adjustment = TransactionType("adjustment")
 )
 
 // TransactionStatus represents the status of a transaction
@@ -13,8 +13,21 @@ type Wallet struct {
 	AcntType  AcntType  `gorm:"not null" json:"acnt_type"`
 	Balance   int64     `gorm:"default:0" json:"balance"` // Balance in cents
 	Status    Status    `json:"status"`
+	Version   int64     `gorm:"not null;default:0" json:"-"` // Incremented on every balance change
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	// ParentID and PocketName are only set on pockets, the ID of the user
+	// wallet owning the pocket and the pocket's name, unique per owner
+	ParentID   *int     `gorm:"uniqueIndex:idx_wallets_parent_pocket" json:"parent_id,omitempty"`
+	PocketName string   `gorm:"not null;default:'';uniqueIndex:idx_wallets_parent_pocket" json:"pocket_name,omitempty"`
+	Pockets    []Wallet `gorm:"-" json:"pockets,omitempty"` // Loaded with the wallet's history only
+	// Transfers and withdrawals above ApprovalThreshold cents need
+	// RequiredApprovals owners to approve them; 0 turns approvals off
+	ApprovalThreshold int64 `gorm:"not null;default:0" json:"approval_threshold,omitempty"`
+	RequiredApprovals int   `gorm:"not null;default:0" json:"required_approvals,omitempty"`
+	// ClosedAt is when the wallet was closed; nil unless its status is Closed
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+	KYCLevel KYCLevel   `gorm:"column:kyc_level;not null;default:'none'" json:"kyc_level,omitempty"`
 }
 
 // NewWallet returns a new instance of the wallet model.
@@ -35,6 +48,12 @@ const (
 	User = AcntType("user")
 	// Provider account type
 	Provider = AcntType("provider")
+	// Escrow account type, holding the funds of a single escrow between a buyer and a seller
+	Escrow = AcntType("escrow")
+	// Pocket account type, a named sub-wallet of a user wallet
+	Pocket = AcntType("pocket")
+	// Merchant account type, a business taking payments through payment intents
+	Merchant = AcntType("merchant")
 )
 
 // Provider wallet constants for master accounts
@@ -45,6 +64,12 @@ const (
 	// WithdrawProviderID is the UserID for the withdraw provider wallet
 	// This is a master account that acts as the destination for all withdraw transactions
 	WithdrawProviderID = "withdraw-provider-master"
+	// AdjustmentProviderID is the UserID for the adjustments provider wallet,
+	// the counterparty of manual balance corrections made by operators
+	AdjustmentProviderID = "adjustment-provider-master"
+	// ExternalFundingID is the counterparty of provider top-ups in the ledger,
+	// standing for the provider's external bank account. It has no wallet.
+	ExternalFundingID = "external-funding"
 )
 
 // Status is the status of the wallet.
@@ -57,6 +82,10 @@ const (
 	Inactive = Status("inactive")
 	// Suspended is the status for a suspended wallet.
 	Suspended = Status("suspended")
+	// Closed is the terminal status of a wallet whose balance was swept out
+	// on closure. Its history stays readable but no money moves in or out,
+	// and only an admin can reopen it.
+	Closed = Status("closed")
 )
 
 // StatusMap is a map of wallet status.
@@ -64,6 +93,7 @@ var StatusMap = map[Status]bool{
 	Active:    true,
 	Inactive:  true,
 	Suspended: true,
+	Closed:    true,
 }
 
 // IsValidStatus checks if the status is valid (Active, Inactive, Suspended)
@@ -75,7 +105,8 @@ func IsValidStatus(fl validator.FieldLevel) bool {
 	return status == Active || status == Inactive || status == Suspended
 }
 
-// IsValidAcntType checks if the account type is valid
+// IsValidAcntType checks if the account type is valid for a new wallet.
+// Escrow, pocket and merchant wallets are only created through their own endpoints.
 func IsValidAcntType(fl validator.FieldLevel) bool {
 	if fl.Field().IsZero() {
 		return true
@@ -83,3 +114,47 @@ func IsValidAcntType(fl validator.FieldLevel) bool {
 	acntType := fl.Field().Interface().(AcntType)
 	return acntType == User || acntType == Provider
 }
+
+// DirectUseError returns the error for a deposit, withdrawal or transfer
+// touching the wallet, or nil if it may be used directly. Escrow wallets and
+// pockets only move funds through their own endpoints.
+func (w *Wallet) DirectUseError() error {
+	switch w.AcntType {
+	case Escrow:
+		return ErrEscrowWallet
+	case Pocket:
+		return ErrPocketWallet
+	}
+	return nil
+}
+
+// BalanceChangeError returns the error for moving money in or out of the
+// wallet, or nil if its balance may change. Closed and suspended wallets are
+// frozen, except disabled providers, which stay suspended while topped up.
+func (w *Wallet) BalanceChangeError() error {
+	switch {
+	case w.Status == Closed:
+		return ErrWalletClosed
+	case w.Status == Suspended && w.AcntType != Provider:
+		return ErrWalletSuspended
+	}
+	return nil
+}
+
+// CloseError returns the error for closing the wallet, or nil if it can be
+// closed. Only user and merchant wallets that are not already closed can be.
+func (w *Wallet) CloseError() error {
+	if w.Status == Closed {
+		return ErrWalletClosed
+	}
+	if w.AcntType != User && w.AcntType != Merchant {
+		return ErrWalletNotClosable
+	}
+	return nil
+}
+
+// BalanceChange is a signed change to a wallet balance; negative amounts debit the wallet.
+type BalanceChange struct {
+	WalletID int
+	Amount   int64
+}
@@ -0,0 +1,12 @@
+package model
+
+// WalletShard is a sub-account holding part of a sharded wallet's balance.
+// Hot provider wallets are split into shards so that parallel operations
+// update different rows instead of queueing on a single one; the wallet's
+// reported balance is its own balance plus the sum of its shards.
+type WalletShard struct {
+	ID       int   `gorm:"primaryKey" json:"id"`
+	WalletID int   `gorm:"not null;uniqueIndex:idx_wallet_shards_wallet_shard" json:"wallet_id"`
+	Shard    int   `gorm:"not null;uniqueIndex:idx_wallet_shards_wallet_shard" json:"shard"`
+	Balance  int64 `gorm:"not null;default:0" json:"balance"` // Balance in cents
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWallet_CloseError(t *testing.T) {
+	tests := []struct {
+		name    string
+		wallet  Wallet
+		wantErr error
+	}{
+		{name: "user", wallet: Wallet{AcntType: User, Status: Active}},
+		{name: "suspended_merchant", wallet: Wallet{AcntType: Merchant, Status: Suspended}},
+		{name: "already_closed", wallet: Wallet{AcntType: User, Status: Closed}, wantErr: ErrWalletClosed},
+		{name: "provider", wallet: Wallet{AcntType: Provider, Status: Active}, wantErr: ErrWalletNotClosable},
+		{name: "escrow", wallet: Wallet{AcntType: Escrow, Status: Active}, wantErr: ErrWalletNotClosable},
+		{name: "pocket", wallet: Wallet{AcntType: Pocket, Status: Active}, wantErr: ErrWalletNotClosable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.wallet.CloseError())
+		})
+	}
+}
+
+func TestWallet_BalanceChangeError(t *testing.T) {
+	tests := []struct {
+		name    string
+		wallet  Wallet
+		wantErr error
+	}{
+		{name: "active", wallet: Wallet{AcntType: User, Status: Active}},
+		{name: "dormant", wallet: Wallet{AcntType: User, Status: Inactive}},
+		{name: "suspended", wallet: Wallet{AcntType: User, Status: Suspended}, wantErr: ErrWalletSuspended},
+		{name: "suspended_pocket", wallet: Wallet{AcntType: Pocket, Status: Suspended}, wantErr: ErrWalletSuspended},
+		{name: "disabled_provider", wallet: Wallet{AcntType: Provider, Status: Suspended}},
+		{name: "closed", wallet: Wallet{AcntType: User, Status: Closed}, wantErr: ErrWalletClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.wallet.BalanceChangeError())
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Package notify delivers payment request events to webhooks and to
+// server-sent event subscribers, and wallet events and alias verification
+// codes to webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// subscriberBuffer is the number of events queued per subscriber. Events for
+// a subscriber that falls further behind are dropped.
+const subscriberBuffer = 16
+
+// webhookTimeout bounds a single webhook delivery.
+const webhookTimeout = 5 * time.Second
+
+// Notifier publishes payment request events to the requester and the payer,
+// and wallet events and alias challenges to the webhook.
+type Notifier interface {
+	// Notify delivers the event to the subscribers of both parties and, if
+	// configured, to the webhook. It never blocks on slow consumers.
+	Notify(ctx context.Context, event model.PaymentRequestEvent)
+	// Subscribe returns the events concerning the user until unsubscribe is called.
+	Subscribe(userID string) (events <-chan model.PaymentRequestEvent, unsubscribe func())
+	// NotifyWallet delivers the wallet event to the webhook, if configured.
+	NotifyWallet(ctx context.Context, event model.WalletEvent)
+	// NotifyAliasChallenge delivers the verification code of an alias to the
+	// webhook, if configured, for sending to the phone number or email address.
+	NotifyAliasChallenge(ctx context.Context, event model.AliasChallengeEvent)
+}
+
+type notifier struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan model.PaymentRequestEvent]struct{}
+
+	client        *http.Client
+	webhookURL    string
+	webhookSecret string
+}
+
+var (
+	instance Notifier
+	once     sync.Once
+)
+
+// ResetNotifier resets the singleton instance for testing purposes
+func ResetNotifier() {
+	once = sync.Once{}
+	instance = nil
+}
+
+// NewNotifier is a factory method that returns the Notifier with singleton pattern
+func NewNotifier() Notifier {
+	once.Do(func() {
+		instance = New(config.GetPaymentRequests())
+	})
+	return instance
+}
+
+// New creates a notifier that posts events to cfg.WebhookURL, if set, and
+// fans them out to in-process subscribers.
+func New(cfg model.PaymentRequests) Notifier {
+	return &notifier{
+		subscribers:   make(map[string]map[chan model.PaymentRequestEvent]struct{}),
+		client:        &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport), Timeout: webhookTimeout},
+		webhookURL:    cfg.WebhookURL,
+		webhookSecret: cfg.WebhookSecret,
+	}
+}
+
+func (n *notifier) Notify(ctx context.Context, event model.PaymentRequestEvent) {
+	n.publish(event.PaymentRequest.RequesterID, event)
+	n.publish(event.PaymentRequest.PayerID, event)
+
+	if n.webhookURL == "" {
+		return
+	}
+	// The webhook outlives the request that changed the state.
+	go func() {
+		if err := n.postWebhook(context.WithoutCancel(ctx), event); err != nil {
+			utils.LogError("Failed to deliver payment request webhook", err)
+			metrics.IncAsyncFailure("payment_request_webhook")
+		}
+	}()
+}
+
+func (n *notifier) NotifyWallet(ctx context.Context, event model.WalletEvent) {
+	if n.webhookURL == "" {
+		return
+	}
+	go func() {
+		if err := n.postWebhook(context.WithoutCancel(ctx), event); err != nil {
+			utils.LogError("Failed to deliver wallet webhook", err)
+			metrics.IncAsyncFailure("wallet_webhook")
+		}
+	}()
+}
+
+func (n *notifier) NotifyAliasChallenge(ctx context.Context, event model.AliasChallengeEvent) {
+	if n.webhookURL == "" {
+		return
+	}
+	go func() {
+		if err := n.postWebhook(context.WithoutCancel(ctx), event); err != nil {
+			utils.LogError("Failed to deliver alias challenge webhook", err)
+			metrics.IncAsyncFailure("alias_challenge_webhook")
+		}
+	}()
+}
+
+func (n *notifier) Subscribe(userID string) (<-chan model.PaymentRequestEvent, func()) {
+	events := make(chan model.PaymentRequestEvent, subscriberBuffer)
+
+	n.mu.Lock()
+	if n.subscribers[userID] == nil {
+		n.subscribers[userID] = make(map[chan model.PaymentRequestEvent]struct{})
+	}
+	n.subscribers[userID][events] = struct{}{}
+	n.mu.Unlock()
+
+	var unsubscribeOnce sync.Once
+	return events, func() {
+		unsubscribeOnce.Do(func() {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			delete(n.subscribers[userID], events)
+			if len(n.subscribers[userID]) == 0 {
+				delete(n.subscribers, userID)
+			}
+			close(events)
+		})
+	}
+}
+
+// publish queues the event for every subscriber of the user, dropping it for
+// subscribers whose buffer is full.
+func (n *notifier) publish(userID string, event model.PaymentRequestEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for events := range n.subscribers[userID] {
+		select {
+		case events <- event:
+		default:
+			metrics.IncAsyncFailure("payment_request_sse")
+		}
+	}
+}
+
+// postWebhook sends the event as JSON. With a secret configured the body is
+// signed with HMAC-SHA256 so the receiver can check where it came from.
+func (n *notifier) postWebhook(ctx context.Context, event any) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.webhookSecret != "" {
+		mac := hmac.New(sha256.New, []byte(n.webhookSecret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() model.PaymentRequestEvent {
+	return model.NewPaymentRequestEvent(model.PaymentRequest{
+		ID:          1,
+		RequesterID: "alice",
+		PayerID:     "bob",
+		Amount:      1500,
+		Status:      model.RequestPending,
+	})
+}
+
+func TestNotifier_Subscribe(t *testing.T) {
+	n := New(model.PaymentRequests{})
+
+	alice, unsubscribeAlice := n.Subscribe("alice")
+	bob, unsubscribeBob := n.Subscribe("bob")
+	carol, unsubscribeCarol := n.Subscribe("carol")
+	defer unsubscribeAlice()
+	defer unsubscribeBob()
+	defer unsubscribeCarol()
+
+	n.Notify(context.Background(), testEvent())
+
+	for name, events := range map[string]<-chan model.PaymentRequestEvent{"alice": alice, "bob": bob} {
+		select {
+		case event := <-events:
+			assert.Equal(t, "payment_request.created", event.Event, name)
+		case <-time.After(time.Second):
+			t.Fatalf("%s did not receive the event", name)
+		}
+	}
+	select {
+	case event := <-carol:
+		t.Fatalf("unrelated subscriber received %v", event)
+	default:
+	}
+}
+
+func TestNotifier_SlowSubscriberDoesNotBlock(t *testing.T) {
+	n := New(model.PaymentRequests{})
+	events, unsubscribe := n.Subscribe("alice")
+
+	for i := 0; i < subscriberBuffer*2; i++ {
+		n.Notify(context.Background(), testEvent())
+	}
+	assert.Len(t, events, subscriberBuffer)
+
+	unsubscribe()
+	unsubscribe() // safe to call twice
+	n.Notify(context.Background(), testEvent())
+}
+
+func TestNotifier_Webhook(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	n := New(model.PaymentRequests{WebhookURL: server.URL, WebhookSecret: "secret"})
+	n.Notify(context.Background(), testEvent())
+
+	select {
+	case r := <-received:
+		body := <-bodies
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get("X-Signature"))
+
+		var event model.PaymentRequestEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, "payment_request.created", event.Event)
+		assert.Equal(t, int64(1500), event.PaymentRequest.Amount)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestNotifier_WalletWebhook(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	n := New(model.PaymentRequests{WebhookURL: server.URL})
+	n.NotifyWallet(context.Background(), model.WalletEvent{
+		Event:   model.WalletEventDormant,
+		UserID:  "john_doe",
+		Status:  model.Inactive,
+		Charged: 500,
+	})
+
+	select {
+	case body := <-bodies:
+		var event model.WalletEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, model.WalletEventDormant, event.Event)
+		assert.Equal(t, "john_doe", event.UserID)
+		assert.Equal(t, int64(500), event.Charged)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestNotifier_AliasChallengeWebhook(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	n := New(model.PaymentRequests{WebhookURL: server.URL})
+	n.NotifyAliasChallenge(context.Background(), model.AliasChallengeEvent{
+		Event: model.AliasChallengeEventName,
+		Type:  model.AliasPhone,
+		Alias: "+14155550100",
+		Code:  "123456",
+	})
+
+	select {
+	case body := <-bodies:
+		var event model.AliasChallengeEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, model.AliasChallengeEventName, event.Event)
+		assert.Equal(t, "+14155550100", event.Alias)
+		assert.Equal(t, "123456", event.Code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
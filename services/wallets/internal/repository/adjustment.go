@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateAdjustment creates a balance adjustment.
+func (td *wallet) CreateAdjustment(ctx context.Context, adjustment *model.BalanceAdjustment) error {
+	return td.db.WithContext(ctx).Create(adjustment).Error
+}
+
+// FindAdjustment retrieves a balance adjustment by ID, returns ErrNotFound if not exists.
+func (td *wallet) FindAdjustment(ctx context.Context, id int) (*model.BalanceAdjustment, error) {
+	var adjustment *model.BalanceAdjustment
+	err := td.db.WithContext(ctx).Where("id = ?", id).Take(&adjustment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// FindAdjustments retrieves the balance adjustments in a status, oldest
+// first, so reviewers work through them in the order they came in.
+func (td *wallet) FindAdjustments(ctx context.Context, status model.AdjustmentStatus) ([]model.BalanceAdjustment, error) {
+	var adjustments []model.BalanceAdjustment
+	err := td.db.WithContext(ctx).Where("status = ?", status).
+		Order("created_at, id").Find(&adjustments).Error
+	return adjustments, err
+}
+
+// ReviewAdjustment approves or rejects a pending balance adjustment and, on
+// approval, applies changes, in one database transaction. It returns
+// ErrInvalidTransition if the adjustment was already reviewed; if the changes
+// fail the adjustment stays pending.
+func (td *wallet) ReviewAdjustment(ctx context.Context, id int, status model.AdjustmentStatus, reviewer, note string, at time.Time, changes ...model.BalanceChange) (*model.BalanceAdjustment, error) {
+	var adjustment model.BalanceAdjustment
+	err := td.WithTransaction(ctx, func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&adjustment).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if adjustment.Status != model.AdjustmentPending {
+			return model.ErrInvalidTransition
+		}
+
+		adjustment.Status, adjustment.Reviewer, adjustment.ReviewNote, adjustment.ReviewedAt = status, reviewer, note, &at
+		if err := tx.Model(&adjustment).Updates(map[string]interface{}{
+			"status":      status,
+			"reviewer":    reviewer,
+			"review_note": note,
+			"reviewed_at": at,
+		}).Error; err != nil {
+			return err
+		}
+		if status != model.AdjustmentApproved {
+			return nil
+		}
+		return td.ApplyBalanceChanges(tx, changes...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
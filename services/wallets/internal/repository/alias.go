@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateAlias registers a new alias, returns ErrAliasTaken if the value is
+// already registered to any wallet.
+func (td *wallet) CreateAlias(ctx context.Context, alias *model.WalletAlias) error {
+	err := td.db.WithContext(ctx).Create(alias).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrAliasTaken
+	}
+	return err
+}
+
+// FindAlias retrieves an alias by its normalized value, returns ErrNotFound if not exists.
+func (td *wallet) FindAlias(ctx context.Context, value string) (*model.WalletAlias, error) {
+	var alias *model.WalletAlias
+	err := td.db.WithContext(ctx).Where("value = ?", value).Take(&alias).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return alias, nil
+}
+
+// FindAliasesByWalletID retrieves every alias of a wallet in registration order.
+func (td *wallet) FindAliasesByWalletID(ctx context.Context, walletID int) ([]model.WalletAlias, error) {
+	aliases := []model.WalletAlias{}
+	if err := td.db.WithContext(ctx).Where("wallet_id = ?", walletID).Order("id").Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// ChallengeAlias starts a new ownership challenge for a pending alias of the
+// wallet and returns the alias and the code to send to it. It returns
+// ErrNotFound if the wallet has no such alias, ErrInvalidTransition if the
+// alias is already verified and ErrChallengeCooldown if the previous code was
+// sent too recently.
+func (td *wallet) ChallengeAlias(ctx context.Context, walletID int, value string, at time.Time) (*model.WalletAlias, string, error) {
+	var alias model.WalletAlias
+	var code string
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockAlias(tx, walletID, value, &alias); err != nil {
+			return err
+		}
+		if alias.Status == model.AliasVerified {
+			return model.ErrInvalidTransition
+		}
+		var err error
+		if code, err = alias.NewChallenge(at); err != nil {
+			return err
+		}
+		return tx.Model(&alias).Updates(map[string]interface{}{
+			"challenge_hash":     alias.ChallengeHash,
+			"challenge_sent_at":  alias.ChallengeSentAt,
+			"challenge_attempts": alias.ChallengeAttempts,
+		}).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &alias, code, nil
+}
+
+// VerifyAlias checks the code of the challenge of an alias of the wallet and,
+// if it matches, marks the alias as verified and returns it. An alias that is
+// already verified keeps its original verification time. A wrong code is
+// counted against the challenge and returns ErrInvalidChallengeCode; without a
+// live challenge it returns ErrExpired. It returns ErrNotFound if the wallet
+// has no such alias.
+func (td *wallet) VerifyAlias(ctx context.Context, walletID int, value, code string, at time.Time) (*model.WalletAlias, error) {
+	var alias model.WalletAlias
+	var checkErr error
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockAlias(tx, walletID, value, &alias); err != nil {
+			return err
+		}
+		if alias.Status == model.AliasVerified {
+			return nil
+		}
+		// A wrong code is committed so the attempts are counted
+		checkErr = alias.CheckChallenge(code, at)
+		switch checkErr {
+		case nil:
+		case model.ErrInvalidChallengeCode:
+			return tx.Model(&alias).Update("challenge_attempts", alias.ChallengeAttempts).Error
+		default:
+			return checkErr
+		}
+		alias.Status, alias.VerifiedAt, alias.ChallengeHash = model.AliasVerified, &at, ""
+		return tx.Model(&alias).Updates(map[string]interface{}{
+			"status":         alias.Status,
+			"verified_at":    at,
+			"challenge_hash": alias.ChallengeHash,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if checkErr != nil {
+		return nil, checkErr
+	}
+	return &alias, nil
+}
+
+// lockAlias loads an alias of the wallet for update, returns ErrNotFound if not exists.
+func lockAlias(tx *gorm.DB, walletID int, value string, alias *model.WalletAlias) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("wallet_id = ? AND value = ?", walletID, value).Take(alias).Error
+	if err == gorm.ErrRecordNotFound {
+		return model.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CloseWallet sweeps the balance of a wallet and of its pockets to the wallet
+// toWalletID and marks them closed, in one database transaction. The wallets
+// are locked first, so no balance change lands between the sweep and the
+// closure, and the closure fails with ErrOpenHolds if funds are still held
+// for the wallet. authorize is called under the locks with the transaction
+// and the total balance to be swept, and its error aborts the closure. It returns the wallet and its
+// pockets with the balances they had before the sweep.
+func (td *wallet) CloseWallet(ctx context.Context, wallet *model.Wallet, toWalletID int, at time.Time, authorize func(tx *gorm.DB, total int64) error) ([]model.Wallet, error) {
+	var swept []model.Wallet
+	err := td.WithTransaction(ctx, func(tx *gorm.DB) error {
+		// Lock the destination too, in ID order with the others, so the
+		// sweep cannot deadlock with a transfer between the same wallets
+		var wallets []model.Wallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? OR parent_id = ?", []int{wallet.ID, toWalletID}, wallet.ID).
+			Order("id").Find(&wallets).Error; err != nil {
+			return err
+		}
+		swept = swept[:0]
+		for _, w := range wallets {
+			if w.ID == wallet.ID || (w.ParentID != nil && *w.ParentID == wallet.ID) {
+				swept = append(swept, w)
+			}
+		}
+		if len(swept) == 0 || swept[0].ID != wallet.ID {
+			// The parent sorts before its pockets, which are created after it
+			return model.ErrNotFound
+		}
+		if swept[0].Status == model.Closed {
+			return model.ErrWalletClosed
+		}
+
+		// A held closure is not a hold on funds; it is the one being carried out
+		var approvals, escrows int64
+		if err := tx.Model(&model.WalletApproval{}).
+			Where("wallet_id = ? AND status = ? AND transaction_type <> ?", wallet.ID, model.ApprovalPending, model.Closure).
+			Count(&approvals).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.EscrowAgreement{}).
+			Where("status = ? AND (buyer_id = ? OR seller_id = ?)", model.EscrowFunded, wallet.UserID, wallet.UserID).
+			Count(&escrows).Error; err != nil {
+			return err
+		}
+		if approvals > 0 || escrows > 0 {
+			return model.ErrOpenHolds
+		}
+
+		var changes []model.BalanceChange
+		var total int64
+		ids := make([]int, len(swept))
+		for i, w := range swept {
+			ids[i] = w.ID
+			if w.Balance > 0 {
+				changes = append(changes, model.BalanceChange{WalletID: w.ID, Amount: -w.Balance})
+				total += w.Balance
+			}
+		}
+		if err := authorize(tx, total); err != nil {
+			return err
+		}
+		if total > 0 {
+			changes = append(changes, model.BalanceChange{WalletID: toWalletID, Amount: total})
+			if err := td.ApplyBalanceChanges(tx, changes...); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&model.Wallet{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":    model.Closed,
+			"closed_at": at,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return swept, nil
+}
+
+// SetWalletStatus changes the status of a wallet and of its pockets from one
+// status to another, returns ErrInvalidTransition if the wallet is not in the
+// from status. It clears the closing time, so wallets are closed through
+// CloseWallet instead.
+func (td *wallet) SetWalletStatus(ctx context.Context, walletID int, from, to model.Status) error {
+	return td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Wallet{}).
+			Where("id = ? AND status = ?", walletID, from).
+			Updates(map[string]interface{}{"status": to, "closed_at": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrInvalidTransition
+		}
+		return tx.Model(&model.Wallet{}).
+			Where("parent_id = ? AND status = ?", walletID, from).
+			Updates(map[string]interface{}{"status": to, "closed_at": nil}).Error
+	})
+}
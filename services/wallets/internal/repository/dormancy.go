@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WithJobLock runs fn unless another instance of the service holds the lock
+// of the job name, and reports whether it ran. The lock is a Postgres advisory
+// lock held on a connection of its own until fn returns, so that a job run by
+// every replica only runs on one at a time.
+func (td *wallet) WithJobLock(ctx context.Context, name string, fn func() error) (bool, error) {
+	var ran bool
+	err := td.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		var locked bool
+		if err := conn.Raw("SELECT pg_try_advisory_lock(hashtext(?))", name).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+		// Released even if ctx is done, as the connection goes back to the pool
+		defer conn.WithContext(context.WithoutCancel(ctx)).Exec("SELECT pg_advisory_unlock(hashtext(?))", name)
+		ran = true
+		return fn()
+	})
+	return ran, err
+}
+
+// FindDormancyCandidates returns the active user wallets not updated since
+// idleSince, oldest first. Whether they are dormant is decided on their
+// transaction history.
+func (td *wallet) FindDormancyCandidates(ctx context.Context, idleSince time.Time) ([]model.Wallet, error) {
+	var wallets []model.Wallet
+	err := td.db.WithContext(ctx).
+		Where("acnt_type = ? AND status = ? AND updated_at <= ?", model.User, model.Active, idleSince).
+		Order("id").Find(&wallets).Error
+	return wallets, err
+}
+
+// MarkWalletDormant charges a wallet the dormancy fee, or escheats its
+// balance and the balances of its pockets, to the wallet toWalletID and marks
+// them inactive, in one database transaction. It returns ErrInvalidTransition
+// if the wallet is no longer active or was updated after idleSince, so a
+// wallet used while the job ran is left alone. It returns the wallet and its
+// pockets with the balances they had before the charge.
+func (td *wallet) MarkWalletDormant(ctx context.Context, walletID int, idleSince time.Time, cfg model.Dormancy, toWalletID int) ([]model.Wallet, error) {
+	var wallets []model.Wallet
+	err := td.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? OR parent_id = ?", walletID, walletID).
+			Order("id").Find(&wallets).Error; err != nil {
+			return err
+		}
+		if len(wallets) == 0 || wallets[0].ID != walletID {
+			return model.ErrNotFound
+		}
+		if wallets[0].Status != model.Active || wallets[0].UpdatedAt.After(idleSince) {
+			return model.ErrInvalidTransition
+		}
+
+		if changes, _ := model.DormancyCharges(cfg, wallets); len(changes) > 0 {
+			var total int64
+			for _, c := range changes {
+				total -= c.Amount
+			}
+			changes = append(changes, model.BalanceChange{WalletID: toWalletID, Amount: total})
+			if err := td.ApplyBalanceChanges(tx, changes...); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&model.Wallet{}).
+			Where("(id = ? OR parent_id = ?) AND status = ?", walletID, walletID, model.Active).
+			Update("status", model.Inactive).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// expiredEscrowBatch bounds the number of expired escrows returned at once.
+const expiredEscrowBatch = 100
+
+// CreateEscrow creates the escrow wallet and the escrow, and moves the escrow
+// amount from the buyer's wallet into it, all in one database transaction.
+func (td *wallet) CreateEscrow(ctx context.Context, escrow *model.EscrowAgreement, buyerWalletID int) error {
+	return td.WithTransaction(ctx, func(tx *gorm.DB) error {
+		// The transaction may be retried, so nothing from a failed attempt is reused
+		escrow.ID = 0
+		escrowWallet := model.NewWallet(escrow.WalletUserID, model.Escrow)
+		if err := tx.Create(escrowWallet).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(escrow).Error; err != nil {
+			return err
+		}
+		return td.ApplyBalanceChanges(tx,
+			model.BalanceChange{WalletID: buyerWalletID, Amount: -escrow.Amount},
+			model.BalanceChange{WalletID: escrowWallet.ID, Amount: escrow.Amount},
+		)
+	})
+}
+
+// FindEscrow retrieves an escrow by ID, returns ErrNotFound if not exists.
+func (td *wallet) FindEscrow(ctx context.Context, id int) (*model.EscrowAgreement, error) {
+	var escrow *model.EscrowAgreement
+	err := td.db.WithContext(ctx).Where("id = ?", id).Take(&escrow).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return escrow, nil
+}
+
+// FindExpiredEscrows retrieves funded escrows whose deadline is at or before
+// now and whose refund was refused fewer than maxRefundAttempts times,
+// earliest deadline first, at most expiredEscrowBatch at a time.
+func (td *wallet) FindExpiredEscrows(ctx context.Context, now time.Time, maxRefundAttempts int) ([]model.EscrowAgreement, error) {
+	var escrows []model.EscrowAgreement
+	err := td.db.WithContext(ctx).
+		Where("status = ? AND deadline <= ? AND refund_attempts < ?", model.EscrowFunded, now, maxRefundAttempts).
+		Order("deadline, id").Limit(expiredEscrowBatch).Find(&escrows).Error
+	if err != nil {
+		return nil, err
+	}
+	return escrows, nil
+}
+
+// RecordEscrowRefundFailure counts a refused refund of a funded escrow with
+// the reason, and returns the number of refunds refused so far.
+// ErrInvalidTransition is returned if the escrow is no longer funded.
+func (td *wallet) RecordEscrowRefundFailure(ctx context.Context, id int, reason string) (int, error) {
+	var escrows []model.EscrowAgreement
+	result := td.db.WithContext(ctx).Model(&escrows).Clauses(clause.Returning{}).
+		Where("id = ? AND status = ?", id, model.EscrowFunded).
+		Updates(map[string]interface{}{"refund_attempts": gorm.Expr("refund_attempts + 1"), "refund_error": reason})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, model.ErrInvalidTransition
+	}
+	return escrows[0].RefundAttempts, nil
+}
+
+// SettleEscrow pays sellerAmount of a funded escrow to the seller and the rest
+// back to the buyer in one database transaction, and records the outcome. The
+// escrow row is locked first, so an escrow is settled at most once;
+// ErrInvalidTransition is returned if it is no longer funded. Paying the
+// seller anything is only allowed before the deadline, after which the escrow
+// can only be refunded, and returns ErrExpired.
+func (td *wallet) SettleEscrow(ctx context.Context, id int, sellerAmount int64, at time.Time) (*model.EscrowAgreement, error) {
+	var escrow model.EscrowAgreement
+	err := td.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&escrow).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return model.ErrNotFound
+			}
+			return err
+		}
+		if escrow.Status != model.EscrowFunded {
+			return model.ErrInvalidTransition
+		}
+		if sellerAmount < 0 || sellerAmount > escrow.Amount {
+			return model.ErrInvalidAmount
+		}
+		if sellerAmount > 0 && !escrow.Deadline.After(at) {
+			return model.ErrExpired
+		}
+
+		var wallets []model.Wallet
+		if err := tx.Where("user_id IN ?", []string{escrow.WalletUserID, escrow.BuyerID, escrow.SellerID}).
+			Find(&wallets).Error; err != nil {
+			return err
+		}
+		walletIDs := make(map[string]int, len(wallets))
+		for _, wallet := range wallets {
+			walletIDs[wallet.UserID] = wallet.ID
+		}
+		if len(walletIDs) != 3 {
+			return model.ErrNotFound
+		}
+
+		refundAmount := escrow.Amount - sellerAmount
+		changes := []model.BalanceChange{{WalletID: walletIDs[escrow.WalletUserID], Amount: -escrow.Amount}}
+		if sellerAmount > 0 {
+			changes = append(changes, model.BalanceChange{WalletID: walletIDs[escrow.SellerID], Amount: sellerAmount})
+		}
+		if refundAmount > 0 {
+			changes = append(changes, model.BalanceChange{WalletID: walletIDs[escrow.BuyerID], Amount: refundAmount})
+		}
+		if err := td.ApplyBalanceChanges(tx, changes...); err != nil {
+			return err
+		}
+
+		escrow.Status = model.EscrowSettlementStatus(escrow.Amount, sellerAmount)
+		escrow.ReleasedAmount, escrow.RefundedAmount, escrow.SettledAt = sellerAmount, refundAmount, &at
+		if err := tx.Model(&escrow).Updates(map[string]interface{}{
+			"status":          escrow.Status,
+			"released_amount": escrow.ReleasedAmount,
+			"refunded_amount": escrow.RefundedAmount,
+			"settled_at":      at,
+		}).Error; err != nil {
+			return err
+		}
+
+		// The emptied escrow wallet is never used again
+		return tx.Model(&model.Wallet{}).Where("id = ?", walletIDs[escrow.WalletUserID]).
+			Update("status", model.Inactive).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &escrow, nil
+}
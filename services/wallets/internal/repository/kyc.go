@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateKYCVerification creates a KYC verification, returns ErrKYCPending if
+// another verification of the wallet awaits review.
+func (td *wallet) CreateKYCVerification(ctx context.Context, verification *model.KYCVerification) error {
+	err := td.db.WithContext(ctx).Create(verification).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrKYCPending
+	}
+	return err
+}
+
+// FindKYCVerification retrieves a KYC verification by ID, returns ErrNotFound if not exists.
+func (td *wallet) FindKYCVerification(ctx context.Context, id int) (*model.KYCVerification, error) {
+	var verification *model.KYCVerification
+	err := td.db.WithContext(ctx).Where("id = ?", id).Take(&verification).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return verification, nil
+}
+
+// FindKYCVerifications retrieves the KYC verifications of a wallet, newest first.
+func (td *wallet) FindKYCVerifications(ctx context.Context, walletID int) ([]model.KYCVerification, error) {
+	var verifications []model.KYCVerification
+	err := td.db.WithContext(ctx).Where("wallet_id = ?", walletID).
+		Order("created_at DESC, id DESC").Find(&verifications).Error
+	return verifications, err
+}
+
+// FindKYCVerificationsByStatus retrieves the KYC verifications in a status,
+// oldest first, so reviewers work through them in the order they came in.
+func (td *wallet) FindKYCVerificationsByStatus(ctx context.Context, status model.KYCStatus) ([]model.KYCVerification, error) {
+	var verifications []model.KYCVerification
+	err := td.db.WithContext(ctx).Where("status = ?", status).
+		Order("created_at, id").Find(&verifications).Error
+	return verifications, err
+}
+
+// ReviewKYCVerification approves or rejects a pending KYC verification and,
+// on approval, raises the wallet to the verified level, in one database
+// transaction. It returns ErrInvalidTransition if the verification was
+// already reviewed.
+func (td *wallet) ReviewKYCVerification(ctx context.Context, id int, status model.KYCStatus, reviewer, note string, at time.Time) (*model.KYCVerification, error) {
+	var verification model.KYCVerification
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&verification).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if verification.Status != model.KYCPending {
+			return model.ErrInvalidTransition
+		}
+
+		verification.Status, verification.Reviewer, verification.ReviewNote, verification.ReviewedAt = status, reviewer, note, &at
+		if err := tx.Model(&verification).Updates(map[string]interface{}{
+			"status":      status,
+			"reviewer":    reviewer,
+			"review_note": note,
+			"reviewed_at": at,
+		}).Error; err != nil {
+			return err
+		}
+		if status != model.KYCApproved {
+			return nil
+		}
+		return tx.Model(&model.Wallet{}).Where("id = ?", verification.WalletID).
+			Update("kyc_level", verification.Level).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
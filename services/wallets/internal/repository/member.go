@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateMember inserts a new wallet member, returns ErrMemberExists if the
+// user is already a member of the wallet.
+func (td *wallet) CreateMember(ctx context.Context, member *model.WalletMember) error {
+	err := td.db.WithContext(ctx).Create(member).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrMemberExists
+	}
+	return err
+}
+
+// FindMember retrieves a member of a wallet, returns ErrNotFound if not exists.
+func (td *wallet) FindMember(ctx context.Context, walletID int, memberID string) (*model.WalletMember, error) {
+	var member *model.WalletMember
+	err := td.db.WithContext(ctx).Where("wallet_id = ? AND member_id = ?", walletID, memberID).Take(&member).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// FindMembers retrieves every member of a wallet in the order they were added.
+func (td *wallet) FindMembers(ctx context.Context, walletID int) ([]model.WalletMember, error) {
+	members := []model.WalletMember{}
+	if err := td.db.WithContext(ctx).Where("wallet_id = ?", walletID).Order("id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// DeleteMember removes a member from a wallet, returns ErrNotFound if not exists.
+func (td *wallet) DeleteMember(ctx context.Context, walletID int, memberID string) error {
+	result := td.db.WithContext(ctx).Where("wallet_id = ? AND member_id = ?", walletID, memberID).Delete(&model.WalletMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+// RecordMemberSpend adds amount to what a member spent from a wallet in its
+// current spend window within the transaction tx. The member is locked and its
+// spend limit checked under the lock, so concurrent debits cannot together
+// exceed it. Returns ErrNotMember if the user is not a member of the wallet.
+func (td *wallet) RecordMemberSpend(tx *gorm.DB, walletID int, memberID string, amount int64, at time.Time) error {
+	var member model.WalletMember
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("wallet_id = ? AND member_id = ?", walletID, memberID).Take(&member).Error
+	if err == gorm.ErrRecordNotFound {
+		return model.ErrNotMember
+	}
+	if err != nil {
+		return err
+	}
+	if err := member.Spend(amount, at); err != nil {
+		return err
+	}
+	return tx.Model(&member).Updates(map[string]interface{}{
+		"window_spent": member.WindowSpent,
+		"window_start": member.WindowStart,
+	}).Error
+}
+
+// UpdateApprovalPolicy sets the approval threshold and the number of
+// approvals required above it on a wallet.
+func (td *wallet) UpdateApprovalPolicy(ctx context.Context, walletID int, threshold int64, required int) error {
+	return td.db.WithContext(ctx).Model(&model.Wallet{}).Where("id = ?", walletID).
+		Updates(map[string]interface{}{"approval_threshold": threshold, "required_approvals": required}).Error
+}
+
+// CreateApproval inserts a new held transfer or withdrawal.
+func (td *wallet) CreateApproval(ctx context.Context, approval *model.WalletApproval) error {
+	return td.db.WithContext(ctx).Create(approval).Error
+}
+
+// FindApproval retrieves a held operation by ID with the owners who approved
+// it, returns ErrNotFound if not exists.
+func (td *wallet) FindApproval(ctx context.Context, id int) (*model.WalletApproval, error) {
+	var approval *model.WalletApproval
+	err := td.db.WithContext(ctx).Where("id = ?", id).Take(&approval).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := td.loadApprovers(ctx, approval); err != nil {
+		return nil, err
+	}
+	return approval, nil
+}
+
+// FindApprovals retrieves the held operations of a wallet, newest first.
+// An empty status returns them all.
+func (td *wallet) FindApprovals(ctx context.Context, walletID int, status model.ApprovalStatus) ([]model.WalletApproval, error) {
+	query := td.db.WithContext(ctx).Where("wallet_id = ?", walletID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	approvals := []model.WalletApproval{}
+	if err := query.Order("created_at DESC, id DESC").Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	for i := range approvals {
+		if err := td.loadApprovers(ctx, &approvals[i]); err != nil {
+			return nil, err
+		}
+	}
+	return approvals, nil
+}
+
+// AddApprovalVote records an owner's approval of a held operation and
+// returns the number of approvals it has. ErrAlreadyApproved is returned if
+// the owner approved it before.
+func (td *wallet) AddApprovalVote(ctx context.Context, approvalID int, memberID string) (int64, error) {
+	var count int64
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&model.WalletApprovalVote{ApprovalID: approvalID, MemberID: memberID}).Error
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return model.ErrAlreadyApproved
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.WalletApprovalVote{}).Where("approval_id = ?", approvalID).Count(&count).Error
+	})
+	return count, err
+}
+
+// UpdateApprovalStatus moves a held operation from one status to another and
+// returns it. The change only applies if the operation is still in the from
+// status, so concurrent reviews cannot both resolve it; ErrInvalidTransition
+// is returned otherwise. resolvedAt is recorded as the time it was resolved.
+func (td *wallet) UpdateApprovalStatus(ctx context.Context, id int, from, to model.ApprovalStatus, reason string, resolvedAt time.Time) (*model.WalletApproval, error) {
+	var approvals []model.WalletApproval
+	result := td.db.WithContext(ctx).Model(&approvals).Clauses(clause.Returning{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": to, "reason": reason, "resolved_at": resolvedAt})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, model.ErrInvalidTransition
+	}
+	if err := td.loadApprovers(ctx, &approvals[0]); err != nil {
+		return nil, err
+	}
+	return &approvals[0], nil
+}
+
+// ExecuteApproval marks a pending held operation executed within the
+// transaction tx carrying it out, so it is only marked once its funds moved
+// and its funds only move once. The row stays locked until tx ends, and
+// ErrInvalidTransition is returned if the operation is no longer pending, as
+// when a concurrent approval carried it out first.
+func (td *wallet) ExecuteApproval(tx *gorm.DB, id int, executedAt time.Time) error {
+	result := tx.Model(&model.WalletApproval{}).
+		Where("id = ? AND status = ?", id, model.ApprovalPending).
+		Updates(map[string]interface{}{"status": model.ApprovalExecuted, "resolved_at": executedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrInvalidTransition
+	}
+	return nil
+}
+
+// loadApprovers fills in the owners who approved a held operation, in the
+// order they approved it.
+func (td *wallet) loadApprovers(ctx context.Context, approval *model.WalletApproval) error {
+	approval.ApprovedBy = []string{}
+	return td.db.WithContext(ctx).Model(&model.WalletApprovalVote{}).
+		Where("approval_id = ?", approval.ID).Order("id").
+		Pluck("member_id", &approval.ApprovedBy).Error
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateMerchant creates the merchant wallet and its profile in one database
+// transaction, returns ErrMerchantExists if the user ID already has a wallet.
+func (td *wallet) CreateMerchant(ctx context.Context, wallet *model.Wallet, profile *model.MerchantProfile) error {
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(wallet).Error; err != nil {
+			return err
+		}
+		profile.WalletID, profile.UserID = wallet.ID, wallet.UserID
+		return tx.Create(profile).Error
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrMerchantExists
+	}
+	return err
+}
+
+// FindMerchant retrieves the profile of a merchant, returns ErrNotFound if not exists.
+func (td *wallet) FindMerchant(ctx context.Context, userID string) (*model.MerchantProfile, error) {
+	var profile *model.MerchantProfile
+	err := td.db.WithContext(ctx).Where("user_id = ?", userID).Take(&profile).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+// CreatePaymentIntent inserts a new payment intent, returns ErrReferenceTaken
+// if the merchant already used its reference.
+func (td *wallet) CreatePaymentIntent(ctx context.Context, intent *model.PaymentIntent) error {
+	err := td.db.WithContext(ctx).Create(intent).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrReferenceTaken
+	}
+	return err
+}
+
+// FindPaymentIntent retrieves a payment intent by ID, returns ErrNotFound if not exists.
+func (td *wallet) FindPaymentIntent(ctx context.Context, id int) (*model.PaymentIntent, error) {
+	var intent *model.PaymentIntent
+	err := td.db.WithContext(ctx).Where("id = ?", id).Take(&intent).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return intent, nil
+}
+
+// FindPaymentIntents retrieves the payment intents of a merchant, newest
+// first. An empty status returns them all.
+func (td *wallet) FindPaymentIntents(ctx context.Context, merchantID string, status model.PaymentIntentStatus) ([]model.PaymentIntent, error) {
+	query := td.db.WithContext(ctx).Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	intents := []model.PaymentIntent{}
+	if err := query.Order("created_at DESC, id DESC").Find(&intents).Error; err != nil {
+		return nil, err
+	}
+	return intents, nil
+}
+
+// ConfirmPaymentIntent pays a pending intent from the customer's wallet to
+// the merchant's in one database transaction and records the customer. The
+// intent row is locked first, so an intent is paid at most once;
+// ErrInvalidTransition is returned if it is no longer pending.
+func (td *wallet) ConfirmPaymentIntent(ctx context.Context, id int, customer *model.Wallet, merchantWalletID int, at time.Time) (*model.PaymentIntent, error) {
+	var intent model.PaymentIntent
+	err := td.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&intent).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return model.ErrNotFound
+			}
+			return err
+		}
+		if !intent.Status.CanTransition(model.IntentSucceeded) {
+			return model.ErrInvalidTransition
+		}
+		if err := td.ApplyBalanceChanges(tx,
+			model.BalanceChange{WalletID: customer.ID, Amount: -intent.Amount},
+			model.BalanceChange{WalletID: merchantWalletID, Amount: intent.Amount},
+		); err != nil {
+			return err
+		}
+
+		intent.Status, intent.CustomerID, intent.PaidAt = model.IntentSucceeded, customer.UserID, &at
+		return tx.Model(&intent).Updates(map[string]interface{}{
+			"status":      intent.Status,
+			"customer_id": intent.CustomerID,
+			"paid_at":     at,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+// CancelPaymentIntent moves a pending intent to cancelled and returns it,
+// ErrInvalidTransition if it is no longer pending.
+func (td *wallet) CancelPaymentIntent(ctx context.Context, id int, at time.Time) (*model.PaymentIntent, error) {
+	var intents []model.PaymentIntent
+	result := td.db.WithContext(ctx).Model(&intents).Clauses(clause.Returning{}).
+		Where("id = ? AND status = ?", id, model.IntentPending).
+		Updates(map[string]interface{}{"status": model.IntentCancelled, "cancelled_at": at})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, model.ErrInvalidTransition
+	}
+	return &intents[0], nil
+}
+
+// RefundPaymentIntent returns refund.Amount of a paid intent from the
+// merchant's wallet to the customer's in one database transaction and
+// records the refund. The intent row is locked first, so concurrent refunds
+// cannot together exceed the amount paid; ErrRefundExceedsPayment is
+// returned if this one would, and ErrInvalidTransition if the intent was
+// never paid.
+func (td *wallet) RefundPaymentIntent(ctx context.Context, refund *model.PaymentRefund, merchantWalletID, customerWalletID int) (*model.PaymentIntent, error) {
+	var intent model.PaymentIntent
+	err := td.WithTransaction(ctx, func(tx *gorm.DB) error {
+		// The transaction may be retried, so nothing from a failed attempt is reused
+		refund.ID = 0
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", refund.PaymentIntentID).Take(&intent).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return model.ErrNotFound
+			}
+			return err
+		}
+		if intent.Status != model.IntentSucceeded {
+			return model.ErrInvalidTransition
+		}
+		if refund.Amount > intent.Refundable() {
+			return model.ErrRefundExceedsPayment
+		}
+		if err := td.ApplyBalanceChanges(tx,
+			model.BalanceChange{WalletID: merchantWalletID, Amount: -refund.Amount},
+			model.BalanceChange{WalletID: customerWalletID, Amount: refund.Amount},
+		); err != nil {
+			return err
+		}
+
+		intent.RefundedAmount += refund.Amount
+		if err := tx.Model(&intent).Update("refunded_amount", intent.RefundedAmount).Error; err != nil {
+			return err
+		}
+		return tx.Create(refund).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+// FindSettlement retrieves the intents of a merchant paid, and its refunds
+// made, between from and to, in time order.
+func (td *wallet) FindSettlement(ctx context.Context, merchantID string, from, to time.Time) ([]model.PaymentIntent, []model.PaymentRefund, error) {
+	payments := []model.PaymentIntent{}
+	err := td.db.WithContext(ctx).
+		Where("merchant_id = ? AND status = ? AND paid_at >= ? AND paid_at < ?", merchantID, model.IntentSucceeded, from, to).
+		Order("paid_at, id").Find(&payments).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refunds := []model.PaymentRefund{}
+	err = td.db.WithContext(ctx).
+		Where("merchant_id = ? AND created_at >= ? AND created_at < ?", merchantID, from, to).
+		Order("created_at, id").Find(&refunds).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return payments, refunds, nil
+}
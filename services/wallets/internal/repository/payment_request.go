@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreatePaymentRequest inserts a new payment request.
+func (td *wallet) CreatePaymentRequest(ctx context.Context, request *model.PaymentRequest) error {
+	return td.db.WithContext(ctx).Create(request).Error
+}
+
+// FindPaymentRequest retrieves a payment request by ID, returns ErrNotFound if not exists.
+func (td *wallet) FindPaymentRequest(ctx context.Context, id int) (*model.PaymentRequest, error) {
+	var request *model.PaymentRequest
+	err := td.db.WithContext(ctx).Where("id = ?", id).Take(&request).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+// FindPaymentRequests retrieves the payment requests matching the filter, newest first.
+func (td *wallet) FindPaymentRequests(ctx context.Context, filter model.PaymentRequestFilter) ([]model.PaymentRequest, error) {
+	query := td.db.WithContext(ctx)
+	if filter.Incoming {
+		query = query.Where("payer_id = ?", filter.UserID)
+	} else {
+		query = query.Where("requester_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	requests := []model.PaymentRequest{}
+	if err := query.Order("created_at DESC, id DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// UpdatePaymentRequestStatus moves a payment request from one status to
+// another and returns the updated request. The change only applies if the
+// request is still in the from status, so concurrent changes cannot both
+// succeed; ErrInvalidTransition is returned otherwise. Moving a request to
+// accepted additionally requires it not to have expired. resolvedAt is
+// recorded as the time the request left the pending state.
+func (td *wallet) UpdatePaymentRequestStatus(ctx context.Context, id int, from, to model.PaymentRequestStatus, resolvedAt time.Time) (*model.PaymentRequest, error) {
+	return updatePaymentRequestStatus(td.db.WithContext(ctx), id, from, to, resolvedAt)
+}
+
+// AcceptPaymentRequest moves a pending payment request to accepted within tx,
+// so it commits or rolls back together with the payment, and returns it. It
+// returns ErrInvalidTransition if the request is no longer pending or has
+// expired.
+func (td *wallet) AcceptPaymentRequest(tx *gorm.DB, id int, at time.Time) (*model.PaymentRequest, error) {
+	return updatePaymentRequestStatus(tx, id, model.RequestPending, model.RequestAccepted, at)
+}
+
+func updatePaymentRequestStatus(db *gorm.DB, id int, from, to model.PaymentRequestStatus, resolvedAt time.Time) (*model.PaymentRequest, error) {
+	var requests []model.PaymentRequest
+	query := db.Model(&requests).Clauses(clause.Returning{}).
+		Where("id = ? AND status = ?", id, from)
+	if to == model.RequestAccepted {
+		query = query.Where("expires_at > ?", resolvedAt)
+	}
+
+	result := query.Updates(map[string]interface{}{"status": to, "resolved_at": resolvedAt})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, model.ErrInvalidTransition
+	}
+	return &requests[0], nil
+}
+
+// ExpirePaymentRequests moves the user's pending requests, sent or received,
+// whose expiry has passed to expired and returns them.
+func (td *wallet) ExpirePaymentRequests(ctx context.Context, userID string, now time.Time) ([]model.PaymentRequest, error) {
+	var requests []model.PaymentRequest
+	err := td.db.WithContext(ctx).Model(&requests).Clauses(clause.Returning{}).
+		Where("(requester_id = ? OR payer_id = ?) AND status = ? AND expires_at <= ?", userID, userID, model.RequestPending, now).
+		Updates(map[string]interface{}{"status": model.RequestExpired, "resolved_at": now}).Error
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// CreatePocket inserts a new pocket, returns ErrPocketExists if its owner
+// already has a pocket of that name.
+func (td *wallet) CreatePocket(ctx context.Context, pocket *model.Wallet) error {
+	err := td.db.WithContext(ctx).Create(pocket).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrPocketExists
+	}
+	return err
+}
+
+// FindPocket retrieves a pocket of a wallet by name, returns ErrNotFound if not exists.
+func (td *wallet) FindPocket(ctx context.Context, parentID int, name string) (*model.Wallet, error) {
+	var pocket *model.Wallet
+	err := td.db.WithContext(ctx).
+		Where("parent_id = ? AND pocket_name = ? AND acnt_type = ?", parentID, name, model.Pocket).
+		Take(&pocket).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return pocket, nil
+}
+
+// FindPockets retrieves every pocket of a wallet in name order.
+func (td *wallet) FindPockets(ctx context.Context, parentID int) ([]model.Wallet, error) {
+	pockets := []model.Wallet{}
+	err := td.db.WithContext(ctx).
+		Where("parent_id = ? AND acnt_type = ?", parentID, model.Pocket).
+		Order("pocket_name").Find(&pockets).Error
+	if err != nil {
+		return nil, err
+	}
+	return pockets, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// CreateProvider creates the provider wallet and its profile in one database
+// transaction, returns ErrProviderExists if the user ID already has a wallet.
+func (td *wallet) CreateProvider(ctx context.Context, wallet *model.Wallet, profile *model.ProviderProfile) error {
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(wallet).Error; err != nil {
+			return err
+		}
+		profile.WalletID, profile.UserID = wallet.ID, wallet.UserID
+		return tx.Create(profile).Error
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrProviderExists
+	}
+	if err == nil {
+		profile.Balance, profile.Status = wallet.Balance, wallet.Status
+	}
+	return err
+}
+
+// FindProvider retrieves the profile of a provider wallet with the wallet's
+// balance and status, returns ErrNotFound if there is no such provider.
+// Providers registered without a profile get an empty one.
+func (td *wallet) FindProvider(ctx context.Context, userID string) (*model.ProviderProfile, error) {
+	wallet, err := td.FindProviderWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := td.providerProfiles(ctx, []model.Wallet{*wallet})
+	if err != nil {
+		return nil, err
+	}
+	return &profiles[0], nil
+}
+
+// FindProviders retrieves the profiles of every provider wallet ordered by
+// wallet ID, with their balances and statuses.
+func (td *wallet) FindProviders(ctx context.Context) ([]model.ProviderProfile, error) {
+	var wallets []model.Wallet
+	if err := td.db.WithContext(ctx).Where("acnt_type = ?", model.Provider).Order("id").Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+	for i := range wallets {
+		if err := td.addShardBalance(ctx, &wallets[i]); err != nil {
+			return nil, err
+		}
+	}
+	return td.providerProfiles(ctx, wallets)
+}
+
+// SetProviderStatus enables or disables a provider wallet, returns
+// ErrNotFound if there is no such provider.
+func (td *wallet) SetProviderStatus(ctx context.Context, userID string, status model.Status) error {
+	result := td.db.WithContext(ctx).Model(&model.Wallet{}).
+		Where("user_id = ? AND acnt_type = ?", userID, model.Provider).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+// providerProfiles returns the profile of each provider wallet, in order,
+// with the wallet's balance and status.
+func (td *wallet) providerProfiles(ctx context.Context, wallets []model.Wallet) ([]model.ProviderProfile, error) {
+	profiles := make([]model.ProviderProfile, 0, len(wallets))
+	if len(wallets) == 0 {
+		return profiles, nil
+	}
+	ids := make([]int, len(wallets))
+	for i := range wallets {
+		ids[i] = wallets[i].ID
+	}
+
+	var stored []model.ProviderProfile
+	if err := td.db.WithContext(ctx).Where("wallet_id IN ?", ids).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	byWallet := make(map[int]model.ProviderProfile, len(stored))
+	for _, profile := range stored {
+		byWallet[profile.WalletID] = profile
+	}
+
+	for i := range wallets {
+		profile, ok := byWallet[wallets[i].ID]
+		if !ok {
+			profile = model.NewProviderProfile(&wallets[i])
+		}
+		profile.Balance, profile.Status = wallets[i].Balance, wallets[i].Status
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateSettlementBatch inserts a pending batch with its entries in one
+// database transaction. ErrSettlementExists is returned if the provider
+// already has a batch for the day or an entry is already in another batch.
+func (td *wallet) CreateSettlementBatch(ctx context.Context, batch *model.SettlementBatch, entries []model.SettlementEntry) error {
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		for i := range entries {
+			entries[i].BatchID = batch.ID
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.Create(&entries).Error
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return model.ErrSettlementExists
+	}
+	return err
+}
+
+// FindSettlementBatch retrieves a batch with its transaction IDs, returns ErrNotFound if not exists.
+func (td *wallet) FindSettlementBatch(ctx context.Context, id int) (*model.SettlementBatch, error) {
+	var batch model.SettlementBatch
+	err := td.db.WithContext(ctx).Where("id = ?", id).Take(&batch).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	batches := []model.SettlementBatch{batch}
+	if err := td.loadSettlementEntries(ctx, batches); err != nil {
+		return nil, err
+	}
+	return &batches[0], nil
+}
+
+// FindSettlementBatches retrieves the batches of a settlement day, or of
+// every day if date is empty, with their transaction IDs. An empty provider
+// ID returns the batches of every provider.
+func (td *wallet) FindSettlementBatches(ctx context.Context, date, providerID string) ([]model.SettlementBatch, error) {
+	query := td.db.WithContext(ctx)
+	if date != "" {
+		query = query.Where("settlement_date = ?", date)
+	}
+	if providerID != "" {
+		query = query.Where("provider_id = ?", providerID)
+	}
+
+	batches := []model.SettlementBatch{}
+	if err := query.Order("settlement_date DESC, provider_id").Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	if err := td.loadSettlementEntries(ctx, batches); err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// FindPendingSettlementBatches retrieves the batches not yet marked as
+// settled in the transactions service, oldest first.
+func (td *wallet) FindPendingSettlementBatches(ctx context.Context) ([]model.SettlementBatch, error) {
+	batches := []model.SettlementBatch{}
+	err := td.db.WithContext(ctx).Where("status = ?", model.SettlementPending).Order("id").Find(&batches).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := td.loadSettlementEntries(ctx, batches); err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// MarkSettlementBatchSettled moves a pending batch to settled and returns it,
+// ErrInvalidTransition if it is no longer pending.
+func (td *wallet) MarkSettlementBatchSettled(ctx context.Context, id int, at time.Time) (*model.SettlementBatch, error) {
+	var batches []model.SettlementBatch
+	result := td.db.WithContext(ctx).Model(&batches).Clauses(clause.Returning{}).
+		Where("id = ? AND status = ?", id, model.SettlementPending).
+		Updates(map[string]interface{}{"status": model.SettlementSettled, "settled_at": at})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, model.ErrInvalidTransition
+	}
+	if err := td.loadSettlementEntries(ctx, batches); err != nil {
+		return nil, err
+	}
+	return &batches[0], nil
+}
+
+// loadSettlementEntries fills in the transaction IDs of the given batches.
+func (td *wallet) loadSettlementEntries(ctx context.Context, batches []model.SettlementBatch) error {
+	if len(batches) == 0 {
+		return nil
+	}
+	index := make(map[int]int, len(batches))
+	ids := make([]int, len(batches))
+	for i := range batches {
+		batches[i].TransactionIDs = []int{}
+		index[batches[i].ID] = i
+		ids[i] = batches[i].ID
+	}
+
+	var entries []model.SettlementEntry
+	err := td.db.WithContext(ctx).Where("batch_id IN ?", ids).Order("transaction_id").Find(&entries).Error
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		batch := &batches[index[entry.BatchID]]
+		batch.TransactionIDs = append(batch.TransactionIDs, entry.TransactionID)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+)
+
+// CreateSnapshots records the ledger balance of every wallet at takenAt,
+// given by user ID; wallets missing from balances have no entries yet and
+// are recorded with a zero balance.
+func (td *wallet) CreateSnapshots(ctx context.Context, takenAt time.Time, balances map[string]int64) (int64, error) {
+	var wallets []model.Wallet
+	if err := td.db.WithContext(ctx).Select("id", "user_id").Find(&wallets).Error; err != nil {
+		return 0, err
+	}
+	if len(wallets) == 0 {
+		return 0, nil
+	}
+
+	snapshots := make([]model.BalanceSnapshot, len(wallets))
+	for i, w := range wallets {
+		snapshots[i] = model.BalanceSnapshot{WalletID: w.ID, Balance: balances[w.UserID], TakenAt: takenAt}
+	}
+	result := td.db.WithContext(ctx).CreateInBatches(snapshots, 1000)
+	return result.RowsAffected, result.Error
+}
+
+// FindLatestSnapshot retrieves the latest snapshot of a wallet taken at or
+// before at, returns ErrNotFound if there is none.
+func (td *wallet) FindLatestSnapshot(ctx context.Context, walletID int, at time.Time) (*model.BalanceSnapshot, error) {
+	var snapshot *model.BalanceSnapshot
+	err := td.db.WithContext(ctx).
+		Where("wallet_id = ? AND taken_at <= ?", walletID, at).
+		Order("taken_at desc").Take(&snapshot).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// DeleteSnapshotsBefore removes snapshots taken before the given time.
+func (td *wallet) DeleteSnapshotsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := td.db.WithContext(ctx).Where("taken_at < ?", before).Delete(&model.BalanceSnapshot{})
+	return result.RowsAffected, result.Error
+}
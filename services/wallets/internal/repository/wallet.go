@@ -2,92 +2,540 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// Postgres error codes for transactions that can safely be retried from scratch.
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgUniqueViolation      = "23505"
+)
+
 // Wallet provides database operations for wallet management.
 type Wallet interface {
 	// Wallet operations
-	Create(t *model.Wallet) error
-	FindByUserID(userID string) (*model.Wallet, error)
-	FindProviderWallet(providerID string) (*model.Wallet, error)
+	Create(ctx context.Context, t *model.Wallet) error
+	FindByID(ctx context.Context, id int) (*model.Wallet, error)
+	FindByUserID(ctx context.Context, userID string) (*model.Wallet, error)
+	FindProviderWallet(ctx context.Context, providerID string) (*model.Wallet, error)
+	FindAll(ctx context.Context) ([]model.Wallet, error)
 
 	// Atomic operations
-	BeginTransaction() *gorm.DB
-	UpdateWalletBalance(tx *gorm.DB, walletID int, amount int64, isCredit bool) error
+	BeginTransaction(ctx context.Context) *gorm.DB
+	WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+	ApplyBalanceChanges(tx *gorm.DB, changes ...model.BalanceChange) error
+	UpdateWalletBalance(tx *gorm.DB, wallet *model.Wallet, amount int64, isCredit bool) error
+	BalanceHeadroom(tx *gorm.DB, walletID int) (int64, bool, error)
+
+	// Sharding
+	ShardProviderWallets(ctx context.Context, shards int) error
+
+	// Balance snapshots
+	CreateSnapshots(ctx context.Context, takenAt time.Time, balances map[string]int64) (int64, error)
+	FindLatestSnapshot(ctx context.Context, walletID int, at time.Time) (*model.BalanceSnapshot, error)
+	DeleteSnapshotsBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// Aliases
+	CreateAlias(ctx context.Context, alias *model.WalletAlias) error
+	FindAlias(ctx context.Context, value string) (*model.WalletAlias, error)
+	FindAliasesByWalletID(ctx context.Context, walletID int) ([]model.WalletAlias, error)
+	ChallengeAlias(ctx context.Context, walletID int, value string, at time.Time) (*model.WalletAlias, string, error)
+	VerifyAlias(ctx context.Context, walletID int, value, code string, at time.Time) (*model.WalletAlias, error)
+
+	// Payment requests
+	CreatePaymentRequest(ctx context.Context, request *model.PaymentRequest) error
+	FindPaymentRequest(ctx context.Context, id int) (*model.PaymentRequest, error)
+	FindPaymentRequests(ctx context.Context, filter model.PaymentRequestFilter) ([]model.PaymentRequest, error)
+	UpdatePaymentRequestStatus(ctx context.Context, id int, from, to model.PaymentRequestStatus, resolvedAt time.Time) (*model.PaymentRequest, error)
+	AcceptPaymentRequest(tx *gorm.DB, id int, at time.Time) (*model.PaymentRequest, error)
+	ExpirePaymentRequests(ctx context.Context, userID string, now time.Time) ([]model.PaymentRequest, error)
+
+	// Escrow
+	CreateEscrow(ctx context.Context, escrow *model.EscrowAgreement, buyerWalletID int) error
+	FindEscrow(ctx context.Context, id int) (*model.EscrowAgreement, error)
+	FindExpiredEscrows(ctx context.Context, now time.Time, maxRefundAttempts int) ([]model.EscrowAgreement, error)
+	RecordEscrowRefundFailure(ctx context.Context, id int, reason string) (int, error)
+	SettleEscrow(ctx context.Context, id int, sellerAmount int64, at time.Time) (*model.EscrowAgreement, error)
+
+	// Pockets
+	CreatePocket(ctx context.Context, pocket *model.Wallet) error
+	FindPocket(ctx context.Context, parentID int, name string) (*model.Wallet, error)
+	FindPockets(ctx context.Context, parentID int) ([]model.Wallet, error)
+
+	// Members and approvals
+	CreateMember(ctx context.Context, member *model.WalletMember) error
+	FindMember(ctx context.Context, walletID int, memberID string) (*model.WalletMember, error)
+	FindMembers(ctx context.Context, walletID int) ([]model.WalletMember, error)
+	DeleteMember(ctx context.Context, walletID int, memberID string) error
+	RecordMemberSpend(tx *gorm.DB, walletID int, memberID string, amount int64, at time.Time) error
+	UpdateApprovalPolicy(ctx context.Context, walletID int, threshold int64, required int) error
+	CreateApproval(ctx context.Context, approval *model.WalletApproval) error
+	FindApproval(ctx context.Context, id int) (*model.WalletApproval, error)
+	FindApprovals(ctx context.Context, walletID int, status model.ApprovalStatus) ([]model.WalletApproval, error)
+	AddApprovalVote(ctx context.Context, approvalID int, memberID string) (int64, error)
+	UpdateApprovalStatus(ctx context.Context, id int, from, to model.ApprovalStatus, reason string, resolvedAt time.Time) (*model.WalletApproval, error)
+	ExecuteApproval(tx *gorm.DB, id int, executedAt time.Time) error
+
+	// Merchants
+	CreateMerchant(ctx context.Context, wallet *model.Wallet, profile *model.MerchantProfile) error
+	FindMerchant(ctx context.Context, userID string) (*model.MerchantProfile, error)
+	CreatePaymentIntent(ctx context.Context, intent *model.PaymentIntent) error
+	FindPaymentIntent(ctx context.Context, id int) (*model.PaymentIntent, error)
+	FindPaymentIntents(ctx context.Context, merchantID string, status model.PaymentIntentStatus) ([]model.PaymentIntent, error)
+	ConfirmPaymentIntent(ctx context.Context, id int, customer *model.Wallet, merchantWalletID int, at time.Time) (*model.PaymentIntent, error)
+	CancelPaymentIntent(ctx context.Context, id int, at time.Time) (*model.PaymentIntent, error)
+	RefundPaymentIntent(ctx context.Context, refund *model.PaymentRefund, merchantWalletID, customerWalletID int) (*model.PaymentIntent, error)
+	FindSettlement(ctx context.Context, merchantID string, from, to time.Time) ([]model.PaymentIntent, []model.PaymentRefund, error)
+
+	// Provider settlement
+	CreateSettlementBatch(ctx context.Context, batch *model.SettlementBatch, entries []model.SettlementEntry) error
+	FindSettlementBatch(ctx context.Context, id int) (*model.SettlementBatch, error)
+	FindSettlementBatches(ctx context.Context, date, providerID string) ([]model.SettlementBatch, error)
+	FindPendingSettlementBatches(ctx context.Context) ([]model.SettlementBatch, error)
+	MarkSettlementBatchSettled(ctx context.Context, id int, at time.Time) (*model.SettlementBatch, error)
+
+	// Providers
+	CreateProvider(ctx context.Context, wallet *model.Wallet, profile *model.ProviderProfile) error
+	FindProvider(ctx context.Context, userID string) (*model.ProviderProfile, error)
+	FindProviders(ctx context.Context) ([]model.ProviderProfile, error)
+	SetProviderStatus(ctx context.Context, userID string, status model.Status) error
+
+	// Closure
+	CloseWallet(ctx context.Context, wallet *model.Wallet, toWalletID int, at time.Time, authorize func(tx *gorm.DB, total int64) error) ([]model.Wallet, error)
+	SetWalletStatus(ctx context.Context, walletID int, from, to model.Status) error
+
+	// Dormancy
+	WithJobLock(ctx context.Context, name string, fn func() error) (bool, error)
+	FindDormancyCandidates(ctx context.Context, idleSince time.Time) ([]model.Wallet, error)
+	MarkWalletDormant(ctx context.Context, walletID int, idleSince time.Time, cfg model.Dormancy, toWalletID int) ([]model.Wallet, error)
+
+	// KYC
+	CreateKYCVerification(ctx context.Context, verification *model.KYCVerification) error
+	FindKYCVerification(ctx context.Context, id int) (*model.KYCVerification, error)
+	FindKYCVerifications(ctx context.Context, walletID int) ([]model.KYCVerification, error)
+	FindKYCVerificationsByStatus(ctx context.Context, status model.KYCStatus) ([]model.KYCVerification, error)
+	ReviewKYCVerification(ctx context.Context, id int, status model.KYCStatus, reviewer, note string, at time.Time) (*model.KYCVerification, error)
+
+	// Balance adjustments
+	CreateAdjustment(ctx context.Context, adjustment *model.BalanceAdjustment) error
+	FindAdjustment(ctx context.Context, id int) (*model.BalanceAdjustment, error)
+	FindAdjustments(ctx context.Context, status model.AdjustmentStatus) ([]model.BalanceAdjustment, error)
+	ReviewAdjustment(ctx context.Context, id int, status model.AdjustmentStatus, reviewer, note string, at time.Time, changes ...model.BalanceChange) (*model.BalanceAdjustment, error)
 }
 
 type wallet struct {
-	db *gorm.DB
+	db            *gorm.DB
+	concurrency   model.Concurrency
+	balanceLimits model.BalanceLimits
+	// shards caches the shard count per wallet ID; shards are only added at startup.
+	shards sync.Map
 }
 
 // NewWalletRepo creates a new wallet repository instance.
+// The concurrency control mode is taken from the global configuration.
 func NewWalletRepo(db *gorm.DB) Wallet {
 	return &wallet{
-		db: db,
+		db:            db,
+		concurrency:   config.GetConcurrency(),
+		balanceLimits: config.GetBalanceLimits(),
 	}
 }
 
 // Create inserts a new wallet record into the database.
-func (td *wallet) Create(t *model.Wallet) error {
-	if err := td.db.Create(t).Error; err != nil {
+func (td *wallet) Create(ctx context.Context, t *model.Wallet) error {
+	if err := td.db.WithContext(ctx).Create(t).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// FindByID retrieves a wallet by its primary key, returns ErrNotFound if not exists.
+func (td *wallet) FindByID(ctx context.Context, id int) (*model.Wallet, error) {
+	var wallet *model.Wallet
+	err := td.db.WithContext(ctx).Where("id = ?", id).Take(&wallet).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	if wallet.AcntType == model.Provider {
+		if err := td.addShardBalance(ctx, wallet); err != nil {
+			return nil, err
+		}
+	}
+	return wallet, nil
+}
+
 // FindByUserID retrieves a wallet by user ID, returns ErrNotFound if not exists.
-func (td *wallet) FindByUserID(userID string) (*model.Wallet, error) {
+func (td *wallet) FindByUserID(ctx context.Context, userID string) (*model.Wallet, error) {
 	var wallet *model.Wallet
-	err := td.db.Where("user_id = ?", userID).Take(&wallet).Error
+	err := td.db.WithContext(ctx).Where("user_id = ?", userID).Take(&wallet).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, model.ErrNotFound
 		}
 		return nil, err
 	}
+	if wallet.AcntType == model.Provider {
+		if err := td.addShardBalance(ctx, wallet); err != nil {
+			return nil, err
+		}
+	}
 	return wallet, nil
 }
 
 // FindProviderWallet retrieves a provider wallet by provider ID for system operations.
-func (td *wallet) FindProviderWallet(providerID string) (*model.Wallet, error) {
+func (td *wallet) FindProviderWallet(ctx context.Context, providerID string) (*model.Wallet, error) {
 	var wallet *model.Wallet
-	err := td.db.Where("user_id = ? AND acnt_type = ?", providerID, model.Provider).Take(&wallet).Error
+	err := td.db.WithContext(ctx).Where("user_id = ? AND acnt_type = ?", providerID, model.Provider).Take(&wallet).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, model.ErrNotFound
 		}
 		return nil, err
 	}
+	if err := td.addShardBalance(ctx, wallet); err != nil {
+		return nil, err
+	}
 	return wallet, nil
 }
 
+// FindAll retrieves every wallet ordered by ID. Balances of sharded provider
+// wallets only include the amount held on the wallet row.
+func (td *wallet) FindAll(ctx context.Context) ([]model.Wallet, error) {
+	var wallets []model.Wallet
+	if err := td.db.WithContext(ctx).Order("id").Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
 // BeginTransaction starts a new database transaction for atomic operations.
-func (td *wallet) BeginTransaction() *gorm.DB {
-	return td.db.Begin()
+// The transaction is rolled back by the driver if ctx is cancelled before commit.
+func (td *wallet) BeginTransaction(ctx context.Context) *gorm.DB {
+	return td.db.WithContext(ctx).Begin()
 }
 
-// UpdateWalletBalance atomically updates wallet balance
-// Used row-level Exclusive Locking to ensure single transaction can update the wallet balance at a time
-func (td *wallet) UpdateWalletBalance(tx *gorm.DB, walletID int, amount int64, isCredit bool) error {
-	var wallet model.Wallet
+// WithTransaction runs fn in a database transaction, retrying it from scratch
+// when Postgres aborts it with a deadlock or serialization failure, or when an
+// optimistic version check fails. fn must therefore be safe to re-run.
+func (td *wallet) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	var err error
+	for attempt := 0; attempt <= td.concurrency.MaxRetries; attempt++ {
+		if attempt > 0 {
+			// Short randomized pause so the competing transactions do not collide again
+			pause := time.Duration(rand.N(10*attempt)+1) * time.Millisecond
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(pause):
+			}
+		}
+
+		err = td.db.WithContext(ctx).Transaction(fn)
+		if !isRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// isRetryable reports whether err aborted a transaction that can be re-run.
+func isRetryable(err error) bool {
+	if errors.Is(err, model.ErrConcurrentUpdate) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+	}
+	return false
+}
+
+// ApplyBalanceChanges applies every change within tx. Changes are applied in
+// ascending wallet ID order, so concurrent operations on the same wallets always
+// lock them in the same order and cannot deadlock, and every debit is checked
+// against the balance under lock. Changes to sharded wallets go to one of their
+// shards; in atomic mode the remaining changes are single conditional UPDATEs,
+// otherwise the wallets are read (and in pessimistic mode locked) in one query.
+// Changes to closed or suspended wallets fail with model.ErrWalletClosed or
+// model.ErrWalletSuspended. A wallet's balance ceiling covers it and its
+// pockets together: credits taking their total above it fail as
+// model.BalanceLimits.CeilingError says. The parents of changed pockets are
+// locked too so the total is checked under the lock, which is why credits
+// under balance ceilings are never applied as atomic UPDATEs.
+func (td *wallet) ApplyBalanceChanges(tx *gorm.DB, changes ...model.BalanceChange) error {
+	changes = mergeBalanceChanges(changes)
+
+	rowChanges := make([]model.BalanceChange, 0, len(changes))
+	for _, change := range changes {
+		shards, err := td.shardCount(tx, change.WalletID)
+		if err != nil {
+			return err
+		}
+		if shards == 0 {
+			rowChanges = append(rowChanges, change)
+			continue
+		}
+		if err := applyShardChange(tx, change, shards); err != nil {
+			return err
+		}
+	}
+	if len(rowChanges) == 0 {
+		return nil
+	}
+
+	if td.concurrency.Mode == model.ConcurrencyAtomic && !td.creditsUnderCeiling(rowChanges) {
+		for _, change := range rowChanges {
+			if err := td.applyAtomicChange(tx, change); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ids := make([]int, len(rowChanges))
+	for i, change := range rowChanges {
+		ids[i] = change.WalletID
+	}
+	lockIDs := ids
+	if td.balanceLimits.Enable {
+		// A pocket's parent is never changed, so it can be read before the lock
+		var parents []int
+		if err := tx.Model(&model.Wallet{}).Where("id IN ? AND parent_id IS NOT NULL", ids).
+			Distinct().Pluck("parent_id", &parents).Error; err != nil {
+			return err
+		}
+		lockIDs = append(parents, ids...)
+	}
+	query := tx.Where("id IN ?", lockIDs).Order("id")
+	if td.concurrency.Mode != model.ConcurrencyOptimistic {
+		// Acquire row-level locks in ascending ID order
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var locked []model.Wallet
+	if err := query.Find(&locked).Error; err != nil {
+		return err
+	}
+	wallets := make(map[int]*model.Wallet, len(locked))
+	for i := range locked {
+		wallets[locked[i].ID] = &locked[i]
+	}
+	for _, id := range ids {
+		if wallets[id] == nil {
+			return model.ErrNotFound
+		}
+	}
+	if err := td.checkBalanceCeilings(tx, wallets, rowChanges); err != nil {
+		return err
+	}
 
-	// Acquire row-level lock
-	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		Where("id = ?", walletID).First(&wallet).Error; err != nil {
+	for _, change := range rowChanges {
+		wallet := wallets[change.WalletID]
+		if err := wallet.BalanceChangeError(); err != nil {
+			return err
+		}
+		amount, isCredit := change.Amount, true
+		if amount < 0 {
+			amount, isCredit = -amount, false
+		}
+		if err := td.UpdateWalletBalance(tx, wallet, amount, isCredit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBalanceCeilings returns the error of model.BalanceLimits.CeilingError
+// if the changes take a wallet and its pockets together above the wallet's
+// balance ceiling.
+// wallets holds the changed wallets and the parents of the pockets among them.
+func (td *wallet) checkBalanceCeilings(tx *gorm.DB, wallets map[int]*model.Wallet, changes []model.BalanceChange) error {
+	if !td.balanceLimits.Enable {
+		return nil
+	}
+	credits := make(map[int]int64)
+	changed := make(map[int]struct{}, len(changes))
+	for _, change := range changes {
+		changed[change.WalletID] = struct{}{}
+		root := change.WalletID
+		if parentID := wallets[root].ParentID; parentID != nil {
+			root = *parentID
+		}
+		credits[root] += change.Amount
+	}
+	for root, amount := range credits {
+		// Moves between a wallet and its pockets leave the total unchanged
+		if amount <= 0 || wallets[root] == nil {
+			continue
+		}
+		maxBalance := td.balanceLimits.MaxBalance(wallets[root])
+		if maxBalance == 0 {
+			continue
+		}
+		total, err := totalBalance(tx, root)
+		if err != nil {
+			return err
+		}
+		if total+amount > maxBalance {
+			return td.balanceLimits.CeilingError(wallets[root])
+		}
+		if _, ok := changed[root]; !ok && td.concurrency.Mode == model.ConcurrencyOptimistic {
+			// Credits to two pockets only conflict on their parent's version
+			if err := td.UpdateWalletBalance(tx, wallets[root], 0, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// totalBalance returns the balance of a wallet plus that of its pockets.
+func totalBalance(tx *gorm.DB, walletID int) (int64, error) {
+	var total int64
+	err := tx.Model(&model.Wallet{}).Where("id = ? OR parent_id = ?", walletID, walletID).
+		Select("COALESCE(SUM(balance), 0)").Scan(&total).Error
+	return total, err
+}
+
+// mergeBalanceChanges combines the changes per wallet and sorts them by wallet ID.
+func mergeBalanceChanges(changes []model.BalanceChange) []model.BalanceChange {
+	byID := make(map[int]int64, len(changes))
+	for _, change := range changes {
+		byID[change.WalletID] += change.Amount
+	}
+	merged := make([]model.BalanceChange, 0, len(byID))
+	for id, amount := range byID {
+		merged = append(merged, model.BalanceChange{WalletID: id, Amount: amount})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].WalletID < merged[j].WalletID })
+	return merged
+}
+
+// creditsUnderCeiling reports whether any of the changes credits a wallet
+// while balance ceilings are enforced.
+func (td *wallet) creditsUnderCeiling(changes []model.BalanceChange) bool {
+	if !td.balanceLimits.Enable {
+		return false
+	}
+	for _, change := range changes {
+		if change.Amount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyAtomicChange applies a change as a single UPDATE that only debits the
+// wallet if the balance covers it, and only changes it if it is neither closed
+// nor suspended.
+// The row lock is taken by the UPDATE itself and held for the shortest
+// possible time.
+func (td *wallet) applyAtomicChange(tx *gorm.DB, change model.BalanceChange) error {
+	query := tx.Model(&model.Wallet{}).
+		Where("id = ?", change.WalletID).
+		Where("status IS DISTINCT FROM ?", model.Closed).
+		Where("status IS DISTINCT FROM ? OR acnt_type = ?", model.Suspended, model.Provider)
+	if change.Amount < 0 {
+		query = query.Where("balance >= ?", -change.Amount)
+	}
+	result := query.Updates(map[string]interface{}{
+		"balance": gorm.Expr("balance + ?", change.Amount),
+		"version": gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// Nothing was updated: the wallet does not exist, is closed or suspended,
+	// or cannot cover the debit
+	var wallet model.Wallet
+	if err := tx.Select("id", "status", "acnt_type").Where("id = ?", change.WalletID).Take(&wallet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrNotFound
+		}
+		return err
+	}
+	if err := wallet.BalanceChangeError(); err != nil {
 		return err
 	}
+	return model.ErrInsufficientFunds
+}
 
+// BalanceHeadroom returns how much more the wallet may be credited before it
+// and its pockets together reach its balance ceiling, and false if it has
+// none. Wallets with balance shards have no ceiling.
+func (td *wallet) BalanceHeadroom(tx *gorm.DB, walletID int) (int64, bool, error) {
+	var wallet model.Wallet
+	if err := tx.Where("id = ?", walletID).Take(&wallet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, model.ErrNotFound
+		}
+		return 0, false, err
+	}
+	maxBalance := td.balanceLimits.MaxBalance(&wallet)
+	if maxBalance == 0 {
+		return 0, false, nil
+	}
+	shards, err := td.shardCount(tx, walletID)
+	if err != nil || shards > 0 {
+		return 0, false, err
+	}
+	total, err := totalBalance(tx, walletID)
+	if err != nil {
+		return 0, false, err
+	}
+	return max(maxBalance-total, 0), true, nil
+}
+
+// UpdateWalletBalance updates the balance of a wallet previously read within tx.
+// In pessimistic mode the row is already locked; in optimistic mode the update
+// only applies if the version is unchanged since the read, and
+// model.ErrConcurrentUpdate is returned otherwise. Balance ceilings are left
+// to ApplyBalanceChanges, which checks them over the wallet and its pockets.
+func (td *wallet) UpdateWalletBalance(tx *gorm.DB, wallet *model.Wallet, amount int64, isCredit bool) error {
+	balance := wallet.Balance
 	if isCredit {
-		wallet.Balance += amount
+		balance += amount
 	} else {
-		wallet.Balance -= amount
-		if wallet.Balance < 0 {
+		balance -= amount
+		if balance < 0 {
 			return model.ErrInsufficientFunds
 		}
 	}
 
-	return tx.Save(&wallet).Error
+	query := tx.Model(&model.Wallet{}).Where("id = ?", wallet.ID)
+	if td.concurrency.Mode == model.ConcurrencyOptimistic {
+		query = query.Where("version = ?", wallet.Version)
+	}
+	result := query.Updates(map[string]interface{}{
+		"balance": balance,
+		"version": gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrConcurrentUpdate
+	}
+
+	wallet.Balance = balance
+	wallet.Version++
+	return nil
 }
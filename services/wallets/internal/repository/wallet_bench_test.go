@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// BenchmarkParallelDeposits measures deposit throughput when every deposit
+// debits the same provider wallet, for each concurrency mode with and without
+// provider sharding. Each parallel worker credits its own user wallet so the
+// provider is the only point of contention. Run with:
+//
+//	go test -run '^$' -bench ParallelDeposits -cpu 1,8,32 ./internal/repository/
+func BenchmarkParallelDeposits(b *testing.B) {
+	dbInstance, err := db.NewTestDB()
+	require.NoError(b, err)
+	require.NoError(b, db.Migrate(dbInstance))
+
+	cases := []struct {
+		mode   string
+		shards int
+	}{
+		{model.ConcurrencyPessimistic, 0},
+		{model.ConcurrencyOptimistic, 0},
+		{model.ConcurrencyAtomic, 0},
+		{model.ConcurrencyAtomic, 16},
+	}
+	for _, tc := range cases {
+		b.Run(fmt.Sprintf("mode=%s/shards=%d", tc.mode, tc.shards), func(b *testing.B) {
+			clearWallets(dbInstance)
+			b.Cleanup(func() { clearWallets(dbInstance) })
+
+			ctx := context.Background()
+			repo := &wallet{db: dbInstance, concurrency: model.Concurrency{Mode: tc.mode, MaxRetries: 1000}}
+			provider := model.NewWallet("bench-provider", model.Provider)
+			provider.Balance = 1 << 50
+			require.NoError(b, dbInstance.Create(provider).Error)
+			require.NoError(b, repo.ShardProviderWallets(ctx, tc.shards))
+
+			var workers atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				user := model.NewWallet(fmt.Sprintf("bench-user-%d", workers.Add(1)), model.User)
+				if err := dbInstance.Create(user).Error; err != nil {
+					b.Error(err)
+					return
+				}
+				for pb.Next() {
+					err := repo.WithTransaction(ctx, func(tx *gorm.DB) error {
+						return repo.ApplyBalanceChanges(tx,
+							model.BalanceChange{WalletID: provider.ID, Amount: -1},
+							model.BalanceChange{WalletID: user.ID, Amount: 1},
+						)
+					})
+					if err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+			b.StopTimer()
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "deposits/s")
+		})
+	}
+}
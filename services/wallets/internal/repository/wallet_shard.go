@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ShardProviderWallets splits every provider wallet into the given number of
+// shards. Missing shards are created and the balance held on the wallet row is
+// spread evenly over all shards, so provisioning can be repeated safely and
+// the shard count can be raised later. Shards are never removed.
+func (td *wallet) ShardProviderWallets(ctx context.Context, shards int) error {
+	if shards <= 1 {
+		return nil
+	}
+
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var providers []model.Wallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("acnt_type = ?", model.Provider).Order("id").Find(&providers).Error; err != nil {
+			return err
+		}
+
+		for _, provider := range providers {
+			var existing int64
+			if err := tx.Model(&model.WalletShard{}).Where("wallet_id = ?", provider.ID).Count(&existing).Error; err != nil {
+				return err
+			}
+			for shard := int(existing); shard < shards; shard++ {
+				if err := tx.Create(&model.WalletShard{WalletID: provider.ID, Shard: shard}).Error; err != nil {
+					return err
+				}
+			}
+			if provider.Balance == 0 {
+				continue
+			}
+
+			// Spread the row balance over the shards, the remainder going to shard 0
+			share := provider.Balance / int64(shards)
+			if err := tx.Model(&model.WalletShard{}).
+				Where("wallet_id = ? AND shard < ?", provider.ID, shards).
+				Update("balance", gorm.Expr("balance + ?", share)).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.WalletShard{}).
+				Where("wallet_id = ? AND shard = 0", provider.ID).
+				Update("balance", gorm.Expr("balance + ?", provider.Balance-share*int64(shards))).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.Wallet{}).Where("id = ?", provider.ID).Updates(map[string]interface{}{
+				"balance": 0,
+				"version": gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Drop cached counts so the new shards are picked up
+	td.shards.Clear()
+	return nil
+}
+
+// shardCount returns the number of shards of a wallet, 0 if it is not sharded.
+func (td *wallet) shardCount(tx *gorm.DB, walletID int) (int, error) {
+	if count, ok := td.shards.Load(walletID); ok {
+		return count.(int), nil
+	}
+
+	var count int64
+	if err := tx.Model(&model.WalletShard{}).Where("wallet_id = ?", walletID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	td.shards.Store(walletID, int(count))
+	return int(count), nil
+}
+
+// applyShardChange applies a change to a single shard of a sharded wallet.
+// Credits go to a random shard. Debits start at a random shard and move on
+// to the next one until a shard can cover the whole amount; a debit larger
+// than every single shard fails even if the shards could cover it together.
+func applyShardChange(tx *gorm.DB, change model.BalanceChange, shards int) error {
+	start := rand.N(shards)
+	if change.Amount >= 0 {
+		return tx.Model(&model.WalletShard{}).
+			Where("wallet_id = ? AND shard = ?", change.WalletID, start).
+			Update("balance", gorm.Expr("balance + ?", change.Amount)).Error
+	}
+
+	for i := 0; i < shards; i++ {
+		result := tx.Model(&model.WalletShard{}).
+			Where("wallet_id = ? AND shard = ? AND balance >= ?", change.WalletID, (start+i)%shards, -change.Amount).
+			Update("balance", gorm.Expr("balance + ?", change.Amount))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+	}
+	return model.ErrInsufficientFunds
+}
+
+// addShardBalance adds the balance held by the wallet's shards to wallet.Balance.
+func (td *wallet) addShardBalance(ctx context.Context, wallet *model.Wallet) error {
+	var sum int64
+	if err := td.db.WithContext(ctx).Model(&model.WalletShard{}).
+		Where("wallet_id = ?", wallet.ID).
+		Select("COALESCE(SUM(balance), 0)").Scan(&sum).Error; err != nil {
+		return err
+	}
+	wallet.Balance += sum
+	return nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestWallet_ConcurrentTransfers runs opposing transfers between the same
+// wallets in parallel. Every transfer must either commit or fail cleanly with
+// insufficient funds, and no money may be created or lost.
+func TestWallet_ConcurrentTransfers(t *testing.T) {
+	const (
+		workers        = 8
+		transfersEach  = 25
+		initialBalance = int64(1000)
+		amount         = int64(70)
+	)
+
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbInstance))
+
+	for _, mode := range []string{model.ConcurrencyPessimistic, model.ConcurrencyOptimistic, model.ConcurrencyAtomic} {
+		t.Run(mode, func(t *testing.T) {
+			clearWallets(dbInstance)
+			t.Cleanup(func() { clearWallets(dbInstance) })
+
+			repo := &wallet{
+				db: dbInstance,
+				// Optimistic mode needs enough retries to get through heavy contention
+				concurrency: model.Concurrency{Mode: mode, MaxRetries: workers * transfersEach},
+			}
+
+			a := createWallet(t, dbInstance, "stress-user-a", initialBalance)
+			b := createWallet(t, dbInstance, "stress-user-b", initialBalance)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, workers*transfersEach)
+			for i := 0; i < workers; i++ {
+				// Half the workers move A→B, the other half B→A
+				from, to := a, b
+				if i%2 == 1 {
+					from, to = b, a
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < transfersEach; j++ {
+						errs <- repo.WithTransaction(context.Background(), func(tx *gorm.DB) error {
+							return repo.ApplyBalanceChanges(tx,
+								model.BalanceChange{WalletID: from.ID, Amount: -amount},
+								model.BalanceChange{WalletID: to.ID, Amount: amount},
+							)
+						})
+					}
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				if err != nil && !errors.Is(err, model.ErrInsufficientFunds) {
+					t.Errorf("unexpected transfer error: %v", err)
+				}
+			}
+
+			var got []model.Wallet
+			require.NoError(t, dbInstance.Where("id IN ?", []int{a.ID, b.ID}).Find(&got).Error)
+			require.Len(t, got, 2)
+			assert.Equal(t, 2*initialBalance, got[0].Balance+got[1].Balance, "total balance must be conserved")
+			for _, w := range got {
+				assert.GreaterOrEqual(t, w.Balance, int64(0), "wallet %s overdrawn", w.UserID)
+			}
+		})
+	}
+}
+
+func TestWallet_ApplyBalanceChanges_InsufficientFunds(t *testing.T) {
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbInstance))
+	clearWallets(dbInstance)
+	t.Cleanup(func() { clearWallets(dbInstance) })
+
+	repo := &wallet{db: dbInstance, concurrency: model.DefaultConcurrency()}
+	a := createWallet(t, dbInstance, "stress-user-a", 100)
+	b := createWallet(t, dbInstance, "stress-user-b", 0)
+
+	err = repo.WithTransaction(context.Background(), func(tx *gorm.DB) error {
+		return repo.ApplyBalanceChanges(tx,
+			model.BalanceChange{WalletID: b.ID, Amount: 101},
+			model.BalanceChange{WalletID: a.ID, Amount: -101},
+		)
+	})
+	assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+
+	// The credit applied before the failed debit must be rolled back
+	var got model.Wallet
+	require.NoError(t, dbInstance.First(&got, b.ID).Error)
+	assert.Equal(t, int64(0), got.Balance)
+}
+
+func TestWallet_ShardProviderWallets(t *testing.T) {
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbInstance))
+	clearWallets(dbInstance)
+	t.Cleanup(func() { clearWallets(dbInstance) })
+
+	ctx := context.Background()
+	repo := &wallet{db: dbInstance, concurrency: model.Concurrency{Mode: model.ConcurrencyAtomic, MaxRetries: 3}}
+	provider := createWalletOfType(t, dbInstance, "stress-provider", model.Provider, 1003)
+	user := createWallet(t, dbInstance, "stress-user-a", 0)
+
+	require.NoError(t, repo.ShardProviderWallets(ctx, 4))
+	// Provisioning again must not move funds twice
+	require.NoError(t, repo.ShardProviderWallets(ctx, 4))
+
+	var shards []model.WalletShard
+	require.NoError(t, dbInstance.Where("wallet_id = ?", provider.ID).Order("shard").Find(&shards).Error)
+	require.Len(t, shards, 4)
+	assert.Equal(t, []int64{253, 250, 250, 250}, []int64{shards[0].Balance, shards[1].Balance, shards[2].Balance, shards[3].Balance})
+
+	got, err := repo.FindProviderWallet(ctx, "stress-provider")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1003), got.Balance, "reported balance must include the shards")
+
+	// Debits are spread over the shards until no single shard can cover one
+	var succeeded int
+	for i := 0; i < 10; i++ {
+		err := repo.WithTransaction(ctx, func(tx *gorm.DB) error {
+			return repo.ApplyBalanceChanges(tx,
+				model.BalanceChange{WalletID: provider.ID, Amount: -200},
+				model.BalanceChange{WalletID: user.ID, Amount: 200},
+			)
+		})
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+	}
+	assert.Equal(t, 4, succeeded)
+
+	got, err = repo.FindProviderWallet(ctx, "stress-provider")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1003-4*200), got.Balance)
+	gotUser, err := repo.FindByUserID(ctx, "stress-user-a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4*200), gotUser.Balance)
+}
+
+func createWallet(t *testing.T, db *gorm.DB, userID string, balance int64) *model.Wallet {
+	return createWalletOfType(t, db, userID, model.User, balance)
+}
+
+func createWalletOfType(t *testing.T, db *gorm.DB, userID string, acntType model.AcntType, balance int64) *model.Wallet {
+	wallet := model.NewWallet(userID, acntType)
+	wallet.Balance = balance
+	require.NoError(t, db.Create(wallet).Error)
+	return wallet
+}
+
+func clearWallets(db *gorm.DB) {
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&model.WalletShard{})
+	db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&model.Wallet{})
+}
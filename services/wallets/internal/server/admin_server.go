@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/controller"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+)
+
+// adminServer serves the /admin endpoints on an internal port of their own,
+// apart from the public API routed through the gateway.
+type adminServer struct {
+	port   int
+	engine *echo.Echo
+	log    *log.Entry
+	// baseCtx is shared by all in-flight requests; cancel aborts it.
+	baseCtx context.Context
+	cancel  context.CancelFunc
+}
+
+// AdminServerOpts is the options for the adminServer
+type AdminServerOpts struct {
+	ListenPort int
+	Config     model.Config
+}
+
+// NewAdmin returns a new instance of the admin server. It refuses to start
+// without operators, as no request could be authenticated, or when a token
+// is unset or still a placeholder, as anyone could guess it.
+func NewAdmin(opts AdminServerOpts) (Server, error) {
+	logger := log.NewEntry(log.StandardLogger())
+	log.SetFormatter(&log.JSONFormatter{})
+
+	if len(opts.Config.AdminServer.Operators) == 0 {
+		return nil, fmt.Errorf("admin server has no operators configured")
+	}
+	if err := opts.Config.AdminServer.TokenError(); err != nil {
+		return nil, fmt.Errorf("admin server: %v", err)
+	}
+
+	dbInstance, err := db.New(opts.Config.PostgreSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+
+	engine := echo.New()
+	engine.HideBanner = true
+
+	baseCtx, cancel := context.WithCancel(context.Background())
+	engine.Server.BaseContext = func(net.Listener) context.Context { return baseCtx }
+
+	engine.Use(otelecho.Middleware(tracing.ServiceName(opts.Config.Tracing)))
+	engine.Use(requestLogger())
+	engine.Validator = controller.NewCustomValidator()
+
+	walletHandler := controller.NewWalletController(service.NewWalletService(repository.NewWalletRepo(dbInstance)))
+	admin := engine.Group("/api/v1/admin", controller.AdminAuth(opts.Config.AdminServer.Operators))
+	controller.InitAdminRoutes(admin, walletHandler)
+
+	s := &adminServer{
+		port:    opts.ListenPort,
+		engine:  engine,
+		log:     logger,
+		baseCtx: baseCtx,
+		cancel:  cancel,
+	}
+	return s, nil
+}
+
+func (s *adminServer) Name() string {
+	return "adminServer"
+}
+
+func (s *adminServer) Run() error {
+	log.Infof("%s serving on port %d", s.Name(), s.port)
+	return s.engine.Start(fmt.Sprintf(":%d", s.port))
+}
+
+func (s *adminServer) Shutdown(ctx context.Context) error {
+	log.Infof("shutting down %s serving on port %d", s.Name(), s.port)
+	err := s.engine.Shutdown(ctx)
+	s.cancel()
+	return err
+}
@@ -2,12 +2,23 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/controller"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/job"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
 	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
@@ -34,8 +45,33 @@ func NewAPI(opts WalletAPIServerOpts) (Server, error) {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
+	sqlDB, err := dbInstance.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database handle: %v", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB); err != nil {
+		return nil, fmt.Errorf("failed to register database metrics: %v", err)
+	}
+
+	// Spread hot provider wallets over sub-accounts before serving traffic
+	if shards := opts.Config.Concurrency.ProviderShards; shards > 1 {
+		if err := repository.NewWalletRepo(dbInstance).ShardProviderWallets(context.Background(), shards); err != nil {
+			return nil, fmt.Errorf("failed to shard provider wallets: %v", err)
+		}
+	}
+
 	engine := echo.New()
 
+	// Every request context derives from baseCtx so that shutdown can cancel them.
+	baseCtx, cancel := context.WithCancel(context.Background())
+	engine.Server.BaseContext = func(net.Listener) context.Context { return baseCtx }
+
+	// Start a server span per request, continuing any incoming W3C trace context
+	engine.Use(otelecho.Middleware(tracing.ServiceName(opts.Config.Tracing)))
+
+	// Record request count and latency per route
+	engine.Use(metrics.Middleware())
+
 	// Allow all origins for CORS
 	engine.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
@@ -44,10 +80,27 @@ func NewAPI(opts WalletAPIServerOpts) (Server, error) {
 	}))
 
 	s := &walletAPIServer{
-		port:   opts.ListenPort,
-		engine: engine,
-		log:    logger,
-		db:     dbInstance,
+		port:    opts.ListenPort,
+		engine:  engine,
+		log:     logger,
+		db:      dbInstance,
+		baseCtx: baseCtx,
+		cancel:  cancel,
+	}
+	if opts.Config.Snapshots.Enable {
+		s.snapshotJob = job.NewSnapshotJob(repository.NewWalletRepo(dbInstance), opts.Config.Snapshots)
+	}
+	if opts.Config.EscrowExpiry.Enable {
+		s.escrowJob = job.NewEscrowExpiryJob(service.NewWalletService(repository.NewWalletRepo(dbInstance)), opts.Config.EscrowExpiry)
+	}
+	if opts.Config.Settlement.Enable {
+		s.settlementJob = job.NewSettlementJob(service.NewWalletService(repository.NewWalletRepo(dbInstance)), opts.Config.Settlement)
+	}
+	if opts.Config.Providers.Enable {
+		s.providerFloatJob = job.NewProviderFloatJob(service.NewWalletService(repository.NewWalletRepo(dbInstance)), opts.Config.Providers)
+	}
+	if opts.Config.Dormancy.Enable {
+		s.dormancyJob = job.NewDormancyJob(service.NewWalletService(repository.NewWalletRepo(dbInstance)), opts.Config.Dormancy)
 	}
 
 	s.setupRoutes(engine)
@@ -72,15 +125,56 @@ func (s *walletAPIServer) initWalletController() controller.WalletHandler {
 	return walletController
 }
 
+// readinessCacheTTL is how long a readiness result is reused before the
+// dependencies are probed again.
+const readinessCacheTTL = 2 * time.Second
+
+// initHealthController creates the health handler with the readiness checks.
+// Redis only backs the transaction history cache, so losing it degrades the
+// service instead of taking it out of rotation.
+func (s *walletAPIServer) initHealthController() controller.HealthHandler {
+	healthService := service.NewHealthService(readinessCacheTTL,
+		service.Check{Name: "postgres", Critical: true, Probe: func(ctx context.Context) error {
+			sqlDB, err := s.db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}},
+		service.Check{Name: "migrations", Critical: true, Probe: func(ctx context.Context) error {
+			pending, err := db.PendingMigrations(s.db.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending: %s", len(pending), strings.Join(pending, ", "))
+			}
+			return nil
+		}},
+		service.Check{Name: "redis", Critical: false, Probe: func(ctx context.Context) error {
+			return cache.NewRedisClient().Ping(ctx)
+		}},
+		service.Check{Name: "transactions", Critical: true, Probe: func(ctx context.Context) error {
+			return client.NewTxnClient().Ping(ctx)
+		}},
+	)
+	return controller.NewHealth(healthService)
+}
+
 // setupRoutes registers the routes for the application.
 func (s *walletAPIServer) setupRoutes(e *echo.Echo) {
 	e.Validator = controller.NewCustomValidator()
 
+	// Prometheus scrape endpoint
+	e.GET("/metrics", metrics.Handler())
+
 	api := e.Group("/api/v1")
 
 	// Health check
-	healthHandler := controller.NewHealth()
+	healthHandler := s.initHealthController()
 	api.GET("/health", healthHandler.Health)
+	api.GET("/health/live", healthHandler.Live)
+	api.GET("/health/ready", healthHandler.Ready)
 
 	walletHandler := s.initWalletController()
 
@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"fmt"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/job"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -14,6 +16,20 @@ type walletAPIServer struct {
 	engine *echo.Echo
 	log    *log.Entry
 	db     *gorm.DB
+	// baseCtx is shared by all in-flight requests and background jobs;
+	// cancel aborts it.
+	baseCtx context.Context
+	cancel  context.CancelFunc
+	// snapshotJob takes periodic balance snapshots; nil when disabled.
+	snapshotJob *job.Snapshot
+	// escrowJob refunds escrows past their deadline; nil when disabled.
+	escrowJob *job.EscrowExpiry
+	// settlementJob settles the provider wallets daily; nil when disabled.
+	settlementJob *job.Settlement
+	// providerFloatJob alerts on provider wallets low on float; nil when disabled.
+	providerFloatJob *job.ProviderFloat
+	// dormancyJob marks wallets without activity inactive; nil when disabled.
+	dormancyJob *job.Dormancy
 }
 
 func (s *walletAPIServer) Name() string {
@@ -22,6 +38,21 @@ func (s *walletAPIServer) Name() string {
 
 // Run starts the Wallet API server
 func (s *walletAPIServer) Run() error {
+	if s.snapshotJob != nil {
+		go s.snapshotJob.Run(s.baseCtx)
+	}
+	if s.escrowJob != nil {
+		go s.escrowJob.Run(s.baseCtx)
+	}
+	if s.settlementJob != nil {
+		go s.settlementJob.Run(s.baseCtx)
+	}
+	if s.providerFloatJob != nil {
+		go s.providerFloatJob.Run(s.baseCtx)
+	}
+	if s.dormancyJob != nil {
+		go s.dormancyJob.Run(s.baseCtx)
+	}
 	log.Infof("%s serving on port %d", s.Name(), s.port)
 	return s.engine.Start(fmt.Sprintf(":%d", s.port))
 }
@@ -29,5 +60,10 @@ func (s *walletAPIServer) Run() error {
 // Shutdown stops the Wallet API server
 func (s *walletAPIServer) Shutdown(ctx context.Context) error {
 	log.Infof("shutting down %s serving on port %d", s.Name(), s.port)
-	return s.engine.Shutdown(ctx)
+	err := s.engine.Shutdown(ctx)
+	// Requests still running once the grace period is over have their
+	// contexts cancelled so DB queries and outbound calls stop promptly.
+	// This also stops the background jobs.
+	s.cancel()
+	return err
 }
@@ -78,3 +78,16 @@ func TestNewAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAdmin_WithoutOperators(t *testing.T) {
+	server, err := NewAdmin(AdminServerOpts{ListenPort: 8083, Config: model.Config{AdminServer: model.AdminServer{Enable: true, Port: 8083}}})
+	require.Error(t, err)
+	assert.Nil(t, server)
+}
+
+func TestNewAdmin_WithPlaceholderToken(t *testing.T) {
+	operators := []model.Operator{{Name: "ops-1", TokenEnv: "WALLET_ADMIN_TOKEN_OPS_1", Token: "dev-ops-1-token-change-me"}}
+	server, err := NewAdmin(AdminServerOpts{ListenPort: 8083, Config: model.Config{AdminServer: model.AdminServer{Enable: true, Port: 8083, Operators: operators}}})
+	require.Error(t, err)
+	assert.Nil(t, server)
+}
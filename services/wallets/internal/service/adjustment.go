@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ProposeAdjustment records a credit or debit of amount cents to a wallet,
+// proposed by the authenticated operator proposedBy with a reason and
+// evidence. Nothing moves until a different operator approves it with
+// ReviewAdjustment.
+func (t *wallet) ProposeAdjustment(ctx context.Context, userID string, operationType model.OperationType, amount int, reason string, evidence []string, proposedBy string) (_ *model.BalanceAdjustment, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ProposeAdjustment",
+		tracing.AttrUserID.String(userID),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+		attribute.String("operation_type", string(operationType)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if amount <= 0 || (operationType != model.Credit && operationType != model.Debit) {
+		return nil, model.ErrInvalidAmount
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, model.ErrReasonRequired
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	userWallet, err := t.findAdjustedWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userWallet.Status == model.Closed {
+		return nil, model.ErrWalletClosed
+	}
+
+	if evidence == nil {
+		evidence = []string{}
+	}
+	adjustment := &model.BalanceAdjustment{
+		WalletID:      userWallet.ID,
+		UserID:        userWallet.UserID,
+		OperationType: operationType,
+		Amount:        int64(amount),
+		Reason:        reason,
+		Evidence:      evidence,
+		Status:        model.AdjustmentPending,
+		ProposedBy:    proposedBy,
+	}
+	if err := t.walletRepository.CreateAdjustment(ctx, adjustment); err != nil {
+		utils.LogError("Failed to create balance adjustment", err)
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"adjustment_id":  adjustment.ID,
+		"user_id":        adjustment.UserID,
+		"operation_type": adjustment.OperationType,
+		"amount":         adjustment.Amount,
+		"proposed_by":    proposedBy,
+	}).Info("balance adjustment proposed")
+	return adjustment, nil
+}
+
+// GetAdjustment returns a balance adjustment by ID.
+func (t *wallet) GetAdjustment(ctx context.Context, id int) (_ *model.BalanceAdjustment, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetAdjustment",
+		attribute.Int("adjustment_id", id),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	return t.walletRepository.FindAdjustment(ctx, id)
+}
+
+// ListAdjustments returns the balance adjustments in a status, the review
+// queue for pending.
+func (t *wallet) ListAdjustments(ctx context.Context, status model.AdjustmentStatus) (_ []model.BalanceAdjustment, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListAdjustments",
+		attribute.String("adjustment_status", string(status)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	return t.walletRepository.FindAdjustments(ctx, status)
+}
+
+// ReviewAdjustment approves or rejects a pending balance adjustment on
+// behalf of the authenticated operator reviewer, who must not be the one who
+// proposed it.
+// Approval applies the change against the adjustments provider wallet and
+// records an adjustment ledger pair; if the change fails, for instance on
+// insufficient funds, the adjustment stays pending.
+func (t *wallet) ReviewAdjustment(ctx context.Context, id int, approve bool, reviewer, note string) (_ *model.BalanceAdjustment, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ReviewAdjustment",
+		attribute.Int("adjustment_id", id),
+		attribute.Bool("approve", approve),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	adjustment, err := t.walletRepository.FindAdjustment(dbCtx, id)
+	if err != nil {
+		utils.LogError("Balance adjustment not found for review", err)
+		return nil, err
+	}
+	if reviewer == adjustment.ProposedBy {
+		return nil, model.ErrSelfReview
+	}
+
+	status := model.AdjustmentRejected
+	var provider *model.Wallet
+	var changes []model.BalanceChange
+	if approve {
+		status = model.AdjustmentApproved
+		provider, err = t.findAdjustmentProvider(dbCtx)
+		if err != nil {
+			return nil, err
+		}
+		changes = []model.BalanceChange{
+			{WalletID: adjustment.WalletID, Amount: adjustment.SignedAmount()},
+			{WalletID: provider.ID, Amount: -adjustment.SignedAmount()},
+		}
+	}
+	reviewed, err := t.walletRepository.ReviewAdjustment(dbCtx, id, status, reviewer, note, time.Now().UTC(), changes...)
+	if approve {
+		metrics.ObserveOperation(model.Adjustment, adjustment.Amount, err)
+	}
+	if err != nil {
+		utils.LogError("Failed to review balance adjustment", err)
+		return nil, err
+	}
+
+	if approve {
+		debitTxn, creditTxn := adjustmentLedgerPair(reviewed.UserID, reviewed.SignedAmount())
+		debitTxn.GroupID, creditTxn.GroupID = reviewed.GroupID(), reviewed.GroupID()
+		recordLedgerPair(ctx, "adjustment", debitTxn, creditTxn)
+		invalidateHistories(ctx, "adjustment", reviewed.UserID, provider.UserID)
+	}
+
+	log.WithFields(log.Fields{
+		"adjustment_id": reviewed.ID,
+		"user_id":       reviewed.UserID,
+		"amount":        reviewed.SignedAmount(),
+		"status":        reviewed.Status,
+		"proposed_by":   reviewed.ProposedBy,
+		"reviewer":      reviewer,
+	}).Warn("balance adjustment reviewed")
+	return reviewed, nil
+}
+
+// findAdjustedWallet returns the wallet to adjust. Escrow wallets and pockets
+// only move funds through their own endpoints, and the adjustments provider
+// wallet cannot be adjusted against itself.
+func (t *wallet) findAdjustedWallet(ctx context.Context, userID string) (*model.Wallet, error) {
+	userWallet, err := t.walletRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		utils.LogError("Wallet not found for adjustment", err)
+		return nil, err
+	}
+	if err := userWallet.DirectUseError(); err != nil {
+		return nil, err
+	}
+	if userWallet.UserID == model.AdjustmentProviderID {
+		return nil, model.ErrSameWallet
+	}
+	return userWallet, nil
+}
+
+// findAdjustmentProvider returns the adjustments provider wallet, or
+// ErrUnknownProvider if it was not seeded.
+func (t *wallet) findAdjustmentProvider(ctx context.Context) (*model.Wallet, error) {
+	provider, err := t.walletRepository.FindProviderWallet(ctx, model.AdjustmentProviderID)
+	if err != nil {
+		utils.LogError("Adjustments provider wallet not found", err)
+		if err == model.ErrNotFound {
+			return nil, model.ErrUnknownProvider
+		}
+		return nil, err
+	}
+	return provider, nil
+}
+
+// adjustmentLedgerPair returns the ledger pair of an adjustment of a wallet by
+// amount cents, positive for a credit.
+func adjustmentLedgerPair(userID string, amount int64) (debitTxn, creditTxn *model.Transaction) {
+	if amount < 0 {
+		return newLedgerPair(model.Adjustment, userID, model.AdjustmentProviderID, -amount)
+	}
+	return newLedgerPair(model.Adjustment, model.AdjustmentProviderID, userID, amount)
+}
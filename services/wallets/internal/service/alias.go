@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/notify"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (t *wallet) RegisterAlias(ctx context.Context, userID, raw, displayName string) (_ *model.WalletAlias, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.RegisterAlias",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.walletRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		utils.LogError("Wallet not found for alias registration", err)
+		return nil, err
+	}
+
+	alias, err := model.NewWalletAlias(wallet.ID, raw, displayName)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("alias_type", string(alias.Type)))
+
+	// Pending aliases are challenged straight away
+	var code string
+	if alias.Status == model.AliasPending {
+		if code, err = alias.NewChallenge(time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.walletRepository.CreateAlias(ctx, alias); err != nil {
+		utils.LogError("Failed to register alias", err)
+		return nil, err
+	}
+	if code != "" {
+		sendAliasChallenge(ctx, alias, code)
+	}
+	return alias, nil
+}
+
+func (t *wallet) ListAliases(ctx context.Context, userID string) (_ []model.WalletAlias, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListAliases",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.walletRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		utils.LogError("Wallet not found for alias listing", err)
+		return nil, err
+	}
+	return t.walletRepository.FindAliasesByWalletID(ctx, wallet.ID)
+}
+
+func (t *wallet) ChallengeAlias(ctx context.Context, userID, raw string) (_ *model.WalletAlias, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ChallengeAlias",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	_, value, err := model.ParseAlias(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.walletRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		utils.LogError("Wallet not found for alias challenge", err)
+		return nil, err
+	}
+	alias, code, err := t.walletRepository.ChallengeAlias(ctx, wallet.ID, value, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	sendAliasChallenge(ctx, alias, code)
+	return alias, nil
+}
+
+func (t *wallet) VerifyAlias(ctx context.Context, userID, raw, code string) (_ *model.WalletAlias, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.VerifyAlias",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	_, value, err := model.ParseAlias(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.walletRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		utils.LogError("Wallet not found for alias verification", err)
+		return nil, err
+	}
+	return t.walletRepository.VerifyAlias(ctx, wallet.ID, value, code, time.Now())
+}
+
+func (t *wallet) ResolveAlias(ctx context.Context, raw string) (_ *model.ResolvedAlias, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ResolveAlias")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	alias, wallet, err := t.findAliasWallet(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	name := alias.DisplayName
+	if name == "" {
+		name = wallet.UserID
+	}
+	return &model.ResolvedAlias{
+		Alias:       alias.Value,
+		Type:        alias.Type,
+		DisplayName: model.MaskDisplayName(name),
+	}, nil
+}
+
+func (t *wallet) TransferToAlias(ctx context.Context, fromUserID, toAlias string, amount int) (*model.Transaction, error) {
+	_, toWallet, err := t.findAliasWallet(ctx, toAlias)
+	if err != nil {
+		return nil, err
+	}
+	if toWallet.UserID == fromUserID {
+		return nil, model.ErrSameWallet
+	}
+	return t.Transfer(ctx, fromUserID, toWallet.UserID, amount)
+}
+
+// sendAliasChallenge hands the verification code of an alias to the webhook
+// for delivery to the phone number or email address.
+func sendAliasChallenge(ctx context.Context, alias *model.WalletAlias, code string) {
+	notify.NewNotifier().NotifyAliasChallenge(ctx, model.AliasChallengeEvent{
+		Event:     model.AliasChallengeEventName,
+		Type:      alias.Type,
+		Alias:     alias.Value,
+		Code:      code,
+		ExpiresAt: alias.ChallengeSentAt.Add(model.AliasChallengeTTL),
+	})
+}
+
+// findAliasWallet returns a verified alias and the active wallet it belongs
+// to. Unknown, unverified and inactive aliases all return ErrNotFound so
+// lookups cannot be used to probe which aliases are registered.
+func (t *wallet) findAliasWallet(ctx context.Context, raw string) (*model.WalletAlias, *model.Wallet, error) {
+	_, value, err := model.ParseAlias(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	alias, err := t.walletRepository.FindAlias(ctx, value)
+	if err != nil {
+		return nil, nil, err
+	}
+	if alias.Status != model.AliasVerified {
+		return nil, nil, model.ErrNotFound
+	}
+
+	wallet, err := t.walletRepository.FindByID(ctx, alias.WalletID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if wallet.Status != model.Active {
+		return nil, nil, model.ErrNotFound
+	}
+	return alias, wallet, nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// maxDailyBalanceDays bounds the length of a daily balance series.
+	maxDailyBalanceDays = 366
+	// ledgerPageSize is the number of ledger entries fetched per request.
+	ledgerPageSize = 500
+)
+
+func (t *wallet) GetBalanceAt(ctx context.Context, userID string, at time.Time) (_ *model.BalanceAt, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetBalanceAt",
+		tracing.AttrUserID.String(userID),
+		attribute.String("at", at.Format(time.RFC3339)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if at.After(time.Now()) {
+		return nil, model.ErrInvalidTimeRange
+	}
+
+	balances, err := t.balancesAt(ctx, userID, []time.Time{at})
+	if err != nil {
+		return nil, err
+	}
+	return &model.BalanceAt{UserID: userID, Balance: balances[0], At: at}, nil
+}
+
+func (t *wallet) GetDailyBalances(ctx context.Context, userID string, from, to time.Time) (_ []model.DailyBalance, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetDailyBalances",
+		tracing.AttrUserID.String(userID),
+		attribute.String("from", from.Format(model.DateLayout)),
+		attribute.String("to", to.Format(model.DateLayout)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	from = truncateToDay(from)
+	to = truncateToDay(to)
+	now := time.Now().UTC()
+	days := int(to.Sub(from).Hours()/24) + 1
+	if to.Before(from) || to.After(now) || days > maxDailyBalanceDays {
+		return nil, model.ErrInvalidTimeRange
+	}
+
+	// The end of each day is the last instant before the next one; the
+	// current day ends now.
+	points := make([]time.Time, days)
+	for i := range points {
+		points[i] = from.AddDate(0, 0, i+1).Add(-time.Nanosecond)
+		if points[i].After(now) {
+			points[i] = now
+		}
+	}
+
+	balances, err := t.balancesAt(ctx, userID, points)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]model.DailyBalance, days)
+	for i := range series {
+		series[i] = model.DailyBalance{
+			Date:    from.AddDate(0, 0, i).Format(model.DateLayout),
+			Balance: balances[i],
+		}
+	}
+	return series, nil
+}
+
+// balancesAt computes the balance of a wallet at each of the given points in
+// time, which must be in ascending order.
+func (t *wallet) balancesAt(ctx context.Context, userID string, points []time.Time) ([]int64, error) {
+	_, balance, since, err := t.startLedger(ctx, userID, points[0])
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]int64, len(points))
+	next := 0
+	err = t.replayLedger(ctx, userID, afterSnapshot(since), points[len(points)-1].Add(time.Microsecond), func(txn model.Transaction) error {
+		for ; next < len(points) && txn.CreatedAt.After(points[next]); next++ {
+			balances[next] = balance
+		}
+		balance += txn.SignedAmount()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for ; next < len(points); next++ {
+		balances[next] = balance
+	}
+	return balances, nil
+}
+
+// startLedger finds the wallet and the latest snapshot taken at or before the
+// given time, returning the snapshot balance and when it was taken. Without a
+// snapshot the ledger starts from a zero balance at the zero time.
+func (t *wallet) startLedger(ctx context.Context, userID string, before time.Time) (*model.Wallet, int64, time.Time, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.walletRepository.FindByUserID(dbCtx, userID)
+	if err != nil {
+		utils.LogError("Wallet not found for balance history", err)
+		return nil, 0, time.Time{}, err
+	}
+
+	snapshot, err := t.walletRepository.FindLatestSnapshot(dbCtx, wallet.ID, before)
+	switch {
+	case err == nil:
+		return wallet, snapshot.Balance, snapshot.TakenAt, nil
+	case err == model.ErrNotFound:
+		return wallet, 0, time.Time{}, nil
+	default:
+		utils.LogError("Failed to find balance snapshot", err)
+		return nil, 0, time.Time{}, err
+	}
+}
+
+// afterSnapshot is the first instant whose ledger entries are not yet in a
+// snapshot taken at since. The ledger stores microseconds.
+func afterSnapshot(since time.Time) time.Time {
+	if since.IsZero() {
+		return since
+	}
+	return since.Add(time.Microsecond)
+}
+
+// replayLedger calls fn with each completed ledger entry of a wallet created
+// from from and before before, oldest first, fetching them a page at a time
+// so a long history is never held in memory.
+func (t *wallet) replayLedger(ctx context.Context, userID string, from, before time.Time, fn func(model.Transaction) error) error {
+	page := model.TransactionPage{After: from, Before: before, Limit: ledgerPageSize}
+	for {
+		transactions, err := client.NewTxnClient().FetchTransactionPage(ctx, userID, page)
+		if err != nil {
+			utils.LogError("Failed to retrieve transactions for balance history", err)
+			return err
+		}
+		for _, txn := range transactions {
+			if txn.Status != model.Completed {
+				continue
+			}
+			if err := fn(txn); err != nil {
+				return err
+			}
+		}
+		if len(transactions) < page.Limit {
+			return nil
+		}
+		last := transactions[len(transactions)-1]
+		page.After, page.AfterID = last.CreatedAt, last.ID
+	}
+}
+
+// truncateToDay returns the start of the UTC calendar day containing t.
+func truncateToDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+// CloseWallet sweeps the balance of a user or merchant wallet and of its
+// pockets and closes them, as actorID: the holder if empty, or an owner
+// member. The balance is withdrawn through toProviderID, the default
+// withdrawal provider if nil, or transferred to the wallet toUserID if set,
+// within the KYC transfer limit of the wallet. The wallet's history stays readable,
+// but no money moves in or out of it until an admin reopens it. The closure of
+// a shared wallet requiring approvals is held until enough owners approve it,
+// and the held operation is returned instead.
+func (t *wallet) CloseWallet(ctx context.Context, actorID, userID string, toProviderID *string, toUserID string) (*model.WalletClosure, *model.WalletApproval, error) {
+	if actorID == userID {
+		actorID = ""
+	}
+	closure, err := t.closeWallet(ctx, actorID, userID, toProviderID, toUserID, false, nil)
+	if err != model.ErrApprovalRequired {
+		return closure, nil, err
+	}
+
+	// The owners see the balance the closure would sweep as of now
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+	userWallet, err := t.walletRepository.FindByUserID(dbCtx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if userWallet.Pockets, err = t.walletRepository.FindPockets(dbCtx, userWallet.ID); err != nil {
+		return nil, nil, err
+	}
+
+	approval, err := t.holdForApproval(ctx, &model.WalletApproval{
+		WalletUserID:    userID,
+		TransactionType: model.Closure,
+		InitiatedBy:     initiator(actorID, userID),
+		ToUserID:        toUserID,
+		ProviderID:      toProviderID,
+		Amount:          userWallet.TotalBalance(),
+	})
+	return nil, approval, err
+}
+
+// closeWallet closes the wallet as CloseWallet does. The closure of a shared
+// wallet requiring approvals returns ErrApprovalRequired unless approved.
+// within, if set, runs in the database transaction of the sweep, as in
+// transfer.
+func (t *wallet) closeWallet(ctx context.Context, actorID, userID string, toProviderID *string, toUserID string, approved bool, within func(tx *gorm.DB) error) (_ *model.WalletClosure, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.CloseWallet",
+		tracing.AttrUserID.String(userID),
+		attribute.String("actor_user_id", actorID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	userWallet, err := t.walletRepository.FindByUserID(dbCtx, userID)
+	if err != nil {
+		utils.LogError("Wallet not found for closure", err)
+		return nil, err
+	}
+	if err := userWallet.CloseError(); err != nil {
+		return nil, err
+	}
+	if err := t.authorizeClosure(dbCtx, userWallet, actorID, approved); err != nil {
+		return nil, err
+	}
+
+	var destination *model.Wallet
+	txnType := model.Withdraw
+	if toUserID != "" {
+		txnType = model.Transfer
+		destination, err = t.walletRepository.FindByUserID(dbCtx, toUserID)
+		if err != nil {
+			utils.LogError("Destination wallet not found for closure", err)
+			return nil, err
+		}
+		if destination.ID == userWallet.ID || (destination.ParentID != nil && *destination.ParentID == userWallet.ID) {
+			return nil, model.ErrSameWallet
+		}
+		if err := destination.DirectUseError(); err != nil {
+			return nil, err
+		}
+		if destination.Status == model.Closed {
+			return nil, model.ErrWalletClosed
+		}
+	} else {
+		providerID := model.WithdrawProviderID
+		if toProviderID != nil {
+			providerID = *toProviderID
+		}
+		destination, err = t.walletRepository.FindProviderWallet(dbCtx, providerID)
+		if err != nil {
+			utils.LogError("Provider wallet not found for closure", err)
+			if err == model.ErrNotFound {
+				return nil, model.ErrUnknownProvider
+			}
+			return nil, err
+		}
+		if destination.Status != model.Active {
+			return nil, model.ErrProviderDisabled
+		}
+	}
+
+	// A sweep to another wallet is a transfer, so it is held to the KYC
+	// transfer limit, checked on the balance locked for the sweep. A sweep to a
+	// provider is not held to the withdrawal limits, so that an unverified
+	// holder can always close a funded wallet.
+	tier := kycTier(userWallet)
+	authorize := func(tx *gorm.DB, total int64) error {
+		if within != nil {
+			if err := within(tx); err != nil {
+				return err
+			}
+		}
+		if total == 0 || txnType != model.Transfer {
+			return nil
+		}
+		return tier.TransferError(total)
+	}
+
+	closedAt := time.Now().UTC()
+	swept, err := t.walletRepository.CloseWallet(dbCtx, userWallet, destination.ID, closedAt, authorize)
+	if err != nil {
+		utils.LogError("Failed to close wallet", err)
+		return nil, err
+	}
+
+	closure := &model.WalletClosure{
+		UserID:       userWallet.UserID,
+		Status:       model.Closed,
+		ClosedAt:     closedAt,
+		SweptTo:      destination.UserID,
+		Transactions: []model.Transaction{},
+	}
+	historyIDs := []string{destination.UserID}
+	for _, w := range swept {
+		historyIDs = append(historyIDs, w.UserID)
+		if w.Balance == 0 {
+			continue
+		}
+		debitTxn, creditTxn := newLedgerPair(txnType, w.UserID, destination.UserID, w.Balance)
+		recordLedgerPair(ctx, "closure", debitTxn, creditTxn)
+		closure.SweptAmount += w.Balance
+		closure.Transactions = append(closure.Transactions, *debitTxn)
+	}
+	invalidateHistories(ctx, "closure", historyIDs...)
+
+	log.WithFields(log.Fields{
+		"user_id":      userWallet.UserID,
+		"swept_amount": closure.SweptAmount,
+		"swept_to":     closure.SweptTo,
+	}).Info("wallet closed")
+	return closure, nil
+}
+
+// authorizeClosure checks that actorID may close the wallet. An empty
+// actorID is the holder itself; any other user must be an owner member. A
+// shared wallet requiring approvals returns ErrApprovalRequired unless
+// approved, whatever its balance.
+func (t *wallet) authorizeClosure(ctx context.Context, wallet *model.Wallet, actorID string, approved bool) error {
+	if actorID != "" {
+		role, err := t.memberRole(ctx, wallet, actorID)
+		if err != nil {
+			return err
+		}
+		if !role.CanApprove() {
+			return model.ErrMemberForbidden
+		}
+	}
+	if !approved && wallet.RequiredApprovals > 0 {
+		return model.ErrApprovalRequired
+	}
+	return nil
+}
+
+// ReopenWallet makes a closed wallet and its pockets active again. It is an
+// admin override; the balance swept on closure is not brought back.
+func (t *wallet) ReopenWallet(ctx context.Context, userID string) (_ *model.Wallet, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ReopenWallet",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	userWallet, err := t.walletRepository.FindByUserID(dbCtx, userID)
+	if err != nil {
+		utils.LogError("Wallet not found for reopening", err)
+		return nil, err
+	}
+	if err := t.walletRepository.SetWalletStatus(dbCtx, userWallet.ID, model.Closed, model.Active); err != nil {
+		utils.LogError("Failed to reopen wallet", err)
+		return nil, err
+	}
+	invalidateHistories(ctx, "reopening", userWallet.UserID)
+
+	log.WithField("user_id", userWallet.UserID).Warn("closed wallet reopened by admin override")
+	return t.walletRepository.FindByUserID(dbCtx, userID)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/notify"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// dormancyBatchSize is the number of wallets whose latest activity is asked
+// from the transactions service at once.
+const dormancyBatchSize = 500
+
+// RunDormancy marks the active user wallets without activity for the
+// configured period inactive, taking the dormancy fee or escheating their
+// balance to the configured provider wallet. A wallet's activity is its
+// latest ledger entry, or its creation if it never had any. The next deposit
+// into a dormant wallet makes it active again. Only one instance of the
+// service runs it at a time; on the others it marks nothing.
+func (t *wallet) RunDormancy(ctx context.Context, now time.Time) (dormant int, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.RunDormancy")
+	defer func() {
+		span.SetAttributes(attribute.Int("dormant", dormant))
+		tracing.EndSpan(span, err)
+	}()
+
+	ran, err := t.walletRepository.WithJobLock(ctx, "dormancy", func() error {
+		dormant, err = t.runDormancy(ctx, now)
+		return err
+	})
+	if err == nil && !ran {
+		log.Info("dormancy run skipped, another instance is running it")
+	}
+	return dormant, err
+}
+
+// runDormancy marks dormant wallets as RunDormancy does, under its lock.
+func (t *wallet) runDormancy(ctx context.Context, now time.Time) (dormant int, err error) {
+	cfg := config.GetDormancy()
+	idleSince := now.Add(-cfg.Period)
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	provider := &model.Wallet{}
+	if cfg.Action != model.DormancyActionNone {
+		provider, err = t.walletRepository.FindProviderWallet(dbCtx, cfg.ProviderID)
+		if err != nil {
+			utils.LogError("Provider wallet not found for dormancy", err)
+			if err == model.ErrNotFound {
+				return 0, model.ErrUnknownProvider
+			}
+			return 0, err
+		}
+		if provider.Status != model.Active {
+			return 0, model.ErrProviderDisabled
+		}
+	}
+
+	candidates, err := t.walletRepository.FindDormancyCandidates(dbCtx, idleSince)
+	if err != nil {
+		return 0, err
+	}
+
+	txnClient := client.NewTxnClient()
+	for start := 0; start < len(candidates); start += dormancyBatchSize {
+		batch := candidates[start:min(start+dormancyBatchSize, len(candidates))]
+		ids := make([]string, len(batch))
+		for i, w := range batch {
+			ids[i] = w.UserID
+		}
+		activity, err := txnClient.FetchLastActivity(ctx, ids)
+		if err != nil {
+			return dormant, err
+		}
+
+		for _, w := range batch {
+			lastActivity, ok := activity[w.UserID]
+			if !ok {
+				lastActivity = w.CreatedAt
+			}
+			if !model.IsDormant(lastActivity, cfg.Period, now) {
+				continue
+			}
+			// One failing wallet must not hold back the others. A wallet used
+			// since it was listed is simply skipped.
+			charged, err := t.markDormant(ctx, w, provider, idleSince, cfg)
+			if err != nil {
+				if err != model.ErrInvalidTransition {
+					utils.LogError("Failed to mark wallet dormant", err)
+				}
+				continue
+			}
+			dormant++
+
+			event := model.WalletEvent{
+				Event:      model.WalletEventDormant,
+				UserID:     w.UserID,
+				Status:     model.Inactive,
+				Charged:    charged,
+				OccurredAt: now,
+			}
+			if ok {
+				event.LastActivityAt = &lastActivity
+			}
+			if charged > 0 {
+				event.ChargedTo = provider.UserID
+			}
+			notify.NewNotifier().NotifyWallet(ctx, event)
+		}
+	}
+	return dormant, nil
+}
+
+// markDormant marks one wallet and its pockets inactive and records the
+// dormancy charges in the ledger. It returns the total charged.
+func (t *wallet) markDormant(ctx context.Context, userWallet model.Wallet, provider *model.Wallet, idleSince time.Time, cfg model.Dormancy) (int64, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallets, err := t.walletRepository.MarkWalletDormant(dbCtx, userWallet.ID, idleSince, cfg, provider.ID)
+	if err != nil {
+		return 0, err
+	}
+	charges, txnType := model.DormancyCharges(cfg, wallets)
+	userIDs := make(map[int]string, len(wallets))
+	for _, w := range wallets {
+		userIDs[w.ID] = w.UserID
+	}
+
+	var charged int64
+	historyIDs := []string{provider.UserID}
+	for _, c := range charges {
+		subject := userIDs[c.WalletID]
+		debitTxn, creditTxn := newLedgerPair(txnType, subject, provider.UserID, -c.Amount)
+		recordLedgerPair(ctx, "dormancy", debitTxn, creditTxn)
+		charged -= c.Amount
+		historyIDs = append(historyIDs, subject)
+	}
+	if charged > 0 {
+		invalidateHistories(ctx, "dormancy", historyIDs...)
+	}
+
+	log.WithFields(log.Fields{
+		"user_id":    userWallet.UserID,
+		"charged":    charged,
+		"charged_to": provider.UserID,
+		"type":       txnType,
+	}).Info("wallet marked dormant")
+	return charged, nil
+}
+
+// reactivate makes a dormant wallet and its pockets active again. A wallet
+// already reactivated by a concurrent deposit is left alone.
+func (t *wallet) reactivate(ctx context.Context, userWallet *model.Wallet) {
+	dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.GetTimeouts().Database)
+	defer cancel()
+
+	err := t.walletRepository.SetWalletStatus(dbCtx, userWallet.ID, model.Inactive, model.Active)
+	if err != nil {
+		if err != model.ErrInvalidTransition {
+			utils.LogError("Failed to reactivate dormant wallet", err)
+		}
+		return
+	}
+	notify.NewNotifier().NotifyWallet(ctx, model.WalletEvent{
+		Event:      model.WalletEventReactivated,
+		UserID:     userWallet.UserID,
+		Status:     model.Active,
+		OccurredAt: time.Now().UTC(),
+	})
+	log.WithField("user_id", userWallet.UserID).Info("dormant wallet reactivated")
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (t *wallet) CreateEscrow(ctx context.Context, buyerID, sellerID, arbiterID string, amount int, deadline time.Time, description string) (_ *model.EscrowAgreement, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.CreateEscrow",
+		tracing.AttrUserID.String(buyerID),
+		attribute.String("seller_user_id", sellerID),
+		attribute.String("arbiter_user_id", arbiterID),
+		tracing.AttrTransactionType.String(string(model.EscrowFund)),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+	)
+	defer func() {
+		metrics.ObserveOperation(model.EscrowFund, int64(amount), err)
+		tracing.EndSpan(span, err)
+	}()
+
+	if amount <= 0 {
+		return nil, model.ErrInvalidAmount
+	}
+	if buyerID == sellerID || arbiterID == buyerID || arbiterID == sellerID {
+		return nil, model.ErrSameWallet
+	}
+	if !deadline.After(time.Now()) {
+		return nil, model.ErrInvalidTimeRange
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	buyerWallet, err := t.walletRepository.FindByUserID(dbCtx, buyerID)
+	if err != nil {
+		utils.LogError("Buyer wallet not found for escrow", err)
+		return nil, err
+	}
+	sellerWallet, err := t.walletRepository.FindByUserID(dbCtx, sellerID)
+	if err != nil {
+		utils.LogError("Seller wallet not found for escrow", err)
+		return nil, err
+	}
+	if err := buyerWallet.DirectUseError(); err != nil {
+		return nil, err
+	}
+	if err := sellerWallet.DirectUseError(); err != nil {
+		return nil, err
+	}
+	if arbiterID != "" {
+		if _, err := t.walletRepository.FindByUserID(dbCtx, arbiterID); err != nil {
+			utils.LogError("Arbiter wallet not found for escrow", err)
+			return nil, err
+		}
+	}
+	// Funding moves money out of the buyer's wallet, so it is held to the
+	// same KYC limit and approval threshold as a transfer
+	if err := kycTier(buyerWallet).TransferError(int64(amount)); err != nil {
+		return nil, err
+	}
+	if buyerWallet.NeedsApproval(int64(amount)) {
+		return nil, model.ErrApprovalRequired
+	}
+
+	escrow := &model.EscrowAgreement{
+		WalletUserID: "escrow-" + strings.ToLower(rand.Text()),
+		BuyerID:      buyerID,
+		SellerID:     sellerID,
+		ArbiterID:    arbiterID,
+		Amount:       int64(amount),
+		Description:  description,
+		Status:       model.EscrowFunded,
+		Deadline:     deadline.UTC(),
+	}
+	if err := t.walletRepository.CreateEscrow(dbCtx, escrow, buyerWallet.ID); err != nil {
+		utils.LogError("Failed to fund escrow", err)
+		return nil, err
+	}
+
+	debitTxn, creditTxn := newLedgerPair(model.EscrowFund, buyerID, escrow.WalletUserID, escrow.Amount)
+	recordLedgerPair(ctx, "escrow_fund", debitTxn, creditTxn)
+	invalidateHistories(ctx, "escrow funding", buyerID)
+	return escrow, nil
+}
+
+func (t *wallet) GetEscrow(ctx context.Context, id int) (_ *model.EscrowAgreement, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetEscrow",
+		attribute.Int("escrow_id", id),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	return t.walletRepository.FindEscrow(ctx, id)
+}
+
+func (t *wallet) ReleaseEscrow(ctx context.Context, id int, actorID string) (*model.EscrowAgreement, error) {
+	return t.settleEscrow(ctx, id, func(escrow *model.EscrowAgreement) (int64, error) {
+		if err := escrow.SettlementError(actorID, escrow.Amount); err != nil {
+			return 0, err
+		}
+		return escrow.Amount, nil
+	})
+}
+
+func (t *wallet) RefundEscrow(ctx context.Context, id int, actorID string) (*model.EscrowAgreement, error) {
+	return t.settleEscrow(ctx, id, func(escrow *model.EscrowAgreement) (int64, error) {
+		if err := escrow.SettlementError(actorID, 0); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	})
+}
+
+func (t *wallet) SplitEscrow(ctx context.Context, id int, actorID string, sellerAmount int) (*model.EscrowAgreement, error) {
+	return t.settleEscrow(ctx, id, func(escrow *model.EscrowAgreement) (int64, error) {
+		// A split gives each party something; use release or refund otherwise
+		if sellerAmount <= 0 || int64(sellerAmount) >= escrow.Amount {
+			return 0, model.ErrInvalidAmount
+		}
+		if err := escrow.SettlementError(actorID, int64(sellerAmount)); err != nil {
+			return 0, err
+		}
+		return int64(sellerAmount), nil
+	})
+}
+
+// settleEscrow pays out a funded escrow, sellerShare deciding how much of it
+// goes to the seller, the rest going back to the buyer, and checking that the
+// actor may settle it so.
+func (t *wallet) settleEscrow(ctx context.Context, id int, sellerShare func(*model.EscrowAgreement) (int64, error)) (_ *model.EscrowAgreement, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.SettleEscrow",
+		attribute.Int("escrow_id", id),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	escrow, err := t.walletRepository.FindEscrow(dbCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if escrow.Status != model.EscrowFunded {
+		return nil, model.ErrInvalidTransition
+	}
+	sellerAmount, err := sellerShare(escrow)
+	if err != nil {
+		return nil, err
+	}
+
+	escrow, err = t.walletRepository.SettleEscrow(dbCtx, id, sellerAmount, time.Now())
+	if err != nil {
+		utils.LogError("Failed to settle escrow", err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("status", string(escrow.Status)))
+
+	if escrow.ReleasedAmount > 0 {
+		debitTxn, creditTxn := newLedgerPair(model.EscrowRelease, escrow.WalletUserID, escrow.SellerID, escrow.ReleasedAmount)
+		recordLedgerPair(ctx, "escrow_release", debitTxn, creditTxn)
+		metrics.ObserveOperation(model.EscrowRelease, escrow.ReleasedAmount, nil)
+	}
+	if escrow.RefundedAmount > 0 {
+		debitTxn, creditTxn := newLedgerPair(model.EscrowRefund, escrow.WalletUserID, escrow.BuyerID, escrow.RefundedAmount)
+		recordLedgerPair(ctx, "escrow_refund", debitTxn, creditTxn)
+		metrics.ObserveOperation(model.EscrowRefund, escrow.RefundedAmount, nil)
+	}
+	invalidateHistories(ctx, "escrow settlement", escrow.WalletUserID, escrow.SellerID, escrow.BuyerID)
+	return escrow, nil
+}
+
+// RefundExpiredEscrows refunds the buyers of funded escrows past their
+// deadline. A refund refused by the buyer's wallet, e.g. because it is closed,
+// suspended or at its balance ceiling, is retried on later runs up to the
+// configured number of attempts, after which the escrow is left funded for an
+// operator and an alert metric is raised.
+func (t *wallet) RefundExpiredEscrows(ctx context.Context) (refunded int, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.RefundExpiredEscrows")
+	defer func() {
+		span.SetAttributes(attribute.Int("refunded", refunded))
+		tracing.EndSpan(span, err)
+	}()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	maxAttempts := config.GetEscrowExpiry().MaxRefundAttempts
+	escrows, err := t.walletRepository.FindExpiredEscrows(dbCtx, time.Now(), maxAttempts)
+	if err != nil {
+		return 0, err
+	}
+	for _, escrow := range escrows {
+		// One failing escrow must not hold back the others. An escrow settled
+		// since it was listed is simply skipped.
+		_, err := t.settleEscrow(ctx, escrow.ID, func(*model.EscrowAgreement) (int64, error) {
+			return 0, nil
+		})
+		switch {
+		case err == nil:
+			refunded++
+		case err == model.ErrInvalidTransition:
+		case refundRefused(err):
+			t.recordRefundFailure(ctx, escrow, err, maxAttempts)
+		default:
+			utils.LogError("Failed to refund expired escrow", err)
+		}
+	}
+	return refunded, nil
+}
+
+// refundRefused reports whether the refund of an expired escrow failed on the
+// state of the buyer's wallet, which retrying does not change until the
+// wallet does.
+func refundRefused(err error) bool {
+	switch err {
+	case model.ErrWalletClosed, model.ErrWalletSuspended, model.ErrBalanceLimitExceeded, model.ErrKYCUpgradeRequired, model.ErrNotFound:
+		return true
+	}
+	return false
+}
+
+// recordRefundFailure counts a refused refund of an expired escrow. Once it
+// has been refused maxAttempts times the escrow is no longer retried, and the
+// failure is logged as an error for an operator to settle it by hand.
+func (t *wallet) recordRefundFailure(ctx context.Context, escrow model.EscrowAgreement, refundErr error, maxAttempts int) {
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	attempts, err := t.walletRepository.RecordEscrowRefundFailure(dbCtx, escrow.ID, refundErr.Error())
+	if err != nil {
+		if err != model.ErrInvalidTransition {
+			utils.LogError("Failed to record refused escrow refund", err)
+		}
+		return
+	}
+	final := attempts >= maxAttempts
+	metrics.IncEscrowRefundFailure(final)
+
+	entry := log.WithFields(log.Fields{
+		"escrow_id":     escrow.ID,
+		"buyer_user_id": escrow.BuyerID,
+		"attempts":      attempts,
+		"error":         refundErr.Error(),
+	})
+	if final {
+		entry.Error("refund of expired escrow abandoned, left funded for an operator")
+		return
+	}
+	entry.Warn("refund of expired escrow refused by the buyer's wallet, will retry")
+}
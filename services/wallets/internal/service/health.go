@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// probeTimeout bounds each individual dependency probe.
+const probeTimeout = 2 * time.Second
+
+// Check is a single dependency probe run by the readiness endpoint.
+type Check struct {
+	Name string
+	// Critical checks make the service unavailable when they fail;
+	// non-critical ones only degrade it.
+	Critical bool
+	Probe    func(ctx context.Context) error
+}
+
+// Health is the service for dependency health checks.
+type Health interface {
+	Readiness(ctx context.Context) model.Readiness
+}
+
+type health struct {
+	checks []Check
+	ttl    time.Duration
+
+	mu     sync.Mutex
+	cached *model.Readiness
+}
+
+// NewHealthService creates a Health service that runs checks and caches the
+// result for ttl so frequent probes do not hammer the dependencies.
+func NewHealthService(ttl time.Duration, checks ...Check) Health {
+	return &health{
+		checks: checks,
+		ttl:    ttl,
+	}
+}
+
+// Readiness returns the cached result if it is fresh, otherwise probes every
+// dependency concurrently. Concurrent callers wait for a single refresh.
+func (h *health) Readiness(ctx context.Context) model.Readiness {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached != nil && time.Since(h.cached.CheckedAt) < h.ttl {
+		return *h.cached
+	}
+
+	result := model.Readiness{
+		Status:     model.HealthUp,
+		CheckedAt:  time.Now(),
+		Components: make(map[string]model.ComponentHealth, len(h.checks)),
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = result.Components
+	)
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			component := runCheck(ctx, check)
+			mu.Lock()
+			results[check.Name] = component
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	for _, component := range results {
+		if component.Status == model.HealthUp {
+			continue
+		}
+		if component.Critical {
+			result.Status = model.HealthDown
+			break
+		}
+		result.Status = model.HealthDegraded
+	}
+
+	h.cached = &result
+	return result
+}
+
+func runCheck(ctx context.Context, check Check) model.ComponentHealth {
+	// The result is cached and shared with other callers, so one caller
+	// going away must not turn into a failed probe.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), probeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Probe(ctx)
+	component := model.ComponentHealth{
+		Status:    model.HealthUp,
+		Critical:  check.Critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		component.Status = model.HealthDown
+		component.Error = err.Error()
+	}
+	return component
+}
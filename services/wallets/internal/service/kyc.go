@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SubmitKYCVerification submits the documents verifying the holder of a user
+// wallet to a level above the wallet's current one, for review.
+func (t *wallet) SubmitKYCVerification(ctx context.Context, userID string, level model.KYCLevel, documents []model.KYCDocument) (_ *model.KYCVerification, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.SubmitKYCVerification",
+		tracing.AttrUserID.String(userID),
+		attribute.String("kyc_level", string(level)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !model.IsValidKYCLevel(level) || !level.Above(wallet.KYCLevelOf()) {
+		return nil, model.ErrInvalidKYCLevel
+	}
+
+	verification := &model.KYCVerification{
+		WalletID:  wallet.ID,
+		UserID:    wallet.UserID,
+		Level:     level,
+		Documents: documents,
+		Status:    model.KYCPending,
+	}
+	if err := t.walletRepository.CreateKYCVerification(ctx, verification); err != nil {
+		utils.LogError("Failed to create KYC verification", err)
+		return nil, err
+	}
+	return verification, nil
+}
+
+// GetKYCProfile returns the KYC level of a user wallet, the limits it sets
+// and the verifications submitted for the wallet.
+func (t *wallet) GetKYCProfile(ctx context.Context, userID string) (_ *model.KYCProfile, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetKYCProfile",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	verifications, err := t.walletRepository.FindKYCVerifications(ctx, wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.KYCProfile{
+		UserID: wallet.UserID,
+		Level:  wallet.KYCLevelOf(),
+		Limits: model.KYCLimits{
+			KYCTier:    config.GetKYC().Tier(wallet.KYCLevelOf()),
+			MaxBalance: config.GetBalanceLimits().MaxBalance(wallet),
+		},
+		Verifications: verifications,
+	}, nil
+}
+
+// ListKYCVerifications returns the KYC verifications in a status, the
+// review queue for pending.
+func (t *wallet) ListKYCVerifications(ctx context.Context, status model.KYCStatus) (_ []model.KYCVerification, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListKYCVerifications",
+		attribute.String("kyc_status", string(status)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	return t.walletRepository.FindKYCVerificationsByStatus(ctx, status)
+}
+
+// ReviewKYCVerification approves or rejects a pending KYC verification on
+// behalf of the authenticated operator reviewer. Approval raises the wallet
+// to the verified level.
+func (t *wallet) ReviewKYCVerification(ctx context.Context, id int, approve bool, reviewer, note string) (_ *model.KYCVerification, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ReviewKYCVerification",
+		attribute.Int("kyc_verification_id", id),
+		attribute.Bool("approve", approve),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	status := model.KYCRejected
+	if approve {
+		status = model.KYCApproved
+	}
+	verification, err := t.walletRepository.ReviewKYCVerification(dbCtx, id, status, reviewer, note, time.Now().UTC())
+	if err != nil {
+		utils.LogError("Failed to review KYC verification", err)
+		return nil, err
+	}
+	if approve {
+		// The wallet's level is shown with its history
+		invalidateHistories(ctx, "kyc review", verification.UserID)
+	}
+
+	log.WithFields(log.Fields{
+		"user_id":  verification.UserID,
+		"level":    verification.Level,
+		"status":   verification.Status,
+		"reviewer": reviewer,
+	}).Info("KYC verification reviewed")
+	return verification, nil
+}
+
+// kycTier returns the limits of the wallet's KYC level. Only user wallets
+// have limits, and only while KYC is enabled; other wallets get no limits.
+func kycTier(wallet *model.Wallet) model.KYCTier {
+	kyc := config.GetKYC()
+	if !kyc.Enable || wallet.AcntType != model.User {
+		return model.KYCTier{Withdrawals: true}
+	}
+	return kyc.Tier(wallet.KYCLevelOf())
+}
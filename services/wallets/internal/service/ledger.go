@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+)
+
+// newLedgerPair returns the completed debit and credit entries of a move of
+// amount cents from one wallet to another.
+func newLedgerPair(txnType model.TransactionType, fromUserID, toUserID string, amount int64) (debitTxn, creditTxn *model.Transaction) {
+	debitTxn = &model.Transaction{
+		SubjectWalletID: fromUserID,
+		ObjectWalletID:  toUserID,
+		TransactionType: txnType,
+		OperationType:   model.Debit,
+		Amount:          amount,
+		Status:          model.Completed,
+	}
+	creditTxn = &model.Transaction{
+		SubjectWalletID: toUserID,
+		ObjectWalletID:  fromUserID,
+		TransactionType: txnType,
+		OperationType:   model.Credit,
+		Amount:          amount,
+		Status:          model.Completed,
+	}
+	return debitTxn, creditTxn
+}
+
+// recordLedgerPair writes a ledger pair to the transactions service
+// asynchronously, once the balance change is committed. The ledger write
+// outlives the request, so it keeps the request's values but not its
+// cancellation.
+func recordLedgerPair(ctx context.Context, task string, debitTxn, creditTxn *model.Transaction) {
+	go func() {
+		if err := client.NewTxnClient().CreateTransactionPair(context.WithoutCancel(ctx), debitTxn, creditTxn); err != nil {
+			utils.LogError("Failed to create transaction pair for "+task, err)
+			metrics.IncAsyncFailure("ledger_" + task)
+		}
+	}()
+}
+
+// invalidateHistories drops the cached transaction history of every given
+// wallet. The balance change is already committed, so this must not be
+// skipped if the caller goes away.
+func invalidateHistories(ctx context.Context, task string, userIDs ...string) {
+	cacheCtx := context.WithoutCancel(ctx)
+	redisClient := cache.NewRedisClient()
+	for _, userID := range userIDs {
+		if err := redisClient.DeleteTransactionHistory(cacheCtx, userID); err != nil {
+			utils.LogError("Failed to invalidate cache after "+task, err)
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+func (t *wallet) AddMember(ctx context.Context, userID, memberID string, role model.MemberRole, spendLimit int, unlimited bool, window model.SpendWindow) (_ *model.WalletMember, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.AddMember",
+		tracing.AttrUserID.String(userID),
+		attribute.String("member_user_id", memberID),
+		attribute.String("role", string(role)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Only spenders have a spend limit; a spender's is required, or must be
+	// explicitly unlimited
+	member := &model.WalletMember{MemberID: memberID, Role: role}
+	if role == model.MemberSpender {
+		if window == "" {
+			window = model.SpendDaily
+		}
+		member.SpendLimit, member.UnlimitedSpend, member.SpendWindow = int64(spendLimit), unlimited, window
+	}
+	if err := member.LimitError(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// The holder is always an owner
+	if memberID == wallet.UserID {
+		return nil, model.ErrMemberExists
+	}
+	if _, err := t.findUserWallet(ctx, memberID); err != nil {
+		return nil, err
+	}
+
+	member.WalletID = wallet.ID
+	if err := t.walletRepository.CreateMember(ctx, member); err != nil {
+		utils.LogError("Failed to add wallet member", err)
+		return nil, err
+	}
+	return member, nil
+}
+
+func (t *wallet) ListMembers(ctx context.Context, userID string) (_ []model.WalletMember, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListMembers",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return t.walletRepository.FindMembers(ctx, wallet.ID)
+}
+
+func (t *wallet) RemoveMember(ctx context.Context, userID, memberID string) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.RemoveMember",
+		tracing.AttrUserID.String(userID),
+		attribute.String("member_user_id", memberID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return err
+	}
+	member, err := t.walletRepository.FindMember(ctx, wallet.ID, memberID)
+	if err != nil {
+		return err
+	}
+
+	// Removing an owner must leave enough owners to meet the approval policy
+	if member.Role.CanApprove() {
+		owners, err := t.countOwners(ctx, wallet)
+		if err != nil {
+			return err
+		}
+		if owners-1 < wallet.RequiredApprovals {
+			return model.ErrInvalidApprovalPolicy
+		}
+	}
+	return t.walletRepository.DeleteMember(ctx, wallet.ID, memberID)
+}
+
+func (t *wallet) SetApprovalPolicy(ctx context.Context, userID string, threshold, requiredApprovals int) (_ *model.Wallet, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.SetApprovalPolicy",
+		tracing.AttrUserID.String(userID),
+		attribute.Int("required_approvals", requiredApprovals),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if threshold < 0 || requiredApprovals < 0 {
+		return nil, model.ErrInvalidApprovalPolicy
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := t.countOwners(ctx, wallet)
+	if err != nil {
+		return nil, err
+	}
+	if requiredApprovals > owners {
+		return nil, model.ErrInvalidApprovalPolicy
+	}
+
+	if err := t.walletRepository.UpdateApprovalPolicy(ctx, wallet.ID, int64(threshold), requiredApprovals); err != nil {
+		utils.LogError("Failed to update approval policy", err)
+		return nil, err
+	}
+	wallet.ApprovalThreshold, wallet.RequiredApprovals = int64(threshold), requiredApprovals
+	return wallet, nil
+}
+
+func (t *wallet) InitiateTransfer(ctx context.Context, actorID, fromUserID, toUserID string, amount int) (*model.Transaction, *model.WalletApproval, error) {
+	if actorID == fromUserID {
+		actorID = ""
+	}
+	transaction, err := t.transfer(ctx, actorID, fromUserID, toUserID, amount, false, nil)
+	if err != model.ErrApprovalRequired {
+		return transaction, nil, err
+	}
+
+	approval, err := t.holdForApproval(ctx, &model.WalletApproval{
+		WalletUserID:    fromUserID,
+		TransactionType: model.Transfer,
+		InitiatedBy:     initiator(actorID, fromUserID),
+		ToUserID:        toUserID,
+		Amount:          int64(amount),
+	})
+	return nil, approval, err
+}
+
+func (t *wallet) InitiateWithdraw(ctx context.Context, actorID, userID string, amount int, providerID *string) (*model.Transaction, *model.WalletApproval, error) {
+	if actorID == userID {
+		actorID = ""
+	}
+	transaction, err := t.withdraw(ctx, actorID, userID, amount, providerID, false, nil)
+	if err != model.ErrApprovalRequired {
+		return transaction, nil, err
+	}
+
+	approval, err := t.holdForApproval(ctx, &model.WalletApproval{
+		WalletUserID:    userID,
+		TransactionType: model.Withdraw,
+		InitiatedBy:     initiator(actorID, userID),
+		ProviderID:      providerID,
+		Amount:          int64(amount),
+	})
+	return nil, approval, err
+}
+
+func (t *wallet) ListApprovals(ctx context.Context, userID string, status model.ApprovalStatus) (_ []model.WalletApproval, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListApprovals",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return t.walletRepository.FindApprovals(ctx, wallet.ID, status)
+}
+
+func (t *wallet) ApproveOperation(ctx context.Context, userID string, id int, approverID string) (_ *model.WalletApproval, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ApproveOperation",
+		tracing.AttrUserID.String(userID),
+		attribute.Int("approval_id", id),
+		attribute.String("approver_user_id", approverID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	wallet, approval, err := t.findApproval(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	role, err := t.memberRole(ctx, wallet, approverID)
+	if err != nil {
+		return nil, err
+	}
+	if !role.CanApprove() {
+		return nil, model.ErrMemberForbidden
+	}
+	if approval.Status != model.ApprovalPending {
+		return nil, model.ErrInvalidTransition
+	}
+	return t.approve(ctx, approval, approverID)
+}
+
+func (t *wallet) RejectOperation(ctx context.Context, userID string, id int, memberID string) (_ *model.WalletApproval, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.RejectOperation",
+		tracing.AttrUserID.String(userID),
+		attribute.Int("approval_id", id),
+		attribute.String("member_user_id", memberID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	wallet, approval, err := t.findApproval(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	// Any owner may reject the operation and its initiator may withdraw it
+	if memberID != approval.InitiatedBy {
+		role, err := t.memberRole(ctx, wallet, memberID)
+		if err != nil {
+			return nil, err
+		}
+		if !role.CanApprove() {
+			return nil, model.ErrMemberForbidden
+		}
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+	return t.walletRepository.UpdateApprovalStatus(dbCtx, id, model.ApprovalPending, model.ApprovalRejected, "", time.Now().UTC())
+}
+
+// authorizeDebit checks that actorID may move amount cents out of the
+// wallet. An empty actorID, or the holder's, is the holder itself; any other
+// user must be a member whose role and the rest of its spend limit in the
+// current window allow it. Amounts above the wallet's approval threshold
+// return ErrApprovalRequired unless approved. The spending is only recorded
+// by recordMemberSpend once the funds move.
+func (t *wallet) authorizeDebit(ctx context.Context, wallet *model.Wallet, actorID string, amount int64, approved bool) error {
+	if actorID != "" && actorID != wallet.UserID {
+		member, err := t.walletRepository.FindMember(ctx, wallet.ID, actorID)
+		if err == model.ErrNotFound {
+			return model.ErrNotMember
+		}
+		if err != nil {
+			return err
+		}
+		if err := member.SpendError(amount, time.Now().UTC()); err != nil {
+			return err
+		}
+	}
+	if !approved && wallet.NeedsApproval(amount) {
+		return model.ErrApprovalRequired
+	}
+	return nil
+}
+
+// recordMemberSpend adds amount to what actorID spent from the wallet in its
+// current spend window, within the transaction tx that moves the funds, and
+// checks its spend limit again under the member's lock. The holder's spending
+// is not limited and not recorded.
+func (t *wallet) recordMemberSpend(tx *gorm.DB, wallet *model.Wallet, actorID string, amount int64) error {
+	if actorID == "" || actorID == wallet.UserID || amount == 0 {
+		return nil
+	}
+	return t.walletRepository.RecordMemberSpend(tx, wallet.ID, actorID, amount, time.Now().UTC())
+}
+
+// holdForApproval records a transfer or withdrawal held for approval. The
+// initiator's approval is counted right away if it is an owner.
+func (t *wallet) holdForApproval(ctx context.Context, approval *model.WalletApproval) (_ *model.WalletApproval, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.HoldForApproval",
+		tracing.AttrUserID.String(approval.WalletUserID),
+		tracing.AttrTransactionType.String(string(approval.TransactionType)),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(approval.Amount)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.walletRepository.FindByUserID(dbCtx, approval.WalletUserID)
+	if err != nil {
+		return nil, err
+	}
+	approval.WalletID = wallet.ID
+	approval.RequiredApprovals = wallet.RequiredApprovals
+	approval.Status = model.ApprovalPending
+	if err := t.walletRepository.CreateApproval(dbCtx, approval); err != nil {
+		utils.LogError("Failed to hold operation for approval", err)
+		return nil, err
+	}
+	approval.ApprovedBy = []string{}
+
+	role, err := t.memberRole(dbCtx, wallet, approval.InitiatedBy)
+	if err != nil || !role.CanApprove() {
+		return approval, nil
+	}
+	return t.approve(ctx, approval, approval.InitiatedBy)
+}
+
+// approve records an owner's approval and, once the operation has the
+// approvals it requires, carries it out. It is marked executed in the
+// database transaction that moves the funds, so it cannot be left approved
+// with its funds unmoved, and only one approval carries it out. An approved
+// operation that cannot be carried out, e.g. for lack of funds, is marked
+// failed with the reason.
+func (t *wallet) approve(ctx context.Context, approval *model.WalletApproval, approverID string) (*model.WalletApproval, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	approvals, err := t.walletRepository.AddApprovalVote(dbCtx, approval.ID, approverID)
+	if err != nil {
+		return nil, err
+	}
+	if approvals < int64(approval.RequiredApprovals) {
+		return t.walletRepository.FindApproval(dbCtx, approval.ID)
+	}
+
+	actorID := approval.InitiatedBy
+	if actorID == approval.WalletUserID {
+		actorID = ""
+	}
+	execute := func(tx *gorm.DB) error {
+		return t.walletRepository.ExecuteApproval(tx, approval.ID, time.Now().UTC())
+	}
+	switch approval.TransactionType {
+	case model.Transfer:
+		_, err = t.transfer(ctx, actorID, approval.WalletUserID, approval.ToUserID, int(approval.Amount), true, execute)
+	case model.Withdraw:
+		_, err = t.withdraw(ctx, actorID, approval.WalletUserID, int(approval.Amount), approval.ProviderID, true, execute)
+	case model.Closure:
+		_, err = t.closeWallet(ctx, actorID, approval.WalletUserID, approval.ProviderID, approval.ToUserID, true, execute)
+	}
+
+	resolveCtx := context.WithoutCancel(ctx)
+	if err != nil && err != model.ErrInvalidTransition {
+		utils.LogError("Failed to carry out approved operation", err)
+		failed, err := t.walletRepository.UpdateApprovalStatus(resolveCtx, approval.ID, model.ApprovalPending, model.ApprovalFailed, err.Error(), time.Now().UTC())
+		if err != model.ErrInvalidTransition {
+			return failed, err
+		}
+	}
+	// Carried out, or resolved first by a concurrent approval or rejection
+	return t.walletRepository.FindApproval(resolveCtx, approval.ID)
+}
+
+// findApproval returns the wallet of userID and its held operation id,
+// ErrNotFound if the operation belongs to another wallet.
+func (t *wallet) findApproval(ctx context.Context, userID string, id int) (*model.Wallet, *model.WalletApproval, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	approval, err := t.walletRepository.FindApproval(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if approval.WalletID != wallet.ID {
+		return nil, nil, model.ErrNotFound
+	}
+	return wallet, approval, nil
+}
+
+// memberRole returns the role of userID on the wallet: owner for its holder,
+// ErrNotMember for a user who is not a member.
+func (t *wallet) memberRole(ctx context.Context, wallet *model.Wallet, userID string) (model.MemberRole, error) {
+	if userID == wallet.UserID {
+		return model.MemberOwner, nil
+	}
+	member, err := t.walletRepository.FindMember(ctx, wallet.ID, userID)
+	if err == model.ErrNotFound {
+		return "", model.ErrNotMember
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// countOwners returns the number of owners of the wallet, its holder included.
+func (t *wallet) countOwners(ctx context.Context, wallet *model.Wallet) (int, error) {
+	members, err := t.walletRepository.FindMembers(ctx, wallet.ID)
+	if err != nil {
+		return 0, err
+	}
+	owners := 1
+	for _, member := range members {
+		if member.Role.CanApprove() {
+			owners++
+		}
+	}
+	return owners, nil
+}
+
+// initiator returns the user who requested an operation on a wallet: the
+// acting member, or the holder when it acted itself.
+func initiator(actorID, holderID string) string {
+	if actorID == "" {
+		return holderID
+	}
+	return actorID
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (t *wallet) CreateMerchant(ctx context.Context, profile *model.MerchantProfile) (_ *model.MerchantProfile, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.CreateMerchant",
+		tracing.AttrUserID.String(profile.UserID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	wallet := model.NewWallet(profile.UserID, model.Merchant)
+	if err := t.walletRepository.CreateMerchant(ctx, wallet, profile); err != nil {
+		utils.LogError("Failed to create merchant", err)
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (t *wallet) GetMerchant(ctx context.Context, userID string) (_ *model.MerchantProfile, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetMerchant",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	return t.findMerchant(ctx, userID)
+}
+
+func (t *wallet) CreatePaymentIntent(ctx context.Context, merchantID string, amount int, reference, description string) (_ *model.PaymentIntent, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.CreatePaymentIntent",
+		tracing.AttrUserID.String(merchantID),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if amount <= 0 {
+		return nil, model.ErrInvalidAmount
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	if _, err := t.findMerchant(ctx, merchantID); err != nil {
+		return nil, err
+	}
+
+	intent := &model.PaymentIntent{
+		MerchantID:  merchantID,
+		Reference:   reference,
+		Amount:      int64(amount),
+		Description: description,
+		Status:      model.IntentPending,
+	}
+	if err := t.walletRepository.CreatePaymentIntent(ctx, intent); err != nil {
+		utils.LogError("Failed to create payment intent", err)
+		return nil, err
+	}
+	return intent, nil
+}
+
+func (t *wallet) GetPaymentIntent(ctx context.Context, id int) (_ *model.PaymentIntent, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetPaymentIntent",
+		attribute.Int("payment_intent_id", id),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	return t.walletRepository.FindPaymentIntent(ctx, id)
+}
+
+func (t *wallet) ListPaymentIntents(ctx context.Context, merchantID string, status model.PaymentIntentStatus) (_ []model.PaymentIntent, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListPaymentIntents",
+		tracing.AttrUserID.String(merchantID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	if _, err := t.findMerchant(ctx, merchantID); err != nil {
+		return nil, err
+	}
+	return t.walletRepository.FindPaymentIntents(ctx, merchantID, status)
+}
+
+func (t *wallet) ConfirmPaymentIntent(ctx context.Context, id int, customerID string) (_ *model.PaymentIntent, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ConfirmPaymentIntent",
+		tracing.AttrUserID.String(customerID),
+		attribute.Int("payment_intent_id", id),
+		tracing.AttrTransactionType.String(string(model.Payment)),
+	)
+	var amount int64
+	defer func() {
+		metrics.ObserveOperation(model.Payment, amount, err)
+		tracing.EndSpan(span, err)
+	}()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	intent, err := t.walletRepository.FindPaymentIntent(dbCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	amount = intent.Amount
+	span.SetAttributes(tracing.AttrAmountBucket.String(tracing.AmountBucket(amount)))
+	if intent.Status != model.IntentPending {
+		return nil, model.ErrInvalidTransition
+	}
+
+	merchant, err := t.findMerchant(dbCtx, intent.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	// Only user wallets pay merchants, so a merchant cannot pay itself
+	customer, err := t.findUserWallet(dbCtx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.authorizeDebit(dbCtx, customer, "", amount, false); err != nil {
+		return nil, err
+	}
+	if err := kycTier(customer).TransferError(amount); err != nil {
+		return nil, err
+	}
+
+	intent, err = t.walletRepository.ConfirmPaymentIntent(dbCtx, id, customer, merchant.WalletID, time.Now())
+	if err != nil {
+		utils.LogError("Failed to confirm payment intent", err)
+		return nil, err
+	}
+
+	debitTxn, creditTxn := newLedgerPair(model.Payment, customerID, intent.MerchantID, intent.Amount)
+	recordLedgerPair(ctx, "payment", debitTxn, creditTxn)
+	invalidateHistories(ctx, "payment", customerID, intent.MerchantID)
+	return intent, nil
+}
+
+func (t *wallet) CancelPaymentIntent(ctx context.Context, id int) (_ *model.PaymentIntent, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.CancelPaymentIntent",
+		attribute.Int("payment_intent_id", id),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	if _, err := t.walletRepository.FindPaymentIntent(ctx, id); err != nil {
+		return nil, err
+	}
+	return t.walletRepository.CancelPaymentIntent(ctx, id, time.Now())
+}
+
+func (t *wallet) RefundPaymentIntent(ctx context.Context, id int, amount int, reason string) (_ *model.PaymentRefund, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.RefundPaymentIntent",
+		attribute.Int("payment_intent_id", id),
+		tracing.AttrTransactionType.String(string(model.Refund)),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+	)
+	defer func() {
+		metrics.ObserveOperation(model.Refund, int64(amount), err)
+		tracing.EndSpan(span, err)
+	}()
+
+	if amount <= 0 {
+		return nil, model.ErrInvalidAmount
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	intent, err := t.walletRepository.FindPaymentIntent(dbCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != model.IntentSucceeded {
+		return nil, model.ErrInvalidTransition
+	}
+	merchant, err := t.findMerchant(dbCtx, intent.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := t.walletRepository.FindByUserID(dbCtx, intent.CustomerID)
+	if err != nil {
+		utils.LogError("Customer wallet not found for refund", err)
+		return nil, err
+	}
+
+	refund := &model.PaymentRefund{
+		PaymentIntentID: intent.ID,
+		MerchantID:      intent.MerchantID,
+		CustomerID:      intent.CustomerID,
+		Amount:          int64(amount),
+		Reason:          reason,
+	}
+	if _, err := t.walletRepository.RefundPaymentIntent(dbCtx, refund, merchant.WalletID, customer.ID); err != nil {
+		utils.LogError("Failed to refund payment intent", err)
+		return nil, err
+	}
+
+	debitTxn, creditTxn := newLedgerPair(model.Refund, refund.MerchantID, refund.CustomerID, refund.Amount)
+	recordLedgerPair(ctx, "refund", debitTxn, creditTxn)
+	invalidateHistories(ctx, "refund", refund.MerchantID, refund.CustomerID)
+	return refund, nil
+}
+
+func (t *wallet) GetSettlementReport(ctx context.Context, merchantID string, from, to time.Time) (_ *model.SettlementReport, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetSettlementReport",
+		tracing.AttrUserID.String(merchantID),
+		attribute.String("from", from.Format(model.DateLayout)),
+		attribute.String("to", to.Format(model.DateLayout)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	from = truncateToDay(from)
+	to = truncateToDay(to)
+	days := int(to.Sub(from).Hours()/24) + 1
+	if to.Before(from) || to.After(time.Now().UTC()) || days > maxDailyBalanceDays {
+		return nil, model.ErrInvalidTimeRange
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	if _, err := t.findMerchant(ctx, merchantID); err != nil {
+		return nil, err
+	}
+	payments, refunds, err := t.walletRepository.FindSettlement(ctx, merchantID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LogError("Failed to find merchant settlement", err)
+		return nil, err
+	}
+	return model.NewSettlementReport(merchantID, from, to, payments, refunds), nil
+}
+
+// findMerchant returns the profile of a merchant, ErrNotMerchant if the user
+// ID belongs to a wallet of another type.
+func (t *wallet) findMerchant(ctx context.Context, userID string) (*model.MerchantProfile, error) {
+	profile, err := t.walletRepository.FindMerchant(ctx, userID)
+	if err != model.ErrNotFound {
+		return profile, err
+	}
+	if _, err := t.walletRepository.FindByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return nil, model.ErrNotMerchant
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/notify"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+func (t *wallet) CreatePaymentRequest(ctx context.Context, requesterID, payerID string, amount int, note string, expiresAt *time.Time) (_ *model.PaymentRequest, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.CreatePaymentRequest",
+		tracing.AttrUserID.String(requesterID),
+		attribute.String("payer_user_id", payerID),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if amount <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+	if requesterID == payerID {
+		return nil, model.ErrSameWallet
+	}
+
+	settings := config.GetPaymentRequests()
+	now := time.Now()
+	expiry := now.Add(settings.DefaultExpiry)
+	if expiresAt != nil {
+		if !expiresAt.After(now) || expiresAt.After(now.Add(settings.MaxExpiry)) {
+			return nil, model.ErrInvalidTimeRange
+		}
+		expiry = *expiresAt
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	for _, userID := range []string{requesterID, payerID} {
+		if _, err := t.walletRepository.FindByUserID(dbCtx, userID); err != nil {
+			utils.LogError("Wallet not found for payment request", err)
+			return nil, err
+		}
+	}
+
+	request := &model.PaymentRequest{
+		RequesterID: requesterID,
+		PayerID:     payerID,
+		Amount:      int64(amount),
+		Note:        note,
+		Status:      model.RequestPending,
+		ExpiresAt:   expiry.UTC(),
+	}
+	if err := t.walletRepository.CreatePaymentRequest(dbCtx, request); err != nil {
+		utils.LogError("Failed to create payment request", err)
+		return nil, err
+	}
+
+	notify.NewNotifier().Notify(ctx, model.NewPaymentRequestEvent(*request))
+	return request, nil
+}
+
+func (t *wallet) ListPaymentRequests(ctx context.Context, filter model.PaymentRequestFilter) (_ []model.PaymentRequest, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListPaymentRequests",
+		tracing.AttrUserID.String(filter.UserID),
+		attribute.Bool("incoming", filter.Incoming),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	if _, err := t.walletRepository.FindByUserID(dbCtx, filter.UserID); err != nil {
+		utils.LogError("Wallet not found for payment requests", err)
+		return nil, err
+	}
+
+	// Expire overdue requests first so they are not listed as pending
+	expired, err := t.walletRepository.ExpirePaymentRequests(dbCtx, filter.UserID, time.Now())
+	if err != nil {
+		utils.LogError("Failed to expire payment requests", err)
+		return nil, err
+	}
+	for _, request := range expired {
+		notify.NewNotifier().Notify(ctx, model.NewPaymentRequestEvent(request))
+	}
+
+	return t.walletRepository.FindPaymentRequests(dbCtx, filter)
+}
+
+func (t *wallet) AcceptPaymentRequest(ctx context.Context, payerID string, id int) (*model.PaymentRequest, error) {
+	return t.resolvePaymentRequest(ctx, payerID, id, model.RequestAccepted)
+}
+
+func (t *wallet) DeclinePaymentRequest(ctx context.Context, payerID string, id int) (*model.PaymentRequest, error) {
+	return t.resolvePaymentRequest(ctx, payerID, id, model.RequestDeclined)
+}
+
+func (t *wallet) CancelPaymentRequest(ctx context.Context, requesterID string, id int) (*model.PaymentRequest, error) {
+	return t.resolvePaymentRequest(ctx, requesterID, id, model.RequestCancelled)
+}
+
+// resolvePaymentRequest moves a pending request to its final state on behalf
+// of userID, who must be the payer to accept or decline it and the requester
+// to cancel it. Requests of other users are reported as not found.
+//
+// Accepting moves the request to accepted and transfers the amount in one
+// database transaction, so it cannot also be declined, cancelled or accepted
+// twice. If the transfer fails nothing changes and the request stays pending,
+// so the payer can retry once the problem, e.g. insufficient funds, is solved.
+func (t *wallet) resolvePaymentRequest(ctx context.Context, userID string, id int, to model.PaymentRequestStatus) (_ *model.PaymentRequest, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ResolvePaymentRequest",
+		tracing.AttrUserID.String(userID),
+		attribute.Int("payment_request_id", id),
+		attribute.String("status", string(to)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	request, err := t.walletRepository.FindPaymentRequest(dbCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	party := request.PayerID
+	if to == model.RequestCancelled {
+		party = request.RequesterID
+	}
+	if party != userID {
+		return nil, model.ErrNotFound
+	}
+
+	now := time.Now()
+	if request.Status == model.RequestPending && !request.ExpiresAt.After(now) {
+		expired, err := t.walletRepository.UpdatePaymentRequestStatus(dbCtx, id, model.RequestPending, model.RequestExpired, now)
+		if err == nil {
+			notify.NewNotifier().Notify(ctx, model.NewPaymentRequestEvent(*expired))
+		}
+		return nil, model.ErrExpired
+	}
+	if !request.Status.CanTransition(to) {
+		return nil, model.ErrInvalidTransition
+	}
+
+	if to == model.RequestAccepted {
+		// The request is accepted in the same database transaction that pays
+		// it, so it is never accepted without the payment or paid twice
+		var accepted *model.PaymentRequest
+		_, err = t.transfer(ctx, "", request.PayerID, request.RequesterID, int(request.Amount), false, func(tx *gorm.DB) error {
+			var err error
+			accepted, err = t.walletRepository.AcceptPaymentRequest(tx, id, now)
+			return err
+		})
+		if err != nil {
+			utils.LogError("Failed to pay payment request", err)
+			return nil, err
+		}
+		request = accepted
+	} else {
+		request, err = t.walletRepository.UpdatePaymentRequestStatus(dbCtx, id, model.RequestPending, to, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	notify.NewNotifier().Notify(ctx, model.NewPaymentRequestEvent(*request))
+	return request, nil
+}
+
+func (t *wallet) SubscribePaymentRequests(ctx context.Context, userID string) (<-chan model.PaymentRequestEvent, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	if _, err := t.walletRepository.FindByUserID(dbCtx, userID); err != nil {
+		return nil, err
+	}
+
+	events, unsubscribe := notify.NewNotifier().Subscribe(userID)
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+	return events, nil
+}
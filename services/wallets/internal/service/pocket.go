@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+func (t *wallet) CreatePocket(ctx context.Context, userID, name string) (_ *model.Wallet, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.CreatePocket",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	name, err = model.ParsePocketName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	owner, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pocket := model.NewPocket(owner, name)
+	if err := t.walletRepository.CreatePocket(ctx, pocket); err != nil {
+		utils.LogError("Failed to create pocket", err)
+		return nil, err
+	}
+	return pocket, nil
+}
+
+func (t *wallet) ListPockets(ctx context.Context, userID string) (_ []model.Wallet, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListPockets",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	owner, err := t.findUserWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return t.walletRepository.FindPockets(ctx, owner.ID)
+}
+
+func (t *wallet) GetPocketWithTransactions(ctx context.Context, userID, name string) (_ *model.Wallet, _ []model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetPocketWithTransactions",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	name, err = model.ParsePocketName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	owner, err := t.findUserWallet(dbCtx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pocket, err := t.walletRepository.FindPocket(dbCtx, owner.ID, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A pocket keeps its history under its own user ID
+	return t.GetWalletWithTransactions(ctx, pocket.UserID)
+}
+
+func (t *wallet) MovePocketFunds(ctx context.Context, userID, from, to string, amount int) (_ *model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.MovePocketFunds",
+		tracing.AttrUserID.String(userID),
+		attribute.String("from_pocket", from),
+		attribute.String("to_pocket", to),
+		tracing.AttrTransactionType.String(string(model.PocketMove)),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+	)
+	defer func() {
+		metrics.ObserveOperation(model.PocketMove, int64(amount), err)
+		tracing.EndSpan(span, err)
+	}()
+
+	if amount <= 0 {
+		return nil, model.ErrInvalidAmount
+	}
+	amountCents := int64(amount)
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	owner, err := t.findUserWallet(dbCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+	fromWallet, err := t.findPocketOrMain(dbCtx, owner, from)
+	if err != nil {
+		return nil, err
+	}
+	toWallet, err := t.findPocketOrMain(dbCtx, owner, to)
+	if err != nil {
+		return nil, err
+	}
+	if fromWallet.ID == toWallet.ID {
+		return nil, model.ErrSameWallet
+	}
+
+	err = t.walletRepository.WithTransaction(dbCtx, func(tx *gorm.DB) error {
+		return t.walletRepository.ApplyBalanceChanges(tx,
+			model.BalanceChange{WalletID: fromWallet.ID, Amount: -amountCents},
+			model.BalanceChange{WalletID: toWallet.ID, Amount: amountCents},
+		)
+	})
+	if err != nil {
+		utils.LogError("Failed to update wallet balances for pocket move", err)
+		return nil, err
+	}
+
+	debitTxn, creditTxn := newLedgerPair(model.PocketMove, fromWallet.UserID, toWallet.UserID, amountCents)
+	recordLedgerPair(ctx, "pocket_move", debitTxn, creditTxn)
+	invalidateHistories(ctx, "pocket move", fromWallet.UserID, toWallet.UserID)
+	return debitTxn, nil
+}
+
+// findUserWallet returns the wallet of userID, ErrNotUserWallet if it is a
+// provider, escrow or pocket wallet. Only user wallets have pockets and
+// members.
+func (t *wallet) findUserWallet(ctx context.Context, userID string) (*model.Wallet, error) {
+	owner, err := t.walletRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		utils.LogError("User wallet not found", err)
+		return nil, err
+	}
+	if owner.AcntType != model.User {
+		return nil, model.ErrNotUserWallet
+	}
+	return owner, nil
+}
+
+// findPocketOrMain returns the owner's pocket of the given name, or the
+// owner's wallet itself for MainPocket.
+func (t *wallet) findPocketOrMain(ctx context.Context, owner *model.Wallet, name string) (*model.Wallet, error) {
+	if strings.ToLower(strings.TrimSpace(name)) == model.MainPocket {
+		return owner, nil
+	}
+	name, err := model.ParsePocketName(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.walletRepository.FindPocket(ctx, owner.ID, name)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"gorm.io/gorm"
+)
+
+func (t *wallet) RegisterProvider(ctx context.Context, profile *model.ProviderProfile) (_ *model.ProviderProfile, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.RegisterProvider",
+		tracing.AttrUserID.String(profile.UserID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	if len(profile.SupportedCurrencies) == 0 {
+		profile.SupportedCurrencies = []string{model.Currency}
+	}
+	wallet := model.NewWallet(profile.UserID, model.Provider)
+	if err := t.walletRepository.CreateProvider(ctx, wallet, profile); err != nil {
+		utils.LogError("Failed to register provider", err)
+		return nil, err
+	}
+	profile.FlagLowFloat(config.GetProviders().LowFloatThreshold)
+	return profile, nil
+}
+
+// ListProviders returns every provider wallet with its profile, flagging
+// those whose balance is below their low-float threshold.
+func (t *wallet) ListProviders(ctx context.Context) (_ []model.ProviderProfile, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListProviders")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	providers, err := t.walletRepository.FindProviders(ctx)
+	if err != nil {
+		utils.LogError("Failed to find providers", err)
+		return nil, err
+	}
+	threshold := config.GetProviders().LowFloatThreshold
+	for i := range providers {
+		providers[i].FlagLowFloat(threshold)
+	}
+	return providers, nil
+}
+
+func (t *wallet) GetProvider(ctx context.Context, userID string) (_ *model.ProviderProfile, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetProvider",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	return t.findProvider(ctx, userID)
+}
+
+// SetProviderEnabled enables or disables deposits and withdrawals through a provider.
+func (t *wallet) SetProviderEnabled(ctx context.Context, userID string, enabled bool) (_ *model.ProviderProfile, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.SetProviderEnabled",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	status := model.Suspended
+	if enabled {
+		status = model.Active
+	}
+	if err := t.walletRepository.SetProviderStatus(ctx, userID, status); err != nil {
+		utils.LogError("Failed to set provider status", err)
+		return nil, err
+	}
+	return t.findProvider(ctx, userID)
+}
+
+// TopUpProvider adds float to a provider wallet from the provider's external
+// bank account. Disabled providers may be topped up.
+func (t *wallet) TopUpProvider(ctx context.Context, userID string, amount int) (_ *model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.TopUpProvider",
+		tracing.AttrUserID.String(userID),
+		tracing.AttrTransactionType.String(string(model.TopUp)),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+	)
+	defer func() {
+		metrics.ObserveOperation(model.TopUp, int64(amount), err)
+		tracing.EndSpan(span, err)
+	}()
+
+	if amount <= 0 {
+		return nil, model.ErrInvalidAmount
+	}
+	amountCents := int64(amount)
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	provider, err := t.walletRepository.FindProviderWallet(dbCtx, userID)
+	if err != nil {
+		utils.LogError("Provider wallet not found for top-up", err)
+		return nil, err
+	}
+
+	err = t.walletRepository.WithTransaction(dbCtx, func(tx *gorm.DB) error {
+		return t.walletRepository.ApplyBalanceChanges(tx,
+			model.BalanceChange{WalletID: provider.ID, Amount: amountCents},
+		)
+	})
+	if err != nil {
+		utils.LogError("Failed to update provider balance for top-up", err)
+		return nil, err
+	}
+
+	debitTxn, creditTxn := newLedgerPair(model.TopUp, model.ExternalFundingID, provider.UserID, amountCents)
+	recordLedgerPair(ctx, "top_up", debitTxn, creditTxn)
+	invalidateHistories(ctx, "top-up", provider.UserID)
+	return creditTxn, nil
+}
+
+// findProvider returns the profile of a provider wallet with its low-float flag.
+func (t *wallet) findProvider(ctx context.Context, userID string) (*model.ProviderProfile, error) {
+	provider, err := t.walletRepository.FindProvider(ctx, userID)
+	if err != nil {
+		utils.LogError("Provider not found", err)
+		return nil, err
+	}
+	provider.FlagLowFloat(config.GetProviders().LowFloatThreshold)
+	return provider, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/settlement"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RunSettlement nets the unsettled deposits and withdrawals of every provider
+// wallet created before the end of the given UTC day into one batch per
+// provider, and marks their entries as settled in the transactions service.
+// Only days that have ended can be settled. Running it again for the same day
+// only settles batches left pending by an earlier run, so it is safe to retry.
+// It returns every batch of the day.
+func (t *wallet) RunSettlement(ctx context.Context, date time.Time) (_ []model.SettlementBatch, err error) {
+	day := truncateToDay(date)
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.RunSettlement",
+		attribute.String("settlement_date", day.Format(model.DateLayout)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	cutoff := day.AddDate(0, 0, 1)
+	if cutoff.After(time.Now().UTC()) {
+		return nil, model.ErrInvalidTimeRange
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	// Entries of a pending batch are excluded from new batches only once the
+	// transactions service knows about them, so a provider whose earlier
+	// batch cannot be settled is skipped until it is.
+	pending, err := t.walletRepository.FindPendingSettlementBatches(ctx)
+	if err != nil {
+		utils.LogError("Failed to find pending settlement batches", err)
+		return nil, err
+	}
+	blocked := make(map[string]bool)
+	for i := range pending {
+		if err := t.settleBatch(ctx, &pending[i]); err != nil {
+			blocked[pending[i].ProviderID] = true
+		}
+	}
+
+	existing, err := t.walletRepository.FindSettlementBatches(ctx, day.Format(model.DateLayout), "")
+	if err != nil {
+		utils.LogError("Failed to find settlement batches", err)
+		return nil, err
+	}
+	for _, batch := range existing {
+		blocked[batch.ProviderID] = true
+	}
+
+	wallets, err := t.walletRepository.FindAll(ctx)
+	if err != nil {
+		utils.LogError("Failed to find provider wallets", err)
+		return nil, err
+	}
+	var providerIDs []string
+	for _, w := range wallets {
+		if w.AcntType == model.Provider && !blocked[w.UserID] {
+			providerIDs = append(providerIDs, w.UserID)
+		}
+	}
+
+	if len(providerIDs) > 0 {
+		txns, err := client.NewTxnClient().FetchUnsettledTransactions(ctx, providerIDs, cutoff)
+		if err != nil {
+			utils.LogError("Failed to fetch unsettled transactions", err)
+			return nil, err
+		}
+		for _, providerID := range providerIDs {
+			batch, entries := model.NewSettlementBatch(providerID, day, cutoff, txns)
+			if len(entries) == 0 {
+				continue
+			}
+			if err := t.walletRepository.CreateSettlementBatch(ctx, batch, entries); err != nil {
+				if errors.Is(err, model.ErrSettlementExists) {
+					// A concurrent run created it first
+					continue
+				}
+				utils.LogError("Failed to create settlement batch", err)
+				return nil, err
+			}
+			// A batch that fails to settle stays pending and is retried by the next run
+			_ = t.settleBatch(ctx, batch)
+		}
+	}
+
+	return t.walletRepository.FindSettlementBatches(ctx, day.Format(model.DateLayout), "")
+}
+
+// settleBatch marks the entries of a pending batch as settled in the
+// transactions service, then the batch itself.
+func (t *wallet) settleBatch(ctx context.Context, batch *model.SettlementBatch) error {
+	if err := client.NewTxnClient().SettleTransactions(ctx, batch.Reference(), batch.TransactionIDs); err != nil {
+		utils.LogError("Failed to settle transactions of batch "+batch.Reference(), err)
+		return err
+	}
+	settled, err := t.walletRepository.MarkSettlementBatchSettled(ctx, batch.ID, time.Now().UTC())
+	if err != nil && !errors.Is(err, model.ErrInvalidTransition) {
+		utils.LogError("Failed to mark settlement batch as settled", err)
+		return err
+	}
+	if settled != nil {
+		*batch = *settled
+		log.WithFields(log.Fields{
+			"batch":      batch.Reference(),
+			"provider":   batch.ProviderID,
+			"net_amount": batch.NetAmount,
+		}).Info("settlement batch settled")
+	}
+	return nil
+}
+
+func (t *wallet) ListSettlementBatches(ctx context.Context, date, providerID string) (_ []model.SettlementBatch, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.ListSettlementBatches",
+		attribute.String("settlement_date", date),
+		attribute.String("provider_id", providerID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	return t.walletRepository.FindSettlementBatches(ctx, date, providerID)
+}
+
+func (t *wallet) GetSettlementBatch(ctx context.Context, id int) (_ *model.SettlementBatch, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetSettlementBatch",
+		attribute.Int("settlement_batch_id", id),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	return t.walletRepository.FindSettlementBatch(ctx, id)
+}
+
+// WriteSettlement renders the batches of the given UTC day to w.
+func (t *wallet) WriteSettlement(ctx context.Context, date time.Time, w settlement.Writer) (err error) {
+	day := truncateToDay(date)
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.WriteSettlement",
+		attribute.String("settlement_date", day.Format(model.DateLayout)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	batches, err := t.walletRepository.FindSettlementBatches(ctx, day.Format(model.DateLayout), "")
+	if err != nil {
+		utils.LogError("Failed to find settlement batches", err)
+		return err
+	}
+	return w.Write(day, batches)
+}
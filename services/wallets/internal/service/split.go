@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+func (t *wallet) TransferSplit(ctx context.Context, fromUserID string, mode model.SplitMode, amount int, recipients []model.SplitRecipient) (_ *model.SplitTransfer, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.TransferSplit",
+		tracing.AttrUserID.String(fromUserID),
+		tracing.AttrTransactionType.String(string(model.Transfer)),
+		attribute.String("split_mode", string(mode)),
+		attribute.Int("recipients", len(recipients)),
+	)
+	var total int64
+	defer func() {
+		metrics.ObserveOperation(model.Transfer, total, err)
+		tracing.EndSpan(span, err)
+	}()
+
+	shares, err := model.AllocateSplit(mode, int64(amount), recipients)
+	if err != nil {
+		return nil, err
+	}
+	for _, share := range shares {
+		total += share
+	}
+	span.SetAttributes(tracing.AttrAmountBucket.String(tracing.AmountBucket(total)))
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	fromWallet, err := t.walletRepository.FindByUserID(dbCtx, fromUserID)
+	if err != nil {
+		utils.LogError("Sender wallet not found for split transfer", err)
+		return nil, err
+	}
+	if err := fromWallet.DirectUseError(); err != nil {
+		return nil, err
+	}
+	if err := kycTier(fromWallet).TransferError(total); err != nil {
+		return nil, err
+	}
+	if fromWallet.NeedsApproval(total) {
+		return nil, model.ErrApprovalRequired
+	}
+
+	// The sender pays the total and every recipient is credited its share,
+	// all in the same database transaction
+	changes := []model.BalanceChange{{WalletID: fromWallet.ID, Amount: -total}}
+	for i, recipient := range recipients {
+		if recipient.UserID == fromUserID {
+			return nil, model.ErrSameWallet
+		}
+		toWallet, err := t.walletRepository.FindByUserID(dbCtx, recipient.UserID)
+		if err != nil {
+			utils.LogError("Receiver wallet not found for split transfer", err)
+			return nil, err
+		}
+		if err := toWallet.DirectUseError(); err != nil {
+			return nil, err
+		}
+		changes = append(changes, model.BalanceChange{WalletID: toWallet.ID, Amount: shares[i]})
+	}
+
+	err = t.walletRepository.WithTransaction(dbCtx, func(tx *gorm.DB) error {
+		return t.walletRepository.ApplyBalanceChanges(tx, changes...)
+	})
+	if err != nil {
+		utils.LogError("Failed to update wallet balances for split transfer", err)
+		return nil, err
+	}
+
+	// Every recipient gets its own ledger pair; the shared group ID ties them
+	// together as one payment
+	split := &model.SplitTransfer{
+		GroupID:      "split-" + rand.Text(),
+		FromUserID:   fromUserID,
+		Amount:       total,
+		Transactions: make([]model.Transaction, 0, len(recipients)),
+	}
+	userIDs := []string{fromUserID}
+	for i, recipient := range recipients {
+		debitTxn, creditTxn := newLedgerPair(model.Transfer, fromUserID, recipient.UserID, shares[i])
+		debitTxn.GroupID, creditTxn.GroupID = split.GroupID, split.GroupID
+		recordLedgerPair(ctx, "split_transfer", debitTxn, creditTxn)
+		split.Transactions = append(split.Transactions, *debitTxn)
+		userIDs = append(userIDs, recipient.UserID)
+	}
+	invalidateHistories(ctx, "split transfer", userIDs...)
+
+	return split, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/statement"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// WriteStatement renders the statement of a wallet for the UTC days from
+// through to, inclusive, to w: the opening balance, every completed ledger
+// entry with the running balance, and the closing balance. The ledger is paged
+// through and each page written as it arrives, so a failure part way through
+// can leave a partial statement in w; an error before the header means
+// nothing was written.
+func (t *wallet) WriteStatement(ctx context.Context, userID string, from, to time.Time, w statement.Writer) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.WriteStatement",
+		tracing.AttrUserID.String(userID),
+		attribute.String("from", from.Format(model.DateLayout)),
+		attribute.String("to", to.Format(model.DateLayout)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	start := truncateToDay(from)
+	end := truncateToDay(to).AddDate(0, 0, 1)
+	now := time.Now().UTC()
+	if end.Before(start) || start.After(now) {
+		return model.ErrInvalidTimeRange
+	}
+	if end.After(now) {
+		end = now
+	}
+
+	// Entries at exactly start belong to this statement, not the opening balance
+	wallet, balance, since, err := t.startLedger(ctx, userID, start.Add(-time.Nanosecond))
+	if err != nil {
+		return err
+	}
+	err = t.replayLedger(ctx, userID, afterSnapshot(since), start, func(txn model.Transaction) error {
+		balance += txn.SignedAmount()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Formats that total the lines ahead of them get an extra pass over the
+	// ledger rather than holding the lines in memory
+	if summarizer, ok := w.(statement.Summarizer); ok {
+		summary := model.StatementSummary{StatementFooter: model.StatementFooter{ClosingBalance: balance}}
+		err = t.replayLedger(ctx, userID, start, end, func(txn model.Transaction) error {
+			amount := txn.SignedAmount()
+			summary.ClosingBalance += amount
+			if amount < 0 {
+				summary.DebitEntries++
+				summary.TotalDebits -= amount
+			} else {
+				summary.CreditEntries++
+				summary.TotalCredits += amount
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		summarizer.Summarize(summary)
+	}
+
+	if err := w.WriteHeader(model.StatementHeader{
+		UserID:         wallet.UserID,
+		AcntType:       wallet.AcntType,
+		Currency:       model.Currency,
+		From:           start,
+		To:             end,
+		OpeningBalance: balance,
+		GeneratedAt:    now,
+	}); err != nil {
+		return err
+	}
+
+	// Lines are written as each page of the ledger arrives
+	var footer model.StatementFooter
+	err = t.replayLedger(ctx, userID, start, end, func(txn model.Transaction) error {
+		amount := txn.SignedAmount()
+		balance += amount
+		if amount < 0 {
+			footer.TotalDebits -= amount
+		} else {
+			footer.TotalCredits += amount
+		}
+		return w.WriteLine(model.StatementLine{
+			TransactionID:   txn.ID,
+			Date:            txn.CreatedAt,
+			TransactionType: txn.TransactionType,
+			OperationType:   txn.OperationType,
+			Counterparty:    txn.ObjectWalletID,
+			Amount:          amount,
+			RunningBalance:  balance,
+			GroupID:         txn.GroupID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	footer.ClosingBalance = balance
+	return w.WriteFooter(footer)
+}
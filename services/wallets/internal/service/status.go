@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// SuspendWallet suspends an active wallet and its pockets.
+func (t *wallet) SuspendWallet(ctx context.Context, userID string) (*model.Wallet, error) {
+	return t.setWalletStatus(ctx, "service.Wallet.SuspendWallet", userID, model.Suspended, model.Active)
+}
+
+// ActivateWallet makes a suspended or dormant wallet and its pockets active
+// again. Closed wallets are reopened with ReopenWallet instead.
+func (t *wallet) ActivateWallet(ctx context.Context, userID string) (*model.Wallet, error) {
+	return t.setWalletStatus(ctx, "service.Wallet.ActivateWallet", userID, model.Active, model.Suspended, model.Inactive)
+}
+
+// setWalletStatus moves a wallet and its pockets to the status to, returns
+// ErrInvalidTransition if the wallet is in none of the from statuses.
+func (t *wallet) setWalletStatus(ctx context.Context, spanName, userID string, to model.Status, from ...model.Status) (_ *model.Wallet, err error) {
+	ctx, span := tracing.StartSpan(ctx, spanName,
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	userWallet, err := t.walletRepository.FindByUserID(dbCtx, userID)
+	if err != nil {
+		utils.LogError("Wallet not found for status change", err)
+		return nil, err
+	}
+	if err := userWallet.DirectUseError(); err != nil {
+		return nil, err
+	}
+	if userWallet.Status == model.Closed {
+		return nil, model.ErrWalletClosed
+	}
+	if !slices.Contains(from, userWallet.Status) {
+		return nil, model.ErrInvalidTransition
+	}
+	if err := t.walletRepository.SetWalletStatus(dbCtx, userWallet.ID, userWallet.Status, to); err != nil {
+		utils.LogError("Failed to change wallet status", err)
+		return nil, err
+	}
+	invalidateHistories(ctx, "status change", userWallet.UserID)
+
+	log.WithFields(log.Fields{
+		"user_id": userWallet.UserID,
+		"from":    userWallet.Status,
+		"to":      to,
+	}).Info("wallet status changed")
+	return t.walletRepository.FindByUserID(dbCtx, userID)
+}
@@ -3,21 +3,98 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"time"
+
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/settlement"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/statement"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
 )
 
 // Wallet is the service for the wallet endpoint.
 type Wallet interface {
-	Create(wallet *model.Wallet) error
-	Deposit(userID string, amount int, providerID *string) (*model.Transaction, error)
-	Withdraw(userID string, amount int, providerID *string) (*model.Transaction, error)
-	Transfer(fromUserID string, toUserID string, amount int) (*model.Transaction, error)
-	GetWalletWithTransactions(userID string) (*model.Wallet, []model.Transaction, error)
+	Create(ctx context.Context, wallet *model.Wallet) error
+	Deposit(ctx context.Context, userID string, amount int, providerID *string) (*model.Transaction, error)
+	Withdraw(ctx context.Context, userID string, amount int, providerID *string) (*model.Transaction, error)
+	Transfer(ctx context.Context, fromUserID string, toUserID string, amount int) (*model.Transaction, error)
+	TransferSplit(ctx context.Context, fromUserID string, mode model.SplitMode, amount int, recipients []model.SplitRecipient) (*model.SplitTransfer, error)
+	GetWalletWithTransactions(ctx context.Context, userID string) (*model.Wallet, []model.Transaction, error)
+	GetBalanceAt(ctx context.Context, userID string, at time.Time) (*model.BalanceAt, error)
+	GetDailyBalances(ctx context.Context, userID string, from, to time.Time) ([]model.DailyBalance, error)
+	WriteStatement(ctx context.Context, userID string, from, to time.Time, w statement.Writer) error
+	RegisterAlias(ctx context.Context, userID, alias, displayName string) (*model.WalletAlias, error)
+	ListAliases(ctx context.Context, userID string) ([]model.WalletAlias, error)
+	ChallengeAlias(ctx context.Context, userID, alias string) (*model.WalletAlias, error)
+	VerifyAlias(ctx context.Context, userID, alias, code string) (*model.WalletAlias, error)
+	ResolveAlias(ctx context.Context, alias string) (*model.ResolvedAlias, error)
+	TransferToAlias(ctx context.Context, fromUserID, toAlias string, amount int) (*model.Transaction, error)
+	CreatePaymentRequest(ctx context.Context, requesterID, payerID string, amount int, note string, expiresAt *time.Time) (*model.PaymentRequest, error)
+	ListPaymentRequests(ctx context.Context, filter model.PaymentRequestFilter) ([]model.PaymentRequest, error)
+	AcceptPaymentRequest(ctx context.Context, payerID string, id int) (*model.PaymentRequest, error)
+	DeclinePaymentRequest(ctx context.Context, payerID string, id int) (*model.PaymentRequest, error)
+	CancelPaymentRequest(ctx context.Context, requesterID string, id int) (*model.PaymentRequest, error)
+	SubscribePaymentRequests(ctx context.Context, userID string) (<-chan model.PaymentRequestEvent, error)
+	CreateEscrow(ctx context.Context, buyerID, sellerID, arbiterID string, amount int, deadline time.Time, description string) (*model.EscrowAgreement, error)
+	GetEscrow(ctx context.Context, id int) (*model.EscrowAgreement, error)
+	ReleaseEscrow(ctx context.Context, id int, actorID string) (*model.EscrowAgreement, error)
+	RefundEscrow(ctx context.Context, id int, actorID string) (*model.EscrowAgreement, error)
+	SplitEscrow(ctx context.Context, id int, actorID string, sellerAmount int) (*model.EscrowAgreement, error)
+	RefundExpiredEscrows(ctx context.Context) (int, error)
+	CreatePocket(ctx context.Context, userID, name string) (*model.Wallet, error)
+	ListPockets(ctx context.Context, userID string) ([]model.Wallet, error)
+	GetPocketWithTransactions(ctx context.Context, userID, name string) (*model.Wallet, []model.Transaction, error)
+	MovePocketFunds(ctx context.Context, userID, from, to string, amount int) (*model.Transaction, error)
+	AddMember(ctx context.Context, userID, memberID string, role model.MemberRole, spendLimit int, unlimited bool, window model.SpendWindow) (*model.WalletMember, error)
+	ListMembers(ctx context.Context, userID string) ([]model.WalletMember, error)
+	RemoveMember(ctx context.Context, userID, memberID string) error
+	SetApprovalPolicy(ctx context.Context, userID string, threshold, requiredApprovals int) (*model.Wallet, error)
+	InitiateTransfer(ctx context.Context, actorID, fromUserID, toUserID string, amount int) (*model.Transaction, *model.WalletApproval, error)
+	InitiateWithdraw(ctx context.Context, actorID, userID string, amount int, providerID *string) (*model.Transaction, *model.WalletApproval, error)
+	ListApprovals(ctx context.Context, userID string, status model.ApprovalStatus) ([]model.WalletApproval, error)
+	ApproveOperation(ctx context.Context, userID string, id int, approverID string) (*model.WalletApproval, error)
+	RejectOperation(ctx context.Context, userID string, id int, memberID string) (*model.WalletApproval, error)
+	CreateMerchant(ctx context.Context, profile *model.MerchantProfile) (*model.MerchantProfile, error)
+	GetMerchant(ctx context.Context, userID string) (*model.MerchantProfile, error)
+	CreatePaymentIntent(ctx context.Context, merchantID string, amount int, reference, description string) (*model.PaymentIntent, error)
+	GetPaymentIntent(ctx context.Context, id int) (*model.PaymentIntent, error)
+	ListPaymentIntents(ctx context.Context, merchantID string, status model.PaymentIntentStatus) ([]model.PaymentIntent, error)
+	ConfirmPaymentIntent(ctx context.Context, id int, customerID string) (*model.PaymentIntent, error)
+	CancelPaymentIntent(ctx context.Context, id int) (*model.PaymentIntent, error)
+	RefundPaymentIntent(ctx context.Context, id int, amount int, reason string) (*model.PaymentRefund, error)
+	GetSettlementReport(ctx context.Context, merchantID string, from, to time.Time) (*model.SettlementReport, error)
+	RunSettlement(ctx context.Context, date time.Time) ([]model.SettlementBatch, error)
+	ListSettlementBatches(ctx context.Context, date, providerID string) ([]model.SettlementBatch, error)
+	GetSettlementBatch(ctx context.Context, id int) (*model.SettlementBatch, error)
+	WriteSettlement(ctx context.Context, date time.Time, w settlement.Writer) error
+	RegisterProvider(ctx context.Context, profile *model.ProviderProfile) (*model.ProviderProfile, error)
+	ListProviders(ctx context.Context) ([]model.ProviderProfile, error)
+	GetProvider(ctx context.Context, userID string) (*model.ProviderProfile, error)
+	SetProviderEnabled(ctx context.Context, userID string, enabled bool) (*model.ProviderProfile, error)
+	TopUpProvider(ctx context.Context, userID string, amount int) (*model.Transaction, error)
+	CloseWallet(ctx context.Context, actorID, userID string, toProviderID *string, toUserID string) (*model.WalletClosure, *model.WalletApproval, error)
+	ReopenWallet(ctx context.Context, userID string) (*model.Wallet, error)
+	SuspendWallet(ctx context.Context, userID string) (*model.Wallet, error)
+	ActivateWallet(ctx context.Context, userID string) (*model.Wallet, error)
+	RunDormancy(ctx context.Context, now time.Time) (int, error)
+	SubmitKYCVerification(ctx context.Context, userID string, level model.KYCLevel, documents []model.KYCDocument) (*model.KYCVerification, error)
+	GetKYCProfile(ctx context.Context, userID string) (*model.KYCProfile, error)
+	ListKYCVerifications(ctx context.Context, status model.KYCStatus) ([]model.KYCVerification, error)
+	ReviewKYCVerification(ctx context.Context, id int, approve bool, reviewer, note string) (*model.KYCVerification, error)
+	ProposeAdjustment(ctx context.Context, userID string, operationType model.OperationType, amount int, reason string, evidence []string, proposedBy string) (*model.BalanceAdjustment, error)
+	GetAdjustment(ctx context.Context, id int) (*model.BalanceAdjustment, error)
+	ListAdjustments(ctx context.Context, status model.AdjustmentStatus) ([]model.BalanceAdjustment, error)
+	ReviewAdjustment(ctx context.Context, id int, approve bool, reviewer, note string) (*model.BalanceAdjustment, error)
 }
 
 type wallet struct {
@@ -31,8 +108,17 @@ func NewWalletService(wr repository.Wallet) Wallet {
 	}
 }
 
-func (t *wallet) Create(wallet *model.Wallet) error {
-	err := t.walletRepository.Create(wallet)
+func (t *wallet) Create(ctx context.Context, wallet *model.Wallet) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.Create",
+		tracing.AttrUserID.String(wallet.UserID),
+		attribute.String("acnt_type", string(wallet.AcntType)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	err = t.walletRepository.Create(ctx, wallet)
 	if err != nil {
 		utils.LogError("Failed to create wallet", err)
 		return err
@@ -40,44 +126,54 @@ func (t *wallet) Create(wallet *model.Wallet) error {
 	return nil
 }
 
-func (t *wallet) Deposit(userID string, amount int, providerID *string) (*model.Transaction, error) {
+func (t *wallet) Deposit(ctx context.Context, userID string, amount int, providerID *string) (_ *model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.Deposit",
+		tracing.AttrUserID.String(userID),
+		tracing.AttrTransactionType.String(string(model.Deposit)),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+	)
+	defer func() {
+		metrics.ObserveOperation(model.Deposit, int64(amount), err)
+		tracing.EndSpan(span, err)
+	}()
+
 	// Validate amount
 	if amount <= 0 {
 		return nil, errors.New("invalid amount")
 	}
 	amountCents := int64(amount)
 
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
 	// FetchTransactions user wallet
-	userWallet, err := t.walletRepository.FindByUserID(userID)
+	userWallet, err := t.walletRepository.FindByUserID(dbCtx, userID)
 	if err != nil {
 		utils.LogError("User wallet not found for deposit", err)
 		return nil, err
 	}
+	if err := userWallet.DirectUseError(); err != nil {
+		return nil, err
+	}
 
 	// Set default provider if not provided
-	defaultProviderID := "deposit-provider-master"
+	defaultProviderID := model.DepositProviderID
 	if providerID == nil {
 		providerID = &defaultProviderID
 	}
 
 	// FetchTransactions or get provider wallet
-	providerWallet, err := t.walletRepository.FindProviderWallet(*providerID)
+	providerWallet, err := t.walletRepository.FindProviderWallet(dbCtx, *providerID)
 	if err != nil {
 		utils.LogError("Provider wallet not found for deposit", err)
-		return nil, errors.New("deposit provider wallet not found")
-	}
-
-	// Begin database transaction
-	tx := t.walletRepository.BeginTransaction()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+		if err == model.ErrNotFound {
+			return nil, model.ErrUnknownProvider
 		}
-	}()
-
-	if err := tx.Error; err != nil {
 		return nil, err
 	}
+	if providerWallet.Status != model.Active {
+		return nil, model.ErrProviderDisabled
+	}
 
 	// Create debit transaction for provider
 	debitTxn := &model.Transaction{
@@ -99,39 +195,43 @@ func (t *wallet) Deposit(userID string, amount int, providerID *string) (*model.
 		Status:          model.Completed,
 	}
 
-	// Create transaction pair via microservice asynchronously
-	go func() {
-		if err := client.NewTxnClient().CreateTransactionPair(debitTxn, creditTxn); err != nil {
-			utils.LogError("Failed to create transaction pair for deposit", err)
-		}
-	}()
-
-	// Update wallet balances
-	if err := t.walletRepository.UpdateWalletBalance(tx, providerWallet.ID, amountCents, false); err != nil {
-		utils.LogError("Failed to update provider wallet balance for deposit", err)
-		tx.Rollback()
+	// Move the funds in a single database transaction. Both wallets are locked
+	// in ID order and the balance is checked under the lock; the transaction is
+	// retried if it loses a deadlock or a concurrent version check.
+	err = t.walletRepository.WithTransaction(dbCtx, func(tx *gorm.DB) error {
+		return t.walletRepository.ApplyBalanceChanges(tx,
+			model.BalanceChange{WalletID: providerWallet.ID, Amount: -amountCents},
+			model.BalanceChange{WalletID: userWallet.ID, Amount: amountCents},
+		)
+	})
+	if err != nil {
+		utils.LogError("Failed to update wallet balances for deposit", err)
 		return nil, err
 	}
 
-	if err := t.walletRepository.UpdateWalletBalance(tx, userWallet.ID, amountCents, true); err != nil {
-		utils.LogError("Failed to update user wallet balance for deposit", err)
-		tx.Rollback()
-		return nil, err
+	// A deposit by the holder is activity, so it wakes a dormant wallet
+	if userWallet.Status == model.Inactive {
+		t.reactivate(ctx, userWallet)
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		utils.LogError("Failed to commit deposit transaction", err)
-		return nil, err
-	}
+	// Create transaction pair via microservice asynchronously, only once the
+	// balance change is committed. The ledger write outlives the request, so it
+	// keeps the request's values but not its cancellation.
+	go func() {
+		if err := client.NewTxnClient().CreateTransactionPair(context.WithoutCancel(ctx), debitTxn, creditTxn); err != nil {
+			utils.LogError("Failed to create transaction pair for deposit", err)
+			metrics.IncAsyncFailure("ledger_deposit")
+		}
+	}()
 
-	// Invalidate cache for both user and provider
-	ctx := context.Background()
+	// Invalidate cache for both user and provider. The balance change is already
+	// committed, so this must not be skipped if the caller goes away.
+	cacheCtx := context.WithoutCancel(ctx)
 	redisClient := cache.NewRedisClient()
-	if err := redisClient.DeleteTransactionHistory(ctx, userWallet.UserID); err != nil {
+	if err := redisClient.DeleteTransactionHistory(cacheCtx, userWallet.UserID); err != nil {
 		utils.LogError("Failed to invalidate user cache after deposit", err)
 	}
-	if err := redisClient.DeleteTransactionHistory(ctx, providerWallet.UserID); err != nil {
+	if err := redisClient.DeleteTransactionHistory(cacheCtx, providerWallet.UserID); err != nil {
 		utils.LogError("Failed to invalidate provider cache after deposit", err)
 	}
 
@@ -139,49 +239,69 @@ func (t *wallet) Deposit(userID string, amount int, providerID *string) (*model.
 	return creditTxn, nil
 }
 
-func (t *wallet) Withdraw(userID string, amount int, providerID *string) (*model.Transaction, error) {
+func (t *wallet) Withdraw(ctx context.Context, userID string, amount int, providerID *string) (*model.Transaction, error) {
+	return t.withdraw(ctx, "", userID, amount, providerID, false, nil)
+}
+
+// withdraw moves amount out of a wallet to a provider. actorID is the member
+// making the withdrawal, empty for the holder; approved is set once the
+// owners have approved a withdrawal above the approval threshold. within, if
+// set, runs in the database transaction that moves the funds, as in transfer.
+func (t *wallet) withdraw(ctx context.Context, actorID, userID string, amount int, providerID *string, approved bool, within func(tx *gorm.DB) error) (_ *model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.Withdraw",
+		tracing.AttrUserID.String(userID),
+		attribute.String("actor_user_id", actorID),
+		tracing.AttrTransactionType.String(string(model.Withdraw)),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+	)
+	defer func() {
+		metrics.ObserveOperation(model.Withdraw, int64(amount), err)
+		tracing.EndSpan(span, err)
+	}()
+
 	// Validate amount
 	if amount <= 0 {
 		return nil, errors.New("invalid amount")
 	}
 	amountCents := int64(amount)
 
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
 	// FetchTransactions user wallet
-	userWallet, err := t.walletRepository.FindByUserID(userID)
+	userWallet, err := t.walletRepository.FindByUserID(dbCtx, userID)
 	if err != nil {
 		utils.LogError("User wallet not found for withdraw", err)
 		return nil, err
 	}
-
-	// Check balance
-	if userWallet.Balance < amountCents {
-		return nil, model.ErrInsufficientFunds
+	if err := userWallet.DirectUseError(); err != nil {
+		return nil, err
+	}
+	if err := kycTier(userWallet).WithdrawError(); err != nil {
+		return nil, err
+	}
+	if err := t.authorizeDebit(dbCtx, userWallet, actorID, amountCents, approved); err != nil {
+		return nil, err
 	}
 
 	// Set default provider if not provided
-	defaultProviderID := "withdraw-provider-master"
+	defaultProviderID := model.WithdrawProviderID
 	if providerID == nil {
 		providerID = &defaultProviderID
 	}
 
 	// FetchTransactions or get provider wallet
-	providerWallet, err := t.walletRepository.FindProviderWallet(*providerID)
+	providerWallet, err := t.walletRepository.FindProviderWallet(dbCtx, *providerID)
 	if err != nil {
 		utils.LogError("Provider wallet not found for withdraw", err)
-		return nil, errors.New("withdraw provider wallet not found")
-	}
-
-	// Begin database transaction
-	tx := t.walletRepository.BeginTransaction()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+		if err == model.ErrNotFound {
+			return nil, model.ErrUnknownProvider
 		}
-	}()
-
-	if err := tx.Error; err != nil {
 		return nil, err
 	}
+	if providerWallet.Status != model.Active {
+		return nil, model.ErrProviderDisabled
+	}
 
 	// Create debit transaction for user
 	debitTxn := &model.Transaction{
@@ -191,6 +311,7 @@ func (t *wallet) Withdraw(userID string, amount int, providerID *string) (*model
 		OperationType:   model.Debit,
 		Amount:          amountCents,
 		Status:          model.Completed,
+		ActorUserID:     actorID,
 	}
 
 	// Create credit transaction for provider
@@ -201,41 +322,49 @@ func (t *wallet) Withdraw(userID string, amount int, providerID *string) (*model
 		OperationType:   model.Credit,
 		Amount:          amountCents,
 		Status:          model.Completed,
+		ActorUserID:     actorID,
 	}
 
-	// Create transaction pair via microservice asynchronously
-	go func() {
-		if err := client.NewTxnClient().CreateTransactionPair(debitTxn, creditTxn); err != nil {
-			utils.LogError("Failed to create transaction pair for withdraw", err)
+	// Move the funds in a single database transaction. Both wallets are locked
+	// in ID order and the balance is checked under the lock; the transaction is
+	// retried if it loses a deadlock or a concurrent version check.
+	err = t.walletRepository.WithTransaction(dbCtx, func(tx *gorm.DB) error {
+		if within != nil {
+			if err := within(tx); err != nil {
+				return err
+			}
 		}
-	}()
-
-	// Update wallet balances
-	if err := t.walletRepository.UpdateWalletBalance(tx, userWallet.ID, amountCents, false); err != nil {
-		utils.LogError("Failed to update user wallet balance for withdraw", err)
-		tx.Rollback()
-		return nil, err
-	}
-
-	if err := t.walletRepository.UpdateWalletBalance(tx, providerWallet.ID, amountCents, true); err != nil {
-		utils.LogError("Failed to update provider wallet balance for withdraw", err)
-		tx.Rollback()
+		if err := t.recordMemberSpend(tx, userWallet, actorID, amountCents); err != nil {
+			return err
+		}
+		return t.walletRepository.ApplyBalanceChanges(tx,
+			model.BalanceChange{WalletID: userWallet.ID, Amount: -amountCents},
+			model.BalanceChange{WalletID: providerWallet.ID, Amount: amountCents},
+		)
+	})
+	if err != nil {
+		utils.LogError("Failed to update wallet balances for withdraw", err)
 		return nil, err
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		utils.LogError("Failed to commit withdraw transaction", err)
-		return nil, err
-	}
+	// Create transaction pair via microservice asynchronously, only once the
+	// balance change is committed. The ledger write outlives the request, so it
+	// keeps the request's values but not its cancellation.
+	go func() {
+		if err := client.NewTxnClient().CreateTransactionPair(context.WithoutCancel(ctx), debitTxn, creditTxn); err != nil {
+			utils.LogError("Failed to create transaction pair for withdraw", err)
+			metrics.IncAsyncFailure("ledger_withdraw")
+		}
+	}()
 
-	// Invalidate cache for both user and provider
-	ctx := context.Background()
+	// Invalidate cache for both user and provider. The balance change is already
+	// committed, so this must not be skipped if the caller goes away.
+	cacheCtx := context.WithoutCancel(ctx)
 	redisClient := cache.NewRedisClient()
-	if err := redisClient.DeleteTransactionHistory(ctx, userWallet.UserID); err != nil {
+	if err := redisClient.DeleteTransactionHistory(cacheCtx, userWallet.UserID); err != nil {
 		utils.LogError("Failed to invalidate user cache after withdraw", err)
 	}
-	if err := redisClient.DeleteTransactionHistory(ctx, providerWallet.UserID); err != nil {
+	if err := redisClient.DeleteTransactionHistory(cacheCtx, providerWallet.UserID); err != nil {
 		utils.LogError("Failed to invalidate provider cache after withdraw", err)
 	}
 
@@ -243,41 +372,60 @@ func (t *wallet) Withdraw(userID string, amount int, providerID *string) (*model
 	return debitTxn, nil
 }
 
-func (t *wallet) Transfer(fromUserID string, toUserID string, amount int) (*model.Transaction, error) {
+func (t *wallet) Transfer(ctx context.Context, fromUserID string, toUserID string, amount int) (*model.Transaction, error) {
+	return t.transfer(ctx, "", fromUserID, toUserID, amount, false, nil)
+}
+
+// transfer moves amount from one wallet to another. actorID is the member
+// making the transfer, empty for the holder; approved is set once the owners
+// have approved a transfer above the approval threshold. within, if set, runs
+// in the database transaction that moves the funds, so its changes commit or
+// roll back together with them.
+func (t *wallet) transfer(ctx context.Context, actorID, fromUserID, toUserID string, amount int, approved bool, within func(tx *gorm.DB) error) (_ *model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.Transfer",
+		tracing.AttrUserID.String(fromUserID),
+		attribute.String("actor_user_id", actorID),
+		attribute.String("to_user_id", toUserID),
+		tracing.AttrTransactionType.String(string(model.Transfer)),
+		tracing.AttrAmountBucket.String(tracing.AmountBucket(int64(amount))),
+	)
+	defer func() {
+		metrics.ObserveOperation(model.Transfer, int64(amount), err)
+		tracing.EndSpan(span, err)
+	}()
+
 	// Validate amount
 	if amount <= 0 {
 		return nil, errors.New("invalid amount")
 	}
 	amountCents := int64(amount)
 
-	// FetchTransactions sender wallet to check balance
-	fromWallet, err := t.walletRepository.FindByUserID(fromUserID)
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
+	// FetchTransactions sender wallet
+	fromWallet, err := t.walletRepository.FindByUserID(dbCtx, fromUserID)
 	if err != nil {
 		utils.LogError("Sender wallet not found for transfer", err)
 		return nil, err
 	}
 
-	// Check balance
-	if fromWallet.Balance < amountCents {
-		return nil, model.ErrInsufficientFunds
-	}
-
 	// FetchTransactions receiver wallet
-	toWallet, err := t.walletRepository.FindByUserID(toUserID)
+	toWallet, err := t.walletRepository.FindByUserID(dbCtx, toUserID)
 	if err != nil {
 		utils.LogError("Receiver wallet not found for transfer", err)
 		return nil, err
 	}
-
-	// Begin database transaction
-	tx := t.walletRepository.BeginTransaction()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	if err := tx.Error; err != nil {
+	if err := fromWallet.DirectUseError(); err != nil {
+		return nil, err
+	}
+	if err := toWallet.DirectUseError(); err != nil {
+		return nil, err
+	}
+	if err := kycTier(fromWallet).TransferError(amountCents); err != nil {
+		return nil, err
+	}
+	if err := t.authorizeDebit(dbCtx, fromWallet, actorID, amountCents, approved); err != nil {
 		return nil, err
 	}
 
@@ -289,6 +437,7 @@ func (t *wallet) Transfer(fromUserID string, toUserID string, amount int) (*mode
 		OperationType:   model.Debit,
 		Amount:          amountCents,
 		Status:          model.Completed,
+		ActorUserID:     actorID,
 	}
 
 	// Create credit transaction for receiver
@@ -299,41 +448,79 @@ func (t *wallet) Transfer(fromUserID string, toUserID string, amount int) (*mode
 		OperationType:   model.Credit,
 		Amount:          amountCents,
 		Status:          model.Completed,
+		ActorUserID:     actorID,
+	}
+
+	// Move the funds in a single database transaction. Both wallets are locked
+	// in ID order and the balance is checked under the lock; the transaction is
+	// retried if it loses a deadlock or a concurrent version check. With
+	// auto-bounce, the part above the receiver's balance ceiling goes straight
+	// back to the sender, so only the rest changes hands.
+	var bounced int64
+	err = t.walletRepository.WithTransaction(dbCtx, func(tx *gorm.DB) error {
+		bounced = 0
+		if within != nil {
+			if err := within(tx); err != nil {
+				return err
+			}
+		}
+		if limits := config.GetBalanceLimits(); limits.Enable && limits.AutoBounce {
+			headroom, capped, err := t.walletRepository.BalanceHeadroom(tx, toWallet.ID)
+			if err != nil {
+				return err
+			}
+			if capped && headroom < amountCents {
+				if headroom == 0 {
+					return limits.CeilingError(toWallet)
+				}
+				bounced = amountCents - headroom
+			}
+		}
+		if err := t.recordMemberSpend(tx, fromWallet, actorID, amountCents-bounced); err != nil {
+			return err
+		}
+		return t.walletRepository.ApplyBalanceChanges(tx,
+			model.BalanceChange{WalletID: fromWallet.ID, Amount: -(amountCents - bounced)},
+			model.BalanceChange{WalletID: toWallet.ID, Amount: amountCents - bounced},
+		)
+	})
+	if err != nil {
+		utils.LogError("Failed to update wallet balances for transfer", err)
+		return nil, err
 	}
-
-	// Create transaction pair via microservice asynchronously
+	if bounced > 0 {
+		// The transfer and its bounce are recorded in full and grouped, so
+		// both parties see the amount sent and the amount returned
+		groupID := "bounce-" + rand.Text()
+		debitTxn.GroupID, creditTxn.GroupID = groupID, groupID
+		bounceDebit, bounceCredit := newLedgerPair(model.Transfer, toWallet.UserID, fromWallet.UserID, bounced)
+		bounceDebit.GroupID, bounceCredit.GroupID = groupID, groupID
+		recordLedgerPair(ctx, "transfer bounce", bounceDebit, bounceCredit)
+		log.WithFields(log.Fields{
+			"from_user_id": fromWallet.UserID,
+			"to_user_id":   toWallet.UserID,
+			"bounced":      bounced,
+		}).Info("transfer above balance ceiling bounced to sender")
+	}
+
+	// Create transaction pair via microservice asynchronously, only once the
+	// balance change is committed. The ledger write outlives the request, so it
+	// keeps the request's values but not its cancellation.
 	go func() {
-		if err := client.NewTxnClient().CreateTransactionPair(debitTxn, creditTxn); err != nil {
+		if err := client.NewTxnClient().CreateTransactionPair(context.WithoutCancel(ctx), debitTxn, creditTxn); err != nil {
 			utils.LogError("Failed to create transaction pair for transfer", err)
+			metrics.IncAsyncFailure("ledger_transfer")
 		}
 	}()
 
-	// Update wallet balances
-	if err := t.walletRepository.UpdateWalletBalance(tx, fromWallet.ID, amountCents, false); err != nil {
-		utils.LogError("Failed to update sender wallet balance for transfer", err)
-		tx.Rollback()
-		return nil, err
-	}
-
-	if err := t.walletRepository.UpdateWalletBalance(tx, toWallet.ID, amountCents, true); err != nil {
-		utils.LogError("Failed to update receiver wallet balance for transfer", err)
-		tx.Rollback()
-		return nil, err
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		utils.LogError("Failed to commit transfer transaction", err)
-		return nil, err
-	}
-
-	// Invalidate cache for both sender and receiver
-	ctx := context.Background()
+	// Invalidate cache for both sender and receiver. The balance change is already
+	// committed, so this must not be skipped if the caller goes away.
+	cacheCtx := context.WithoutCancel(ctx)
 	redisClient := cache.NewRedisClient()
-	if err := redisClient.DeleteTransactionHistory(ctx, fromWallet.UserID); err != nil {
+	if err := redisClient.DeleteTransactionHistory(cacheCtx, fromWallet.UserID); err != nil {
 		utils.LogError("Failed to invalidate sender cache after transfer", err)
 	}
-	if err := redisClient.DeleteTransactionHistory(ctx, toWallet.UserID); err != nil {
+	if err := redisClient.DeleteTransactionHistory(cacheCtx, toWallet.UserID); err != nil {
 		utils.LogError("Failed to invalidate receiver cache after transfer", err)
 	}
 
@@ -341,27 +528,58 @@ func (t *wallet) Transfer(fromUserID string, toUserID string, amount int) (*mode
 	return debitTxn, nil
 }
 
-func (t *wallet) GetWalletWithTransactions(userID string) (*model.Wallet, []model.Transaction, error) {
+func (t *wallet) GetWalletWithTransactions(ctx context.Context, userID string) (_ *model.Wallet, _ []model.Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "service.Wallet.GetWalletWithTransactions",
+		tracing.AttrUserID.String(userID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, config.GetTimeouts().Database)
+	defer cancel()
+
 	// Get wallet
-	wallet, err := t.walletRepository.FindByUserID(userID)
+	wallet, err := t.walletRepository.FindByUserID(dbCtx, userID)
 	if err != nil {
 		utils.LogError("Wallet not found", err)
 		return nil, nil, err
 	}
+	if wallet.AcntType == model.User {
+		// Pockets are listed with their owner for the aggregated balance
+		if wallet.Pockets, err = t.walletRepository.FindPockets(dbCtx, wallet.ID); err != nil {
+			utils.LogError("Failed to retrieve pockets", err)
+			return nil, nil, err
+		}
+	}
 
-	ctx := context.Background()
 	redisClient := cache.NewRedisClient()
 
 	// Try to get transactions from Redis cache first
 	transactions, err := redisClient.GetTransactionHistory(ctx, wallet.UserID)
-	if err != nil {
+	switch {
+	case err != nil:
+		metrics.ObserveCacheLookup(metrics.CacheError)
 		utils.LogError("Failed to get transactions from cache", err)
 		// Continue to fetch from transaction service
+	case transactions == nil:
+		metrics.ObserveCacheLookup(metrics.CacheMiss)
+	default:
+		metrics.ObserveCacheLookup(metrics.CacheHit)
 	}
 
 	// If cache miss or error, fetch from transaction microservice
+	span.SetAttributes(tracing.AttrCacheHit.Bool(transactions != nil))
 	if transactions == nil {
-		transactions, err = client.NewTxnClient().FetchTransactions(wallet.UserID)
+		transactions, err = client.NewTxnClient().FetchTransactions(ctx, wallet.UserID)
+		if errors.Is(err, client.ErrCircuitOpen) {
+			// The transactions service is known to be down; fall back to the
+			// last known history rather than failing the read.
+			stale, staleErr := redisClient.GetStaleTransactionHistory(ctx, wallet.UserID)
+			if staleErr == nil && stale != nil {
+				metrics.ObserveCacheLookup(metrics.CacheStale)
+				span.SetAttributes(attribute.Bool("stale", true))
+				return wallet, stale, nil
+			}
+		}
 		if err != nil {
 			utils.LogError("Failed to retrieve transactions from transaction service", err)
 			return nil, nil, err
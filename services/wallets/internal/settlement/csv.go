@@ -0,0 +1,49 @@
+package settlement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// csvWriter renders one row per batch. Amounts are decimals in major units.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+var csvColumns = []string{
+	"settlement_date", "reference", "provider_id", "status", "deposit_count", "deposits_amount",
+	"withdrawal_count", "withdrawals_amount", "net_amount", "currency",
+}
+
+func (cw *csvWriter) Write(_ time.Time, batches []model.SettlementBatch) error {
+	if err := cw.w.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		err := cw.w.Write([]string{
+			batch.SettlementDate,
+			batch.Reference(),
+			batch.ProviderID,
+			string(batch.Status),
+			strconv.Itoa(batch.DepositCount),
+			formatAmount(batch.DepositsAmount),
+			strconv.Itoa(batch.WithdrawalCount),
+			formatAmount(batch.WithdrawalsAmount),
+			formatAmount(batch.NetAmount),
+			model.Currency,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,178 @@
+package settlement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+const (
+	// recordSize is the length of every record of a NACHA file.
+	recordSize = 94
+	// blockingFactor is the number of records per block; the last block is
+	// padded with records of nines.
+	blockingFactor = 10
+
+	serviceClassMixed = "200"
+	// Checking account transaction codes
+	codeCheckingCredit = "22"
+	codeCheckingDebit  = "27"
+)
+
+// nachaWriter renders the batches of a day as a NACHA-style ACH file with a
+// single CCD batch and one entry per provider whose net amount is not zero.
+// Providers owing the platform are debited and providers owed are credited.
+// The file is built in memory and only written once complete, so an error
+// means nothing was written.
+type nachaWriter struct {
+	w         io.Writer
+	cfg       model.Settlement
+	createdAt time.Time
+}
+
+func newNACHAWriter(w io.Writer, cfg model.Settlement, createdAt time.Time) *nachaWriter {
+	return &nachaWriter{w: w, cfg: cfg, createdAt: createdAt}
+}
+
+func (nw *nachaWriter) Write(date time.Time, batches []model.SettlementBatch) error {
+	odfi := prefix(nw.cfg.OriginRouting, 8)
+	var records []string
+
+	records = append(records, "1"+
+		"01"+
+		" "+numeric(nw.cfg.DestinationRouting, 9)+
+		" "+numeric(nw.cfg.OriginRouting, 9)+
+		nw.createdAt.Format("060102")+
+		nw.createdAt.Format("1504")+
+		"A"+
+		"094"+
+		"10"+
+		"1"+
+		alpha(nw.cfg.DestinationName, 23)+
+		alpha(nw.cfg.OriginName, 23)+
+		alpha(date.Format("20060102"), 8))
+
+	records = append(records, "5"+
+		serviceClassMixed+
+		alpha(nw.cfg.CompanyName, 16)+
+		alpha("", 20)+
+		alpha(nw.cfg.CompanyID, 10)+
+		"CCD"+
+		alpha("SETTLEMENT", 10)+
+		date.Format("060102")+
+		nw.createdAt.Format("060102")+
+		"   "+
+		"1"+
+		numeric(odfi, 8)+
+		numeric("1", 7))
+
+	var entries int
+	var hash, debits, credits int64
+	for _, batch := range batches {
+		if batch.NetAmount == 0 {
+			continue
+		}
+		account, ok := nw.cfg.Account(batch.ProviderID)
+		if !ok {
+			return model.ErrNoSettlementAccount
+		}
+
+		code, amount := codeCheckingDebit, batch.NetAmount
+		if amount < 0 {
+			code, amount = codeCheckingCredit, -amount
+			credits += amount
+		} else {
+			debits += amount
+		}
+		rdfi := prefix(account.RoutingNumber, 8)
+		routing, _ := strconv.ParseInt(rdfi, 10, 64)
+		hash += routing
+		entries++
+
+		records = append(records, "6"+
+			code+
+			numeric(rdfi, 8)+
+			numeric(account.RoutingNumber[len(rdfi):], 1)+
+			alpha(account.AccountNumber, 17)+
+			amountField(amount, 10)+
+			alpha(batch.Reference(), 15)+
+			alpha(batch.ProviderID, 22)+
+			"  "+
+			"0"+
+			numeric(odfi, 8)+numeric(strconv.Itoa(entries), 7))
+	}
+	hash %= 10_000_000_000
+
+	records = append(records, "8"+
+		serviceClassMixed+
+		numeric(strconv.Itoa(entries), 6)+
+		numeric(strconv.FormatInt(hash, 10), 10)+
+		amountField(debits, 12)+
+		amountField(credits, 12)+
+		alpha(nw.cfg.CompanyID, 10)+
+		alpha("", 19)+
+		alpha("", 6)+
+		numeric(odfi, 8)+
+		numeric("1", 7))
+
+	// The file control record is counted in the blocks it reports
+	blocks := (len(records) + 1 + blockingFactor - 1) / blockingFactor
+	records = append(records, "9"+
+		numeric("1", 6)+
+		numeric(strconv.Itoa(blocks), 6)+
+		numeric(strconv.Itoa(entries), 8)+
+		numeric(strconv.FormatInt(hash, 10), 10)+
+		amountField(debits, 12)+
+		amountField(credits, 12)+
+		alpha("", 39))
+	for len(records)%blockingFactor != 0 {
+		records = append(records, strings.Repeat("9", recordSize))
+	}
+
+	var buf bytes.Buffer
+	for _, record := range records {
+		if len(record) != recordSize {
+			return fmt.Errorf("settlement record of %d characters: %q", len(record), record)
+		}
+		buf.WriteString(record)
+		buf.WriteString("\n")
+	}
+	_, err := buf.WriteTo(nw.w)
+	return err
+}
+
+// alpha left-justifies s in an upper case field of n characters, truncating it if longer.
+func alpha(s string, n int) string {
+	s = strings.ToUpper(s)
+	if len(s) > n {
+		return s[:n]
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
+
+// numeric right-justifies s in a zero-filled field of n characters, keeping
+// its last n characters if longer.
+func numeric(s string, n int) string {
+	if len(s) > n {
+		return s[len(s)-n:]
+	}
+	return strings.Repeat("0", n-len(s)) + s
+}
+
+// amountField renders an amount in cents in a zero-filled field of n characters.
+func amountField(cents int64, n int) string {
+	return numeric(strconv.FormatInt(cents, 10), n)
+}
+
+// prefix returns the first n characters of s, or s if shorter.
+func prefix(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
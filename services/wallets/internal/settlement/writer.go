@@ -0,0 +1,61 @@
+// Package settlement renders the provider settlement batches of a day in the
+// supported file formats.
+package settlement
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// Writer renders the settlement batches of a day.
+type Writer interface {
+	Write(date time.Time, batches []model.SettlementBatch) error
+}
+
+// ErrUnsupportedFormat is returned for a settlement format without a writer.
+var ErrUnsupportedFormat = fmt.Errorf("unsupported settlement format")
+
+// NewWriter returns a writer rendering settlement batches in the given format
+// to w. The NACHA file is built from the originator and provider bank
+// accounts in cfg.
+func NewWriter(format model.SettlementFormat, w io.Writer, cfg model.Settlement) (Writer, error) {
+	switch format {
+	case model.SettlementCSV:
+		return newCSVWriter(w), nil
+	case model.SettlementNACHA:
+		return newNACHAWriter(w, cfg, time.Now().UTC()), nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ContentType returns the MIME type of settlement files in the given format.
+func ContentType(format model.SettlementFormat) string {
+	switch format {
+	case model.SettlementCSV:
+		return "text/csv; charset=utf-8"
+	case model.SettlementNACHA:
+		return "text/plain; charset=us-ascii"
+	}
+	return "application/octet-stream"
+}
+
+// FileName returns the file name of the settlement file of the given day.
+func FileName(date time.Time, format model.SettlementFormat) string {
+	extension := string(format)
+	if format == model.SettlementNACHA {
+		extension = "ach"
+	}
+	return fmt.Sprintf("settlement_%s.%s", date.Format(model.DateLayout), extension)
+}
+
+// formatAmount renders an amount in cents as a decimal in major units, e.g. -1234 as "-12.34".
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
@@ -0,0 +1,97 @@
+package settlement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testDate    = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	testBatches = []model.SettlementBatch{
+		{ID: 7, ProviderID: "deposit-provider-master", SettlementDate: "2026-03-01", Status: model.SettlementSettled,
+			DepositCount: 2, DepositsAmount: 7500, WithdrawalCount: 1, WithdrawalsAmount: 1000, NetAmount: 6500},
+		{ID: 8, ProviderID: "withdraw-provider-master", SettlementDate: "2026-03-01", Status: model.SettlementPending,
+			WithdrawalCount: 1, WithdrawalsAmount: 12345, NetAmount: -12345},
+		{ID: 9, ProviderID: "idle-provider", SettlementDate: "2026-03-01", Status: model.SettlementSettled,
+			DepositCount: 1, DepositsAmount: 100, WithdrawalCount: 1, WithdrawalsAmount: 100},
+	}
+	testConfig = model.Settlement{
+		CompanyName:        "Digital Wallet",
+		CompanyID:          "1234567890",
+		OriginRouting:      "011000015",
+		OriginName:         "Origin Bank",
+		DestinationRouting: "011000028",
+		DestinationName:    "Destination Bank",
+		Accounts: []model.SettlementAccount{
+			{ProviderID: "deposit-provider-master", RoutingNumber: "021000021", AccountNumber: "123456789"},
+			{ProviderID: "withdraw-provider-master", RoutingNumber: "026009593", AccountNumber: "987654321"},
+		},
+	}
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(model.SettlementCSV, &buf, testConfig)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(testDate, testBatches))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"settlement_date", "reference", "provider_id", "status", "deposit_count", "deposits_amount", "withdrawal_count", "withdrawals_amount", "net_amount", "currency"},
+		{"2026-03-01", "stl-7", "deposit-provider-master", "settled", "2", "75.00", "1", "10.00", "65.00", "USD"},
+		{"2026-03-01", "stl-8", "withdraw-provider-master", "pending", "0", "0.00", "1", "123.45", "-123.45", "USD"},
+		{"2026-03-01", "stl-9", "idle-provider", "settled", "1", "1.00", "1", "1.00", "0.00", "USD"},
+	}, records)
+}
+
+func TestNACHAWriter(t *testing.T) {
+	var buf bytes.Buffer
+	createdAt := time.Date(2026, 3, 2, 4, 5, 0, 0, time.UTC)
+	require.NoError(t, newNACHAWriter(&buf, testConfig, createdAt).Write(testDate, testBatches))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 10, "records are padded to a full block")
+	for _, line := range lines {
+		assert.Len(t, line, recordSize)
+	}
+
+	assert.Equal(t, "101 011000028 0110000152603020405A094101DESTINATION BANK       ORIGIN BANK            20260301", lines[0])
+	assert.Equal(t, "5200DIGITAL WALLET  "+strings.Repeat(" ", 20)+"1234567890CCDSETTLEMENT260301260302   1011000010000001", lines[1])
+	// The provider owing the platform is debited, the one owed is credited,
+	// and the one with nothing to settle has no entry
+	assert.Equal(t, "627021000021123456789        0000006500STL-7          DEPOSIT-PROVIDER-MASTE  0011000010000001", lines[2])
+	assert.Equal(t, "622026009593987654321        0000012345STL-8          WITHDRAW-PROVIDER-MAST  0011000010000002", lines[3])
+	// Entry hash is the sum of the 8-digit RDFI routing numbers
+	assert.Equal(t, "82000000020004700961000000006500000000012345"+"1234567890"+strings.Repeat(" ", 25)+"011000010000001", lines[4])
+	assert.Equal(t, "9000001000001000000020004700961000000006500000000012345"+strings.Repeat(" ", 39), lines[5])
+	for _, line := range lines[6:] {
+		assert.Equal(t, strings.Repeat("9", recordSize), line)
+	}
+}
+
+func TestNACHAWriter_MissingAccount(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := testConfig
+	cfg.Accounts = cfg.Accounts[:1]
+	err := newNACHAWriter(&buf, cfg, time.Now()).Write(testDate, testBatches)
+	assert.ErrorIs(t, err, model.ErrNoSettlementAccount)
+	assert.Zero(t, buf.Len(), "nothing is written on error")
+}
+
+func TestNewWriter_UnsupportedFormat(t *testing.T) {
+	_, err := NewWriter(model.SettlementFormat("xml"), &bytes.Buffer{}, testConfig)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "settlement_2026-03-01.csv", FileName(testDate, model.SettlementCSV))
+	assert.Equal(t, "settlement_2026-03-01.ach", FileName(testDate, model.SettlementNACHA))
+}
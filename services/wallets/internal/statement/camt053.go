@@ -0,0 +1,341 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// camt053Namespace is the namespace of the ISO 20022 BankToCustomerStatementV08 message.
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+// Bank transaction codes: wallet operations are payments, credits are received
+// and debits issued credit transfers.
+const (
+	camtDomainPayments       = "PMNT"
+	camtFamilyReceivedCredit = "RCDT"
+	camtFamilyIssuedCredit   = "ICDT"
+	camtSubFamilyOther       = "OTHR"
+	camtProprietaryIssuer    = "WALLET"
+)
+
+// camt053Writer renders an ISO 20022 camt.053.001.08 bank-to-customer
+// statement. camt.053 places the balances and transaction totals before the
+// entries, so the writer takes them from the summary given before the header
+// and streams each entry as it is written; WriteFooter checks that the
+// entries matched the summary.
+type camt053Writer struct {
+	w        io.Writer
+	encoder  *xml.Encoder
+	summary  *model.StatementSummary
+	currency string
+	// written totals the entries written so far.
+	written model.StatementSummary
+}
+
+// ErrStatementSummaryMismatch is returned when the lines of a camt.053
+// statement do not add up to the summary written before them, e.g. because
+// the ledger changed in between.
+var ErrStatementSummaryMismatch = fmt.Errorf("statement lines do not match the summary")
+
+func newCamt053Writer(w io.Writer) *camt053Writer {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return &camt053Writer{w: w, encoder: encoder}
+}
+
+func (cw *camt053Writer) Summarize(summary model.StatementSummary) {
+	cw.summary = &summary
+}
+
+// WriteHeader writes the document up to the first entry: the group header,
+// the statement identification, balances and transaction summary.
+func (cw *camt053Writer) WriteHeader(h model.StatementHeader) error {
+	if cw.summary == nil {
+		return fmt.Errorf("camt.053 statement written without a summary")
+	}
+	summary := cw.summary
+	cw.currency = h.Currency
+
+	if _, err := io.WriteString(cw.w, xml.Header); err != nil {
+		return err
+	}
+	document := xml.StartElement{
+		Name: xml.Name{Local: "Document"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace}},
+	}
+	elements := []struct {
+		name  string
+		value any
+	}{
+		{"Id", h.From.UTC().Format("20060102") + "-" + h.To.UTC().Format("20060102")},
+		{"CreDtTm", camtDateTime(h.GeneratedAt)},
+		{"FrToDt", camtPeriod{From: camtDateTime(h.From), To: camtDateTime(h.To)}},
+		{"Acct", camtAccount{
+			ID:  camtAccountID{Other: camtOtherID{ID: truncate(h.UserID, 34)}},
+			Ccy: h.Currency,
+		}},
+		{"Bal", newCamtBalance("OPBD", h.OpeningBalance, h.Currency, h.From)},
+		{"Bal", newCamtBalance("CLBD", summary.ClosingBalance, h.Currency, h.To)},
+		{"TxsSummry", camtSummary{
+			TtlNtries: camtTotalEntries{
+				NbOfNtries: strconv.Itoa(summary.CreditEntries + summary.DebitEntries),
+				Sum:        formatAmount(summary.TotalCredits + summary.TotalDebits),
+				TtlNetNtry: newCamtAmountAndDirection(summary.TotalCredits - summary.TotalDebits),
+			},
+			TtlCdtNtries: &camtCountAndSum{NbOfNtries: strconv.Itoa(summary.CreditEntries), Sum: formatAmount(summary.TotalCredits)},
+			TtlDbtNtries: &camtCountAndSum{NbOfNtries: strconv.Itoa(summary.DebitEntries), Sum: formatAmount(summary.TotalDebits)},
+		}},
+	}
+
+	for _, start := range []xml.StartElement{document, camtElement("BkToCstmrStmt")} {
+		if err := cw.encoder.EncodeToken(start); err != nil {
+			return err
+		}
+	}
+	groupHeader := camtGroupHeader{
+		MsgID:   "STMT-" + h.GeneratedAt.UTC().Format("20060102150405.000000"),
+		CreDtTm: camtDateTime(h.GeneratedAt),
+	}
+	if err := cw.encoder.EncodeElement(groupHeader, camtElement("GrpHdr")); err != nil {
+		return err
+	}
+	if err := cw.encoder.EncodeToken(camtElement("Stmt")); err != nil {
+		return err
+	}
+	for _, element := range elements {
+		if err := cw.encoder.EncodeElement(element.value, camtElement(element.name)); err != nil {
+			return err
+		}
+	}
+	return cw.encoder.Flush()
+}
+
+func (cw *camt053Writer) WriteLine(line model.StatementLine) error {
+	if line.Amount < 0 {
+		cw.written.DebitEntries++
+		cw.written.TotalDebits -= line.Amount
+	} else {
+		cw.written.CreditEntries++
+		cw.written.TotalCredits += line.Amount
+	}
+	if err := cw.encoder.EncodeElement(newCamtEntry(line, cw.currency), camtElement("Ntry")); err != nil {
+		return err
+	}
+	return cw.encoder.Flush()
+}
+
+// WriteFooter closes the document. The closing balance and totals were
+// written with the header, so the footer only has to agree with them.
+func (cw *camt053Writer) WriteFooter(footer model.StatementFooter) error {
+	written := cw.written
+	written.ClosingBalance = footer.ClosingBalance
+	if footer != cw.summary.StatementFooter || written != *cw.summary {
+		return ErrStatementSummaryMismatch
+	}
+
+	for _, name := range []string{"Stmt", "BkToCstmrStmt", "Document"} {
+		if err := cw.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	if err := cw.encoder.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(cw.w, "\n")
+	return err
+}
+
+// camtElement returns the start of an element in the camt.053 namespace,
+// which is declared once on the document.
+func camtElement(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+const (
+	camtCredit = "CRDT"
+	camtDebit  = "DBIT"
+)
+
+type camtGroupHeader struct {
+	MsgID   string `xml:"MsgId"`
+	CreDtTm string `xml:"CreDtTm"`
+}
+
+type camtPeriod struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	ID  camtAccountID `xml:"Id"`
+	Ccy string        `xml:"Ccy"`
+}
+
+type camtAccountID struct {
+	Other camtOtherID `xml:"Othr"`
+}
+
+type camtOtherID struct {
+	ID string `xml:"Id"`
+}
+
+type camtAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type camtCode struct {
+	Cd string `xml:"Cd"`
+}
+
+type camtDateTimeChoice struct {
+	DtTm string `xml:"DtTm"`
+}
+
+type camtBalance struct {
+	Tp        camtBalanceType    `xml:"Tp"`
+	Amt       camtAmount         `xml:"Amt"`
+	CdtDbtInd string             `xml:"CdtDbtInd"`
+	Dt        camtDateTimeChoice `xml:"Dt"`
+}
+
+type camtBalanceType struct {
+	CdOrPrtry camtCode `xml:"CdOrPrtry"`
+}
+
+type camtSummary struct {
+	TtlNtries    camtTotalEntries `xml:"TtlNtries"`
+	TtlCdtNtries *camtCountAndSum `xml:"TtlCdtNtries"`
+	TtlDbtNtries *camtCountAndSum `xml:"TtlDbtNtries"`
+}
+
+type camtTotalEntries struct {
+	NbOfNtries string                 `xml:"NbOfNtries"`
+	Sum        string                 `xml:"Sum"`
+	TtlNetNtry camtAmountAndDirection `xml:"TtlNetNtry"`
+}
+
+type camtAmountAndDirection struct {
+	Amt       string `xml:"Amt"`
+	CdtDbtInd string `xml:"CdtDbtInd"`
+}
+
+type camtCountAndSum struct {
+	NbOfNtries string `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum"`
+}
+
+type camtEntry struct {
+	NtryRef      string                  `xml:"NtryRef"`
+	Amt          camtAmount              `xml:"Amt"`
+	CdtDbtInd    string                  `xml:"CdtDbtInd"`
+	Sts          camtCode                `xml:"Sts"`
+	BookgDt      camtDateTimeChoice      `xml:"BookgDt"`
+	ValDt        camtDateTimeChoice      `xml:"ValDt"`
+	AcctSvcrRef  string                  `xml:"AcctSvcrRef"`
+	BkTxCd       camtBankTransactionCode `xml:"BkTxCd"`
+	NtryDtls     *camtEntryDetails       `xml:"NtryDtls,omitempty"`
+	AddtlNtryInf string                  `xml:"AddtlNtryInf,omitempty"`
+}
+
+// camtEntryDetails carries the group ID of a split payment as the payment
+// information identification, the camt.053 reference for entries that
+// belong to one payment instruction.
+type camtEntryDetails struct {
+	TxDtls struct {
+		PmtInfID string `xml:"Refs>PmtInfId"`
+	} `xml:"TxDtls"`
+}
+
+type camtBankTransactionCode struct {
+	Domn  camtDomain      `xml:"Domn"`
+	Prtry camtProprietary `xml:"Prtry"`
+}
+
+type camtDomain struct {
+	Cd   string     `xml:"Cd"`
+	Fmly camtFamily `xml:"Fmly"`
+}
+
+type camtFamily struct {
+	Cd        string `xml:"Cd"`
+	SubFmlyCd string `xml:"SubFmlyCd"`
+}
+
+type camtProprietary struct {
+	Cd   string `xml:"Cd"`
+	Issr string `xml:"Issr"`
+}
+
+// newCamtBalance returns a balance of the given type; negative balances are
+// reported as debit balances.
+func newCamtBalance(code string, cents int64, currency string, at time.Time) camtBalance {
+	direction := newCamtAmountAndDirection(cents)
+	return camtBalance{
+		Tp:        camtBalanceType{CdOrPrtry: camtCode{Cd: code}},
+		Amt:       camtAmount{Ccy: currency, Value: direction.Amt},
+		CdtDbtInd: direction.CdtDbtInd,
+		Dt:        camtDateTimeChoice{DtTm: camtDateTime(at)},
+	}
+}
+
+// newCamtAmountAndDirection splits a signed amount into the unsigned amount and
+// credit/debit indicator used by camt.053.
+func newCamtAmountAndDirection(cents int64) camtAmountAndDirection {
+	if cents < 0 {
+		return camtAmountAndDirection{Amt: formatAmount(-cents), CdtDbtInd: camtDebit}
+	}
+	return camtAmountAndDirection{Amt: formatAmount(cents), CdtDbtInd: camtCredit}
+}
+
+func newCamtEntry(line model.StatementLine, currency string) camtEntry {
+	ref := strconv.Itoa(line.TransactionID)
+	direction := newCamtAmountAndDirection(line.Amount)
+	family := camtFamilyReceivedCredit
+	if direction.CdtDbtInd == camtDebit {
+		family = camtFamilyIssuedCredit
+	}
+
+	entry := camtEntry{
+		NtryRef:     ref,
+		Amt:         camtAmount{Ccy: currency, Value: direction.Amt},
+		CdtDbtInd:   direction.CdtDbtInd,
+		Sts:         camtCode{Cd: "BOOK"},
+		BookgDt:     camtDateTimeChoice{DtTm: camtDateTime(line.Date)},
+		ValDt:       camtDateTimeChoice{DtTm: camtDateTime(line.Date)},
+		AcctSvcrRef: ref,
+		BkTxCd: camtBankTransactionCode{
+			Domn:  camtDomain{Cd: camtDomainPayments, Fmly: camtFamily{Cd: family, SubFmlyCd: camtSubFamilyOther}},
+			Prtry: camtProprietary{Cd: string(line.TransactionType), Issr: camtProprietaryIssuer},
+		},
+	}
+	if line.GroupID != "" {
+		entry.NtryDtls = &camtEntryDetails{}
+		entry.NtryDtls.TxDtls.PmtInfID = truncate(line.GroupID, 35)
+	}
+	if line.Counterparty != "" {
+		entry.AddtlNtryInf = truncate(string(line.TransactionType)+" "+string(line.OperationType)+", counterparty "+line.Counterparty, 500)
+	}
+	return entry
+}
+
+func camtDateTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,60 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// csvWriter renders one row per ledger entry between an opening and a closing
+// balance row. Amounts are decimals in major units.
+type csvWriter struct {
+	w        *csv.Writer
+	currency string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+var csvColumns = []string{"date", "transaction_id", "description", "operation", "counterparty", "amount", "currency", "running_balance", "group_id"}
+
+func (cw *csvWriter) WriteHeader(header model.StatementHeader) error {
+	cw.currency = header.Currency
+	if err := cw.w.Write(csvColumns); err != nil {
+		return err
+	}
+	return cw.write([]string{
+		header.From.UTC().Format(time.RFC3339), "", "opening_balance", "", "", "", cw.currency, formatAmount(header.OpeningBalance), "",
+	})
+}
+
+func (cw *csvWriter) WriteLine(line model.StatementLine) error {
+	return cw.write([]string{
+		line.Date.UTC().Format(time.RFC3339),
+		strconv.Itoa(line.TransactionID),
+		string(line.TransactionType),
+		string(line.OperationType),
+		line.Counterparty,
+		formatAmount(line.Amount),
+		cw.currency,
+		formatAmount(line.RunningBalance),
+		line.GroupID,
+	})
+}
+
+func (cw *csvWriter) WriteFooter(footer model.StatementFooter) error {
+	return cw.write([]string{"", "", "closing_balance", "", "", "", cw.currency, formatAmount(footer.ClosingBalance), ""})
+}
+
+// write writes a row and flushes it so it reaches the client straight away.
+func (cw *csvWriter) write(record []string) error {
+	if err := cw.w.Write(record); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
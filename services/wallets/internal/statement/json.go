@@ -0,0 +1,55 @@
+package statement
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// jsonWriter streams a single JSON document: the header fields, a
+// "transactions" array written entry by entry, then the footer fields.
+// Amounts are in cents, like the rest of the API.
+type jsonWriter struct {
+	w     io.Writer
+	lines int
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: w}
+}
+
+func (jw *jsonWriter) WriteHeader(header model.StatementHeader) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	// Reopen the header object to append the transactions array to it
+	_, err = fmt.Fprintf(jw.w, `%s,"transactions":[`, data[:len(data)-1])
+	return err
+}
+
+func (jw *jsonWriter) WriteLine(line model.StatementLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	if jw.lines > 0 {
+		if _, err := io.WriteString(jw.w, ","); err != nil {
+			return err
+		}
+	}
+	jw.lines++
+	_, err = jw.w.Write(data)
+	return err
+}
+
+func (jw *jsonWriter) WriteFooter(footer model.StatementFooter) error {
+	data, err := json.Marshal(footer)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(jw.w, "],%s\n", data[1:])
+	return err
+}
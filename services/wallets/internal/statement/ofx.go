@@ -0,0 +1,84 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// ofxDateLayout is the OFX datetime format, always written in UTC.
+const ofxDateLayout = "20060102150405.000[0:GMT]"
+
+// ofxWriter renders an OFX 2.2 bank statement response, importable by
+// personal finance and accounting software. Amounts are decimals in major units.
+type ofxWriter struct {
+	w      io.Writer
+	header model.StatementHeader
+}
+
+func newOFXWriter(w io.Writer) *ofxWriter {
+	return &ofxWriter{w: w}
+}
+
+func (ow *ofxWriter) WriteHeader(header model.StatementHeader) error {
+	ow.header = header
+	_, err := fmt.Fprintf(ow.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>WALLET</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`,
+		ofxDate(header.GeneratedAt), escape(header.Currency), escape(header.UserID),
+		ofxDate(header.From), ofxDate(header.To))
+	return err
+}
+
+func (ow *ofxWriter) WriteLine(line model.StatementLine) error {
+	trnType := "CREDIT"
+	if line.OperationType == model.Debit {
+		trnType = "DEBIT"
+	}
+	name := line.Counterparty
+	if name == "" {
+		name = string(line.TransactionType)
+	}
+	// OFX limits NAME and REFNUM to 32 characters
+	name = truncate(name, 32)
+	refNum := ""
+	if line.GroupID != "" {
+		// The entries of a split payment share their reference number
+		refNum = "<REFNUM>" + escape(truncate(line.GroupID, 32)) + "</REFNUM>"
+	}
+	_, err := fmt.Fprintf(ow.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID>%s<NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+		trnType, ofxDate(line.Date), formatAmount(line.Amount), strconv.Itoa(line.TransactionID), refNum,
+		escape(name), escape(string(line.TransactionType)))
+	return err
+}
+
+func (ow *ofxWriter) WriteFooter(footer model.StatementFooter) error {
+	_, err := fmt.Fprintf(ow.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`, formatAmount(footer.ClosingBalance), ofxDate(ow.header.To))
+	return err
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format(ofxDateLayout)
+}
+
+// escape escapes s for use as XML character data.
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
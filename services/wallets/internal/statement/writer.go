@@ -0,0 +1,89 @@
+// Package statement renders account statements in the supported file formats.
+// Writers emit each part of a statement as soon as it is written, so large
+// statements can be streamed without being held in memory.
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// Writer renders a statement: the header once, every line in order, then the footer.
+type Writer interface {
+	WriteHeader(header model.StatementHeader) error
+	WriteLine(line model.StatementLine) error
+	WriteFooter(footer model.StatementFooter) error
+}
+
+// Summarizer is implemented by writers whose format places the statement
+// totals before the lines. Summarize is called before WriteHeader with the
+// totals of the lines that will follow.
+type Summarizer interface {
+	Summarize(summary model.StatementSummary)
+}
+
+// ErrUnsupportedFormat is returned for a statement format without a writer.
+var ErrUnsupportedFormat = fmt.Errorf("unsupported statement format")
+
+// NewWriter returns a writer rendering statements in the given format to w.
+func NewWriter(format model.StatementFormat, w io.Writer) (Writer, error) {
+	switch format {
+	case model.StatementCSV:
+		return newCSVWriter(w), nil
+	case model.StatementJSON:
+		return newJSONWriter(w), nil
+	case model.StatementOFX:
+		return newOFXWriter(w), nil
+	case model.StatementCamt053:
+		return newCamt053Writer(w), nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ContentType returns the MIME type of statements in the given format.
+func ContentType(format model.StatementFormat) string {
+	switch format {
+	case model.StatementCSV:
+		return "text/csv; charset=utf-8"
+	case model.StatementJSON:
+		return "application/json; charset=utf-8"
+	case model.StatementOFX:
+		return "application/x-ofx"
+	case model.StatementCamt053:
+		return "application/xml; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+// FileName returns the file name of the statement of userID for the given
+// period label. Characters that are unsafe in file names or headers are
+// replaced with underscores.
+func FileName(userID, period string, format model.StatementFormat) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, userID)
+	return fmt.Sprintf("%s_%s.%s", safe, period, fileExtension(format))
+}
+
+// fileExtension returns the file extension, without a dot, for the given format.
+func fileExtension(format model.StatementFormat) string {
+	if format == model.StatementCamt053 {
+		return "xml"
+	}
+	return string(format)
+}
+
+// formatAmount renders an amount in cents as a decimal in major units, e.g. -1234 as "-12.34".
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
@@ -0,0 +1,234 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testHeader = model.StatementHeader{
+		UserID:         "user-<001>",
+		AcntType:       model.User,
+		Currency:       "USD",
+		From:           time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 10000,
+		GeneratedAt:    time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC),
+	}
+	testLines = []model.StatementLine{
+		{TransactionID: 1, Date: time.Date(2024, 5, 3, 10, 0, 0, 0, time.UTC), TransactionType: model.Deposit, OperationType: model.Credit, Counterparty: "deposit-provider-master", Amount: 5005, RunningBalance: 15005},
+		{TransactionID: 2, Date: time.Date(2024, 5, 9, 16, 30, 0, 0, time.UTC), TransactionType: model.Transfer, OperationType: model.Debit, Counterparty: "user-002", Amount: -2000, RunningBalance: 13005, GroupID: "split-group-1"},
+	}
+	testFooter = model.StatementFooter{TotalCredits: 5005, TotalDebits: 2000, ClosingBalance: 13005}
+)
+
+func render(t *testing.T, format model.StatementFormat, lines []model.StatementLine) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+	footer := testFooter
+	if len(lines) == 0 {
+		footer = model.StatementFooter{ClosingBalance: testHeader.OpeningBalance}
+	}
+	if s, ok := w.(Summarizer); ok {
+		s.Summarize(summarize(lines, footer))
+	}
+	require.NoError(t, w.WriteHeader(testHeader))
+	for _, line := range lines {
+		require.NoError(t, w.WriteLine(line))
+	}
+	require.NoError(t, w.WriteFooter(footer))
+	return buf.Bytes()
+}
+
+func summarize(lines []model.StatementLine, footer model.StatementFooter) model.StatementSummary {
+	summary := model.StatementSummary{StatementFooter: footer}
+	for _, line := range lines {
+		if line.Amount < 0 {
+			summary.DebitEntries++
+		} else {
+			summary.CreditEntries++
+		}
+	}
+	return summary
+}
+
+func TestCSVWriter(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(render(t, model.StatementCSV, testLines))).ReadAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"date", "transaction_id", "description", "operation", "counterparty", "amount", "currency", "running_balance", "group_id"},
+		{"2024-05-01T00:00:00Z", "", "opening_balance", "", "", "", "USD", "100.00", ""},
+		{"2024-05-03T10:00:00Z", "1", "deposit", "credit", "deposit-provider-master", "50.05", "USD", "150.05", ""},
+		{"2024-05-09T16:30:00Z", "2", "transfer", "debit", "user-002", "-20.00", "USD", "130.05", "split-group-1"},
+		{"", "", "closing_balance", "", "", "", "USD", "130.05", ""},
+	}, records)
+}
+
+func TestJSONWriter(t *testing.T) {
+	for name, lines := range map[string][]model.StatementLine{"with_lines": testLines, "empty": nil} {
+		t.Run(name, func(t *testing.T) {
+			var got struct {
+				model.StatementHeader
+				Transactions []model.StatementLine `json:"transactions"`
+				model.StatementFooter
+			}
+			require.NoError(t, json.Unmarshal(render(t, model.StatementJSON, lines), &got))
+
+			assert.Equal(t, testHeader.UserID, got.UserID)
+			assert.Equal(t, testHeader.OpeningBalance, got.OpeningBalance)
+			assert.Len(t, got.Transactions, len(lines))
+			if len(lines) > 0 {
+				assert.Equal(t, testLines[1], got.Transactions[1])
+				assert.Equal(t, testFooter, got.StatementFooter)
+			}
+		})
+	}
+}
+
+func TestOFXWriter(t *testing.T) {
+	out := render(t, model.StatementOFX, testLines)
+
+	// The document must be well-formed XML
+	decoder := xml.NewDecoder(bytes.NewReader(out))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, string(out))
+	}
+
+	var doc struct {
+		Statement struct {
+			Currency string `xml:"CURDEF"`
+			Account  string `xml:"BANKACCTFROM>ACCTID"`
+			Entries  []struct {
+				Type   string `xml:"TRNTYPE"`
+				Posted string `xml:"DTPOSTED"`
+				Amount string `xml:"TRNAMT"`
+				FITID  string `xml:"FITID"`
+				RefNum string `xml:"REFNUM"`
+			} `xml:"BANKTRANLIST>STMTTRN"`
+			LedgerBalance string `xml:"LEDGERBAL>BALAMT"`
+		} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &doc))
+
+	assert.Equal(t, "USD", doc.Statement.Currency)
+	assert.Equal(t, "user-<001>", doc.Statement.Account)
+	require.Len(t, doc.Statement.Entries, 2)
+	assert.Equal(t, "CREDIT", doc.Statement.Entries[0].Type)
+	assert.Equal(t, "20240503100000.000[0:GMT]", doc.Statement.Entries[0].Posted)
+	assert.Equal(t, "50.05", doc.Statement.Entries[0].Amount)
+	assert.Equal(t, "DEBIT", doc.Statement.Entries[1].Type)
+	assert.Equal(t, "-20.00", doc.Statement.Entries[1].Amount)
+	assert.Equal(t, "2", doc.Statement.Entries[1].FITID)
+	assert.Empty(t, doc.Statement.Entries[0].RefNum)
+	assert.Equal(t, "split-group-1", doc.Statement.Entries[1].RefNum)
+	assert.Equal(t, "130.05", doc.Statement.LedgerBalance)
+}
+
+func TestNewWriter_UnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("pdf", io.Discard)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "user-001_2024-05.csv", FileName("user-001", "2024-05", model.StatementCSV))
+	assert.Equal(t, "___etc_passwd_2024-05.ofx", FileName("../etc/passwd", "2024-05", model.StatementOFX))
+	assert.Equal(t, "user-001_2024-05.xml", FileName("user-001", "2024-05", model.StatementCamt053))
+}
+
+func TestFormatAmount(t *testing.T) {
+	for cents, want := range map[int64]string{0: "0.00", 5: "0.05", -5: "-0.05", 123456: "1234.56", -100: "-1.00"} {
+		assert.Equal(t, want, formatAmount(cents))
+	}
+}
+
+// TestCamt053Writer validates the rendered document against the official
+// ISO 20022 camt.053.001.08 schema with xmllint.
+func TestCamt053Writer(t *testing.T) {
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint not installed")
+	}
+
+	for name, lines := range map[string][]model.StatementLine{"with_lines": testLines, "empty": nil} {
+		t.Run(name, func(t *testing.T) {
+			out := render(t, model.StatementCamt053, lines)
+
+			cmd := exec.Command(xmllint, "--noout", "--schema", filepath.Join("testdata", "camt.053.001.08.xsd"), "-")
+			cmd.Stdin = bytes.NewReader(out)
+			result, err := cmd.CombinedOutput()
+			require.NoError(t, err, "schema validation failed:\n%s\n%s", result, out)
+		})
+	}
+
+	var doc struct {
+		Statement struct {
+			Account  string `xml:"Acct>Id>Othr>Id"`
+			Balances []struct {
+				Type   string `xml:"Tp>CdOrPrtry>Cd"`
+				Amount struct {
+					Value    string `xml:",chardata"`
+					Currency string `xml:"Ccy,attr"`
+				} `xml:"Amt"`
+				Indicator string `xml:"CdtDbtInd"`
+			} `xml:"Bal"`
+			Entries []struct {
+				Ref       string `xml:"NtryRef"`
+				Amount    string `xml:"Amt"`
+				Indicator string `xml:"CdtDbtInd"`
+				Family    string `xml:"BkTxCd>Domn>Fmly>Cd"`
+				PmtInfID  string `xml:"NtryDtls>TxDtls>Refs>PmtInfId"`
+			} `xml:"Ntry"`
+			EntryCount string `xml:"TxsSummry>TtlNtries>NbOfNtries"`
+		} `xml:"BkToCstmrStmt>Stmt"`
+	}
+	require.NoError(t, xml.Unmarshal(render(t, model.StatementCamt053, testLines), &doc))
+
+	assert.Equal(t, "user-<001>", doc.Statement.Account)
+	require.Len(t, doc.Statement.Balances, 2)
+	assert.Equal(t, "OPBD", doc.Statement.Balances[0].Type)
+	assert.Equal(t, "100.00", doc.Statement.Balances[0].Amount.Value)
+	assert.Equal(t, "USD", doc.Statement.Balances[0].Amount.Currency)
+	assert.Equal(t, "CLBD", doc.Statement.Balances[1].Type)
+	assert.Equal(t, "130.05", doc.Statement.Balances[1].Amount.Value)
+	assert.Equal(t, "2", doc.Statement.EntryCount)
+	require.Len(t, doc.Statement.Entries, 2)
+	assert.Equal(t, "1", doc.Statement.Entries[0].Ref)
+	assert.Equal(t, "CRDT", doc.Statement.Entries[0].Indicator)
+	assert.Equal(t, "RCDT", doc.Statement.Entries[0].Family)
+	assert.Equal(t, "20.00", doc.Statement.Entries[1].Amount)
+	assert.Equal(t, "DBIT", doc.Statement.Entries[1].Indicator)
+	assert.Equal(t, "ICDT", doc.Statement.Entries[1].Family)
+	assert.Empty(t, doc.Statement.Entries[0].PmtInfID)
+	assert.Equal(t, "split-group-1", doc.Statement.Entries[1].PmtInfID)
+}
+
+func TestCamt053WriterSummaryMismatch(t *testing.T) {
+	w, err := NewWriter(model.StatementCamt053, io.Discard)
+	require.NoError(t, err)
+	assert.Error(t, w.WriteHeader(testHeader), "no summary")
+
+	w, err = NewWriter(model.StatementCamt053, io.Discard)
+	require.NoError(t, err)
+	w.(Summarizer).Summarize(summarize(testLines, testFooter))
+	require.NoError(t, w.WriteHeader(testHeader))
+	require.NoError(t, w.WriteLine(testLines[0]))
+	assert.ErrorIs(t, w.WriteFooter(testFooter), ErrStatementSummaryMismatch)
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "otel:span"
+
+type gormPlugin struct{}
+
+// NewGormPlugin returns a GORM plugin that wraps every query in a client span.
+func NewGormPlugin() gorm.Plugin {
+	return gormPlugin{}
+}
+
+// Name returns the plugin name.
+func (gormPlugin) Name() string {
+	return "otel-tracing"
+}
+
+// Initialize registers the span callbacks around each GORM operation.
+func (gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("otel:before_create", beforeGorm("create")),
+		cb.Create().After("gorm:create").Register("otel:after_create", afterGorm),
+		cb.Query().Before("gorm:query").Register("otel:before_query", beforeGorm("select")),
+		cb.Query().After("gorm:query").Register("otel:after_query", afterGorm),
+		cb.Update().Before("gorm:update").Register("otel:before_update", beforeGorm("update")),
+		cb.Update().After("gorm:update").Register("otel:after_update", afterGorm),
+		cb.Delete().Before("gorm:delete").Register("otel:before_delete", beforeGorm("delete")),
+		cb.Delete().After("gorm:delete").Register("otel:after_delete", afterGorm),
+		cb.Row().Before("gorm:row").Register("otel:before_row", beforeGorm("row")),
+		cb.Row().After("gorm:row").Register("otel:after_row", afterGorm),
+		cb.Raw().Before("gorm:raw").Register("otel:before_raw", beforeGorm("raw")),
+		cb.Raw().After("gorm:raw").Register("otel:after_raw", afterGorm),
+	)
+}
+
+func beforeGorm(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, span := Tracer().Start(tx.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func afterGorm(tx *gorm.DB) {
+	v, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBCollectionName(tx.Statement.Table),
+		semconv.DBQueryText(tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
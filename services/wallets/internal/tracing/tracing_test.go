@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmountBucket(t *testing.T) {
+	tests := []struct {
+		amount int64
+		want   string
+	}{
+		{0, "<1"},
+		{99, "<1"},
+		{100, "1-10"},
+		{999, "1-10"},
+		{5000, "10-100"},
+		{10000, "100-1k"},
+		{250000, "1k-10k"},
+		{1000000, ">=10k"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, AmountBucket(tt.amount), "amount %d", tt.amount)
+	}
+}
+
+func TestInit(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		shutdown, err := Init(model.Tracing{Enable: false})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("Unknown_exporter", func(t *testing.T) {
+		_, err := Init(model.Tracing{Enable: true, Exporter: "zipkin"})
+		assert.Error(t, err)
+	})
+
+	t.Run("File_exporter", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.json")
+		shutdown, err := Init(model.Tracing{Enable: true, Exporter: model.TraceExporterFile, FilePath: path})
+		require.NoError(t, err)
+
+		_, span := StartSpan(context.Background(), "test-span", AttrUserID.String("user-001"))
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "test-span")
+		assert.Contains(t, string(content), "user-001")
+	})
+}
@@ -0,0 +1,7 @@
+-- Optimistic Concurrency Control
+-- Adds the version column checked by optimistic balance updates
+-- Every balance change increments it, so a stale read fails the update instead of overwriting it
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN wallets.version IS 'Incremented on every balance change; used for optimistic concurrency control';
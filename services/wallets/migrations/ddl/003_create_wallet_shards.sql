@@ -0,0 +1,21 @@
+-- Provider Wallet Shards
+-- Sub-accounts that spread the balance of hot provider wallets over several rows
+-- A sharded wallet's balance is wallets.balance plus the sum of its shards
+
+CREATE TABLE IF NOT EXISTS wallet_shards (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    shard INTEGER NOT NULL,
+    balance BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_shards_wallet_shard ON wallet_shards(wallet_id, shard);
+
+-- Shards are debited with conditional updates, so each one must stay non-negative on its own
+ALTER TABLE wallet_shards DROP CONSTRAINT IF EXISTS chk_wallet_shards_balance;
+ALTER TABLE wallet_shards ADD CONSTRAINT chk_wallet_shards_balance CHECK (balance >= 0);
+
+COMMENT ON TABLE wallet_shards IS 'Balance sub-accounts of sharded provider wallets';
+COMMENT ON COLUMN wallet_shards.wallet_id IS 'Wallet the shard belongs to';
+COMMENT ON COLUMN wallet_shards.shard IS 'Shard index, from 0 to the configured shard count minus one';
+COMMENT ON COLUMN wallet_shards.balance IS 'Part of the wallet balance held by this shard, in cents';
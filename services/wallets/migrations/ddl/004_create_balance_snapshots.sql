@@ -0,0 +1,18 @@
+-- Balance Snapshots
+-- Periodic ledger balances of every wallet, written by the background snapshot job
+-- Historical balances are the latest snapshot before the requested time plus later ledger entries
+
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    balance BIGINT NOT NULL,
+    taken_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Lookups always ask for the latest snapshot of one wallet before a given time
+CREATE INDEX IF NOT EXISTS idx_balance_snapshots_wallet_taken_at ON balance_snapshots(wallet_id, taken_at);
+
+COMMENT ON TABLE balance_snapshots IS 'Point-in-time copies of wallet balances used for historical balance queries';
+COMMENT ON COLUMN balance_snapshots.wallet_id IS 'Wallet the balance belongs to';
+COMMENT ON COLUMN balance_snapshots.balance IS 'Ledger balance in cents at taken_at: credits minus debits of the completed entries created up to then';
+COMMENT ON COLUMN balance_snapshots.taken_at IS 'Time the snapshot was taken';
@@ -0,0 +1,31 @@
+-- Wallet Aliases
+-- Phone numbers, email addresses and @handles that can be used instead of a user ID to receive transfers
+-- Values are stored normalized (lower-cased, phone separators stripped) so the unique index catches duplicates
+
+CREATE TABLE IF NOT EXISTS wallet_aliases (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    display_name VARCHAR(255),
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_aliases_value ON wallet_aliases(value);
+CREATE INDEX IF NOT EXISTS idx_wallet_aliases_wallet_id ON wallet_aliases(wallet_id);
+
+ALTER TABLE wallet_aliases DROP CONSTRAINT IF EXISTS chk_wallet_aliases_type;
+ALTER TABLE wallet_aliases ADD CONSTRAINT chk_wallet_aliases_type CHECK (type IN ('phone', 'email', 'handle'));
+ALTER TABLE wallet_aliases DROP CONSTRAINT IF EXISTS chk_wallet_aliases_status;
+ALTER TABLE wallet_aliases ADD CONSTRAINT chk_wallet_aliases_status CHECK (status IN ('pending', 'verified'));
+
+COMMENT ON TABLE wallet_aliases IS 'Phone, email and handle aliases that resolve to a wallet';
+COMMENT ON COLUMN wallet_aliases.wallet_id IS 'Wallet the alias resolves to';
+COMMENT ON COLUMN wallet_aliases.type IS 'Alias type: phone, email or handle';
+COMMENT ON COLUMN wallet_aliases.value IS 'Normalized alias value, unique across all wallets';
+COMMENT ON COLUMN wallet_aliases.display_name IS 'Name shown, masked, to payers resolving the alias';
+COMMENT ON COLUMN wallet_aliases.status IS 'Verification status: pending or verified; only verified aliases resolve';
+COMMENT ON COLUMN wallet_aliases.verified_at IS 'Time ownership of the alias was confirmed';
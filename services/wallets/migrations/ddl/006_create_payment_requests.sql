@@ -0,0 +1,34 @@
+-- Payment Requests
+-- Peer-to-peer requests for money: the requester asks the payer for an amount
+-- A request starts pending and moves once to accepted, declined, cancelled or expired
+
+CREATE TABLE IF NOT EXISTS payment_requests (
+    id SERIAL PRIMARY KEY,
+    requester_id VARCHAR(255) NOT NULL,
+    payer_id VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL,
+    note TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_requests_requester_id ON payment_requests(requester_id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_payer_id ON payment_requests(payer_id);
+
+ALTER TABLE payment_requests DROP CONSTRAINT IF EXISTS chk_payment_requests_amount;
+ALTER TABLE payment_requests ADD CONSTRAINT chk_payment_requests_amount CHECK (amount > 0);
+ALTER TABLE payment_requests DROP CONSTRAINT IF EXISTS chk_payment_requests_status;
+ALTER TABLE payment_requests ADD CONSTRAINT chk_payment_requests_status CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled', 'expired'));
+ALTER TABLE payment_requests DROP CONSTRAINT IF EXISTS chk_payment_requests_parties;
+ALTER TABLE payment_requests ADD CONSTRAINT chk_payment_requests_parties CHECK (requester_id <> payer_id);
+
+COMMENT ON TABLE payment_requests IS 'Peer-to-peer payment requests and their state';
+COMMENT ON COLUMN payment_requests.requester_id IS 'User ID of the wallet asking for, and receiving, the money';
+COMMENT ON COLUMN payment_requests.payer_id IS 'User ID of the wallet asked to pay';
+COMMENT ON COLUMN payment_requests.amount IS 'Requested amount in cents';
+COMMENT ON COLUMN payment_requests.status IS 'pending, accepted, declined, cancelled or expired';
+COMMENT ON COLUMN payment_requests.expires_at IS 'Time after which a pending request can no longer be accepted';
+COMMENT ON COLUMN payment_requests.resolved_at IS 'Time the request left the pending state';
@@ -0,0 +1,45 @@
+-- Escrow
+-- Funds held between a buyer and a seller in a dedicated wallet of type escrow
+-- An agreement is funded once and settled once: released, refunded or split
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_acnt_type_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_acnt_type_check CHECK (acnt_type IN ('user', 'provider', 'escrow'));
+COMMENT ON COLUMN wallets.acnt_type IS 'Account type: user, provider or escrow';
+
+CREATE TABLE IF NOT EXISTS escrow_agreements (
+    id SERIAL PRIMARY KEY,
+    wallet_user_id VARCHAR(255) NOT NULL,
+    buyer_id VARCHAR(255) NOT NULL,
+    seller_id VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL,
+    description TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'funded',
+    deadline TIMESTAMP WITH TIME ZONE NOT NULL,
+    released_amount BIGINT NOT NULL DEFAULT 0,
+    refunded_amount BIGINT NOT NULL DEFAULT 0,
+    settled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_escrow_agreements_wallet_user_id ON escrow_agreements(wallet_user_id);
+CREATE INDEX IF NOT EXISTS idx_escrow_agreements_buyer_id ON escrow_agreements(buyer_id);
+CREATE INDEX IF NOT EXISTS idx_escrow_agreements_seller_id ON escrow_agreements(seller_id);
+CREATE INDEX IF NOT EXISTS idx_escrow_agreements_status ON escrow_agreements(status);
+
+ALTER TABLE escrow_agreements DROP CONSTRAINT IF EXISTS chk_escrow_agreements_amounts;
+ALTER TABLE escrow_agreements ADD CONSTRAINT chk_escrow_agreements_amounts
+    CHECK (amount > 0 AND released_amount >= 0 AND refunded_amount >= 0 AND released_amount + refunded_amount <= amount);
+ALTER TABLE escrow_agreements DROP CONSTRAINT IF EXISTS chk_escrow_agreements_status;
+ALTER TABLE escrow_agreements ADD CONSTRAINT chk_escrow_agreements_status CHECK (status IN ('funded', 'released', 'refunded', 'split'));
+
+COMMENT ON TABLE escrow_agreements IS 'Funds held in escrow between a buyer and a seller';
+COMMENT ON COLUMN escrow_agreements.wallet_user_id IS 'User ID of the escrow wallet holding the funds';
+COMMENT ON COLUMN escrow_agreements.buyer_id IS 'User ID of the wallet that funded the escrow';
+COMMENT ON COLUMN escrow_agreements.seller_id IS 'User ID of the wallet the escrow is released to';
+COMMENT ON COLUMN escrow_agreements.amount IS 'Escrowed amount in cents';
+COMMENT ON COLUMN escrow_agreements.status IS 'funded, released, refunded or split';
+COMMENT ON COLUMN escrow_agreements.deadline IS 'Time at which a still funded escrow is refunded to the buyer';
+COMMENT ON COLUMN escrow_agreements.released_amount IS 'Amount paid out to the seller, in cents';
+COMMENT ON COLUMN escrow_agreements.refunded_amount IS 'Amount returned to the buyer, in cents';
+COMMENT ON COLUMN escrow_agreements.settled_at IS 'Time the escrow was released, refunded or split';
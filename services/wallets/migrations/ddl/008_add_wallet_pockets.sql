@@ -0,0 +1,21 @@
+-- Pockets
+-- Named sub-wallets of a user wallet. A pocket is a wallet of type pocket with a
+-- generated user ID, linked to its owner by parent_id; names are unique per owner
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_acnt_type_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_acnt_type_check CHECK (acnt_type IN ('user', 'provider', 'escrow', 'pocket'));
+COMMENT ON COLUMN wallets.acnt_type IS 'Account type: user, provider, escrow or pocket';
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS parent_id INTEGER;
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS pocket_name VARCHAR(30) NOT NULL DEFAULT '';
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS fk_wallets_parent;
+ALTER TABLE wallets ADD CONSTRAINT fk_wallets_parent FOREIGN KEY (parent_id) REFERENCES wallets(id);
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS chk_wallets_pocket;
+ALTER TABLE wallets ADD CONSTRAINT chk_wallets_pocket
+    CHECK ((acnt_type = 'pocket') = (parent_id IS NOT NULL AND pocket_name <> ''));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_parent_pocket ON wallets(parent_id, pocket_name);
+
+COMMENT ON COLUMN wallets.parent_id IS 'ID of the user wallet owning a pocket; NULL for other wallets';
+COMMENT ON COLUMN wallets.pocket_name IS 'Name of a pocket, unique per owner; empty for other wallets';
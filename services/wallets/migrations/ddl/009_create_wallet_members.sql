@@ -0,0 +1,89 @@
+-- Shared Wallets
+-- Members other than the holder authorized on a wallet as owner, spender or viewer,
+-- and the transfers and withdrawals above the wallet's approval threshold held until
+-- enough owners approve them
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS approval_threshold BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS required_approvals INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS chk_wallets_approval_policy;
+ALTER TABLE wallets ADD CONSTRAINT chk_wallets_approval_policy CHECK (approval_threshold >= 0 AND required_approvals >= 0);
+
+COMMENT ON COLUMN wallets.approval_threshold IS 'Transfers and withdrawals above this amount in cents need approval';
+COMMENT ON COLUMN wallets.required_approvals IS 'Number of owners who must approve a transfer or withdrawal above the threshold; 0 turns approvals off';
+
+CREATE TABLE IF NOT EXISTS wallet_members (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    member_id VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    spend_limit BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_members_wallet_member ON wallet_members(wallet_id, member_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_members_member_id ON wallet_members(member_id);
+
+ALTER TABLE wallet_members DROP CONSTRAINT IF EXISTS fk_wallet_members_wallet;
+ALTER TABLE wallet_members ADD CONSTRAINT fk_wallet_members_wallet FOREIGN KEY (wallet_id) REFERENCES wallets(id);
+ALTER TABLE wallet_members DROP CONSTRAINT IF EXISTS chk_wallet_members_role;
+ALTER TABLE wallet_members ADD CONSTRAINT chk_wallet_members_role CHECK (role IN ('owner', 'spender', 'viewer'));
+ALTER TABLE wallet_members DROP CONSTRAINT IF EXISTS chk_wallet_members_spend_limit;
+ALTER TABLE wallet_members ADD CONSTRAINT chk_wallet_members_spend_limit CHECK (spend_limit >= 0);
+
+COMMENT ON TABLE wallet_members IS 'Users other than the holder authorized on a shared wallet';
+COMMENT ON COLUMN wallet_members.member_id IS 'User ID of the member';
+COMMENT ON COLUMN wallet_members.role IS 'owner, spender or viewer';
+COMMENT ON COLUMN wallet_members.spend_limit IS 'Largest transfer or withdrawal of a spender in cents; 0 is unlimited';
+
+CREATE TABLE IF NOT EXISTS wallet_approvals (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    wallet_user_id VARCHAR(255) NOT NULL,
+    transaction_type VARCHAR(50) NOT NULL,
+    initiated_by VARCHAR(255) NOT NULL,
+    to_user_id VARCHAR(255),
+    provider_id VARCHAR(255),
+    amount BIGINT NOT NULL,
+    required_approvals INTEGER NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    reason TEXT,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_approvals_wallet_id ON wallet_approvals(wallet_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_approvals_status ON wallet_approvals(status);
+
+ALTER TABLE wallet_approvals DROP CONSTRAINT IF EXISTS chk_wallet_approvals_transaction_type;
+ALTER TABLE wallet_approvals ADD CONSTRAINT chk_wallet_approvals_transaction_type CHECK (transaction_type IN ('transfer', 'withdraw'));
+ALTER TABLE wallet_approvals DROP CONSTRAINT IF EXISTS chk_wallet_approvals_status;
+ALTER TABLE wallet_approvals ADD CONSTRAINT chk_wallet_approvals_status CHECK (status IN ('pending', 'approved', 'executed', 'rejected', 'failed'));
+ALTER TABLE wallet_approvals DROP CONSTRAINT IF EXISTS chk_wallet_approvals_amount;
+ALTER TABLE wallet_approvals ADD CONSTRAINT chk_wallet_approvals_amount CHECK (amount > 0 AND required_approvals > 0);
+
+COMMENT ON TABLE wallet_approvals IS 'Transfers and withdrawals from shared wallets held for approval';
+COMMENT ON COLUMN wallet_approvals.wallet_user_id IS 'User ID of the wallet the funds move out of';
+COMMENT ON COLUMN wallet_approvals.initiated_by IS 'User ID of the holder or member who requested the operation';
+COMMENT ON COLUMN wallet_approvals.to_user_id IS 'Receiver of a transfer';
+COMMENT ON COLUMN wallet_approvals.provider_id IS 'Provider of a withdrawal';
+COMMENT ON COLUMN wallet_approvals.required_approvals IS 'Number of owner approvals required, fixed when the operation was requested';
+COMMENT ON COLUMN wallet_approvals.status IS 'pending, approved, executed, rejected or failed';
+COMMENT ON COLUMN wallet_approvals.reason IS 'Why an approved operation could not be carried out';
+
+CREATE TABLE IF NOT EXISTS wallet_approval_votes (
+    id SERIAL PRIMARY KEY,
+    approval_id INTEGER NOT NULL,
+    member_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_approval_votes_approval_member ON wallet_approval_votes(approval_id, member_id);
+
+ALTER TABLE wallet_approval_votes DROP CONSTRAINT IF EXISTS fk_wallet_approval_votes_approval;
+ALTER TABLE wallet_approval_votes ADD CONSTRAINT fk_wallet_approval_votes_approval FOREIGN KEY (approval_id) REFERENCES wallet_approvals(id);
+
+COMMENT ON TABLE wallet_approval_votes IS 'Owner approvals of held transfers and withdrawals';
+COMMENT ON COLUMN wallet_approval_votes.member_id IS 'User ID of the approving owner, the holder or an owner member';
@@ -0,0 +1,81 @@
+-- Merchants
+-- Business wallets with a profile, taking payments through payment intents a customer
+-- confirms from their wallet, and refunding them up to the amount paid
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_acnt_type_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_acnt_type_check CHECK (acnt_type IN ('user', 'provider', 'escrow', 'pocket', 'merchant'));
+COMMENT ON COLUMN wallets.acnt_type IS 'Account type: user, provider, escrow, pocket or merchant';
+
+CREATE TABLE IF NOT EXISTS merchant_profiles (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    display_name VARCHAR(100) NOT NULL,
+    category VARCHAR(50),
+    website VARCHAR(255),
+    support_email VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchant_profiles_wallet_id ON merchant_profiles(wallet_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchant_profiles_user_id ON merchant_profiles(user_id);
+
+ALTER TABLE merchant_profiles DROP CONSTRAINT IF EXISTS fk_merchant_profiles_wallet;
+ALTER TABLE merchant_profiles ADD CONSTRAINT fk_merchant_profiles_wallet FOREIGN KEY (wallet_id) REFERENCES wallets(id);
+
+COMMENT ON TABLE merchant_profiles IS 'Businesses behind merchant wallets';
+COMMENT ON COLUMN merchant_profiles.user_id IS 'User ID of the merchant wallet';
+
+CREATE TABLE IF NOT EXISTS payment_intents (
+    id SERIAL PRIMARY KEY,
+    merchant_id VARCHAR(255) NOT NULL,
+    reference VARCHAR(100) NOT NULL,
+    amount BIGINT NOT NULL,
+    description TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    customer_id VARCHAR(255),
+    refunded_amount BIGINT NOT NULL DEFAULT 0,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_intents_merchant_reference ON payment_intents(merchant_id, reference);
+CREATE INDEX IF NOT EXISTS idx_payment_intents_status ON payment_intents(status);
+CREATE INDEX IF NOT EXISTS idx_payment_intents_customer_id ON payment_intents(customer_id);
+CREATE INDEX IF NOT EXISTS idx_payment_intents_paid_at ON payment_intents(paid_at);
+
+ALTER TABLE payment_intents DROP CONSTRAINT IF EXISTS chk_payment_intents_status;
+ALTER TABLE payment_intents ADD CONSTRAINT chk_payment_intents_status CHECK (status IN ('pending', 'succeeded', 'cancelled'));
+ALTER TABLE payment_intents DROP CONSTRAINT IF EXISTS chk_payment_intents_amounts;
+ALTER TABLE payment_intents ADD CONSTRAINT chk_payment_intents_amounts CHECK (amount > 0 AND refunded_amount >= 0 AND refunded_amount <= amount);
+
+COMMENT ON TABLE payment_intents IS 'Merchant requests to be paid for an order, confirmed by a customer';
+COMMENT ON COLUMN payment_intents.merchant_id IS 'User ID of the merchant wallet paid';
+COMMENT ON COLUMN payment_intents.reference IS 'Merchant order reference, unique per merchant';
+COMMENT ON COLUMN payment_intents.status IS 'pending, succeeded or cancelled';
+COMMENT ON COLUMN payment_intents.customer_id IS 'User ID of the wallet that paid the intent';
+COMMENT ON COLUMN payment_intents.refunded_amount IS 'Part of the amount refunded to the customer in cents';
+
+CREATE TABLE IF NOT EXISTS payment_refunds (
+    id SERIAL PRIMARY KEY,
+    payment_intent_id INTEGER NOT NULL,
+    merchant_id VARCHAR(255) NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_payment_intent_id ON payment_refunds(payment_intent_id);
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_merchant_created_at ON payment_refunds(merchant_id, created_at);
+
+ALTER TABLE payment_refunds DROP CONSTRAINT IF EXISTS fk_payment_refunds_payment_intent;
+ALTER TABLE payment_refunds ADD CONSTRAINT fk_payment_refunds_payment_intent FOREIGN KEY (payment_intent_id) REFERENCES payment_intents(id);
+ALTER TABLE payment_refunds DROP CONSTRAINT IF EXISTS chk_payment_refunds_amount;
+ALTER TABLE payment_refunds ADD CONSTRAINT chk_payment_refunds_amount CHECK (amount > 0);
+
+COMMENT ON TABLE payment_refunds IS 'Merchant refunds of paid payment intents';
+COMMENT ON COLUMN payment_refunds.customer_id IS 'User ID of the wallet refunded';
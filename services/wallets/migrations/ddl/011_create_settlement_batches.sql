@@ -0,0 +1,49 @@
+-- Provider settlement
+-- Daily batches netting the unsettled deposits and withdrawals of every provider wallet,
+-- with the ledger entries each batch includes
+
+CREATE TABLE IF NOT EXISTS settlement_batches (
+    id SERIAL PRIMARY KEY,
+    provider_id VARCHAR(255) NOT NULL,
+    settlement_date VARCHAR(10) NOT NULL,
+    cutoff TIMESTAMP WITH TIME ZONE NOT NULL,
+    deposit_count INTEGER NOT NULL DEFAULT 0,
+    deposits_amount BIGINT NOT NULL DEFAULT 0,
+    withdrawal_count INTEGER NOT NULL DEFAULT 0,
+    withdrawals_amount BIGINT NOT NULL DEFAULT 0,
+    net_amount BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    settled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_settlement_batches_provider_date ON settlement_batches(provider_id, settlement_date);
+CREATE INDEX IF NOT EXISTS idx_settlement_batches_settlement_date ON settlement_batches(settlement_date);
+CREATE INDEX IF NOT EXISTS idx_settlement_batches_status ON settlement_batches(status);
+
+ALTER TABLE settlement_batches DROP CONSTRAINT IF EXISTS chk_settlement_batches_status;
+ALTER TABLE settlement_batches ADD CONSTRAINT chk_settlement_batches_status CHECK (status IN ('pending', 'settled'));
+
+COMMENT ON TABLE settlement_batches IS 'Daily net settlement of a provider wallet';
+COMMENT ON COLUMN settlement_batches.settlement_date IS 'UTC day settled (YYYY-MM-DD)';
+COMMENT ON COLUMN settlement_batches.cutoff IS 'Entries created before this time are included';
+COMMENT ON COLUMN settlement_batches.net_amount IS 'Deposits less withdrawals in cents; positive is owed by the provider to the platform';
+COMMENT ON COLUMN settlement_batches.status IS 'pending until the entries are marked as settled in the transactions service, then settled';
+
+CREATE TABLE IF NOT EXISTS settlement_entries (
+    id SERIAL PRIMARY KEY,
+    batch_id INTEGER NOT NULL,
+    transaction_id INTEGER NOT NULL,
+    transaction_type VARCHAR(50) NOT NULL,
+    amount BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlement_entries_batch_id ON settlement_entries(batch_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_settlement_entries_transaction_id ON settlement_entries(transaction_id);
+
+ALTER TABLE settlement_entries DROP CONSTRAINT IF EXISTS fk_settlement_entries_batch;
+ALTER TABLE settlement_entries ADD CONSTRAINT fk_settlement_entries_batch FOREIGN KEY (batch_id) REFERENCES settlement_batches(id);
+
+COMMENT ON TABLE settlement_entries IS 'Provider ledger entries included in a settlement batch';
+COMMENT ON COLUMN settlement_entries.transaction_id IS 'ID of the entry in the transactions service; in at most one batch';
@@ -0,0 +1,40 @@
+-- Provider profiles
+-- Metadata of the external payment providers behind provider wallets: the bank holding
+-- their funds, their fees, the currencies they support and when their float runs low
+
+CREATE TABLE IF NOT EXISTS provider_profiles (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    external_bank VARCHAR(100) NOT NULL DEFAULT '',
+    external_account VARCHAR(34) NOT NULL DEFAULT '',
+    fee_deposit_bps INTEGER NOT NULL DEFAULT 0,
+    fee_withdrawal_bps INTEGER NOT NULL DEFAULT 0,
+    fee_fixed BIGINT NOT NULL DEFAULT 0,
+    supported_currencies TEXT,
+    low_float_threshold BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_profiles_wallet_id ON provider_profiles(wallet_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_profiles_user_id ON provider_profiles(user_id);
+
+ALTER TABLE provider_profiles DROP CONSTRAINT IF EXISTS fk_provider_profiles_wallet;
+ALTER TABLE provider_profiles ADD CONSTRAINT fk_provider_profiles_wallet FOREIGN KEY (wallet_id) REFERENCES wallets(id);
+ALTER TABLE provider_profiles DROP CONSTRAINT IF EXISTS chk_provider_profiles_fees;
+ALTER TABLE provider_profiles ADD CONSTRAINT chk_provider_profiles_fees CHECK (
+    fee_deposit_bps BETWEEN 0 AND 10000 AND fee_withdrawal_bps BETWEEN 0 AND 10000 AND fee_fixed >= 0
+);
+ALTER TABLE provider_profiles DROP CONSTRAINT IF EXISTS chk_provider_profiles_low_float_threshold;
+ALTER TABLE provider_profiles ADD CONSTRAINT chk_provider_profiles_low_float_threshold CHECK (low_float_threshold >= 0);
+
+COMMENT ON TABLE provider_profiles IS 'External payment providers behind provider wallets; wallets without a profile use the defaults';
+COMMENT ON COLUMN provider_profiles.user_id IS 'User ID of the provider wallet';
+COMMENT ON COLUMN provider_profiles.external_bank IS 'Bank holding the provider funds';
+COMMENT ON COLUMN provider_profiles.fee_deposit_bps IS 'Fee on each deposit in basis points';
+COMMENT ON COLUMN provider_profiles.fee_withdrawal_bps IS 'Fee on each withdrawal in basis points';
+COMMENT ON COLUMN provider_profiles.fee_fixed IS 'Flat fee per operation in cents';
+COMMENT ON COLUMN provider_profiles.supported_currencies IS 'JSON array of ISO 4217 currency codes';
+COMMENT ON COLUMN provider_profiles.low_float_threshold IS 'Balance in cents below which the provider is low on float; 0 uses the configured default';
@@ -0,0 +1,14 @@
+-- Wallet closure
+-- Closed wallets have their balance swept to a withdrawal provider or another wallet.
+-- Their history stays readable, but no money moves in or out until an admin reopens them
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_status_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_status_check CHECK (status IN ('active', 'inactive', 'suspended', 'closed'));
+COMMENT ON COLUMN wallets.status IS 'Wallet status: active, inactive, suspended or closed';
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS chk_wallets_closed_at;
+ALTER TABLE wallets ADD CONSTRAINT chk_wallets_closed_at CHECK ((status = 'closed') = (closed_at IS NOT NULL));
+
+COMMENT ON COLUMN wallets.closed_at IS 'When the wallet was closed; set only while the status is closed';
@@ -0,0 +1,43 @@
+-- KYC tiers
+-- The KYC level of each wallet, which sets its maximum balance, largest single transfer
+-- and whether it may withdraw, and the verifications submitted to raise it
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS kyc_level VARCHAR(20) NOT NULL DEFAULT 'none';
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS chk_wallets_kyc_level;
+ALTER TABLE wallets ADD CONSTRAINT chk_wallets_kyc_level CHECK (kyc_level IN ('none', 'basic', 'full'));
+
+COMMENT ON COLUMN wallets.kyc_level IS 'How far the holder is verified: none, basic or full';
+
+CREATE TABLE IF NOT EXISTS kyc_verifications (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    level VARCHAR(20) NOT NULL,
+    documents TEXT,
+    status VARCHAR(50) NOT NULL,
+    reviewer VARCHAR(255),
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_verifications_wallet_id ON kyc_verifications(wallet_id);
+CREATE INDEX IF NOT EXISTS idx_kyc_verifications_status ON kyc_verifications(status);
+-- At most one verification of a wallet awaits review at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_kyc_verifications_pending ON kyc_verifications(wallet_id) WHERE status = 'pending';
+
+ALTER TABLE kyc_verifications DROP CONSTRAINT IF EXISTS fk_kyc_verifications_wallet;
+ALTER TABLE kyc_verifications ADD CONSTRAINT fk_kyc_verifications_wallet FOREIGN KEY (wallet_id) REFERENCES wallets(id);
+ALTER TABLE kyc_verifications DROP CONSTRAINT IF EXISTS chk_kyc_verifications_level;
+ALTER TABLE kyc_verifications ADD CONSTRAINT chk_kyc_verifications_level CHECK (level IN ('basic', 'full'));
+ALTER TABLE kyc_verifications DROP CONSTRAINT IF EXISTS chk_kyc_verifications_status;
+ALTER TABLE kyc_verifications ADD CONSTRAINT chk_kyc_verifications_status CHECK (status IN ('pending', 'approved', 'rejected'));
+ALTER TABLE kyc_verifications DROP CONSTRAINT IF EXISTS chk_kyc_verifications_reviewed;
+ALTER TABLE kyc_verifications ADD CONSTRAINT chk_kyc_verifications_reviewed CHECK ((status = 'pending') = (reviewed_at IS NULL));
+
+COMMENT ON TABLE kyc_verifications IS 'Requests to verify the holder of a user wallet to a KYC level';
+COMMENT ON COLUMN kyc_verifications.level IS 'Level applied for: basic or full';
+COMMENT ON COLUMN kyc_verifications.documents IS 'JSON array of document metadata: type, number, country and expiry';
+COMMENT ON COLUMN kyc_verifications.reviewer IS 'Who approved or rejected the verification';
@@ -0,0 +1,44 @@
+-- Balance adjustments
+-- Manual corrections of wallet balances against the adjustments provider wallet, proposed by
+-- one operator and approved or rejected by another (maker-checker)
+
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    operation_type VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL,
+    reason TEXT NOT NULL,
+    evidence TEXT,
+    status VARCHAR(50) NOT NULL,
+    proposed_by VARCHAR(255) NOT NULL,
+    reviewer VARCHAR(255),
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_wallet_id ON balance_adjustments(wallet_id);
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_status ON balance_adjustments(status);
+
+ALTER TABLE balance_adjustments DROP CONSTRAINT IF EXISTS fk_balance_adjustments_wallet;
+ALTER TABLE balance_adjustments ADD CONSTRAINT fk_balance_adjustments_wallet FOREIGN KEY (wallet_id) REFERENCES wallets(id);
+ALTER TABLE balance_adjustments DROP CONSTRAINT IF EXISTS chk_balance_adjustments_operation_type;
+ALTER TABLE balance_adjustments ADD CONSTRAINT chk_balance_adjustments_operation_type CHECK (operation_type IN ('credit', 'debit'));
+ALTER TABLE balance_adjustments DROP CONSTRAINT IF EXISTS chk_balance_adjustments_amount;
+ALTER TABLE balance_adjustments ADD CONSTRAINT chk_balance_adjustments_amount CHECK (amount > 0);
+ALTER TABLE balance_adjustments DROP CONSTRAINT IF EXISTS chk_balance_adjustments_status;
+ALTER TABLE balance_adjustments ADD CONSTRAINT chk_balance_adjustments_status CHECK (status IN ('pending', 'approved', 'rejected'));
+ALTER TABLE balance_adjustments DROP CONSTRAINT IF EXISTS chk_balance_adjustments_reviewed;
+ALTER TABLE balance_adjustments ADD CONSTRAINT chk_balance_adjustments_reviewed CHECK ((status = 'pending') = (reviewed_at IS NULL));
+-- Maker-checker: the operator proposing an adjustment cannot review it
+ALTER TABLE balance_adjustments DROP CONSTRAINT IF EXISTS chk_balance_adjustments_reviewer;
+ALTER TABLE balance_adjustments ADD CONSTRAINT chk_balance_adjustments_reviewer CHECK (reviewer IS NULL OR reviewer <> proposed_by);
+
+COMMENT ON TABLE balance_adjustments IS 'Manual corrections of wallet balances, proposed by one operator and reviewed by another';
+COMMENT ON COLUMN balance_adjustments.operation_type IS 'Credit or debit of the wallet';
+COMMENT ON COLUMN balance_adjustments.amount IS 'Amount in cents';
+COMMENT ON COLUMN balance_adjustments.evidence IS 'JSON array of references backing the correction: tickets, statements, files';
+COMMENT ON COLUMN balance_adjustments.proposed_by IS 'Operator who proposed the adjustment';
+COMMENT ON COLUMN balance_adjustments.reviewer IS 'Operator who approved or rejected the adjustment';
@@ -0,0 +1,11 @@
+-- Alias challenges
+-- Phone numbers and email addresses are verified with a six digit code sent to them; only the
+-- hash of the code is kept, and a challenge is void after 15 minutes or 5 wrong codes
+
+ALTER TABLE wallet_aliases ADD COLUMN IF NOT EXISTS challenge_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE wallet_aliases ADD COLUMN IF NOT EXISTS challenge_sent_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE wallet_aliases ADD COLUMN IF NOT EXISTS challenge_attempts INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN wallet_aliases.challenge_hash IS 'SHA-256 of the alias and the verification code sent to it; empty once verified';
+COMMENT ON COLUMN wallet_aliases.challenge_sent_at IS 'Time the latest verification code was sent';
+COMMENT ON COLUMN wallet_aliases.challenge_attempts IS 'Wrong codes given for the latest verification code';
@@ -0,0 +1,7 @@
+-- Escrow arbiter
+-- An optional third party who can release, refund or split an escrow to settle a dispute;
+-- without one only the buyer can release and only the seller refund
+
+ALTER TABLE escrow_agreements ADD COLUMN IF NOT EXISTS arbiter_id VARCHAR(255) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN escrow_agreements.arbiter_id IS 'User ID of the arbiter who can release, refund or split the escrow; empty if none';
@@ -0,0 +1,21 @@
+-- Member spend windows
+-- A spender's limit caps what it transfers and withdraws in total per day, week or
+-- month rather than per operation, and a spender without a limit must be explicitly
+-- unlimited
+
+ALTER TABLE wallet_members ADD COLUMN IF NOT EXISTS unlimited_spend BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE wallet_members ADD COLUMN IF NOT EXISTS spend_window VARCHAR(50) NOT NULL DEFAULT 'day';
+ALTER TABLE wallet_members ADD COLUMN IF NOT EXISTS window_spent BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE wallet_members ADD COLUMN IF NOT EXISTS window_start TIMESTAMP WITH TIME ZONE;
+
+-- Spenders added when a spend limit of 0 meant unlimited keep spending without limit
+UPDATE wallet_members SET unlimited_spend = TRUE WHERE role = 'spender' AND spend_limit = 0 AND NOT unlimited_spend;
+
+ALTER TABLE wallet_members DROP CONSTRAINT IF EXISTS chk_wallet_members_spend_window;
+ALTER TABLE wallet_members ADD CONSTRAINT chk_wallet_members_spend_window CHECK (spend_window IN ('day', 'week', 'month') AND window_spent >= 0);
+
+COMMENT ON COLUMN wallet_members.spend_limit IS 'Most a spender may transfer and withdraw in total per spend window, in cents';
+COMMENT ON COLUMN wallet_members.unlimited_spend IS 'Whether a spender may spend without limit';
+COMMENT ON COLUMN wallet_members.spend_window IS 'day, week or month, in UTC, over which spending is added up';
+COMMENT ON COLUMN wallet_members.window_spent IS 'Amount spent in the window starting at window_start, in cents';
+COMMENT ON COLUMN wallet_members.window_start IS 'Start of the window of window_spent';
@@ -0,0 +1,11 @@
+-- Closure approvals
+-- Closing a shared wallet that requires approvals is held like a transfer or a
+-- withdrawal above its threshold; the amount is the balance when it was requested
+
+ALTER TABLE wallet_approvals DROP CONSTRAINT IF EXISTS chk_wallet_approvals_transaction_type;
+ALTER TABLE wallet_approvals ADD CONSTRAINT chk_wallet_approvals_transaction_type CHECK (transaction_type IN ('transfer', 'withdraw', 'closure'));
+ALTER TABLE wallet_approvals DROP CONSTRAINT IF EXISTS chk_wallet_approvals_amount;
+ALTER TABLE wallet_approvals ADD CONSTRAINT chk_wallet_approvals_amount CHECK ((amount > 0 OR (transaction_type = 'closure' AND amount = 0)) AND required_approvals > 0);
+
+COMMENT ON COLUMN wallet_approvals.to_user_id IS 'Receiver of a transfer or of the sweep of a closure';
+COMMENT ON COLUMN wallet_approvals.provider_id IS 'Provider of a withdrawal or of the sweep of a closure';
@@ -0,0 +1,12 @@
+-- Approvals carried out atomically
+-- An approved operation is marked executed in the database transaction that
+-- moves its funds, so it no longer waits in 'approved' while it is carried
+-- out. Operations left there by an interrupted run are marked failed, for an
+-- operator to check against the ledger
+
+UPDATE wallet_approvals
+SET status = 'failed', reason = 'interrupted while carried out; check the ledger', resolved_at = NOW()
+WHERE status = 'approved';
+
+ALTER TABLE wallet_approvals DROP CONSTRAINT IF EXISTS chk_wallet_approvals_status;
+ALTER TABLE wallet_approvals ADD CONSTRAINT chk_wallet_approvals_status CHECK (status IN ('pending', 'executed', 'rejected', 'failed'));
@@ -0,0 +1,11 @@
+-- Escrow refund attempts
+-- The automatic refund of an expired escrow is refused while the buyer's wallet
+-- is closed, suspended or at its ceiling; refusals are counted so the expiry
+-- job stops retrying after escrowExpiry.maxRefundAttempts and leaves the escrow
+-- funded for an operator
+
+ALTER TABLE escrow_agreements ADD COLUMN IF NOT EXISTS refund_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE escrow_agreements ADD COLUMN IF NOT EXISTS refund_error TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN escrow_agreements.refund_attempts IS 'Automatic refunds after the deadline refused by the buyer''s wallet';
+COMMENT ON COLUMN escrow_agreements.refund_error IS 'Why the last automatic refund was refused';
@@ -20,6 +20,13 @@ INSERT INTO wallets (user_id, acnt_type, balance, status, created_at, updated_at
 VALUES ('withdraw-provider-master', 'provider', 0, 'active', NOW(), NOW())
 ON CONFLICT (user_id) DO NOTHING;
 
+-- Insert adjustments provider wallet
+-- This wallet is the counterparty of manual balance corrections made by operators
+-- It has a large balance so that credits to wallets can always be booked against it
+INSERT INTO wallets (user_id, acnt_type, balance, status, created_at, updated_at)
+VALUES ('adjustment-provider-master', 'provider', 999999999999, 'active', NOW(), NOW())
+ON CONFLICT (user_id) DO NOTHING;
+
 -- =============================================================================
 -- SAMPLE USER WALLETS (FOR TESTING AND DEMONSTRATION)
 -- =============================================================================